@@ -109,6 +109,14 @@ TEXT	foo(SB), DUPOK|NOSPLIT, $-8
 	FMOVD	F1, 1(R2) // 411000fc
 	FMOVD	F1, 8(R2) // 410400fd
 
+	// offset between 256 and 504 that is not a multiple of 8,
+	// too large for the unscaled form, scaled if aligned
+	MOVB	257(R1), R2 // 22048439
+	MOVH	258(R1), R2 // 22048279
+	MOVW	260(R1), R2 // 220481b9
+	MOVH	R1, 258(R2) // 41040279
+	MOVW	R1, 260(R2) // 410401b9
+
 	// large aligned offset, use two instructions
 	MOVB	0x1001(R1), R2 // MOVB	4097(R1), R2  // 3b04409162078039
 	MOVH	0x2002(R1), R2 // MOVH	8194(R1), R2  // 3b08409162078079
@@ -138,6 +146,14 @@ TEXT	foo(SB), DUPOK|NOSPLIT, $-8
 	MOVD	R1, 0x44332211(R2) // MOVD	R1, 1144201745(R2)
 	FMOVS	F1, 0x44332211(R2) // FMOVS	F1, 1144201745(R2)
 	FMOVD	F1, 0x44332211(R2) // FMOVD	F1, 1144201745(R2)
+	MOVH	257(R1), R2
+	MOVW	258(R1), R2
+	MOVD	345(R1), R2
+	FMOVD	260(R1), F2
+	MOVH	R1, 257(R2)
+	MOVW	R1, 258(R2)
+	MOVD	R1, 345(R2)
+	FMOVD	F1, 260(R2)
 
 //
 // MOVK
@@ -0,0 +1,148 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLine(t *testing.T) {
+	for _, tt := range []struct {
+		line string
+		name string
+		vals []measurement
+	}{
+		{"BenchmarkEncode-8   \t     100\t  13401231 ns/op\t 144.82 MB/s", "Encode-8", []measurement{{13401231, "ns/op"}, {144.82, "MB/s"}}},
+		{"BenchmarkSort/size=10 2000000 812 ns/op 41.5 compares/op", "Sort/size=10", []measurement{{812, "ns/op"}, {41.5, "compares/op"}}},
+		{"Benchmark_x 10 1.5 ns/op", "_x", []measurement{{1.5, "ns/op"}}},
+		{"Benchmarks 10 1 ns/op", "", nil},
+		{"Benchmark 10 1 ns/op", "", nil},
+		{"BenchmarkX-8 \t10", "", nil},
+		{"BenchmarkX-8 ten 1 ns/op", "", nil},
+		{"BenchmarkX-8 10 1 ns/op 2", "", nil},
+		{"BenchmarkX-8 10 fast ns/op", "", nil},
+		{"--- BENCH: BenchmarkX-8", "", nil},
+		{"ok  \tencoding/json\t12.345s", "", nil},
+	} {
+		name, vals, ok := parseLine(tt.line)
+		if ok != (tt.vals != nil) || name != tt.name || !reflect.DeepEqual(vals, tt.vals) {
+			t.Errorf("parseLine(%q) = %q, %v, %v, want %q, %v, %v", tt.line, name, vals, ok, tt.name, tt.vals, tt.vals != nil)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	s := summarize([]float64{12, 10, 11, 9, 10, 100})
+	if want := []float64{9, 10, 10, 11, 12}; !reflect.DeepEqual(s.values, want) {
+		t.Errorf("values without outliers = %v, want %v", s.values, want)
+	}
+	if s.mean != 10.4 || s.min != 9 || s.max != 12 {
+		t.Errorf("mean, min, max = %v, %v, %v, want 10.4, 9, 12", s.mean, s.min, s.max)
+	}
+	if v := s.variation(); math.Abs(v-1.6/10.4*100) > 1e-9 {
+		t.Errorf("variation = %v, want %v", v, 1.6/10.4*100)
+	}
+}
+
+func TestUTest(t *testing.T) {
+	for _, tt := range []struct {
+		x, y []float64
+		p    float64
+	}{
+		// Exact distribution of U.
+		{[]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}, 0.007937},
+		{[]float64{6, 7, 8, 9, 10}, []float64{1, 2, 3, 4, 5}, 0.007937},
+		{[]float64{1, 3, 5, 7, 9}, []float64{2, 4, 6, 8, 10}, 0.690476},
+		{[]float64{1, 2, 3}, []float64{4, 5, 6, 7}, 0.057143},
+		{[]float64{1}, []float64{2}, 1},
+		// Normal approximation with ties and continuity correction.
+		{[]float64{1, 1, 1, 1, 1}, []float64{2, 2, 2, 2, 2}, 0.003977},
+	} {
+		p, err := uTest(&summary{values: tt.x}, &summary{values: tt.y})
+		if err != nil {
+			t.Errorf("uTest(%v, %v): %v", tt.x, tt.y, err)
+			continue
+		}
+		if math.Abs(p-tt.p) > 1e-6 {
+			t.Errorf("uTest(%v, %v) = %.6f, want %.6f", tt.x, tt.y, p, tt.p)
+		}
+	}
+
+	if _, err := uTest(&summary{values: []float64{3, 3}}, &summary{values: []float64{3, 3, 3}}); err != errSamplesEqual {
+		t.Errorf("uTest of equal samples: err = %v, want %v", err, errSamplesEqual)
+	}
+}
+
+func TestUCounts(t *testing.T) {
+	for m := 0; m <= 6; m++ {
+		for n := 0; n <= 6; n++ {
+			counts := uCounts(m, n)
+			if len(counts) != m*n+1 {
+				t.Fatalf("len(uCounts(%d, %d)) = %d, want %d", m, n, len(counts), m*n+1)
+			}
+			// The counts add up to the number of orderings, m+n choose m,
+			// and the distribution of U is symmetric.
+			total := 0.0
+			for u, c := range counts {
+				total += c
+				if c != counts[m*n-u] {
+					t.Errorf("uCounts(%d, %d) is not symmetric: %v", m, n, counts)
+					break
+				}
+			}
+			if want := binomial(m+n, m); total != want {
+				t.Errorf("uCounts(%d, %d) adds up to %v, want %v", m, n, total, want)
+			}
+		}
+	}
+}
+
+func binomial(n, k int) float64 {
+	r := 1.0
+	for i := 1; i <= k; i++ {
+		r = r * float64(n-k+i) / float64(i)
+	}
+	return r
+}
+
+func TestGolden(t *testing.T) {
+	read := func(name string) *results {
+		f, err := os.Open(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		r, err := parseResults(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	old, new := read("old.txt"), read("new.txt")
+
+	for _, tt := range []struct {
+		golden string
+		tables []*table
+	}{
+		{"summary.golden", summaryTables(old)},
+		{"compare.golden", compareTables(old, new, 0.05, uTest)},
+	} {
+		want, err := ioutil.ReadFile(filepath.Join("testdata", tt.golden))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		writeTables(&buf, tt.tables)
+		if got := buf.Bytes(); !bytes.Equal(got, want) {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", tt.golden, got, want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Benchstat computes and compares statistical summaries of benchmark results.
+//
+// Usage:
+//
+//	go tool benchstat [-alpha α] [-delta-test test] old.txt [new.txt]
+//
+// Each input file should contain the concatenated output of a number
+// of runs of ``go test -bench.'' The simplest way to collect such output
+// is to run the benchmarks with the -count flag, as in
+//
+//	go test -bench=. -count=10 > old.txt
+//
+// For each benchmark and each unit it reports, such as ns/op, B/op or a
+// custom metric added with testing.B.ReportMetric, benchstat summarizes
+// the values from all runs as their mean and their variation, the
+// largest deviation from the mean as a percentage of the mean.
+// Outliers, values more than 1.5 times the interquartile range away
+// from the first or third quartile, are discarded before summarizing.
+//
+// Given a single input file, benchstat prints a summary of each benchmark.
+//
+// Given two input files, benchstat compares the summaries of each benchmark
+// present in both, printing the percentage change from the first file
+// to the second. Each change is accompanied by the p-value of a
+// statistical test of whether the two sets of values differ and by
+// the number of values, after discarding outliers, in each set.
+// If the p-value is not below the significance level set by -alpha,
+// the change is not considered significant and is printed as ``~''.
+// Benchmarks should be run at least a few times in each configuration
+// for the test to be able to find a significant difference.
+//
+// The -alpha flag sets the significance level. The default is 0.05.
+//
+// The -delta-test flag selects the significance test. The default, utest,
+// is the Mann-Whitney U-test, which makes no assumption about the
+// distribution of the values. The value none disables the test and
+// reports every change as significant.
+//
+// Example
+//
+// Comparing two sets of five runs of the same benchmarks, in which
+// a change removed an allocation from Encode:
+//
+//	$ go tool benchstat old.txt new.txt
+//	name      old time/op  new time/op  delta
+//	Encode-8  13.4ms ± 1%  13.2ms ± 0%   -1.54%  (p=0.016 n=5+4)
+//	Decode-8  32.0ms ± 1%  32.0ms ± 1%     ~     (p=0.841 n=5+5)
+//
+//	name      old allocs/op  new allocs/op  delta
+//	Encode-8      2.00 ± 0%      1.00 ± 0%  -50.00%  (p=0.004 n=5+5)
+//	Decode-8      12.0 ± 0%      12.0 ± 0%     ~     (all equal)
+//
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+var (
+	flagAlpha     = flag.Float64("alpha", 0.05, "consider changes significant if p < `α`")
+	flagDeltaTest = flag.String("delta-test", "utest", "significance `test` to apply to changes: utest or none")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: go tool benchstat [-alpha α] [-delta-test test] old.txt [new.txt]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	var test deltaTest
+	switch *flagDeltaTest {
+	case "utest":
+		test = uTest
+	case "none":
+		test = noTest
+	default:
+		fmt.Fprintf(os.Stderr, "benchstat: unknown -delta-test %q\n", *flagDeltaTest)
+		usage()
+	}
+	if flag.NArg() != 1 && flag.NArg() != 2 {
+		usage()
+	}
+
+	var inputs []*results
+	for _, file := range flag.Args() {
+		f, err := os.Open(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "benchstat: %v\n", err)
+			os.Exit(1)
+		}
+		r, err := parseResults(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "benchstat: %s: %v\n", file, err)
+			os.Exit(1)
+		}
+		inputs = append(inputs, r)
+	}
+
+	var tables []*table
+	if len(inputs) == 1 {
+		tables = summaryTables(inputs[0])
+	} else {
+		tables = compareTables(inputs[0], inputs[1], *flagAlpha, test)
+	}
+	if len(tables) == 0 {
+		fmt.Fprintf(os.Stderr, "benchstat: no benchmark results to report\n")
+		os.Exit(1)
+	}
+	writeTables(os.Stdout, tables)
+}
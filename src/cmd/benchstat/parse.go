@@ -0,0 +1,103 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A key identifies the values of one unit reported by one benchmark.
+type key struct {
+	name, unit string
+}
+
+// results holds the benchmark values read from one input.
+type results struct {
+	names  []string          // benchmark names, in order of first appearance
+	units  []string          // units, in order of first appearance
+	values map[key][]float64 // values reported in each run, in input order
+}
+
+// parseResults reads benchmark result lines, in the format printed by
+// ``go test -bench'', from r. Lines that are not benchmark results,
+// such as test output and package summaries, are ignored.
+func parseResults(r io.Reader) (*results, error) {
+	res := &results{values: make(map[key][]float64)}
+	seenName := make(map[string]bool)
+	seenUnit := make(map[string]bool)
+	scan := bufio.NewScanner(r)
+	for scan.Scan() {
+		name, vals, ok := parseLine(scan.Text())
+		if !ok {
+			continue
+		}
+		if !seenName[name] {
+			seenName[name] = true
+			res.names = append(res.names, name)
+		}
+		for _, v := range vals {
+			k := key{name, v.unit}
+			res.values[k] = append(res.values[k], v.value)
+			if !seenUnit[v.unit] {
+				seenUnit[v.unit] = true
+				res.units = append(res.units, v.unit)
+			}
+		}
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// A measurement is one value reported on a benchmark result line.
+type measurement struct {
+	value float64
+	unit  string
+}
+
+// parseLine parses a benchmark result line of the form
+//
+//	BenchmarkName-8   	 1000000	      1234 ns/op	      56 B/op
+//
+// returning the benchmark name without its "Benchmark" prefix and the
+// value and unit pairs that follow the iteration count.
+// It reports whether line was a well-formed result line.
+func parseLine(line string) (name string, vals []measurement, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || len(fields)%2 != 0 || !isBenchmarkName(fields[0]) {
+		return "", nil, false
+	}
+	if _, err := strconv.Atoi(fields[1]); err != nil {
+		return "", nil, false
+	}
+	for i := 2; i < len(fields); i += 2 {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return "", nil, false
+		}
+		vals = append(vals, measurement{v, fields[i+1]})
+	}
+	return strings.TrimPrefix(fields[0], "Benchmark"), vals, true
+}
+
+// isBenchmarkName reports whether s names a benchmark: it must begin
+// with "Benchmark", not followed by a lower-case letter.
+func isBenchmarkName(s string) bool {
+	if !strings.HasPrefix(s, "Benchmark") {
+		return false
+	}
+	rest := s[len("Benchmark"):]
+	if rest == "" {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(rest)
+	return !unicode.IsLower(r)
+}
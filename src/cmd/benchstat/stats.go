@@ -0,0 +1,187 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// A summary describes the values one benchmark reported for one unit.
+type summary struct {
+	values   []float64 // values with outliers removed, sorted
+	mean     float64
+	min, max float64
+}
+
+// summarize returns the summary of vals, after discarding outliers:
+// values more than 1.5 times the interquartile range below the
+// first quartile or above the third quartile.
+func summarize(vals []float64) *summary {
+	sorted := append([]float64(nil), vals...)
+	sort.Float64s(sorted)
+	q1, q3 := quantile(sorted, 0.25), quantile(sorted, 0.75)
+	lo, hi := q1-1.5*(q3-q1), q3+1.5*(q3-q1)
+
+	s := new(summary)
+	sum := 0.0
+	for _, v := range sorted {
+		if v < lo || v > hi {
+			continue
+		}
+		s.values = append(s.values, v)
+		sum += v
+	}
+	s.mean = sum / float64(len(s.values))
+	s.min = s.values[0]
+	s.max = s.values[len(s.values)-1]
+	return s
+}
+
+// quantile returns the q'th quantile of the sorted values,
+// interpolating linearly between adjacent values.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(i)
+	return sorted[i] + frac*(sorted[i+1]-sorted[i])
+}
+
+// variation returns the largest deviation of a value from the mean,
+// as a percentage of the mean.
+func (s *summary) variation() float64 {
+	if s.mean == 0 {
+		return 0
+	}
+	return math.Max(s.max-s.mean, s.mean-s.min) / math.Abs(s.mean) * 100
+}
+
+// errSamplesEqual is returned by uTest when all the values being
+// compared are equal, so that no difference can be found.
+var errSamplesEqual = errors.New("all samples are equal")
+
+// A deltaTest returns the p-value of the null hypothesis that the values
+// summarized by old and new come from the same distribution.
+// A negative p-value means that no test was applied.
+type deltaTest func(old, new *summary) (float64, error)
+
+// noTest is the deltaTest that reports every change as significant.
+func noTest(old, new *summary) (float64, error) {
+	return -1, nil
+}
+
+// uTest is the deltaTest that applies the two-sided Mann-Whitney U-test,
+// also known as the Wilcoxon rank-sum test, to the values of old and new.
+//
+// When there are no ties between the values and the samples are small,
+// the p-value is computed from the exact distribution of U.
+// Otherwise it is computed from the normal approximation of the
+// distribution, corrected for ties.
+func uTest(old, new *summary) (float64, error) {
+	x, y := old.values, new.values
+	n1, n2 := len(x), len(y)
+
+	// Rank the combined samples, giving tied values their average rank.
+	type value struct {
+		v     float64
+		fromX bool
+	}
+	all := make([]value, 0, n1+n2)
+	for _, v := range x {
+		all = append(all, value{v, true})
+	}
+	for _, v := range y {
+		all = append(all, value{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	n := n1 + n2
+	rankSumX := 0.0
+	tieCorrection := 0.0 // sum of t³-t over groups of t tied values
+	for i := 0; i < n; {
+		j := i + 1
+		for j < n && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2 // average of ranks i+1 through j
+		for k := i; k < j; k++ {
+			if all[k].fromX {
+				rankSumX += rank
+			}
+		}
+		if t := float64(j - i); t > 1 {
+			tieCorrection += t*t*t - t
+		}
+		i = j
+	}
+	if tieCorrection == float64(n*n*n-n) {
+		return 1, errSamplesEqual
+	}
+
+	// U is the number of pairs (x[i], y[j]) with x[i] > y[j],
+	// counting ties as one half.
+	u := rankSumX - float64(n1*(n1+1))/2
+
+	if tieCorrection == 0 && n1 <= maxExactSample && n2 <= maxExactSample {
+		counts := uCounts(n1, n2)
+		total, lower, upper := 0.0, 0.0, 0.0
+		for k, c := range counts {
+			total += c
+			if float64(k) <= u {
+				lower += c
+			}
+			if float64(k) >= u {
+				upper += c
+			}
+		}
+		return math.Min(1, 2*math.Min(lower, upper)/total), nil
+	}
+
+	mean := float64(n1*n2) / 2
+	variance := float64(n1*n2) / 12 * (float64(n+1) - tieCorrection/float64(n*(n-1)))
+	// Apply a continuity correction of one half.
+	z := math.Max(0, math.Abs(u-mean)-0.5) / math.Sqrt(variance)
+	return math.Min(1, math.Erfc(z/math.Sqrt2)), nil
+}
+
+// maxExactSample is the largest sample size for which uTest
+// computes the exact distribution of U.
+const maxExactSample = 50
+
+// uCounts returns, for each u in [0, m*n], the number of orderings of
+// m distinct values of x and n distinct values of y for which the
+// Mann-Whitney U statistic of x is u.
+func uCounts(m, n int) []float64 {
+	// Consider the largest of i values of x and j values of y.
+	// If it is from x, it exceeds all j values of y and the rest
+	// are i-1 values of x and j of y. Otherwise it exceeds nothing
+	// and the rest are i values of x and j-1 of y. So
+	//	count(i, j, u) = count(i-1, j, u-j) + count(i, j-1, u).
+	// prev[j] holds count(i-1, j, ·) and cur[j] holds count(i, j, ·).
+	prev := make([][]float64, n+1)
+	for j := range prev {
+		prev[j] = []float64{1}
+	}
+	for i := 1; i <= m; i++ {
+		cur := make([][]float64, n+1)
+		cur[0] = []float64{1}
+		for j := 1; j <= n; j++ {
+			c := make([]float64, i*j+1)
+			for u, v := range prev[j] {
+				c[u+j] += v
+			}
+			for u, v := range cur[j-1] {
+				c[u] += v
+			}
+			cur[j] = c
+		}
+		prev = cur
+	}
+	return prev[n]
+}
@@ -0,0 +1,221 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// A table is the formatted summary or comparison of the values of
+// one unit across all benchmarks.
+type table struct {
+	metric  string   // name of the reported metric, such as "time/op"
+	configs []string // column headers for the inputs, such as "old" and "new"
+	rows    []*row
+}
+
+// A row is one benchmark's line in a table.
+type row struct {
+	name  string
+	cols  []string // formatted summary for each input
+	delta string   // percentage change, or "~" if not significant
+	note  string   // details of the significance test
+}
+
+// metricNames maps the units printed by the testing package to the
+// names benchstat uses for them in table headers.
+var metricNames = map[string]string{
+	"ns/op":     "time/op",
+	"B/op":      "alloc/op",
+	"allocs/op": "allocs/op",
+	"MB/s":      "speed",
+}
+
+func metricName(unit string) string {
+	if name, ok := metricNames[unit]; ok {
+		return name
+	}
+	return unit
+}
+
+// summaryTables returns tables summarizing the values in r.
+func summaryTables(r *results) []*table {
+	var tables []*table
+	for _, unit := range r.units {
+		t := &table{metric: metricName(unit), configs: []string{""}}
+		for _, name := range r.names {
+			vals := r.values[key{name, unit}]
+			if len(vals) == 0 {
+				continue
+			}
+			t.rows = append(t.rows, &row{
+				name: name,
+				cols: []string{formatSummary(summarize(vals), unit)},
+			})
+		}
+		tables = append(tables, t)
+	}
+	return tables
+}
+
+// compareTables returns tables comparing the values in old and new
+// for the benchmarks present in both. A change is significant
+// if test returns a p-value less than alpha.
+func compareTables(old, new *results, alpha float64, test deltaTest) []*table {
+	var tables []*table
+	for _, unit := range old.units {
+		t := &table{metric: metricName(unit), configs: []string{"old", "new"}}
+		for _, name := range old.names {
+			oldVals, newVals := old.values[key{name, unit}], new.values[key{name, unit}]
+			if len(oldVals) == 0 || len(newVals) == 0 {
+				continue
+			}
+			o, n := summarize(oldVals), summarize(newVals)
+			r := &row{
+				name: name,
+				cols: []string{formatSummary(o, unit), formatSummary(n, unit)},
+			}
+			p, err := test(o, n)
+			switch {
+			case err == errSamplesEqual:
+				r.delta = "~"
+				r.note = "(all equal)"
+			case err != nil:
+				r.delta = "~"
+				r.note = "(" + err.Error() + ")"
+			case p >= alpha:
+				r.delta = "~"
+				r.note = fmt.Sprintf("(p=%0.3f n=%d+%d)", p, len(o.values), len(n.values))
+			default:
+				r.delta = fmt.Sprintf("%+.2f%%", (n.mean-o.mean)/o.mean*100)
+				if p < 0 {
+					r.note = fmt.Sprintf("(n=%d+%d)", len(o.values), len(n.values))
+				} else {
+					r.note = fmt.Sprintf("(p=%0.3f n=%d+%d)", p, len(o.values), len(n.values))
+				}
+			}
+			t.rows = append(t.rows, r)
+		}
+		if len(t.rows) > 0 {
+			tables = append(tables, t)
+		}
+	}
+	return tables
+}
+
+// formatSummary formats s as its mean followed by its variation.
+func formatSummary(s *summary, unit string) string {
+	return fmt.Sprintf("%s ± %.0f%%", formatValue(s.mean, unit), s.variation())
+}
+
+// formatValue formats v, a value of the given unit, to three significant
+// digits, scaled by a suitable prefix.
+func formatValue(v float64, unit string) string {
+	switch unit {
+	case "ns/op":
+		return scale(v, "ns", []string{"µs", "ms", "s"})
+	case "B/op":
+		return scale(v, "B", []string{"kB", "MB", "GB", "TB"})
+	case "MB/s":
+		return scale(v*1e6, "B/s", []string{"kB/s", "MB/s", "GB/s", "TB/s"})
+	}
+	return scale(v, "", []string{"k", "M", "G", "T"})
+}
+
+// scale divides v by 1000 for each of the larger suffixes it needs to be
+// printed with fewer than four digits before the decimal point,
+// and formats it with that suffix.
+func scale(v float64, suffix string, larger []string) string {
+	for _, s := range larger {
+		if math.Abs(v) < 999.5 {
+			break
+		}
+		v /= 1000
+		suffix = s
+	}
+	switch a := math.Abs(v); {
+	case a >= 99.95:
+		return fmt.Sprintf("%.0f%s", v, suffix)
+	case a >= 9.995:
+		return fmt.Sprintf("%.1f%s", v, suffix)
+	default:
+		return fmt.Sprintf("%.2f%s", v, suffix)
+	}
+}
+
+// writeTables writes the tables to w, separated by blank lines.
+// The columns of each table are padded to line up.
+func writeTables(w io.Writer, tables []*table) {
+	var buf bytes.Buffer
+	for i, t := range tables {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		header := []string{"name"}
+		for _, c := range t.configs {
+			header = append(header, strings.TrimSpace(c+" "+t.metric))
+		}
+		compare := len(t.configs) > 1
+		if compare {
+			header = append(header, "delta")
+		}
+
+		lines := [][]string{header}
+		for _, r := range t.rows {
+			line := append([]string{r.name}, r.cols...)
+			if compare {
+				line = append(line, r.delta, r.note)
+			}
+			lines = append(lines, line)
+		}
+
+		var width []int
+		for _, line := range lines {
+			for j, s := range line {
+				if j >= len(width) {
+					width = append(width, 0)
+				}
+				if n := utf8.RuneCountInString(s); n > width[j] {
+					width[j] = n
+				}
+			}
+		}
+
+		for k, line := range lines {
+			for j, s := range line {
+				pad := width[j] - utf8.RuneCountInString(s)
+				switch {
+				case j == 0 || k == 0:
+					// Names and headers are left-aligned.
+					buf.WriteString(s)
+					if j < len(line)-1 {
+						buf.WriteString(strings.Repeat(" ", pad))
+					}
+				case compare && j == len(line)-1:
+					// So are notes.
+					buf.WriteString(s)
+				case s == "~":
+					// Insignificant deltas are centered.
+					buf.WriteString(strings.Repeat(" ", pad/2))
+					buf.WriteString(s)
+					buf.WriteString(strings.Repeat(" ", pad-pad/2))
+				default:
+					buf.WriteString(strings.Repeat(" ", pad))
+					buf.WriteString(s)
+				}
+				if j < len(line)-1 {
+					buf.WriteString("  ")
+				}
+			}
+			buf.WriteString("\n")
+		}
+	}
+	w.Write(buf.Bytes())
+}
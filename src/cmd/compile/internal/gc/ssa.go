@@ -4362,6 +4362,10 @@ type SSAGenState struct {
 	// Map from GC safe points to stack map index, generated by
 	// liveness analysis.
 	stackMapIndex map[*ssa.Value]int
+
+	// unsafe reports whether the code currently being emitted is
+	// marked as unsafe for asynchronous preemption.
+	unsafe bool
 }
 
 // Prog appends a new Prog.
@@ -4369,6 +4373,23 @@ func (s *SSAGenState) Prog(as obj.As) *obj.Prog {
 	return s.pp.Prog(as)
 }
 
+// setUnsafePoint marks the code emitted from now on as safe or
+// unsafe for asynchronous preemption, emitting a PCDATA instruction
+// if that changes.
+func (s *SSAGenState) setUnsafePoint(unsafe bool) {
+	if unsafe == s.unsafe {
+		return
+	}
+	s.unsafe = unsafe
+	p := s.Prog(obj.APCDATA)
+	Addrconst(&p.From, objabi.PCDATA_UnsafePoint)
+	if unsafe {
+		Addrconst(&p.To, objabi.PCDATA_UnsafePointUnsafe)
+	} else {
+		Addrconst(&p.To, objabi.PCDATA_UnsafePointSafe)
+	}
+}
+
 // Pc returns the current Prog.
 func (s *SSAGenState) Pc() *obj.Prog {
 	return s.pp.next
@@ -4434,6 +4455,8 @@ func genssa(f *ssa.Func, pp *Progs) {
 
 	s.ScratchFpMem = e.scratchFpMem
 
+	unsafePts := unsafePoints(f)
+
 	logLocationLists := Debug_locationlist != 0
 	if Ctxt.Flag_locationlists {
 		e.curfn.Func.DebugInfo = ssa.BuildFuncDebug(f, logLocationLists)
@@ -4445,6 +4468,7 @@ func genssa(f *ssa.Func, pp *Progs) {
 		// Emit values in block
 		thearch.SSAMarkMoves(&s, b)
 		for _, v := range b.Values {
+			s.setUnsafePoint(unsafePts.value(v))
 			x := s.pp.next
 			s.DebugFriendlySetPosFrom(v)
 			switch v.Op {
@@ -4504,6 +4528,7 @@ func genssa(f *ssa.Func, pp *Progs) {
 			// line numbers for otherwise empty blocks.
 			next = f.Blocks[i+1]
 		}
+		s.setUnsafePoint(unsafePts.block(b))
 		x := s.pp.next
 		s.SetPos(b.Pos)
 		thearch.SSAGenBlock(&s, b, next)
@@ -4585,6 +4610,73 @@ func genssa(f *ssa.Func, pp *Progs) {
 	f.HTMLWriter = nil
 }
 
+// unsafePointSet records the values and block control instructions
+// at which a goroutine must not be asynchronously preempted.
+type unsafePointSet struct {
+	all    bool   // the whole function is unsafe
+	values []bool // indexed by value ID
+	blocks []bool // indexed by block ID
+}
+
+// unsafePoints computes the unsafe points of f.
+//
+// Nosplit functions are entirely unsafe. Otherwise, the unsafe points
+// are the write barrier sequences: once the write barrier flag has
+// been loaded, the GC must not change phase until the stores that
+// depend on it are done, so everything from the flag load through
+// both arms of the branch is unsafe.
+func unsafePoints(f *ssa.Func) *unsafePointSet {
+	u := &unsafePointSet{all: f.NoSplit}
+	if u.all || len(f.WBLoads) == 0 {
+		return u
+	}
+	u.values = make([]bool, f.NumValues())
+	u.blocks = make([]bool, f.NumBlocks())
+	for _, b := range f.WBLoads {
+		if len(b.Succs) != 2 {
+			// The branch was optimized away.
+			continue
+		}
+		// Find the flag load. Depending on the architecture the
+		// load or the address computation feeding it refers to
+		// the writeBarrier symbol. If neither is found, mark
+		// the whole block.
+		start := 0
+		for i, v := range b.Values {
+			if sym, ok := v.Aux.(*ssa.ExternSymbol); ok && sym.Sym == writeBarrier {
+				start = i
+				break
+			}
+		}
+		for _, v := range b.Values[start:] {
+			u.values[v.ID] = true
+		}
+		u.blocks[b.ID] = true
+
+		// Both successors end with the stores (or the write
+		// barrier calls) and then rejoin, so mark them entirely.
+		for _, e := range b.Succs {
+			s := e.Block()
+			for _, v := range s.Values {
+				u.values[v.ID] = true
+			}
+			u.blocks[s.ID] = true
+		}
+	}
+	return u
+}
+
+// value reports whether v is unsafe for asynchronous preemption.
+func (u *unsafePointSet) value(v *ssa.Value) bool {
+	return u.all || u.values != nil && u.values[v.ID]
+}
+
+// block reports whether the control instructions of b are unsafe
+// for asynchronous preemption.
+func (u *unsafePointSet) block(b *ssa.Block) bool {
+	return u.all || u.blocks != nil && u.blocks[b.ID]
+}
+
 func defframe(s *SSAGenState, e *ssafn) {
 	pp := s.pp
 
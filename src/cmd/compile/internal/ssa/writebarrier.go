@@ -173,6 +173,11 @@ func writebarrier(f *Func) {
 		bThen.AddEdgeTo(bEnd)
 		bElse.AddEdgeTo(bEnd)
 
+		// Record the branch so code generation can mark the
+		// sequence from the flag load to the stores as unsafe
+		// for asynchronous preemption.
+		f.WBLoads = append(f.WBLoads, b)
+
 		// for each write barrier store, append write barrier version to bThen
 		// and simple store version to bElse
 		memThen := mem
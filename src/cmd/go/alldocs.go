@@ -36,10 +36,14 @@
 //
 // 	c           calling between Go and C
 // 	buildmode   description of build modes
+// 	cache       build and test caching
 // 	filetype    file types
 // 	gopath      GOPATH environment variable
 // 	environment environment variables
 // 	importpath  import path syntax
+// 	modules     modules, module versions, and more
+// 	module-get  module-aware go get
+// 	goproxy     module proxy protocol
 // 	packages    description of package lists
 // 	testflag    description of testing flags
 // 	testfunc    description of testing functions
@@ -108,6 +112,11 @@
 // 		arguments to pass on each go tool asm invocation.
 // 	-buildmode mode
 // 		build mode to use. See 'go help buildmode' for more.
+// 	-buildvcs
+// 		whether to stamp binaries with version control information.
+// 		By default, the revision, commit time and modification status of
+// 		the Git or Mercurial checkout holding the main package are recorded
+// 		in the binary. Use -buildvcs=false to omit them.
 // 	-compiler name
 // 		name of compiler to use, as in runtime.Compiler (gccgo or gc).
 // 	-gccgoflags 'arg list'
@@ -162,7 +171,7 @@
 //
 // Usage:
 //
-// 	go clean [-i] [-r] [-n] [-x] [build flags] [packages]
+// 	go clean [-i] [-r] [-n] [-x] [-cache] [build flags] [packages]
 //
 // Clean removes object files from package source directories.
 // The go command builds most objects in a temporary directory,
@@ -200,6 +209,9 @@
 //
 // The -x flag causes clean to print remove commands as it executes them.
 //
+// The -cache flag causes clean to remove the entire go build cache,
+// in addition to cleaning specified packages (if any).
+//
 // For more about build flags, see 'go help build'.
 //
 // For more about specifying packages, see 'go help packages'.
@@ -558,7 +570,7 @@
 //
 // Usage:
 //
-// 	go list [-e] [-f format] [-json] [build flags] [packages]
+// 	go list [-e] [-f format] [-json] [-m] [build flags] [packages]
 //
 // List lists the packages named by the import paths, one per line.
 //
@@ -574,20 +586,21 @@
 // '{{.ImportPath}}'. The struct being passed to the template is:
 //
 //     type Package struct {
-//         Dir           string // directory containing package sources
-//         ImportPath    string // import path of package in dir
-//         ImportComment string // path in import comment on package statement
-//         Name          string // package name
-//         Doc           string // package documentation string
-//         Target        string // install path
-//         Shlib         string // the shared library that contains this package (only set when -linkshared)
-//         Goroot        bool   // is this package in the Go root?
-//         Standard      bool   // is this package part of the standard Go library?
-//         Stale         bool   // would 'go install' do anything for this package?
-//         StaleReason   string // explanation for Stale==true
-//         Root          string // Go root or Go path dir containing this package
-//         ConflictDir   string // this directory shadows Dir in $GOPATH
-//         BinaryOnly    bool   // binary-only package: cannot be recompiled from sources
+//         Dir           string  // directory containing package sources
+//         ImportPath    string  // import path of package in dir
+//         ImportComment string  // path in import comment on package statement
+//         Name          string  // package name
+//         Doc           string  // package documentation string
+//         Target        string  // install path
+//         Shlib         string  // the shared library that contains this package (only set when -linkshared)
+//         Goroot        bool    // is this package in the Go root?
+//         Standard      bool    // is this package part of the standard Go library?
+//         Stale         bool    // would 'go install' do anything for this package?
+//         StaleReason   string  // explanation for Stale==true
+//         Root          string  // Go root or Go path dir containing this package
+//         ConflictDir   string  // this directory shadows Dir in $GOPATH
+//         BinaryOnly    bool    // binary-only package: cannot be recompiled from sources
+//         Module        *Module // info about package's containing module, if any (can be nil)
 //
 //         // Source files
 //         GoFiles        []string // .go source files (excluding CgoFiles, TestGoFiles, XTestGoFiles)
@@ -672,6 +685,31 @@
 // a non-nil Error field; other information may or may not be missing
 // (zeroed).
 //
+// The -m flag causes list to list modules instead of packages.
+// It requires module-aware mode (see 'go help modules').
+// With no arguments, list -m lists the main module.
+// The argument "all" lists every module in the build list,
+// and other arguments are module paths or patterns containing "..."
+// matched against the build list. The default output shows the module
+// path and version, followed by any replacement. With -f or -json,
+// the struct being passed to the template or encoded is:
+//
+//     type Module struct {
+//         Path     string       // module path
+//         Version  string       // module version
+//         Replace  *Module      // replaced by this module
+//         Time     *time.Time   // time version was created
+//         Main     bool         // is this the main module?
+//         Indirect bool         // is this module only an indirect dependency of main module?
+//         Dir      string       // directory holding files for this module, if any
+//         GoMod    string       // path to go.mod file for this module, if any
+//         Error    *ModuleError // error loading module
+//     }
+//
+//     type ModuleError struct {
+//         Err string // the error itself
+//     }
+//
 // For more about build flags, see 'go help build'.
 //
 // For more about specifying packages, see 'go help packages'.
@@ -736,6 +774,22 @@
 // The package is built in a temporary directory so it does not interfere with the
 // non-test installation.
 //
+// When 'go test' is given an explicit list of packages, it caches the
+// results of successful package tests to avoid unnecessary repeated
+// running of tests. If the result of a test can be recovered from the
+// cache, go test redisplays the previous output instead of running the
+// test binary again, and it prints '(cached)' in place of the elapsed
+// time in the summary line.
+//
+// A cached result is reused only when the test binary is the same,
+// the flags passed to it are all from the restricted set -cpu, -failfast,
+// -list, -parallel, -run, -short, and -v, and the environment variables and
+// files the test consulted while running are unchanged. Tests run with
+// any other flag, or run without a package list, are not cached.
+// To disable test caching for a run, use any test flag or argument
+// other than the cacheable flags, or set GOCACHE=off.
+// See 'go help cache' for more about the cache.
+//
 // In addition to the build flags, the flags handled by 'go test' itself are:
 //
 // 	-args
@@ -757,6 +811,12 @@
 // 	    Install packages that are dependencies of the test.
 // 	    Do not run the test.
 //
+// 	-json
+// 	    Convert test output to JSON suitable for automated processing.
+// 	    Each test binary is run as if -v had been given, and its output
+// 	    is reported as a stream of events for each test.
+// 	    See 'go doc test2json' for the encoding details.
+//
 // 	-o file
 // 	    Compile the test binary to the named file.
 // 	    The test still runs (unless -c or -i is specified).
@@ -789,9 +849,29 @@
 //
 // Usage:
 //
-// 	go version
+// 	go version [-m] [-v] [file ...]
+//
+// Version prints the build information for Go executables.
+//
+// Go version reports the Go version used to build each of the named
+// executable files.
+//
+// If no files are named on the command line, go version prints its own
+// version information.
+//
+// If a directory is named, go version walks that directory, recursively,
+// looking for recognized Go binaries and reporting their versions.
+// By default, go version does not report unrecognized files found
+// during a directory scan. The -v flag causes it to report unrecognized files.
 //
-// Version prints the Go version, as reported by runtime.Version.
+// The -m flag causes go version to print each executable's embedded
+// build information, when available: the main package path, the
+// versions of the modules it was built from, the build flags and
+// target, and the version control revision of its source tree.
+// In the output, the build information consists of multiple lines
+// following the version line, each indented by a leading tab character.
+//
+// See also: go doc runtime/debug.BuildInfo.
 //
 //
 // Run go tool vet on packages
@@ -880,6 +960,44 @@
 // 		import, into a Go plugin. Packages not named main are ignored.
 //
 //
+// Build and test caching
+//
+// The go command caches build outputs for reuse in future builds.
+// The default location for cache data is a subdirectory named go-build
+// in the standard user cache directory for the current operating system.
+// Setting the GOCACHE environment variable overrides this default,
+// and running 'go env GOCACHE' prints the current cache directory.
+// Setting GOCACHE=off disables the cache.
+//
+// The cache is keyed by the content of the inputs to each build step,
+// not by file modification times: the source files, the compiled
+// dependencies, the toolchain binaries, the target operating system and
+// architecture, and the build flags. A package compiled once can therefore
+// be reused after switching branches or build tags and back again, or
+// from another checkout of the same sources in the same directory.
+//
+// The go command periodically deletes cached data that has not been
+// used recently. Running 'go clean -cache' deletes all cached data.
+//
+// The go command also caches successful package test results.
+// See 'go help test' for details. Running 'go clean -cache'
+// removes all cached test results.
+//
+// The GODEBUG environment variable can enable printing of debugging
+// information about the state of the cache:
+//
+// GODEBUG=gocacheverify=1 causes the go command to bypass the
+// use of any cache entries and instead rebuild everything and check
+// that the results match existing cache entries.
+//
+// GODEBUG=gocachehash=1 causes the go command to print the inputs
+// for all of the content hashes it uses to construct cache lookup keys.
+// The output is voluminous but can be useful for debugging the cache.
+//
+// GODEBUG=gocachetest=1 causes the go command to print details of its
+// decisions about whether to reuse a cached test result.
+//
+//
 // File types
 //
 // The go command examines the contents of a restricted set of files
@@ -1090,11 +1208,16 @@
 // 		Examples are amd64, 386, arm, ppc64.
 // 	GOBIN
 // 		The directory where 'go install' will install a command.
+// 	GOCACHE
+// 		The directory where the go command will store cached
+// 		information for reuse in future builds.
 // 	GOOS
 // 		The operating system for which to compile code.
 // 		Examples are linux, darwin, windows, netbsd.
 // 	GOPATH
 // 		For more details see: 'go help gopath'.
+// 	GOPROXY
+// 		URL of Go module proxy. See 'go help goproxy'.
 // 	GORACE
 // 		Options for the race detector.
 // 		See https://golang.org/doc/articles/race_detector.html.
@@ -1137,6 +1260,9 @@
 //
 // Special-purpose environment variables:
 //
+// 	GO111MODULE
+// 		Controls whether the go command runs in module-aware mode:
+// 		on, off, or auto (the default). See 'go help modules'.
 // 	GOROOT_FINAL
 // 		The root of the installed Go tree, when it is
 // 		installed in a location other than where it is built.
@@ -1325,6 +1451,265 @@
 // See https://golang.org/s/go14customimport for details.
 //
 //
+// Modules, module versions, and more
+//
+// A module is a collection of related Go packages.
+// Modules are the unit of source code interchange and versioning.
+// The go command has direct support for working with modules,
+// including recording and resolving dependencies on other modules.
+// Modules replace the old GOPATH-based approach to specifying
+// which source files are used in a given build.
+//
+// Module-aware mode
+//
+// The go command can run in module-aware mode or in legacy GOPATH mode.
+// The GO111MODULE environment variable selects between them.
+//
+// If GO111MODULE=off, the go command never uses module support.
+// Instead it looks in vendor directories and GOPATH to find dependencies;
+// we now refer to this as "GOPATH mode."
+//
+// If GO111MODULE=on, the go command requires the use of modules,
+// never consulting GOPATH to resolve imports.
+//
+// If GO111MODULE=auto or is unset, the go command enables module support
+// when the current directory is outside GOPATH/src and either the current
+// directory or any of its parents contains a file named go.mod.
+//
+// In module-aware mode, GOPATH no longer defines the meaning of imports
+// during a build, but it still stores downloaded dependencies (in
+// GOPATH/pkg/mod) and installed commands (in GOPATH/bin, unless GOBIN
+// is set).
+//
+// Defining a module
+//
+// A module is defined by a tree of Go source files with a go.mod file
+// in the tree's root directory. The directory containing the go.mod file
+// is called the module root. Typically the module root will also correspond
+// to a source code repository root (but in general it need not).
+// The module is the set of all Go packages in the module root and its
+// subdirectories, but excluding subtrees with their own go.mod files.
+//
+// The "module path" is the import path prefix corresponding to the module root.
+// The go.mod file defines the module path and lists the specific versions
+// of other modules that should be used when resolving imports during a build,
+// by giving their module paths and versions.
+//
+// For example, this go.mod declares that the directory containing it is the root
+// of the module with path example.com/m, and it also declares that the module
+// depends on specific versions of golang.org/x/text and gopkg.in/yaml.v2:
+//
+// 	module example.com/m
+//
+// 	require (
+// 		golang.org/x/text v0.3.0
+// 		gopkg.in/yaml.v2 v2.1.0
+// 	)
+//
+// The go.mod file is line-oriented, with // comments. Each line holds
+// a single directive, made up of a verb followed by arguments:
+//
+// 	module example.com/m          // declares the module path
+// 	require example.com/a v1.2.3  // requires a minimum version of a module
+// 	exclude example.com/a v1.2.4  // excludes a module version from use
+// 	replace example.com/a => ../a // replaces a module with another one
+//
+// A replace directive may name a specific version on the left-hand side,
+// in which case only that version is replaced. The right-hand side is either
+// a module path and version or a directory path, which must begin with
+// ./ or ../ or be an absolute path; a replacement directory's go.mod file,
+// if any, supplies the module's requirements. Exclude and replace directives
+// apply only in the main module's go.mod and are ignored in dependencies.
+//
+// Adjacent directives with the same verb can be grouped into a block,
+// as in the require block above. The go command reformats go.mod
+// when it updates it, as 'go get' does.
+//
+// The main module and the build list
+//
+// The "main module" is the module containing the directory where the go command
+// is run. The go command finds the module root by looking for a go.mod in the
+// current directory, or else the current directory's parent directory,
+// or else the parent's parent directory, and so on.
+//
+// The main module's go.mod file defines the precise set of packages available
+// for use by the go command, through require, replace, and exclude statements.
+// Dependency modules, found by following require statements, also contribute
+// to the definition of that set of packages, but only through their go.mod
+// files' require statements: any replace and exclude statements in dependency
+// modules are ignored.
+//
+// The set of modules providing packages to builds is called the "build list".
+// The build list initially contains only the main module. Then the go command
+// adds to the list the exact module versions required by modules already
+// on the list, recursively, until there is nothing left to add to the list.
+// If multiple versions of a particular module are added to the list,
+// then at the end only the latest version (according to semantic version
+// ordering) is kept for use in the build. This algorithm is called
+// minimal version selection: it never uses a version newer than some
+// module in the build asks for, so builds are reproducible without a
+// separate lock file.
+//
+// The 'go list -m' command prints the build list:
+//
+// 	go list -m all
+//
+// Module downloading and verification
+//
+// The go command downloads modules from the module proxy named by
+// $GOPROXY and maintains a cache of downloaded modules in GOPATH/pkg/mod.
+// See 'go help goproxy' for details about the proxy protocol.
+//
+// The go command maintains, in the main module's root directory alongside
+// go.mod, a file named go.sum containing the expected cryptographic checksums
+// of the content of specific module versions. Each line has the form
+//
+// 	<module> <version>[/go.mod] <hash>
+//
+// where the hash is "h1:" followed by the base64-encoded SHA-256 hash of
+// a sorted list of the module's files and their own SHA-256 hashes, or,
+// for the /go.mod form, of the version's go.mod file alone.
+//
+// Each time a dependency is used, its checksum is added to go.sum if
+// missing or else required to match the existing entry in go.sum.
+// A mismatch is reported as an error and stops the build. The go.sum
+// file should be checked in to version control along with go.mod.
+//
+// Modules and package patterns
+//
+// In module-aware mode, the package pattern "all" means the packages in
+// the main module together with all the packages they import, recursively.
+// Patterns containing "..." match packages in the modules of the build list,
+// and relative patterns like ./... match packages in the main module.
+//
+//
+// Module-aware go get
+//
+// The 'go get' command changes behavior depending on whether the
+// go command is running in module-aware mode or legacy GOPATH mode.
+// This help text, accessible as 'go help module-get' even in legacy GOPATH mode,
+// describes 'go get' as it operates in module-aware mode.
+//
+// Usage: get [-d] [-u] [build flags] [packages]
+//
+// Get resolves and adds dependencies to the current development module
+// and then builds and installs them.
+//
+// The first step is to resolve which dependencies to add.
+//
+// For each named package or package pattern, get must decide which version of
+// the corresponding module to use. By default, get chooses the latest tagged
+// release version, such as v0.4.5 or v1.2.3. If there are no tagged release
+// versions, get chooses the latest tagged pre-release version, such as
+// v0.0.1-pre1.
+//
+// This default version selection can be overridden by adding an @version
+// suffix to the package argument, as in 'go get golang.org/x/text@v0.3.0'.
+// The version suffix must be a semantic version, such as v1.2.3, or a
+// prefix of one, such as v1 or v1.2, denoting the latest release with that
+// prefix. The suffix @latest explicitly requests the default behavior
+// described above.
+//
+// If a module under consideration is already a dependency of the current
+// development module, then get will update the required version.
+// Specifying a version earlier than the current required version is valid,
+// although the requirements of other modules may still select a later one.
+// The version suffix @none indicates that the dependency should be removed
+// from go.mod entirely.
+//
+// Although get defaults to using the latest version of the module containing
+// a named package, it does not use the latest version of that module's
+// dependencies. Instead it prefers to use the specific dependency versions
+// requested by that module. For example, if the latest A requires module
+// B v1.2.3, while B v1.2.4 and v1.3.1 are also available, then 'go get A'
+// will use the latest A but then use B v1.2.3, as requested by A.
+//
+// The -u flag instructs get to update the modules providing dependencies of
+// the named packages to use newer minor or patch releases when available.
+// Continuing the previous example, 'go get -u A' will use the latest A with
+// B v1.3.1 (not B v1.2.3). With no package arguments, 'go get -u' updates
+// every module in the build list.
+//
+// The second step is to download (if needed), build, and install
+// the named packages.
+//
+// The -d flag instructs get to download the source code needed to build
+// the named packages, including downloading necessary dependencies,
+// but not to build and install them.
+//
+// With no package arguments, 'go get' applies to the main module,
+// and to the Go package in the current directory, if any.
+//
+// Modules are downloaded from the module proxy named by $GOPROXY.
+// For more about the module proxy protocol, see 'go help goproxy'.
+//
+// For more about modules, see 'go help modules'.
+//
+// For more about specifying packages, see 'go help packages'.
+//
+// This text describes the behavior of get using modules to manage source
+// code and dependencies. If instead the go command is running in GOPATH
+// mode, the details of get's flags and effects change; see 'go help get'.
+//
+// See also: go build, go install, go clean.
+//
+//
+// Module proxy protocol
+//
+// The go command downloads modules from a module proxy, an ordinary
+// web server (or file tree) that responds to GET requests for URLs
+// of a specified form. The GOPROXY environment variable gives the
+// base URL of the proxy. It may use the https, http, or file scheme:
+//
+// 	GOPROXY=https://proxy.example.com
+// 	GOPROXY=file:///home/gopher/proxy
+//
+// Setting GOPROXY=off disallows downloading modules entirely;
+// only modules already present in the module cache can be used.
+//
+// The requests sent to a module proxy are:
+//
+// GET $GOPROXY/<module>/@v/list returns a list of all known versions
+// of the given module, one per line.
+//
+// GET $GOPROXY/<module>/@v/<version>.info returns JSON-formatted
+// metadata about that version of the given module.
+//
+// GET $GOPROXY/<module>/@v/<version>.mod returns the go.mod file
+// for that version of the given module.
+//
+// GET $GOPROXY/<module>/@v/<version>.zip returns the zip archive
+// for that version of the given module.
+//
+// To avoid problems when serving from case-sensitive file systems,
+// the <module> and <version> elements are case-encoded, replacing every
+// uppercase letter with an exclamation mark followed by the corresponding
+// lower-case letter: github.com/Azure encodes as github.com/!azure.
+//
+// The JSON-formatted metadata about a given module corresponds to
+// this Go data structure, which may be expanded in the future:
+//
+// 	type Info struct {
+// 		Version string    // version string
+// 		Time    time.Time // commit time
+// 	}
+//
+// The zip archive for a specific version of a given module is a
+// standard zip file that contains the file tree corresponding
+// to the module's source code and related files. Every file in
+// the archive must begin with the prefix <module>@<version>/,
+// where the module and version are substituted directly, not
+// case-encoded. The root of the module file tree corresponds
+// to the <module>@<version>/ prefix in the archive.
+//
+// The go command stores the info, mod, and zip files it downloads
+// in its local cache, $GOPATH/pkg/mod/cache/download.
+// The cache layout is the same as the proxy URL space, so
+// serving $GOPATH/pkg/mod/cache/download at (or copying it to)
+// https://example.com/proxy would let other users access those
+// cached module versions with GOPROXY=https://example.com/proxy.
+//
+//
 // Description of package lists
 //
 // Many commands apply to a set of packages:
@@ -1472,10 +1857,41 @@
 // 	    benchmarks should be executed. The default is the current value
 // 	    of GOMAXPROCS.
 //
+// 	-failfast
+// 	    Do not start new tests after the first test failure.
+//
+// 	-fuzz regexp
+// 	    Run the fuzz target matching the regular expression. When specified,
+// 	    the command line argument must match exactly one package, and regexp
+// 	    must match exactly one fuzz target within that package. Fuzzing runs
+// 	    after tests, benchmarks, seed corpora of other fuzz targets, and
+// 	    examples have completed, and only if they all pass. The package is
+// 	    built with coverage instrumentation, which guides the generation of
+// 	    new inputs. Inputs that expand coverage are kept in the build cache
+// 	    (see 'go help cache') and reused by later runs. An input that causes
+// 	    a failure is minimized and written to testdata/fuzz/FuzzXXX, where
+// 	    it becomes part of the seed corpus run by later tests.
+// 	    The -timeout flag does not apply to fuzzing.
+//
+// 	-fuzzminimizetime t
+// 	    Run enough iterations of the fuzz target during each minimization
+// 	    attempt to take t, as specified as a time.Duration (for example,
+// 	    -fuzzminimizetime 30s). The default is 60s. The special syntax Nx
+// 	    means to run the fuzz target N times (for example,
+// 	    -fuzzminimizetime 100x).
+//
+// 	-fuzztime t
+// 	    Run enough iterations of the fuzz target during fuzzing to take t,
+// 	    specified as a time.Duration (for example, -fuzztime 1h30s).
+// 	    The default is to run forever, until a failure is found or the
+// 	    test is interrupted. The special syntax Nx means to run the fuzz
+// 	    target N times (for example, -fuzztime 1000x).
+//
 // 	-list regexp
-// 	    List tests, benchmarks, or examples matching the regular expression.
-// 	    No tests, benchmarks or examples will be run. This will only
-// 	    list top-level tests. No subtest or subbenchmarks will be shown.
+// 	    List tests, benchmarks, fuzz targets, or examples matching the
+// 	    regular expression. No tests, benchmarks, fuzz targets or examples
+// 	    will be run. This will only list top-level tests. No subtest or
+// 	    subbenchmarks will be shown.
 //
 // 	-parallel n
 // 	    Allow parallel execution of test functions that call t.Parallel.
@@ -1487,7 +1903,9 @@
 // 	    (see 'go help build').
 //
 // 	-run regexp
-// 	    Run only those tests and examples matching the regular expression.
+// 	    Run only those tests, examples, and fuzz targets matching the regular
+// 	    expression. For fuzz targets, the fuzz function is called once for
+// 	    each input in the seed corpus, as a subtest named after the input.
 // 	    For tests, the regular expression is split by unbracketed slash (/)
 // 	    characters into a sequence of regular expressions, and each part
 // 	    of a test's identifier must match the corresponding element in
@@ -1502,6 +1920,13 @@
 // 	    the Go tree can run a sanity check but not spend time running
 // 	    exhaustive tests.
 //
+// 	-shuffle off,on,N
+// 	    Randomize the execution order of tests and benchmarks.
+// 	    It is off by default. If -shuffle is set to on, then it will seed
+// 	    the randomizer using the system clock. If -shuffle is set to an
+// 	    integer N, then N will be used as the seed value. In both cases,
+// 	    the seed will be reported for reproducibility.
+//
 // 	-timeout d
 // 	    If a test binary runs longer than duration d, panic.
 // 	    The default is 10 minutes (10m).
@@ -1625,8 +2050,8 @@
 //
 // Description of testing functions
 //
-// The 'go test' command expects to find test, benchmark, and example functions
-// in the "*_test.go" files corresponding to the package under test.
+// The 'go test' command expects to find test, benchmark, fuzz, and example
+// functions in the "*_test.go" files corresponding to the package under test.
 //
 // A test function is one named TestXXX (where XXX is any alphanumeric string
 // not starting with a lower case letter) and should have the signature,
@@ -1637,6 +2062,10 @@
 //
 // 	func BenchmarkXXX(b *testing.B) { ... }
 //
+// A fuzz target is one named FuzzXXX and should have the signature,
+//
+// 	func FuzzXXX(f *testing.F) { ... }
+//
 // An example function is similar to a test function but, instead of using
 // *testing.T to report success or failure, prints output to os.Stdout.
 // If the last comment in the function starts with "Output:" then the output
@@ -1676,7 +2105,7 @@
 //
 // The entire test file is presented as the example when it contains a single
 // example function, at least one other function, type, variable, or constant
-// declaration, and no test or benchmark functions.
+// declaration, and no test, benchmark, or fuzz functions.
 //
 // See the documentation of the testing package for more information.
 //
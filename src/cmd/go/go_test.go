@@ -5,9 +5,11 @@
 package main_test
 
 import (
+	"archive/zip"
 	"bytes"
 	"debug/elf"
 	"debug/macho"
+	"encoding/json"
 	"fmt"
 	"go/format"
 	"internal/race"
@@ -132,6 +134,8 @@ func TestMain(m *testing.M) {
 	os.Unsetenv("GOBIN")
 	os.Unsetenv("GOPATH")
 	os.Unsetenv("GIT_ALLOW_PROTOCOL")
+	os.Unsetenv("GO111MODULE")
+	os.Unsetenv("GOPROXY")
 	if home, ccacheDir := os.Getenv("HOME"), os.Getenv("CCACHE_DIR"); home != "" && ccacheDir == "" {
 		// On some systems the default C compiler is ccache.
 		// Setting HOME to a non-existent directory will break
@@ -139,6 +143,9 @@ func TestMain(m *testing.M) {
 		os.Setenv("CCACHE_DIR", filepath.Join(home, ".ccache"))
 	}
 	os.Setenv("HOME", "/test-go-home-does-not-exist")
+	if os.Getenv("GOCACHE") == "" {
+		os.Setenv("GOCACHE", "off") // because $HOME is gone
+	}
 
 	r := m.Run()
 
@@ -3248,6 +3255,275 @@ func TestGoTestRaceFailures(t *testing.T) {
 	tg.grepBothNot("PASS", "something passed")
 }
 
+func TestGoTestJSON(t *testing.T) {
+	tg := testgo(t)
+	defer tg.cleanup()
+	tg.parallel()
+	tg.tempFile("src/jsontest/pass/pass_test.go", `package pass
+
+import "testing"
+
+func TestPass(t *testing.T) { t.Log("passing") }
+
+func TestParallel(t *testing.T) {
+	t.Run("sub", func(t *testing.T) { t.Parallel() })
+}
+`)
+	tg.tempFile("src/jsontest/fail/fail_test.go", `package fail
+
+import "testing"
+
+func TestFail(t *testing.T) {
+	t.Run("sub", func(t *testing.T) { t.Error("failing") })
+}
+`)
+	tg.tempFile("src/jsontest/notest/notest.go", "package notest\n")
+	tg.setenv("GOPATH", tg.path("."))
+
+	tg.runFail("test", "-json", "jsontest/...")
+
+	type event struct {
+		Action  string
+		Package string
+		Test    string
+		Elapsed *float64
+		Output  string
+	}
+	var events []event
+	for _, line := range strings.Split(strings.TrimSpace(tg.getStdout()), "\n") {
+		var e event
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		events = append(events, e)
+	}
+	has := func(pkg, test, action string) bool {
+		for _, e := range events {
+			if e.Package == pkg && e.Test == test && e.Action == action {
+				return true
+			}
+		}
+		return false
+	}
+	for _, want := range []struct{ pkg, test, action string }{
+		{"jsontest/pass", "TestPass", "run"},
+		{"jsontest/pass", "TestPass", "pass"},
+		{"jsontest/pass", "TestParallel/sub", "pause"},
+		{"jsontest/pass", "TestParallel/sub", "cont"},
+		{"jsontest/pass", "TestParallel", "pass"},
+		{"jsontest/pass", "", "pass"},
+		{"jsontest/fail", "TestFail/sub", "fail"},
+		{"jsontest/fail", "TestFail", "fail"},
+		{"jsontest/fail", "", "fail"},
+		{"jsontest/notest", "", "skip"},
+	} {
+		if !has(want.pkg, want.test, want.action) {
+			t.Errorf("missing %q event for package %q test %q", want.action, want.pkg, want.test)
+		}
+	}
+	sawLog := false
+	for _, e := range events {
+		if (e.Action == "pass" || e.Action == "fail") && e.Elapsed == nil {
+			t.Errorf("%q event for package %q test %q has no elapsed time", e.Action, e.Package, e.Test)
+		}
+		if e.Action == "output" && e.Test == "TestPass" && strings.Contains(e.Output, "passing") {
+			sawLog = true
+		}
+	}
+	if !sawLog {
+		t.Errorf("did not find output of t.Log in TestPass")
+	}
+}
+
+func TestGoTestFailFast(t *testing.T) {
+	tg := testgo(t)
+	defer tg.cleanup()
+	tg.parallel()
+	tg.setenv("GOPATH", filepath.Join(tg.pwd(), "testdata"))
+
+	tests := []struct {
+		run      string
+		failfast bool
+		nfail    int
+	}{
+		{"TestFailingA", true, 1},
+		{"TestFailing[AB]", true, 1},
+		{"TestFailing[AB]", false, 2},
+		// mix with non-failing tests:
+		{"TestA|TestFailing[AB]", true, 1},
+		{"TestA|TestFailing[AB]", false, 2},
+		// mix with parallel tests:
+		{"TestFailingB|TestParallelFailingA", true, 2},
+		{"TestFailingB|TestParallelFailingA", false, 2},
+		{"TestFailingB|TestParallelFailing[AB]", true, 3},
+		{"TestFailingB|TestParallelFailing[AB]", false, 3},
+		// mix with parallel sub-tests:
+		{"TestFailingB|TestParallelFailing[AB]|TestParallelFailingSubtestsA", true, 3},
+		{"TestFailingB|TestParallelFailing[AB]|TestParallelFailingSubtestsA", false, 5},
+		{"TestParallelFailingSubtestsA", true, 1},
+		// only parallels:
+		{"TestParallelFailing[AB]", false, 2},
+		// non-parallel subtests:
+		{"TestFailingSubtestsA", true, 1},
+		{"TestFailingSubtestsA", false, 2},
+	}
+
+	for _, tt := range tests {
+		tg.runFail("test", "failfast", "-run="+tt.run, "-failfast="+strconv.FormatBool(tt.failfast))
+		if nfail := strings.Count(tg.getStdout(), "FAIL - "); nfail != tt.nfail {
+			t.Errorf("go test -run=%s -failfast=%t printed %d FAILs, want %d", tt.run, tt.failfast, nfail, tt.nfail)
+		}
+	}
+}
+
+func TestGoTestShuffle(t *testing.T) {
+	tg := testgo(t)
+	defer tg.cleanup()
+	tg.parallel()
+	var src bytes.Buffer
+	src.WriteString("package shuffle\n\nimport \"testing\"\n")
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&src, "\nfunc Test%d(t *testing.T) {}\n", i)
+	}
+	tg.tempFile("src/shuffle/shuffle_test.go", src.String())
+	tg.setenv("GOPATH", tg.path("."))
+
+	// Running twice with the same seed must run the tests in the same order.
+	tg.run("test", "-v", "-shuffle=12345", "shuffle")
+	tg.grepStdout(`^-test\.shuffle 12345$`, "seed was not printed")
+	first := testRunOrder(tg.getStdout())
+	if len(first) != 10 {
+		t.Fatalf("ran %d tests, want 10", len(first))
+	}
+	if strings.Join(first, " ") == "Test0 Test1 Test2 Test3 Test4 Test5 Test6 Test7 Test8 Test9" {
+		t.Errorf("-shuffle=12345 ran the tests in source order")
+	}
+	tg.run("test", "-v", "-shuffle=12345", "shuffle")
+	if second := testRunOrder(tg.getStdout()); strings.Join(second, " ") != strings.Join(first, " ") {
+		t.Errorf("runs with the same -shuffle seed ran tests in different orders: %v and %v", first, second)
+	}
+
+	tg.run("test", "-v", "-shuffle=on", "shuffle")
+	tg.grepStdout(`^-test\.shuffle -?[0-9]+$`, "seed was not printed")
+
+	tg.runFail("test", "-shuffle=bad", "shuffle")
+	tg.grepBoth(`-shuffle should be "off", "on", or a valid integer`, "bad -shuffle value was not reported")
+}
+
+// testRunOrder returns the names of the tests started in the verbose
+// test output out, in the order they were started.
+func testRunOrder(out string) []string {
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "=== RUN   ") {
+			names = append(names, strings.TrimPrefix(line, "=== RUN   "))
+		}
+	}
+	return names
+}
+
+func TestGoTestFuzz(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping fuzzing test in short mode")
+	}
+	tg := testgo(t)
+	defer tg.cleanup()
+	tg.parallel()
+	tg.tempFile("src/fuzztest/fuzz.go", `package fuzztest
+
+func Check(b []byte) bool {
+	if len(b) > 0 && b[0] == 'F' {
+		if len(b) > 1 && b[1] == 'U' {
+			if len(b) > 2 && b[2] == 'Z' {
+				return true
+			}
+		}
+	}
+	return false
+}
+`)
+	tg.tempFile("src/fuzztest/fuzz_test.go", `package fuzztest
+
+import "testing"
+
+func FuzzCheck(f *testing.F) {
+	f.Add([]byte("hello"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		if Check(b) {
+			t.Fatalf("Check(%q) = true", b)
+		}
+	})
+}
+`)
+	tg.setenv("GOPATH", tg.path("."))
+	tg.setenv("GOCACHE", tg.path("cache"))
+
+	// Without -fuzz, only the seed corpus is run.
+	tg.run("test", "-v", "fuzztest")
+	tg.grepStdout("--- PASS: FuzzCheck/seed#0", "seed corpus entry was not run as a subtest")
+
+	tg.runFail("test", "-fuzz=FuzzCheck", "-fuzztime=5m", "fuzztest")
+	tg.grepStdout("Failing input written to testdata/fuzz/FuzzCheck/", "failing input was not reported")
+	files, err := ioutil.ReadDir(tg.path("src/fuzztest/testdata/fuzz/FuzzCheck"))
+	tg.must(err)
+	if len(files) != 1 {
+		t.Fatalf("found %d files in corpus directory, want 1", len(files))
+	}
+	data, err := ioutil.ReadFile(filepath.Join(tg.path("src/fuzztest/testdata/fuzz/FuzzCheck"), files[0].Name()))
+	tg.must(err)
+	if want := "go test fuzz v1\n[]byte(\"FUZ\")\n"; string(data) != want {
+		t.Errorf("minimized input = %q, want %q", data, want)
+	}
+
+	// The failing input is now part of the seed corpus.
+	tg.runFail("test", "fuzztest")
+	tg.grepStdout("--- FAIL: FuzzCheck/"+files[0].Name(), "failing input was not rerun")
+}
+
+func TestGoTestFuzzExit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping fuzzing test in short mode")
+	}
+	tg := testgo(t)
+	defer tg.cleanup()
+	tg.parallel()
+	tg.tempFile("src/fuzzexit/fuzz_test.go", `package fuzzexit
+
+import (
+	"os"
+	"testing"
+)
+
+func FuzzExit(f *testing.F) {
+	f.Add([]byte("hello"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		if string(b) != "hello" {
+			os.Exit(3)
+		}
+	})
+}
+`)
+	tg.setenv("GOPATH", tg.path("."))
+	tg.setenv("GOCACHE", tg.path("cache"))
+
+	// An input that makes the fuzz function exit is recorded like
+	// any other failure, instead of taking down the test binary.
+	tg.runFail("test", "-fuzz=FuzzExit", "-fuzztime=5m", "fuzzexit")
+	tg.grepStdout("fuzzing process terminated unexpectedly: exit status 3", "worker exit was not reported")
+	tg.grepStdout("Failing input written to testdata/fuzz/FuzzExit/", "failing input was not reported")
+	files, err := ioutil.ReadDir(tg.path("src/fuzzexit/testdata/fuzz/FuzzExit"))
+	tg.must(err)
+	if len(files) != 1 {
+		t.Fatalf("found %d files in corpus directory, want 1", len(files))
+	}
+	data, err := ioutil.ReadFile(filepath.Join(tg.path("src/fuzzexit/testdata/fuzz/FuzzExit"), files[0].Name()))
+	tg.must(err)
+	// Every input but the seed fails, so minimization leaves one byte.
+	if !regexp.MustCompile(`^go test fuzz v1\n\[\]byte\("(.|\\.)"\)\n$`).Match(data) {
+		t.Errorf("minimized input = %q, want a single byte", data)
+	}
+}
+
 func TestGoTestImportErrorStack(t *testing.T) {
 	const out = `package testdep/p1 (test)
 	imports testdep/p2
@@ -4498,3 +4774,411 @@ func TestParallelNumber(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildCache(t *testing.T) {
+	tg := testgo(t)
+	defer tg.cleanup()
+	tg.parallel()
+	tg.makeTempdir()
+	tg.setenv("GOCACHE", tg.path("cache"))
+	tg.tempFile("gopath/src/p/p.go", "package p; func F() int { return 1 }\n")
+	tg.setenv("GOPATH", tg.path("gopath"))
+
+	tg.run("env", "GOCACHE")
+	tg.grepStdout(regexp.QuoteMeta(tg.path("cache")), "GOCACHE not honored")
+
+	tg.run("build", "-x", "p")
+	tg.grepStderr(`[\\/]compile( |\.exe)`, "did not compile p")
+	tg.run("build", "-x", "p")
+	tg.grepStderrNot(`[\\/]compile( |\.exe)`, "compiled p again, want cached archive")
+
+	// A change to the source must not use the cached archive.
+	tg.tempFile("gopath/src/p/p.go", "package p; func F() int { return 2 }\n")
+	tg.run("build", "-x", "p")
+	tg.grepStderr(`[\\/]compile( |\.exe)`, "did not recompile p after change")
+
+	// Changing the source back reuses the earlier archive.
+	tg.tempFile("gopath/src/p/p.go", "package p; func F() int { return 1 }\n")
+	tg.run("build", "-x", "p")
+	tg.grepStderrNot(`[\\/]compile( |\.exe)`, "compiled p again after revert, want cached archive")
+
+	// Different compiler flags must not share cache entries.
+	tg.run("build", "-x", "-gcflags=-N", "p")
+	tg.grepStderr(`[\\/]compile( |\.exe)`, "did not compile p with new -gcflags")
+
+	tg.run("clean", "-cache")
+	tg.run("build", "-x", "p")
+	tg.grepStderr(`[\\/]compile( |\.exe)`, "did not compile p after go clean -cache")
+
+	tg.setenv("GOCACHE", "off")
+	tg.run("build", "-x", "p")
+	tg.grepStderr(`[\\/]compile( |\.exe)`, "did not compile p with GOCACHE=off")
+}
+
+func TestTestCache(t *testing.T) {
+	tg := testgo(t)
+	defer tg.cleanup()
+	tg.makeTempdir()
+	tg.setenv("GOCACHE", tg.path("cache"))
+	tg.setenv("GOPATH", tg.path("gopath"))
+	tg.tempFile("gopath/src/t/t_test.go", `package t
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestFile(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("file %s", data)
+}
+
+func TestEnv(t *testing.T) {
+	t.Logf("env %s", os.Getenv("TESTCACHEVAR"))
+}
+`)
+	file := tg.path("gopath/src/t/testdata/file.txt")
+	writeOld := func(data string) {
+		tg.tempFile("gopath/src/t/testdata/file.txt", data)
+		// Back-date the file, since recently modified files
+		// are not trusted as cache inputs.
+		old := time.Now().Add(-1 * time.Hour)
+		tg.must(os.Chtimes(file, old, old))
+	}
+	writeOld("x")
+
+	tg.run("test", "t")
+	tg.grepStdoutNot(`\(cached\)`, "first run was cached")
+	tg.run("test", "t")
+	tg.grepStdout(`^ok  \tt\t\(cached\)`, "second run was not cached")
+
+	// Flags that change only the output are part of the key.
+	tg.run("test", "-v", "t")
+	tg.grepStdoutNot(`\(cached\)`, "-v run used cached result of non-verbose run")
+	tg.run("test", "-v", "t")
+	tg.grepStdout(`\(cached\)`, "second -v run was not cached")
+	tg.grepStdout("file x", "cached -v run did not replay test output")
+
+	// Other flags disable caching.
+	tg.run("test", "-count=1", "t")
+	tg.grepStdoutNot(`\(cached\)`, "-count=1 run was cached")
+
+	// Changing a file or environment variable the test used invalidates the result.
+	writeOld("y")
+	tg.run("test", "t")
+	tg.grepStdoutNot(`\(cached\)`, "run after file change was cached")
+	tg.setenv("TESTCACHEVAR", "z")
+	tg.run("test", "t")
+	tg.grepStdoutNot(`\(cached\)`, "run after environment change was cached")
+	tg.run("test", "t")
+	tg.grepStdout(`\(cached\)`, "run with unchanged inputs was not cached")
+
+	// Tests run in the current directory without a package list are never cached.
+	tg.cd(tg.path("gopath/src/t"))
+	tg.run("test")
+	tg.grepStdoutNot(`\(cached\)`, "go test without package list was cached")
+
+	tg.run("clean", "-cache")
+	tg.run("test", "t")
+	tg.grepStdoutNot(`\(cached\)`, "run after go clean -cache was cached")
+}
+
+// tempModule adds version vers of the module with the given path
+// to the file-based module proxy in the "proxy" directory of tg's
+// temporary directory. The files map gives the module's files,
+// which must include go.mod.
+func (tg *testgoData) tempModule(path, vers string, files map[string]string) {
+	tg.t.Helper()
+	dir := "proxy/" + path + "/@v/"
+	tg.tempFile(dir+vers+".info", fmt.Sprintf(`{"Version":%q,"Time":"2018-02-14T00:00:00Z"}`, vers))
+	tg.tempFile(dir+vers+".mod", files["go.mod"])
+
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := z.Create(path + "@" + vers + "/" + name)
+		tg.must(err)
+		_, err = w.Write([]byte(data))
+		tg.must(err)
+	}
+	tg.must(z.Close())
+	tg.tempFile(dir+vers+".zip", buf.String())
+
+	list, _ := ioutil.ReadFile(tg.path(dir + "list"))
+	tg.tempFile(dir+"list", string(list)+vers+"\n")
+}
+
+// setupModProxy configures tg to use the file-based module
+// proxy in its temporary directory and a fresh GOPATH.
+func (tg *testgoData) setupModProxy() {
+	tg.t.Helper()
+	u := filepath.ToSlash(tg.path("proxy"))
+	if !strings.HasPrefix(u, "/") {
+		u = "/" + u
+	}
+	tg.setenv("GOPROXY", "file://"+u)
+	tg.setenv("GOPATH", tg.path("gopath"))
+}
+
+// addModTestProxy populates the module proxy with a small
+// dependency graph used by the module tests:
+//
+//	example.com/greet v1.0.0 requires example.com/lang v0.2.0
+//	example.com/greet v1.1.0 requires example.com/lang v0.3.0
+//	example.com/lang v0.1.0, v0.2.0, v0.3.0 have no requirements
+func (tg *testgoData) addModTestProxy() {
+	tg.t.Helper()
+	for _, v := range []string{"v0.1.0", "v0.2.0", "v0.3.0"} {
+		tg.tempModule("example.com/lang", v, map[string]string{
+			"go.mod":  "module example.com/lang\n",
+			"lang.go": "package lang\n\nfunc Version() string { return \"lang " + v + "\" }\n",
+		})
+	}
+	for _, v := range []string{"v1.0.0", "v1.1.0"} {
+		langVers := "v0.2.0"
+		if v == "v1.1.0" {
+			langVers = "v0.3.0"
+		}
+		tg.tempModule("example.com/greet", v, map[string]string{
+			"go.mod":              "module example.com/greet\n\nrequire example.com/lang " + langVers + "\n",
+			"greet.go":            "package greet\n\nimport \"example.com/lang\"\n\nfunc Greet() string { return \"greet " + v + " \" + lang.Version() }\n",
+			"internal/x/x.go":     "package x\n",
+			"cmd/greet/greet.go":  "package main\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/greet\"\n)\n\nfunc main() { fmt.Println(greet.Greet()) }\n",
+			"cmd/greet/greet2.go": "package main\n\nimport _ \"example.com/greet/internal/x\"\n",
+		})
+	}
+}
+
+const modTestMain = `package main
+
+import (
+	"fmt"
+
+	"example.com/greet"
+)
+
+func main() { fmt.Println(greet.Greet()) }
+`
+
+func TestModBuild(t *testing.T) {
+	tg := testgo(t)
+	defer tg.cleanup()
+	tg.makeTempdir()
+	tg.setupModProxy()
+	tg.addModTestProxy()
+	tg.tempFile("hello/go.mod", "module example.com/hello\n\nrequire (\n\texample.com/greet v1.0.0\n\texample.com/lang v0.1.0\n)\n")
+	tg.tempFile("hello/main.go", modTestMain)
+	tg.cd(tg.path("hello"))
+
+	// Minimal version selection: greet v1.0.0 needs lang v0.2.0,
+	// which is newer than the v0.1.0 listed in go.mod.
+	tg.run("list", "-m", "all")
+	tg.grepStdout(`^example.com/hello$`, "main module not listed first")
+	tg.grepStdout(`^example.com/greet v1.0.0$`, "wrong version of greet")
+	tg.grepStdout(`^example.com/lang v0.2.0$`, "wrong version of lang")
+
+	tg.run("build", "-o", "hello"+exeSuffix, ".")
+	tg.wantExecutable(tg.path("hello/hello"+exeSuffix), "go build did not write hello")
+	tg.run("run", "main.go")
+	tg.grepStdout(`greet v1.0.0 lang v0.2.0`, "go run used wrong versions")
+
+	tg.run("list", "-f", "{{.ImportPath}} {{.Module.Path}} {{.Module.Version}}", "example.com/lang")
+	tg.grepStdout(`^example.com/lang example.com/lang v0.2.0$`, "go list reported wrong module for package")
+
+	// The checksums of the downloaded modules are recorded in go.sum.
+	sum, err := ioutil.ReadFile(tg.path("hello/go.sum"))
+	tg.must(err)
+	for _, want := range []string{
+		"example.com/greet v1.0.0 h1:",
+		"example.com/greet v1.0.0/go.mod h1:",
+		"example.com/lang v0.2.0 h1:",
+	} {
+		if !strings.Contains(string(sum), want) {
+			t.Errorf("go.sum does not contain %q:\n%s", want, sum)
+		}
+	}
+
+	// A go.sum mismatch is an error, even for a cached download.
+	bad := regexp.MustCompile(`(example.com/lang v0.2.0 h1:)\S+`).ReplaceAllString(string(sum), "${1}AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")
+	tg.tempFile("hello/go.sum", bad)
+	tg.runFail("build", "-o", "hello"+exeSuffix, ".")
+	tg.grepStderr(`verifying example.com/lang@v0.2.0: checksum mismatch`, "did not detect go.sum mismatch")
+	tg.tempFile("hello/go.sum", string(sum))
+
+	// Exclusions select the next available version.
+	tg.tempFile("hello/go.mod", "module example.com/hello\n\nrequire example.com/greet v1.0.0\n\nexclude example.com/lang v0.2.0\n")
+	tg.run("list", "-m", "all")
+	tg.grepStdout(`^example.com/lang v0.3.0$`, "exclude did not select next version")
+
+	// Replacements can point at a local directory.
+	tg.tempFile("lang/go.mod", "module example.com/lang\n")
+	tg.tempFile("lang/lang.go", "package lang\n\nfunc Version() string { return \"local lang\" }\n")
+	tg.tempFile("hello/go.mod", "module example.com/hello\n\nrequire example.com/greet v1.0.0\n\nreplace example.com/lang => ../lang\n")
+	tg.run("run", "main.go")
+	tg.grepStdout(`greet v1.0.0 local lang`, "replacement directory not used")
+	tg.run("list", "-m", "example.com/lang")
+	tg.grepStdout(`^example.com/lang v0.2.0 => \.\./lang$`, "go list -m did not report replacement")
+
+	// Internal packages of a dependency are not visible to the main module.
+	tg.tempFile("hello/internal.go", "package main\n\nimport _ \"example.com/greet/internal/x\"\n")
+	tg.runFail("build", "-o", "hello"+exeSuffix, ".")
+	tg.grepStderr(`use of internal package not allowed`, "imported internal package of another module")
+	tg.must(os.Remove(tg.path("hello/internal.go")))
+
+	// But they are visible to the module that contains them.
+	tg.run("install", "example.com/greet/cmd/greet")
+	tg.wantExecutable(tg.path("gopath/bin/greet"+exeSuffix), "go install did not install greet in GOPATH/bin")
+
+	// A missing dependency is reported by package path.
+	tg.tempFile("hello/missing.go", "package main\n\nimport _ \"example.com/missing\"\n")
+	tg.runFail("build", "-o", "hello"+exeSuffix, ".")
+	tg.grepStderr(`cannot find module providing package example.com/missing`, "missing module not reported")
+}
+
+func TestModBuildInfo(t *testing.T) {
+	tg := testgo(t)
+	defer tg.cleanup()
+	tg.makeTempdir()
+	tg.setupModProxy()
+	tg.addModTestProxy()
+	tg.tempFile("hello/go.mod", "module example.com/hello\n\nrequire example.com/greet v1.0.0\n\nreplace example.com/lang => ../lang\n")
+	tg.tempFile("hello/main.go", `package main
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"example.com/greet"
+)
+
+func main() {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		panic("no build info")
+	}
+	fmt.Println(greet.Greet())
+	fmt.Printf("path %s\n", info.Path)
+	fmt.Printf("main %s %s\n", info.Main.Path, info.Main.Version)
+	for _, m := range info.Deps {
+		fmt.Printf("dep %s %s\n", m.Path, m.Version)
+	}
+}
+`)
+	tg.tempFile("lang/go.mod", "module example.com/lang\n")
+	tg.tempFile("lang/lang.go", "package lang\n\nfunc Version() string { return \"local lang\" }\n")
+	tg.cd(tg.path("hello"))
+
+	tg.run("build", "-buildvcs=false", "-ldflags=-X main.unused=1", "-o", "hello"+exeSuffix, ".")
+	tg.run("version", "-m", "hello"+exeSuffix)
+	tg.grepStdout(`^hello`+regexp.QuoteMeta(exeSuffix)+`: `, "go version -m did not report Go version")
+	tg.grepStdout(`^\tpath\texample.com/hello$`, "go version -m did not report main package")
+	tg.grepStdout(`^\tmod\texample.com/hello\t\(devel\)\t$`, "go version -m did not report main module")
+	tg.grepStdout(`^\tdep\texample.com/greet\tv1.0.0\th1:`, "go version -m did not report dependency with checksum")
+	tg.grepStdout(`^\tdep\texample.com/lang\tv0.2.0$`, "go version -m did not report replaced dependency")
+	tg.grepStdout(`^\t=>\t\.\./lang\t\t$`, "go version -m did not report replacement")
+	tg.grepStdout(`^\tbuild\t-ldflags="-X main.unused=1"$`, "go version -m did not report -ldflags")
+	tg.grepStdout(`^\tbuild\tGOOS=`+runtime.GOOS+`$`, "go version -m did not report GOOS")
+	tg.grepStdoutNot(`\tvcs`, "go version -m reported VCS information with -buildvcs=false")
+
+	// The same information is available to the program itself.
+	out, err := exec.Command(tg.path("hello/hello" + exeSuffix)).Output()
+	if err != nil {
+		t.Fatalf("running hello: %v", err)
+	}
+	for _, want := range []string{
+		"greet v1.0.0 local lang\n",
+		"path example.com/hello\n",
+		"main example.com/hello (devel)\n",
+		"dep example.com/greet v1.0.0\n",
+		"dep example.com/lang v0.2.0\n",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("hello output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	// Directory scans skip files that are not Go binaries.
+	tg.run("version", "-m", tg.path("hello"))
+	tg.grepStderrNot(`.`, "go version -m reported unrecognized files in a directory")
+	tg.runFail("version", "-m", "main.go")
+	tg.grepStderr(`main.go: not executable file`, "go version -m did not reject source file")
+	tg.runFail("version", "-m")
+	tg.grepStderr(`flags can only be used with arguments`, "go version -m did not require arguments")
+}
+
+func TestModGet(t *testing.T) {
+	tg := testgo(t)
+	defer tg.cleanup()
+	tg.makeTempdir()
+	tg.setupModProxy()
+	tg.addModTestProxy()
+	tg.tempFile("hello/go.mod", "module example.com/hello\n")
+	tg.tempFile("hello/main.go", modTestMain)
+	tg.cd(tg.path("hello"))
+
+	tg.runFail("build", "-o", "hello"+exeSuffix, ".")
+	tg.grepStderr(`cannot find module providing package example.com/greet`, "missing requirement not reported")
+
+	tg.run("get", "-d", "example.com/greet@v1.0.0")
+	tg.run("list", "-m", "all")
+	tg.grepStdout(`^example.com/greet v1.0.0$`, "go get did not add greet v1.0.0")
+	tg.grepStdout(`^example.com/lang v0.2.0$`, "go get did not use version of lang required by greet")
+	tg.run("run", "main.go")
+	tg.grepStdout(`greet v1.0.0 lang v0.2.0`, "go run used wrong versions after go get")
+
+	// A version prefix selects the latest matching release.
+	tg.run("get", "-d", "example.com/lang@v0.1")
+	tg.run("list", "-m", "all")
+	tg.grepStdout(`^example.com/lang v0.2.0$`, "go get of older version downgraded below requirement of greet")
+
+	tg.run("get", "-d", "-u")
+	tg.run("list", "-m", "all")
+	tg.grepStdout(`^example.com/greet v1.1.0$`, "go get -u did not upgrade greet")
+	tg.grepStdout(`^example.com/lang v0.3.0$`, "go get -u did not upgrade lang")
+
+	tg.run("get", "-d", "example.com/lang@none")
+	gomod, err := ioutil.ReadFile(tg.path("hello/go.mod"))
+	tg.must(err)
+	if strings.Contains(string(gomod), "example.com/lang") {
+		t.Errorf("go get example.com/lang@none did not remove requirement:\n%s", gomod)
+	}
+
+	tg.runFail("get", "-d", "example.com/greet@v9.9.9")
+	tg.grepStderr(`example.com/greet@v9.9.9`, "go get of unknown version not reported")
+}
+
+func TestModGOPATHMode(t *testing.T) {
+	tg := testgo(t)
+	defer tg.cleanup()
+	tg.makeTempdir()
+	tg.setupModProxy()
+	tg.tempFile("gopath/src/x/go.mod", "module x\n")
+	tg.tempFile("gopath/src/x/x.go", "package x\n")
+	tg.tempFile("m/go.mod", "module m\n")
+	tg.tempFile("m/m.go", "package m\n")
+
+	// Inside GOPATH/src, go.mod files are ignored unless GO111MODULE=on.
+	tg.cd(tg.path("gopath/src/x"))
+	tg.run("list", ".")
+	tg.grepStdout(`^x$`, "wrong import path in GOPATH mode")
+	tg.runFail("list", "-m")
+	tg.grepStderr(`not using modules`, "go list -m in GOPATH mode")
+	tg.setenv("GO111MODULE", "on")
+	tg.run("list", "-m")
+	tg.grepStdout(`^x$`, "GO111MODULE=on did not enable module mode")
+
+	// Outside GOPATH, a go.mod enables module mode, unless GO111MODULE=off.
+	tg.cd(tg.path("m"))
+	tg.setenv("GO111MODULE", "auto")
+	tg.run("env", "GOMOD")
+	tg.grepStdout(regexp.QuoteMeta(tg.path("m/go.mod")), "GOMOD not set in module mode")
+	tg.run("list", ".")
+	tg.grepStdout(`^m$`, "wrong import path in module mode")
+	tg.setenv("GO111MODULE", "off")
+	tg.run("list", ".")
+	tg.grepStdout(`^_/`, "GO111MODULE=off did not disable module mode")
+}
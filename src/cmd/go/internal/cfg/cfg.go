@@ -20,6 +20,7 @@ import (
 var (
 	BuildA                 bool   // -a flag
 	BuildBuildmode         string // -buildmode flag
+	BuildBuildvcs          = true // -buildvcs flag
 	BuildContext           = build.Default
 	BuildI                 bool               // -i flag
 	BuildLdflags           []string           // -ldflags flag
@@ -37,6 +38,11 @@ var (
 	BuildV                 bool // -v flag
 	BuildWork              bool // -work flag
 	BuildX                 bool // -x flag
+
+	// ModulesEnabled specifies whether the go command is running
+	// in module-aware mode (as opposed to GOPATH mode).
+	// It is set by modload.Init and must not be changed afterward.
+	ModulesEnabled bool
 )
 
 func init() {
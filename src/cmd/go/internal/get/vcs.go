@@ -16,11 +16,14 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"cmd/go/internal/base"
 	"cmd/go/internal/cfg"
+	"cmd/go/internal/load"
 	"cmd/go/internal/web"
 )
 
@@ -43,6 +46,7 @@ type vcsCmd struct {
 
 	remoteRepo  func(v *vcsCmd, rootDir string) (remoteRepo string, err error)
 	resolveRepo func(v *vcsCmd, rootDir, remoteRepo string) (realRepo string, err error)
+	status      func(v *vcsCmd, rootDir string) (load.VCSStatus, error)
 }
 
 var defaultSecureScheme = map[string]bool{
@@ -130,6 +134,7 @@ var vcsHg = &vcsCmd{
 	scheme:     []string{"https", "http", "ssh"},
 	pingCmd:    "identify {scheme}://{repo}",
 	remoteRepo: hgRemoteRepo,
+	status:     hgStatus,
 }
 
 func hgRemoteRepo(vcsHg *vcsCmd, rootDir string) (remoteRepo string, err error) {
@@ -140,6 +145,46 @@ func hgRemoteRepo(vcsHg *vcsCmd, rootDir string) (remoteRepo string, err error)
 	return strings.TrimSpace(string(out)), nil
 }
 
+func hgStatus(vcsHg *vcsCmd, rootDir string) (load.VCSStatus, error) {
+	// Output changeset ID and seconds since epoch.
+	out, err := vcsHg.runOutputVerboseOnly(rootDir, "log -l1 -T {node}:{date|hgdate}")
+	if err != nil {
+		return load.VCSStatus{}, err
+	}
+
+	// Successful execution without output indicates an empty repo (no commits).
+	var rev string
+	var commitTime time.Time
+	if len(out) > 0 {
+		// Strip trailing timezone offset.
+		if i := bytes.IndexByte(out, ' '); i > 0 {
+			out = out[:i]
+		}
+		i := bytes.IndexByte(out, ':')
+		if i < 0 {
+			return load.VCSStatus{}, fmt.Errorf("unrecognized output from hg: %q", out)
+		}
+		rev = string(out[:i])
+		secs, err := strconv.ParseInt(string(out[i+1:]), 10, 64)
+		if err != nil {
+			return load.VCSStatus{}, fmt.Errorf("unrecognized output from hg: %q", out)
+		}
+		commitTime = time.Unix(secs, 0)
+	}
+
+	// Also look for untracked files.
+	out, err = vcsHg.runOutputVerboseOnly(rootDir, "status")
+	if err != nil {
+		return load.VCSStatus{}, err
+	}
+
+	return load.VCSStatus{
+		Revision: rev,
+		Time:     commitTime,
+		Modified: len(out) > 0,
+	}, nil
+}
+
 // vcsGit describes how to use Git.
 var vcsGit = &vcsCmd{
 	name: "Git",
@@ -167,6 +212,7 @@ var vcsGit = &vcsCmd{
 	scheme:     []string{"git", "https", "http", "git+ssh", "ssh"},
 	pingCmd:    "ls-remote {scheme}://{repo}",
 	remoteRepo: gitRemoteRepo,
+	status:     gitStatus,
 }
 
 // scpSyntaxRe matches the SCP-like addresses used by Git to access
@@ -217,6 +263,41 @@ func gitRemoteRepo(vcsGit *vcsCmd, rootDir string) (remoteRepo string, err error
 	return "", errParse
 }
 
+func gitStatus(vcsGit *vcsCmd, rootDir string) (load.VCSStatus, error) {
+	out, err := vcsGit.runOutputVerboseOnly(rootDir, "status --porcelain")
+	if err != nil {
+		return load.VCSStatus{}, err
+	}
+	modified := len(out) > 0
+
+	// "git status" works for empty repositories, but "git log" does not.
+	// Assume there are no commits in the repo when "git log" fails with
+	// uncommitted files and skip recording the revision and commit time.
+	var rev string
+	var commitTime time.Time
+	out, err = vcsGit.runOutputVerboseOnly(rootDir, "-c log.showsignature=false log -1 --format=%H:%ct")
+	if err != nil && !modified {
+		return load.VCSStatus{}, err
+	} else if err == nil {
+		f := strings.SplitN(strings.TrimSpace(string(out)), ":", 2)
+		if len(f) != 2 {
+			return load.VCSStatus{}, fmt.Errorf("unrecognized output from git: %q", out)
+		}
+		secs, err := strconv.ParseInt(f[1], 10, 64)
+		if err != nil {
+			return load.VCSStatus{}, fmt.Errorf("unrecognized output from git: %q", out)
+		}
+		rev = f[0]
+		commitTime = time.Unix(secs, 0)
+	}
+
+	return load.VCSStatus{
+		Revision: rev,
+		Time:     commitTime,
+		Modified: modified,
+	}, nil
+}
+
 // vcsBzr describes how to use Bazaar.
 var vcsBzr = &vcsCmd{
 	name: "Bazaar",
@@ -380,6 +461,12 @@ func (v *vcsCmd) runOutput(dir string, cmd string, keyval ...string) ([]byte, er
 	return v.run1(dir, cmd, keyval, true)
 }
 
+// runOutputVerboseOnly is like runOutput but only generates error output to
+// standard error in verbose mode.
+func (v *vcsCmd) runOutputVerboseOnly(dir string, cmd string, keyval ...string) ([]byte, error) {
+	return v.run1(dir, cmd, keyval, false)
+}
+
 // run1 is the generalized implementation of run and runOutput.
 func (v *vcsCmd) run1(dir string, cmdline string, keyval []string, verbose bool) ([]byte, error) {
 	m := make(map[string]string)
@@ -568,6 +655,65 @@ func vcsFromDir(dir, srcRoot string) (vcs *vcsCmd, root string, err error) {
 	return nil, "", fmt.Errorf("directory %q is not using a known version control system", origDir)
 }
 
+// vcsStatusCache caches the results of vcsStatus by repository root.
+var vcsStatusCache struct {
+	sync.Mutex
+	m map[string]vcsStatusResult
+}
+
+type vcsStatusResult struct {
+	vcs    string
+	status load.VCSStatus
+	err    error
+}
+
+// vcsStatus reports the state of the version control checkout
+// containing dir, for recording in binaries built from it.
+// It searches dir and its parents for a repository of a known
+// version control system. It returns an empty vcs name if there is
+// none, or if the version control tool cannot report the status of
+// the checkout or is not installed.
+func vcsStatus(dir string) (vcsName string, status load.VCSStatus, err error) {
+	dir = filepath.Clean(dir)
+	var vcs *vcsCmd
+	var root string
+Search:
+	for {
+		for _, v := range vcsList {
+			if _, err := os.Stat(filepath.Join(dir, "."+v.cmd)); err == nil {
+				vcs, root = v, dir
+				break Search
+			}
+		}
+		ndir := filepath.Dir(dir)
+		if len(ndir) >= len(dir) {
+			return "", load.VCSStatus{}, nil
+		}
+		dir = ndir
+	}
+	if vcs.status == nil {
+		return "", load.VCSStatus{}, nil
+	}
+	if _, err := exec.LookPath(vcs.cmd); err != nil {
+		return "", load.VCSStatus{}, nil
+	}
+
+	vcsStatusCache.Lock()
+	defer vcsStatusCache.Unlock()
+	if r, ok := vcsStatusCache.m[root]; ok {
+		return r.vcs, r.status, r.err
+	}
+	status, err = vcs.status(vcs, root)
+	if err != nil {
+		err = fmt.Errorf("error obtaining %s status in %s: %v", vcs.name, root, err)
+	}
+	if vcsStatusCache.m == nil {
+		vcsStatusCache.m = make(map[string]vcsStatusResult)
+	}
+	vcsStatusCache.m[root] = vcsStatusResult{vcs.cmd, status, err}
+	return vcs.cmd, status, err
+}
+
 // repoRoot represents a version control system, a repo, and a root of
 // where to put it on disk.
 type repoRoot struct {
@@ -969,6 +1115,8 @@ var vcsPathsAfterDynamic = []*vcsPath{
 }
 
 func init() {
+	load.VCSStatusForDir = vcsStatus
+
 	// fill in cached regexps.
 	// Doing this eagerly discovers invalid regexp syntax
 	// without having to run a command that needs that regexp.
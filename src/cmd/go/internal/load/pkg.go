@@ -6,7 +6,9 @@
 package load
 
 import (
+	"bytes"
 	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"go/build"
 	"go/token"
@@ -16,17 +18,56 @@ import (
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"cmd/go/internal/base"
 	"cmd/go/internal/buildid"
 	"cmd/go/internal/cfg"
+	"cmd/go/internal/modinfo"
 	"cmd/go/internal/str"
 )
 
 var IgnoreImports bool // control whether we ignore imports in packages
 
+// Hooks for module-aware mode, installed by modload.Init.
+// They are function variables because modload imports load.
+var (
+	ModLookup            func(path string) (dir string, err error) // directory for import path; "" for standard packages
+	ModPackageModuleInfo func(path string) *modinfo.ModulePublic   // module providing package
+	ModDirImportPath     func(dir string) string                   // import path of directory in main module
+	ModMatchPackages     func(pattern string) []string             // expand package pattern
+	ModBinDir            func() string                             // install location for commands
+	ModPackageBuildInfo  func(main string, deps []string) string   // module lines of build info for a main package
+)
+
+// VCSStatusForDir reports the version control status of the checkout
+// containing dir, for recording in binaries built from it. It returns
+// an empty vcs name if dir is not in a checkout of a version control
+// system the go command knows how to query. It is installed by package
+// get, which knows how to run version control commands.
+var VCSStatusForDir func(dir string) (vcs string, status VCSStatus, err error)
+
+// A VCSStatus describes the state of a version control checkout.
+type VCSStatus struct {
+	Revision string    // current revision; empty for a repository without commits
+	Time     time.Time // commit time of Revision
+	Modified bool      // the checkout has uncommitted changes
+}
+
+// A BuildSetting is a key-value pair recorded in the build
+// information of a binary. See runtime/debug.BuildSetting.
+type BuildSetting struct {
+	Key, Value string
+}
+
+// BuildFlagSettings holds the build flags set on the command line,
+// in the order they are recorded in binaries. It is filled in by
+// package work before the go command adds flags of its own.
+var BuildFlagSettings []BuildSetting
+
 // A Package describes a single package found in a directory.
 type Package struct {
 	PackagePublic                 // visible in 'go list'
@@ -52,6 +93,8 @@ type PackagePublic struct {
 	ConflictDir   string `json:",omitempty"` // Dir is hidden by this other directory
 	BinaryOnly    bool   `json:",omitempty"` // package cannot be recompiled
 
+	Module *modinfo.ModulePublic `json:",omitempty"` // info about package's containing module, if any
+
 	// Source files
 	GoFiles        []string `json:",omitempty"` // .go source files (excluding CgoFiles, TestGoFiles, XTestGoFiles)
 	CgoFiles       []string `json:",omitempty"` // .go sources files that import "C"
@@ -111,6 +154,7 @@ type PackageInternal struct {
 	OmitDebug    bool                 // tell linker not to write debug information
 	BuildID      string               // expected build ID for generated package
 	GobinSubdir  bool                 // install target would be subdir of GOBIN
+	BuildInfo    string               // add this info to package main
 }
 
 type NoGoError struct {
@@ -413,15 +457,38 @@ func LoadImport(path, srcDir string, parent *Package, stk *ImportStack, importPo
 			// Not vendoring, or we already found the vendored path.
 			buildMode |= build.IgnoreVendor
 		}
-		bp, err := cfg.BuildContext.Import(path, srcDir, buildMode)
+		var bp *build.Package
+		var err error
+		modDir := ""
+		if cfg.ModulesEnabled && !isLocal {
+			modDir, err = ModLookup(path)
+		}
+		switch {
+		case err != nil:
+			bp = new(build.Package)
+		case modDir != "":
+			bp, err = cfg.BuildContext.ImportDir(modDir, buildMode)
+			// Packages in modules have no install location
+			// for their archives; only commands are installed.
+			bp.Root = ""
+			bp.SrcRoot = ""
+			bp.PkgRoot = ""
+			bp.PkgObj = ""
+			bp.BinDir = ModBinDir()
+		default:
+			bp, err = cfg.BuildContext.Import(path, srcDir, buildMode)
+		}
 		bp.ImportPath = importPath
 		if cfg.GOBIN != "" {
 			bp.BinDir = cfg.GOBIN
 		}
-		if err == nil && !isLocal && bp.ImportComment != "" && bp.ImportComment != path &&
+		if err == nil && !cfg.ModulesEnabled && !isLocal && bp.ImportComment != "" && bp.ImportComment != path &&
 			!strings.Contains(path, "/vendor/") && !strings.HasPrefix(path, "vendor/") {
 			err = fmt.Errorf("code in directory %s expects import %q", bp.Dir, bp.ImportComment)
 		}
+		if modDir != "" {
+			p.Module = ModPackageModuleInfo(path)
+		}
 		p.load(stk, bp, err)
 		if p.Error != nil && p.Error.Pos == "" {
 			p = setErrorPos(p, importPos)
@@ -659,6 +726,23 @@ func disallowInternal(srcDir string, p *Package, stk *ImportStack) *Package {
 	if i > 0 {
 		i-- // rewind over slash in ".../internal"
 	}
+	if p.Module != nil {
+		// The package is in a module, possibly in the module cache,
+		// where its directory does not mirror its import path.
+		// Decide using the importer's import path instead.
+		importer := strings.TrimSuffix((*stk)[len(*stk)-2], " (test)")
+		importer = strings.TrimSuffix(importer, "_test")
+		if i == 0 || hasPathPrefix(importer, p.ImportPath[:i]) {
+			return p
+		}
+		perr := *p
+		perr.Error = &PackageError{
+			ImportStack: stk.Copy(),
+			Err:         "use of internal package not allowed",
+		}
+		perr.Incomplete = true
+		return &perr
+	}
 	parent := p.Dir[:i+len(p.Dir)-len(p.ImportPath)]
 	if hasFilePathPrefix(filepath.Clean(srcDir), filepath.Clean(parent)) {
 		return p
@@ -815,6 +899,7 @@ var GoTools = map[string]targetDir{
 	"cmd/addr2line": ToTool,
 	"cmd/api":       ToTool,
 	"cmd/asm":       ToTool,
+	"cmd/benchstat": ToTool,
 	"cmd/compile":   ToTool,
 	"cmd/cgo":       ToTool,
 	"cmd/cover":     ToTool,
@@ -827,6 +912,7 @@ var GoTools = map[string]targetDir{
 	"cmd/objdump":   ToTool,
 	"cmd/pack":      ToTool,
 	"cmd/pprof":     ToTool,
+	"cmd/test2json": ToTool,
 	"cmd/trace":     ToTool,
 	"cmd/vet":       ToTool,
 	"code.google.com/p/go.tools/cmd/cover": StalePath,
@@ -894,6 +980,12 @@ func (p *Package) load(stk *ImportStack, bp *build.Package, err error) {
 			return
 		}
 		_, elem := filepath.Split(p.Dir)
+		if p.Module != nil {
+			// The module cache directory name includes
+			// the module version; name the command after
+			// its import path instead.
+			elem = pathpkg.Base(p.ImportPath)
+		}
 		full := cfg.BuildContext.GOOS + "_" + cfg.BuildContext.GOARCH + "/" + elem
 		if cfg.BuildContext.GOOS != base.ToolGOOS || cfg.BuildContext.GOARCH != base.ToolGOARCH {
 			// Install cross-compiled binaries to subdirectories of bin.
@@ -1156,6 +1248,16 @@ func (p *Package) load(stk *ImportStack, bp *build.Package, err error) {
 		return
 	}
 
+	if p.Name == "main" && !p.Internal.ForceLibrary {
+		if err := p.setBuildInfo(); err != nil {
+			p.Error = &PackageError{
+				ImportStack: stk.Copy(),
+				Err:         err.Error(),
+			}
+			return
+		}
+	}
+
 	if p.BinaryOnly {
 		// For binary-only package, use build ID from supplied package binary.
 		buildID, err := buildid.ReadBuildID(p.Name, p.Target)
@@ -1167,6 +1269,100 @@ func (p *Package) load(stk *ImportStack, bp *build.Package, err error) {
 	}
 }
 
+// setBuildInfo records the build information for the main package p
+// in p.Internal.BuildInfo, in the format read by
+// runtime/debug.ParseBuildInfo: the import path of p, the modules
+// providing p and its dependencies, the build flags and target, and
+// the version control status of the checkout holding p.
+func (p *Package) setBuildInfo() error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "path\t%s\n", p.ImportPath)
+	if ModPackageBuildInfo != nil {
+		buf.WriteString(ModPackageBuildInfo(p.ImportPath, p.Deps))
+	}
+
+	settings := append([]BuildSetting(nil), BuildFlagSettings...)
+	add := func(key, value string) {
+		settings = append(settings, BuildSetting{key, value})
+	}
+	cgo := "0"
+	if cfg.BuildContext.CgoEnabled {
+		cgo = "1"
+	}
+	add("CGO_ENABLED", cgo)
+	add("GOARCH", cfg.BuildContext.GOARCH)
+	add("GOOS", cfg.BuildContext.GOOS)
+	switch cfg.BuildContext.GOARCH {
+	case "arm":
+		add("GOARM", cfg.GOARM)
+	case "386":
+		add("GO386", cfg.GO386)
+	}
+
+	// Record the version control status of the checkout holding p,
+	// unless p comes from the module cache or GOROOT, which are not
+	// checkouts of their own.
+	if cfg.BuildBuildvcs && VCSStatusForDir != nil && !p.Goroot && p.Dir != "" &&
+		(p.Module == nil || p.Module.Version == "") {
+		vcs, st, err := VCSStatusForDir(p.Dir)
+		if err != nil {
+			return fmt.Errorf("%v\n\tUse -buildvcs=false to disable VCS stamping.", err)
+		}
+		if vcs != "" {
+			add("vcs", vcs)
+			if st.Revision != "" {
+				add("vcs.revision", st.Revision)
+				add("vcs.time", st.Time.UTC().Format(time.RFC3339))
+			}
+			add("vcs.modified", strconv.FormatBool(st.Modified))
+		}
+	}
+
+	for _, s := range settings {
+		key := s.Key
+		if buildInfoQuoteKey(key) {
+			key = strconv.Quote(key)
+		}
+		value := s.Value
+		if buildInfoQuoteValue(value) {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&buf, "build\t%s=%s\n", key, value)
+	}
+
+	p.Internal.BuildInfo = buf.String()
+	return nil
+}
+
+var (
+	infoStart, _ = hex.DecodeString("3077af0c9274080241e1c107e6d618e6")
+	infoEnd, _   = hex.DecodeString("f932433186182072008242104116d8f2")
+)
+
+// ModInfoProg returns the source of a Go file in package main that
+// sets runtime.modinfo to the build information info. The information
+// is bracketed by markers so that 'go version -m' can check that it
+// found it in a binary.
+func ModInfoProg(info string) []byte {
+	return []byte(fmt.Sprintf(`package main
+import _ "unsafe"
+//go:linkname __debug_modinfo__ runtime.modinfo
+var __debug_modinfo__ = %q
+`, string(infoStart)+info+string(infoEnd)))
+}
+
+// buildInfoQuoteKey reports whether a build setting key must be
+// quoted in build information. It matches runtime/debug.
+func buildInfoQuoteKey(key string) bool {
+	return len(key) == 0 || strings.ContainsAny(key, "= \t\r\n\"`")
+}
+
+// buildInfoQuoteValue reports whether a build setting value must be
+// quoted in build information. It matches runtime/debug.
+func buildInfoQuoteValue(value string) bool {
+	return strings.ContainsAny(value, " \t\r\n\"`")
+}
+
 // InternalDeps returns the full dependency list for p,
 // built by traversing p.Internal.Imports, their .Internal.Imports, and so on.
 // It guarantees that the returned list has only one package per ImportPath
@@ -1730,6 +1926,12 @@ func computeBuildID(p *Package) {
 		fmt.Fprintf(h, "dep %s %s\n", p1.ImportPath, p1.Internal.BuildID)
 	}
 
+	// Include the build information recorded in commands,
+	// so that they are relinked when it changes.
+	if p.Internal.BuildInfo != "" {
+		fmt.Fprintf(h, "modinfo %q\n", p.Internal.BuildInfo)
+	}
+
 	p.Internal.BuildID = fmt.Sprintf("%x", h.Sum(nil))
 }
 
@@ -1786,6 +1988,14 @@ func LoadPackage(arg string, stk *ImportStack) *Package {
 		return p
 	}
 
+	// In module mode, a directory in the main module
+	// is named by its module import path.
+	if cfg.ModulesEnabled && build.IsLocalImport(arg) {
+		if path := ModDirImportPath(filepath.Join(base.Cwd, arg)); path != "" {
+			return LoadImport(path, base.Cwd, nil, stk, nil, 0)
+		}
+	}
+
 	// Wasn't a command; must be a package.
 	// If it is a local import path but names a standard package,
 	// we treat it as if the user specified the standard package.
@@ -21,7 +21,12 @@ import (
 // The pattern is either "all" (all packages), "std" (standard packages),
 // "cmd" (standard commands), or a path including "...".
 func allPackages(pattern string) []string {
-	pkgs := MatchPackages(pattern)
+	var pkgs []string
+	if cfg.ModulesEnabled {
+		pkgs = ModMatchPackages(pattern)
+	} else {
+		pkgs = MatchPackages(pattern)
+	}
 	if len(pkgs) == 0 {
 		fmt.Fprintf(os.Stderr, "warning: %q matched no packages\n", pattern)
 	}
@@ -197,6 +202,19 @@ func MatchPackagesInFS(pattern string) []string {
 	return pkgs
 }
 
+// MatchPattern(pattern)(name) reports whether
+// name matches pattern. See matchPattern.
+func MatchPattern(pattern string) func(name string) bool {
+	return matchPattern(pattern)
+}
+
+// TreeCanMatchPattern(pattern)(name) reports whether
+// name or children of name can possibly match pattern.
+// See treeCanMatchPattern.
+func TreeCanMatchPattern(pattern string) func(name string) bool {
+	return treeCanMatchPattern(pattern)
+}
+
 // treeCanMatchPattern(pattern)(name) reports whether
 // name or children of name can possibly match pattern.
 // Pattern is the same limited glob accepted by matchPattern.
@@ -0,0 +1,329 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package modfetch downloads modules from a module proxy
+// and maintains the local module cache and the go.sum file.
+package modfetch
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/dirhash"
+	"cmd/go/internal/module"
+)
+
+// PkgMod is the root of the module cache, $GOPATH/pkg/mod.
+// It is set by modload.Init.
+var PkgMod string
+
+// CachePath returns the name of the file in the download cache
+// holding the given suffix ("info", "mod", "zip", or "ziphash")
+// for the module version.
+func CachePath(m module.Version, suffix string) (string, error) {
+	if PkgMod == "" {
+		return "", fmt.Errorf("internal error: modfetch.PkgMod not set")
+	}
+	enc, err := module.EncodePath(m.Path)
+	if err != nil {
+		return "", err
+	}
+	encVer, err := module.EncodeVersion(m.Version)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(PkgMod, "cache/download", enc, "@v", encVer+"."+suffix), nil
+}
+
+// DownloadDir returns the directory to which m should be
+// extracted in the module cache.
+func DownloadDir(m module.Version) (string, error) {
+	if PkgMod == "" {
+		return "", fmt.Errorf("internal error: modfetch.PkgMod not set")
+	}
+	enc, err := module.EncodePath(m.Path)
+	if err != nil {
+		return "", err
+	}
+	encVer, err := module.EncodeVersion(m.Version)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(PkgMod, enc+"@"+encVer), nil
+}
+
+// Download downloads the specific module version to the
+// local download cache and returns the name of the directory
+// corresponding to the root of the module's file tree.
+func Download(mod module.Version) (dir string, err error) {
+	if err := module.Check(mod.Path, mod.Version); err != nil {
+		return "", err
+	}
+	dir, err = DownloadDir(mod)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(dir); err == nil {
+		// The module is already extracted; make sure it is
+		// the one go.sum expects.
+		if err := checkMod(mod); err != nil {
+			return "", err
+		}
+		return dir, nil
+	}
+
+	zipfile, err := CachePath(mod, "zip")
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(zipfile); err != nil {
+		if err := downloadZip(mod, zipfile); err != nil {
+			return "", err
+		}
+	}
+	if err := checkMod(mod); err != nil {
+		return "", err
+	}
+
+	fmt.Fprintf(os.Stderr, "go: extracting %s %s\n", mod.Path, mod.Version)
+	if err := os.MkdirAll(filepath.Dir(dir), 0777); err != nil {
+		return "", err
+	}
+	tmp, err := ioutil.TempDir(filepath.Dir(dir), "tmp-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+	if err := unzip(tmp, zipfile, mod.Path+"@"+mod.Version+"/"); err != nil {
+		return "", fmt.Errorf("%s@%s: %v", mod.Path, mod.Version, err)
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// downloadZip fetches the zip file for mod from the proxy
+// and saves it and its hash in the download cache.
+func downloadZip(mod module.Version, zipfile string) error {
+	fmt.Fprintf(os.Stderr, "go: downloading %s %s\n", mod.Path, mod.Version)
+	data, err := download(mod.Path, mod.Version, ".zip")
+	if err != nil {
+		return err
+	}
+	if err := writeCacheFile(zipfile, data); err != nil {
+		return err
+	}
+	hash, err := dirhash.HashZip(zipfile, dirhash.DefaultHash)
+	if err != nil {
+		os.Remove(zipfile)
+		return fmt.Errorf("%s@%s: %v", mod.Path, mod.Version, err)
+	}
+	return writeCacheFile(zipfile+"hash", []byte(hash))
+}
+
+// unzip extracts the files in zipfile, all of which must
+// begin with prefix, into dir.
+func unzip(dir, zipfile, prefix string) error {
+	z, err := zip.OpenReader(zipfile)
+	if err != nil {
+		return err
+	}
+	defer z.Close()
+
+	for _, zf := range z.File {
+		if !strings.HasPrefix(zf.Name, prefix) {
+			return fmt.Errorf("unexpected file name %s in zip file", zf.Name)
+		}
+		name := zf.Name[len(prefix):]
+		if name == "" || strings.HasSuffix(name, "/") {
+			continue
+		}
+		for _, elem := range strings.Split(name, "/") {
+			if elem == "" || elem == "." || elem == ".." {
+				return fmt.Errorf("invalid file name %s in zip file", zf.Name)
+			}
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0777); err != nil {
+			return err
+		}
+		r, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		w, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+		if err != nil {
+			r.Close()
+			return err
+		}
+		_, err = io.Copy(w, r)
+		r.Close()
+		if err1 := w.Close(); err == nil {
+			err = err1
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCacheFile writes data to the named file in the download cache,
+// writing to a temporary file first so that a concurrent reader
+// never observes a partially written file.
+func writeCacheFile(file string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), file)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Sum returns the checksum for the downloaded copy of the given module,
+// if present in the download cache.
+func Sum(mod module.Version) string {
+	if PkgMod == "" {
+		// Do not use current directory.
+		return ""
+	}
+
+	ziphash, err := CachePath(mod, "ziphash")
+	if err != nil {
+		return ""
+	}
+	data, err := ioutil.ReadFile(ziphash)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// GoSumFile is the name of the go.sum file to use for verification.
+// It is set by modload.Init; if empty, downloads are not checked.
+var GoSumFile string
+
+var goSum struct {
+	m     map[module.Version][]string // content of go.sum file
+	dirty bool                        // whether we added any new hashes
+}
+
+// initGoSum reads the go.sum file, if it has not been read already.
+func initGoSum() {
+	if GoSumFile == "" || goSum.m != nil {
+		return
+	}
+	goSum.m = make(map[module.Version][]string)
+	data, err := ioutil.ReadFile(GoSumFile)
+	if err != nil && !os.IsNotExist(err) {
+		base.Fatalf("go: %v", err)
+	}
+	for lineno, line := range strings.Split(string(data), "\n") {
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			continue
+		}
+		if len(f) != 3 {
+			base.Fatalf("go: malformed go.sum:\n%s:%d: wrong number of fields %v", GoSumFile, lineno+1, len(f))
+		}
+		mod := module.Version{Path: f[0], Version: f[1]}
+		goSum.m[mod] = append(goSum.m[mod], f[2])
+	}
+}
+
+// checkMod checks the hash of the downloaded zip file for mod
+// against the go.sum file.
+func checkMod(mod module.Version) error {
+	ziphash, err := CachePath(mod, "ziphash")
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(ziphash)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// This can happen if someone extracts a module
+			// into the cache by hand; there is nothing to check.
+			return nil
+		}
+		return err
+	}
+	return checkModSum(mod, strings.TrimSpace(string(data)))
+}
+
+// checkGoMod checks the given go.mod file content for mod
+// against the go.sum file.
+func checkGoMod(mod module.Version, data []byte) error {
+	h, err := dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	})
+	if err != nil {
+		return err
+	}
+	return checkModSum(module.Version{Path: mod.Path, Version: mod.Version + "/go.mod"}, h)
+}
+
+// checkModSum checks that the recorded checksum for mod is h.
+// If go.sum has no checksum for mod, h is recorded.
+func checkModSum(mod module.Version, h string) error {
+	initGoSum()
+	if goSum.m == nil {
+		return nil
+	}
+	for _, vh := range goSum.m[mod] {
+		if h == vh {
+			return nil
+		}
+	}
+	if len(goSum.m[mod]) > 0 {
+		return fmt.Errorf("verifying %s@%s: checksum mismatch\n\tdownloaded: %v\n\tgo.sum:     %v", mod.Path, mod.Version, h, strings.Join(goSum.m[mod], ", "))
+	}
+	goSum.m[mod] = append(goSum.m[mod], h)
+	goSum.dirty = true
+	return nil
+}
+
+// WriteGoSum writes the go.sum file if it needs to be updated.
+func WriteGoSum() {
+	if !goSum.dirty {
+		return
+	}
+	var mods []module.Version
+	for m := range goSum.m {
+		mods = append(mods, m)
+	}
+	module.Sort(mods)
+	var buf bytes.Buffer
+	for _, m := range mods {
+		list := goSum.m[m]
+		sort.Strings(list)
+		for _, h := range list {
+			fmt.Fprintf(&buf, "%s %s %s\n", m.Path, m.Version, h)
+		}
+	}
+	if err := ioutil.WriteFile(GoSumFile, buf.Bytes(), 0666); err != nil {
+		base.Errorf("go: writing go.sum: %v", err)
+	}
+	goSum.dirty = false
+}
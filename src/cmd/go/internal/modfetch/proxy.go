@@ -0,0 +1,253 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modfetch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/cfg"
+	"cmd/go/internal/module"
+	"cmd/go/internal/semver"
+	"cmd/go/internal/web"
+)
+
+var HelpGoproxy = &base.Command{
+	UsageLine: "goproxy",
+	Short:     "module proxy protocol",
+	Long: `
+The go command downloads modules from a module proxy, an ordinary
+web server (or file tree) that responds to GET requests for URLs
+of a specified form. The GOPROXY environment variable gives the
+base URL of the proxy. It may use the https, http, or file scheme:
+
+	GOPROXY=https://proxy.example.com
+	GOPROXY=file:///home/gopher/proxy
+
+Setting GOPROXY=off disallows downloading modules entirely;
+only modules already present in the module cache can be used.
+
+The requests sent to a module proxy are:
+
+GET $GOPROXY/<module>/@v/list returns a list of all known versions
+of the given module, one per line.
+
+GET $GOPROXY/<module>/@v/<version>.info returns JSON-formatted
+metadata about that version of the given module.
+
+GET $GOPROXY/<module>/@v/<version>.mod returns the go.mod file
+for that version of the given module.
+
+GET $GOPROXY/<module>/@v/<version>.zip returns the zip archive
+for that version of the given module.
+
+To avoid problems when serving from case-sensitive file systems,
+the <module> and <version> elements are case-encoded, replacing every
+uppercase letter with an exclamation mark followed by the corresponding
+lower-case letter: github.com/Azure encodes as github.com/!azure.
+
+The JSON-formatted metadata about a given module corresponds to
+this Go data structure, which may be expanded in the future:
+
+	type Info struct {
+		Version string    // version string
+		Time    time.Time // commit time
+	}
+
+The zip archive for a specific version of a given module is a
+standard zip file that contains the file tree corresponding
+to the module's source code and related files. Every file in
+the archive must begin with the prefix <module>@<version>/,
+where the module and version are substituted directly, not
+case-encoded. The root of the module file tree corresponds
+to the <module>@<version>/ prefix in the archive.
+
+The go command stores the info, mod, and zip files it downloads
+in its local cache, $GOPATH/pkg/mod/cache/download.
+The cache layout is the same as the proxy URL space, so
+serving $GOPATH/pkg/mod/cache/download at (or copying it to)
+https://example.com/proxy would let other users access those
+cached module versions with GOPROXY=https://example.com/proxy.
+`,
+}
+
+// proxyURL is the base URL of the module proxy, from $GOPROXY.
+var proxyURL = os.Getenv("GOPROXY")
+
+// A RevInfo describes a single revision in a module repository.
+type RevInfo struct {
+	Version string    // version string
+	Time    time.Time // commit time
+}
+
+// errNotExist is returned by proxyGet when the proxy
+// does not have the requested file.
+var errNotExist = errors.New("not found")
+
+// proxyGet fetches the named file, relative to the proxy base URL.
+func proxyGet(file string) ([]byte, error) {
+	switch proxyURL {
+	case "":
+		return nil, errors.New("GOPROXY not set; see 'go help goproxy'")
+	case "off":
+		return nil, errors.New("module lookup disabled by GOPROXY=off")
+	}
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid $GOPROXY setting: %v", err)
+	}
+	switch u.Scheme {
+	case "file":
+		dir := u.Path
+		if runtime.GOOS == "windows" {
+			dir = strings.TrimPrefix(dir, "/")
+		}
+		data, err := ioutil.ReadFile(filepath.Join(filepath.FromSlash(dir), filepath.FromSlash(file)))
+		if os.IsNotExist(err) {
+			return nil, errNotExist
+		}
+		return data, err
+	case "http", "https":
+		target := strings.TrimSuffix(proxyURL, "/") + "/" + file
+		if cfg.BuildX {
+			fmt.Fprintf(os.Stderr, "# get %s\n", target)
+		}
+		data, err := web.Get(target)
+		if herr, ok := err.(*web.HTTPError); ok && (herr.StatusCode == 404 || herr.StatusCode == 410) {
+			return nil, errNotExist
+		}
+		return data, err
+	}
+	return nil, fmt.Errorf("invalid $GOPROXY setting: unsupported scheme %q", u.Scheme)
+}
+
+// proxyFile returns the proxy-relative name of the file
+// for the given module version and suffix (such as ".mod").
+func proxyFile(path, version, suffix string) (string, error) {
+	enc, err := module.EncodePath(path)
+	if err != nil {
+		return "", err
+	}
+	encVer, err := module.EncodeVersion(version)
+	if err != nil {
+		return "", err
+	}
+	return enc + "/@v/" + encVer + suffix, nil
+}
+
+// Versions returns the list of known release and pre-release
+// versions of the module with the given path, sorted in
+// increasing semantic version order.
+func Versions(path string) ([]string, error) {
+	enc, err := module.EncodePath(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := proxyGet(enc + "/@v/list")
+	if err != nil {
+		if err == errNotExist {
+			return nil, fmt.Errorf("unknown module %s", path)
+		}
+		return nil, fmt.Errorf("listing versions of %s: %v", path, err)
+	}
+	var list []string
+	for _, line := range strings.Split(string(data), "\n") {
+		f := strings.Fields(line)
+		if len(f) >= 1 && semver.IsValid(f[0]) && semver.Canonical(f[0]) == f[0] {
+			list = append(list, f[0])
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return semver.Compare(list[i], list[j]) < 0
+	})
+	return list, nil
+}
+
+// Stat returns information about the given version of the module.
+func Stat(path, version string) (*RevInfo, error) {
+	if err := module.Check(path, version); err != nil {
+		return nil, err
+	}
+	file, err := CachePath(module.Version{Path: path, Version: version}, "info")
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		data, err = download(path, version, ".info")
+		if err != nil {
+			return nil, err
+		}
+		if err := writeCacheFile(file, data); err != nil {
+			return nil, err
+		}
+	}
+	info := new(RevInfo)
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("%s@%s: invalid info file: %v", path, version, err)
+	}
+	if info.Version != version {
+		return nil, fmt.Errorf("%s@%s: info file reports version %s", path, version, info.Version)
+	}
+	return info, nil
+}
+
+// GoMod returns the go.mod file for the given version of the module,
+// after checking it against the go.sum file.
+func GoMod(path, version string) ([]byte, error) {
+	if err := module.Check(path, version); err != nil {
+		return nil, err
+	}
+	mod := module.Version{Path: path, Version: version}
+	file, err := CachePath(mod, "mod")
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		data, err = download(path, version, ".mod")
+		if err != nil {
+			return nil, err
+		}
+		if err := checkGoMod(mod, data); err != nil {
+			return nil, err
+		}
+		if err := writeCacheFile(file, data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+	if err := checkGoMod(mod, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// download fetches the file with the given suffix for
+// the module version from the proxy.
+func download(path, version, suffix string) ([]byte, error) {
+	file, err := proxyFile(path, version, suffix)
+	if err != nil {
+		return nil, err
+	}
+	data, err := proxyGet(file)
+	if err != nil {
+		if err == errNotExist {
+			return nil, fmt.Errorf("unknown module version %s@%s", path, version)
+		}
+		return nil, fmt.Errorf("downloading %s@%s: %v", path, version, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,505 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package modfile implements parsing and formatting for go.mod files.
+//
+// A go.mod file is a sequence of directives, one per line.
+// The module directive names the module defined by the file.
+// The require, exclude and replace directives each take one argument
+// list per line, and may be factored into a parenthesized block:
+//
+//	module example.com/hello
+//
+//	require (
+//		example.com/greet v1.2.0
+//		example.com/lang v0.3.1 // indirect
+//	)
+//
+//	exclude example.com/greet v1.1.0
+//
+//	replace example.com/lang v0.3.1 => ../lang
+//
+// Comments begin with // and run to the end of the line.
+// Comments on lines of their own and at the end of directives
+// are preserved when the file is formatted.
+package modfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"cmd/go/internal/module"
+	"cmd/go/internal/semver"
+)
+
+// A File is the parsed, interpreted form of a go.mod file.
+type File struct {
+	Module  *Module
+	Require []*Require
+	Exclude []*Exclude
+	Replace []*Replace
+
+	// Trailing holds comment lines following the last directive.
+	Trailing []string
+}
+
+// Comments holds the comments attached to a directive:
+// whole-line comments immediately before it and
+// a comment at the end of its line.
+// Comment text excludes the leading "//".
+type Comments struct {
+	Before []string
+	Suffix string
+}
+
+// A Module is the module statement.
+type Module struct {
+	Mod module.Version
+	Comments
+}
+
+// A Require is a single require statement.
+type Require struct {
+	Mod      module.Version
+	Indirect bool // has "// indirect" comment
+	Comments
+}
+
+// An Exclude is a single exclude statement.
+type Exclude struct {
+	Mod module.Version
+	Comments
+}
+
+// A Replace is a single replace statement.
+// If New.Version is empty, New.Path is a directory
+// holding the replacement module's source tree.
+type Replace struct {
+	Old module.Version
+	New module.Version
+	Comments
+}
+
+// An Error is a syntax or semantic error in a go.mod file.
+type Error struct {
+	File string
+	Line int
+	Err  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Err)
+}
+
+// Parse parses the data, reported in errors as being from file,
+// into a File struct.
+func Parse(file string, data []byte) (*File, error) {
+	p := &parser{file: file, f: new(File)}
+	lines := strings.Split(string(data), "\n")
+	var block string // verb of current parenthesized block, if any
+	var before []string
+	for i, line := range lines {
+		p.line = i + 1
+		text, comment, err := splitComment(line)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		args, err := splitArgs(text)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		if len(args) == 0 {
+			if strings.TrimSpace(line) != "" {
+				// A comment on a line of its own is attached
+				// to the directive that follows.
+				before = append(before, comment)
+			}
+			continue
+		}
+		c := Comments{Before: before, Suffix: comment}
+		before = nil
+
+		if block != "" {
+			if len(args) == 1 && args[0] == ")" {
+				block = ""
+				continue
+			}
+			if err := p.directive(block, args, c); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if len(args) == 2 && args[1] == "(" {
+			switch args[0] {
+			case "require", "exclude", "replace":
+				// Comments before the block are attached
+				// to its first directive.
+				block = args[0]
+				before = c.Before
+				continue
+			}
+			return nil, p.errorf("unknown block type: %s", args[0])
+		}
+		if err := p.directive(args[0], args[1:], c); err != nil {
+			return nil, err
+		}
+	}
+	if block != "" {
+		return nil, p.errorf("unterminated %s block", block)
+	}
+	p.f.Trailing = append(p.f.Trailing, before...)
+	return p.f, nil
+}
+
+type parser struct {
+	file string
+	line int
+	f    *File
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &Error{File: p.file, Line: p.line, Err: fmt.Sprintf(format, args...)}
+}
+
+// directive records the directive verb with the given arguments.
+func (p *parser) directive(verb string, args []string, c Comments) error {
+	f := p.f
+	switch verb {
+	default:
+		return p.errorf("unknown directive: %s", verb)
+
+	case "module":
+		if f.Module != nil {
+			return p.errorf("repeated module statement")
+		}
+		if len(args) != 1 {
+			return p.errorf("usage: module module/path")
+		}
+		f.Module = &Module{Mod: module.Version{Path: args[0]}, Comments: c}
+
+	case "require", "exclude":
+		if len(args) != 2 {
+			return p.errorf("usage: %s module/path v1.2.3", verb)
+		}
+		path := args[0]
+		if err := module.CheckPath(path); err != nil {
+			return p.errorf("invalid module path: %v", err)
+		}
+		v, err := p.version(path, args[1])
+		if err != nil {
+			return err
+		}
+		mod := module.Version{Path: path, Version: v}
+		if verb == "require" {
+			f.Require = append(f.Require, &Require{
+				Mod:      mod,
+				Indirect: isIndirect(c.Suffix),
+				Comments: c,
+			})
+		} else {
+			f.Exclude = append(f.Exclude, &Exclude{Mod: mod, Comments: c})
+		}
+
+	case "replace":
+		arrow := 2
+		if len(args) >= 2 && args[1] == "=>" {
+			arrow = 1
+		}
+		if len(args) < arrow+2 || len(args) > arrow+3 || args[arrow] != "=>" {
+			return p.errorf("usage: %s module/path [v1.2.3] => other/module v1.4\n\t or %s module/path [v1.2.3] => ../local/directory", verb, verb)
+		}
+		s := args[0]
+		if err := module.CheckPath(s); err != nil {
+			return p.errorf("invalid module path: %v", err)
+		}
+		var v string
+		if arrow == 2 {
+			var err error
+			if v, err = p.version(s, args[1]); err != nil {
+				return err
+			}
+		}
+		ns := args[arrow+1]
+		nv := ""
+		if len(args) == arrow+2 {
+			if !IsDirectoryPath(ns) {
+				return p.errorf("replacement module without version must be directory path (rooted or starting with ./ or ../)")
+			}
+			if filepath.Separator == '/' && strings.Contains(ns, `\`) {
+				return p.errorf("replacement directory appears to be Windows path (on a non-windows system)")
+			}
+		}
+		if len(args) == arrow+3 {
+			var err error
+			nv, err = p.version(ns, args[arrow+2])
+			if err != nil {
+				return err
+			}
+			if IsDirectoryPath(ns) {
+				return p.errorf("replacement module directory path %q cannot have version", ns)
+			}
+		}
+		f.Replace = append(f.Replace, &Replace{
+			Old:      module.Version{Path: s, Version: v},
+			New:      module.Version{Path: ns, Version: nv},
+			Comments: c,
+		})
+	}
+	return nil
+}
+
+// version checks that v is a valid semantic version for path
+// and returns its canonical form.
+func (p *parser) version(path, v string) (string, error) {
+	cv := semver.Canonical(v)
+	if cv == "" {
+		return "", p.errorf("invalid module version %q: not a semantic version", v)
+	}
+	if _, pathMajor, _ := module.SplitPathVersion(path); !module.MatchPathMajor(cv, pathMajor) {
+		return "", p.errorf("invalid module version %q: does not match path %s", v, path)
+	}
+	return cv, nil
+}
+
+// IsDirectoryPath reports whether the given path should be interpreted
+// as a directory path. Just like on the go command line, relative paths
+// and rooted paths are directory paths; the rest are module paths.
+func IsDirectoryPath(ns string) bool {
+	// Because go.mod files can move from one system to another,
+	// we check all known path syntaxes, both Unix and Windows.
+	return strings.HasPrefix(ns, "./") || strings.HasPrefix(ns, "../") || strings.HasPrefix(ns, "/") ||
+		strings.HasPrefix(ns, `.\`) || strings.HasPrefix(ns, `..\`) || strings.HasPrefix(ns, `\`) ||
+		len(ns) >= 2 && ('A' <= ns[0] && ns[0] <= 'Z' || 'a' <= ns[0] && ns[0] <= 'z') && ns[1] == ':'
+}
+
+// isIndirect reports whether the comment text marks
+// a requirement as indirect.
+func isIndirect(comment string) bool {
+	f := strings.Fields(comment)
+	return len(f) > 0 && f[0] == "indirect"
+}
+
+// splitComment splits line into its text and the text of its
+// trailing comment, if any, ignoring // inside quoted strings.
+func splitComment(line string) (text, comment string, err error) {
+	inQuote := false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case inQuote && c == '\\':
+			i++
+		case c == '"':
+			inQuote = !inQuote
+		case !inQuote && c == '/' && i+1 < len(line) && line[i+1] == '/':
+			return line[:i], strings.TrimSpace(line[i+2:]), nil
+		}
+	}
+	if inQuote {
+		return "", "", errors.New("unterminated quoted string")
+	}
+	return line, "", nil
+}
+
+// splitArgs splits text into space-separated arguments.
+// Arguments may be Go quoted strings, and "(", ")" and "=>"
+// are returned as arguments of their own.
+func splitArgs(text string) ([]string, error) {
+	var args []string
+	for {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		if text == "" {
+			return args, nil
+		}
+		switch {
+		case text[0] == '"':
+			i := 1
+			for ; i < len(text) && text[i] != '"'; i++ {
+				if text[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(text) {
+				return nil, errors.New("unterminated quoted string")
+			}
+			s, err := strconv.Unquote(text[:i+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted string %s", text[:i+1])
+			}
+			args = append(args, s)
+			text = text[i+1:]
+		case text[0] == '(' || text[0] == ')':
+			args = append(args, text[:1])
+			text = text[1:]
+		case strings.HasPrefix(text, "=>"):
+			args = append(args, "=>")
+			text = text[2:]
+		default:
+			i := strings.IndexFunc(text, func(r rune) bool {
+				return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+			})
+			if i < 0 {
+				i = len(text)
+			}
+			if j := strings.Index(text[:i], "=>"); j > 0 {
+				i = j
+			}
+			args = append(args, text[:i])
+			text = text[i:]
+		}
+	}
+}
+
+// AddModuleStmt sets the module path of the file.
+func (f *File) AddModuleStmt(path string) {
+	if f.Module == nil {
+		f.Module = new(Module)
+	}
+	f.Module.Mod = module.Version{Path: path}
+}
+
+// AddRequire adds or updates the requirement on path
+// to be at version vers.
+func (f *File) AddRequire(path, vers string) {
+	for _, r := range f.Require {
+		if r.Mod.Path == path {
+			r.Mod.Version = vers
+			return
+		}
+	}
+	f.Require = append(f.Require, &Require{Mod: module.Version{Path: path, Version: vers}})
+}
+
+// SetRequire replaces the requirements of the file with req.
+// Comments attached to requirements that remain are kept.
+func (f *File) SetRequire(req []*Require) {
+	old := make(map[string]*Require)
+	for _, r := range f.Require {
+		old[r.Mod.Path] = r
+	}
+	f.Require = nil
+	for _, r := range req {
+		if o := old[r.Mod.Path]; o != nil && r.Comments.Before == nil && r.Comments.Suffix == "" {
+			r.Comments = o.Comments
+		}
+		f.Require = append(f.Require, r)
+	}
+}
+
+// DropRequire removes the requirement on path, if any.
+func (f *File) DropRequire(path string) {
+	var req []*Require
+	for _, r := range f.Require {
+		if r.Mod.Path != path {
+			req = append(req, r)
+		}
+	}
+	f.Require = req
+}
+
+// Format returns the formatted form of the file.
+func (f *File) Format() []byte {
+	var b bytes.Buffer
+	sep := func() {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+	}
+	if f.Module != nil {
+		writeComments(&b, "", f.Module.Before)
+		fmt.Fprintf(&b, "module %s%s\n", quote(f.Module.Mod.Path), suffix(f.Module.Suffix))
+	}
+
+	var lines []stmt
+	for _, r := range f.Require {
+		lines = append(lines, stmt{r.Comments, quote(r.Mod.Path) + " " + r.Mod.Version})
+	}
+	if len(lines) > 0 {
+		sep()
+		writeBlock(&b, "require", lines)
+	}
+
+	lines = nil
+	for _, x := range f.Exclude {
+		lines = append(lines, stmt{x.Comments, quote(x.Mod.Path) + " " + x.Mod.Version})
+	}
+	if len(lines) > 0 {
+		sep()
+		writeBlock(&b, "exclude", lines)
+	}
+
+	lines = nil
+	for _, r := range f.Replace {
+		s := quote(r.Old.Path)
+		if r.Old.Version != "" {
+			s += " " + r.Old.Version
+		}
+		s += " => " + quote(r.New.Path)
+		if r.New.Version != "" {
+			s += " " + r.New.Version
+		}
+		lines = append(lines, stmt{r.Comments, s})
+	}
+	if len(lines) > 0 {
+		sep()
+		writeBlock(&b, "replace", lines)
+	}
+
+	if len(f.Trailing) > 0 {
+		sep()
+		writeComments(&b, "", f.Trailing)
+	}
+	return b.Bytes()
+}
+
+// A stmt is a formatted directive argument list with its comments.
+type stmt struct {
+	Comments
+	text string
+}
+
+// writeBlock writes the statements for verb, using
+// a parenthesized block if there is more than one.
+func writeBlock(b *bytes.Buffer, verb string, lines []stmt) {
+	if len(lines) == 1 {
+		writeComments(b, "", lines[0].Before)
+		fmt.Fprintf(b, "%s %s%s\n", verb, lines[0].text, suffix(lines[0].Suffix))
+		return
+	}
+	fmt.Fprintf(b, "%s (\n", verb)
+	for _, l := range lines {
+		writeComments(b, "\t", l.Before)
+		fmt.Fprintf(b, "\t%s%s\n", l.text, suffix(l.Suffix))
+	}
+	b.WriteString(")\n")
+}
+
+func writeComments(b *bytes.Buffer, indent string, comments []string) {
+	for _, c := range comments {
+		if c == "" {
+			fmt.Fprintf(b, "%s//\n", indent)
+		} else {
+			fmt.Fprintf(b, "%s// %s\n", indent, c)
+		}
+	}
+}
+
+func suffix(comment string) string {
+	if comment == "" {
+		return ""
+	}
+	return " // " + comment
+}
+
+// quote returns s, quoted if it would otherwise not
+// parse back as a single argument.
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\"()") || strings.Contains(s, "=>") || strings.Contains(s, "//") {
+		return strconv.Quote(s)
+	}
+	return s
+}
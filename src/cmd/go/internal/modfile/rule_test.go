@@ -0,0 +1,150 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modfile
+
+import (
+	"strings"
+	"testing"
+
+	"cmd/go/internal/module"
+)
+
+func TestParse(t *testing.T) {
+	const data = `// The hello module.
+module example.com/hello
+
+require (
+	// greet prints greetings.
+	example.com/greet v1.2
+	"example.com/lang" v0.3.1 // indirect
+)
+
+exclude example.com/greet v1.1.0
+replace example.com/lang v0.3.1 => ../lang
+replace example.com/greet => example.com/greet/fork v1.2.1 // fork
+
+// trailing comment
+`
+	f, err := Parse("go.mod", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Module == nil || f.Module.Mod.Path != "example.com/hello" {
+		t.Fatalf("Module = %+v, want example.com/hello", f.Module)
+	}
+	want := []module.Version{
+		{Path: "example.com/greet", Version: "v1.2.0"},
+		{Path: "example.com/lang", Version: "v0.3.1"},
+	}
+	if len(f.Require) != len(want) {
+		t.Fatalf("len(Require) = %d, want %d", len(f.Require), len(want))
+	}
+	for i, r := range f.Require {
+		if r.Mod != want[i] {
+			t.Errorf("Require[%d] = %v, want %v", i, r.Mod, want[i])
+		}
+	}
+	if f.Require[0].Indirect || !f.Require[1].Indirect {
+		t.Errorf("Indirect = %v, %v, want false, true", f.Require[0].Indirect, f.Require[1].Indirect)
+	}
+	if len(f.Exclude) != 1 || f.Exclude[0].Mod != (module.Version{Path: "example.com/greet", Version: "v1.1.0"}) {
+		t.Errorf("Exclude = %v, want example.com/greet v1.1.0", f.Exclude)
+	}
+	if len(f.Replace) != 2 {
+		t.Fatalf("len(Replace) = %d, want 2", len(f.Replace))
+	}
+	if r := f.Replace[0]; r.Old != want[1] || r.New != (module.Version{Path: "../lang"}) {
+		t.Errorf("Replace[0] = %v => %v, want example.com/lang@v0.3.1 => ../lang", r.Old, r.New)
+	}
+	if r := f.Replace[1]; r.Old != (module.Version{Path: "example.com/greet"}) || r.New != (module.Version{Path: "example.com/greet/fork", Version: "v1.2.1"}) {
+		t.Errorf("Replace[1] = %v => %v, want example.com/greet => example.com/greet/fork@v1.2.1", r.Old, r.New)
+	}
+
+	const formatted = `// The hello module.
+module example.com/hello
+
+require (
+	// greet prints greetings.
+	example.com/greet v1.2.0
+	example.com/lang v0.3.1 // indirect
+)
+
+exclude example.com/greet v1.1.0
+
+replace (
+	example.com/lang v0.3.1 => ../lang
+	example.com/greet => example.com/greet/fork v1.2.1 // fork
+)
+
+// trailing comment
+`
+	out := f.Format()
+	if string(out) != formatted {
+		t.Fatalf("Format:\n%s\nwant:\n%s", out, formatted)
+	}
+
+	// Formatting must be stable.
+	f2, err := Parse("go.mod", out)
+	if err != nil {
+		t.Fatalf("parsing formatted file: %v", err)
+	}
+	if out2 := f2.Format(); string(out2) != formatted {
+		t.Fatalf("Format of formatted file:\n%s\nwant:\n%s", out2, formatted)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	f, err := Parse("go.mod", []byte("module x.y/z\n\nrequire a.b/c v1.0.0 // keep\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.AddRequire("a.b/c", "v1.1.0")
+	f.AddRequire("d.e/f", "v0.1.0")
+	f.DropRequire("g.h/i")
+	const want = `module x.y/z
+
+require (
+	a.b/c v1.1.0 // keep
+	d.e/f v0.1.0
+)
+`
+	if out := f.Format(); string(out) != want {
+		t.Fatalf("Format after edit:\n%s\nwant:\n%s", out, want)
+	}
+	f.DropRequire("d.e/f")
+	const want2 = "module x.y/z\n\nrequire a.b/c v1.1.0 // keep\n"
+	if out := f.Format(); string(out) != want2 {
+		t.Fatalf("Format after DropRequire:\n%s\nwant:\n%s", out, want2)
+	}
+}
+
+var parseErrorTests = []struct {
+	data string
+	err  string
+}{
+	{"module x.y/z\nmodule x.y/w\n", "go.mod:2: repeated module statement"},
+	{"frob x.y/z\n", "go.mod:1: unknown directive: frob"},
+	{"require x.y/z\n", "go.mod:1: usage: require module/path v1.2.3"},
+	{"require x.y/z 1.0\n", `go.mod:1: invalid module version "1.0": not a semantic version`},
+	{"require x.y/z v2.0.0\n", `go.mod:1: invalid module version "v2.0.0": does not match path x.y/z`},
+	{"require xyz v1.0.0\n", `go.mod:1: invalid module path: malformed module path "xyz": missing dot in first path element`},
+	{"require (\nx.y/z v1.0.0\n", "go.mod:3: unterminated require block"},
+	{"replace x.y/z => x.y/w\n", "go.mod:1: replacement module without version must be directory path (rooted or starting with ./ or ../)"},
+	{"replace x.y/z => ../w v1.0.0\n", `go.mod:1: replacement module directory path "../w" cannot have version`},
+	{"module \"x.y/z\n", "go.mod:1: unterminated quoted string"},
+}
+
+func TestParseError(t *testing.T) {
+	for _, tt := range parseErrorTests {
+		_, err := Parse("go.mod", []byte(tt.data))
+		if err == nil {
+			t.Errorf("Parse(%q) succeeded, want error %q", tt.data, tt.err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Parse(%q): error %q, want %q", tt.data, err, tt.err)
+		}
+	}
+}
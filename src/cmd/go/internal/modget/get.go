@@ -0,0 +1,289 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package modget implements the module-aware ``go get'' command.
+package modget
+
+import (
+	"fmt"
+	"go/build"
+	"os"
+	"strings"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/cfg"
+	"cmd/go/internal/load"
+	"cmd/go/internal/modfetch"
+	"cmd/go/internal/modload"
+	"cmd/go/internal/module"
+	"cmd/go/internal/semver"
+	"cmd/go/internal/work"
+)
+
+var CmdGet = &base.Command{
+	UsageLine: "get [-d] [-u] [build flags] [packages]",
+	Short:     "add dependencies to current module and install them",
+	Long: `
+Get resolves and adds dependencies to the current development module
+and then builds and installs them.
+
+The first step is to resolve which dependencies to add.
+
+For each named package or package pattern, get must decide which version of
+the corresponding module to use. By default, get chooses the latest tagged
+release version, such as v0.4.5 or v1.2.3. If there are no tagged release
+versions, get chooses the latest tagged pre-release version, such as
+v0.0.1-pre1.
+
+This default version selection can be overridden by adding an @version
+suffix to the package argument, as in 'go get golang.org/x/text@v0.3.0'.
+The version suffix must be a semantic version, such as v1.2.3, or a
+prefix of one, such as v1 or v1.2, denoting the latest release with that
+prefix. The suffix @latest explicitly requests the default behavior
+described above.
+
+If a module under consideration is already a dependency of the current
+development module, then get will update the required version.
+Specifying a version earlier than the current required version is valid,
+although the requirements of other modules may still select a later one.
+The version suffix @none indicates that the dependency should be removed
+from go.mod entirely.
+
+Although get defaults to using the latest version of the module containing
+a named package, it does not use the latest version of that module's
+dependencies. Instead it prefers to use the specific dependency versions
+requested by that module. For example, if the latest A requires module
+B v1.2.3, while B v1.2.4 and v1.3.1 are also available, then 'go get A'
+will use the latest A but then use B v1.2.3, as requested by A.
+
+The -u flag instructs get to update the modules providing dependencies of
+the named packages to use newer minor or patch releases when available.
+Continuing the previous example, 'go get -u A' will use the latest A with
+B v1.3.1 (not B v1.2.3). With no package arguments, 'go get -u' updates
+every module in the build list.
+
+The second step is to download (if needed), build, and install
+the named packages.
+
+The -d flag instructs get to download the source code needed to build
+the named packages, including downloading necessary dependencies,
+but not to build and install them.
+
+With no package arguments, 'go get' applies to the main module,
+and to the Go package in the current directory, if any.
+
+Modules are downloaded from the module proxy named by $GOPROXY.
+For more about the module proxy protocol, see 'go help goproxy'.
+
+For more about modules, see 'go help modules'.
+
+For more about specifying packages, see 'go help packages'.
+
+This text describes the behavior of get using modules to manage source
+code and dependencies. If instead the go command is running in GOPATH
+mode, the details of get's flags and effects change; see 'go help get'.
+
+See also: go build, go install, go clean.
+	`,
+}
+
+// HelpModuleGet is the help topic describing 'go get'
+// in module-aware mode, which is available even when
+// the go command is running in GOPATH mode.
+var HelpModuleGet = &base.Command{
+	UsageLine: "module-get",
+	Short:     "module-aware go get",
+	Long: `
+The 'go get' command changes behavior depending on whether the
+go command is running in module-aware mode or legacy GOPATH mode.
+This help text, accessible as 'go help module-get' even in legacy GOPATH mode,
+describes 'go get' as it operates in module-aware mode.
+
+Usage: ` + CmdGet.UsageLine + `
+` + CmdGet.Long,
+}
+
+var (
+	getD = CmdGet.Flag.Bool("d", false, "")
+	getU = CmdGet.Flag.Bool("u", false, "")
+)
+
+func init() {
+	work.AddBuildFlags(CmdGet)
+	CmdGet.Run = runGet // break init loop
+}
+
+func runGet(cmd *base.Command, args []string) {
+	if !modload.HasModRoot() {
+		base.Fatalf("go get: cannot find main module; see 'go help modules'")
+	}
+	modload.InitMod()
+	modload.LoadBuildList()
+
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	modFile := modload.ModFile()
+	var install []string // packages to install after updating go.mod
+	var named []string   // module paths named on the command line
+	for _, arg := range args {
+		path, vers := arg, ""
+		if i := strings.Index(arg, "@"); i >= 0 {
+			path, vers = arg[:i], arg[i+1:]
+		}
+		if build.IsLocalImport(path) || strings.Contains(path, "...") || load.IsMetaPackage(path) {
+			if vers != "" {
+				base.Errorf("go get %s: cannot specify version for package pattern", arg)
+				continue
+			}
+			install = append(install, path)
+			continue
+		}
+		if vers == "" {
+			vers = "latest"
+		}
+		if err := module.CheckImportPath(path); err != nil {
+			base.Errorf("go get %s: %v", arg, err)
+			continue
+		}
+		mod, err := moduleForPackage(path)
+		if err != nil {
+			base.Errorf("go get %s: %v", arg, err)
+			continue
+		}
+		if mod == modload.Target.Path {
+			if vers != "latest" {
+				base.Errorf("go get %s: cannot specify version of main module", arg)
+				continue
+			}
+			install = append(install, path)
+			continue
+		}
+		named = append(named, mod)
+		if vers == "none" {
+			modFile.DropRequire(mod)
+			continue
+		}
+		v, err := modload.Query(mod, vers)
+		if err != nil {
+			base.Errorf("go get %s: %v", arg, err)
+			continue
+		}
+		modFile.AddRequire(mod, v)
+		install = append(install, path)
+	}
+	base.ExitIfErrors()
+
+	list := modload.ReloadBuildList()
+	if *getU {
+		upgrade(list, named)
+		modload.ReloadBuildList()
+	}
+	modload.WriteGoMod()
+	modfetch.WriteGoSum()
+
+	if len(install) == 0 {
+		return
+	}
+	if *getD {
+		// Download the packages and their dependencies
+		// by loading them, but do not build anything.
+		load.PackagesForBuild(install)
+		return
+	}
+	work.InstrumentInit()
+	work.BuildModeInit()
+	work.InstallPackages(install, true)
+}
+
+// moduleForPackage returns the path of the module
+// providing the package with the given import path.
+// It prefers a module already in the build list;
+// otherwise it asks the module proxy about each prefix
+// of path, longest first.
+func moduleForPackage(path string) (string, error) {
+	best := ""
+	for _, m := range modload.LoadBuildList() {
+		if (m.Path == path || strings.HasPrefix(path, m.Path+"/")) && len(m.Path) > len(best) {
+			best = m.Path
+		}
+	}
+	if best != "" {
+		return best, nil
+	}
+
+	var firstErr error
+	for p := path; ; {
+		versions, err := modfetch.Versions(p)
+		if err == nil && len(versions) > 0 {
+			return p, nil
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		i := strings.LastIndex(p, "/")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+	if cfg.BuildV && firstErr != nil {
+		fmt.Fprintf(os.Stderr, "go: %v\n", firstErr)
+	}
+	return "", fmt.Errorf("cannot find module providing package %s", path)
+}
+
+// upgrade updates the requirements in go.mod so that the modules
+// required by the named modules, or every module in the build list
+// if none were named, are at their latest versions.
+func upgrade(list []module.Version, named []string) {
+	modFile := modload.ModFile()
+	upgradeAll := len(named) == 0
+	isNamed := make(map[string]bool)
+	for _, path := range named {
+		isNamed[path] = true
+	}
+	reqs := modload.Reqs()
+	upgradeMod := make(map[string]bool)
+	if !upgradeAll {
+		// Upgrade the modules reachable from the named ones.
+		var walk func(module.Version)
+		seen := make(map[module.Version]bool)
+		walk = func(m module.Version) {
+			if seen[m] {
+				return
+			}
+			seen[m] = true
+			required, err := reqs.Required(m)
+			if err != nil {
+				base.Errorf("go get: %v", err)
+				return
+			}
+			for _, r := range required {
+				upgradeMod[r.Path] = true
+				walk(r)
+			}
+		}
+		for _, m := range list {
+			if isNamed[m.Path] {
+				walk(m)
+			}
+		}
+	}
+	for _, m := range list[1:] {
+		if isNamed[m.Path] || !upgradeAll && !upgradeMod[m.Path] {
+			continue
+		}
+		latest, err := modload.Query(m.Path, "latest")
+		if err != nil {
+			base.Errorf("go get: upgrading %s: %v", m.Path, err)
+			continue
+		}
+		if semver.Compare(latest, m.Version) > 0 {
+			modFile.AddRequire(m.Path, latest)
+		}
+	}
+	base.ExitIfErrors()
+}
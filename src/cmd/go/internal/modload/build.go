@@ -0,0 +1,59 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modload
+
+import (
+	"bytes"
+	"fmt"
+
+	"cmd/go/internal/modfetch"
+	"cmd/go/internal/module"
+)
+
+// PackageBuildInfo returns the module lines of the build information
+// recorded in the binary for the main package path, whose dependencies
+// are deps: a "mod" line for the module providing path and a "dep"
+// line for each module providing one of deps, each followed by a "=>"
+// line if the module is replaced. It returns the empty string if path
+// is not provided by a module.
+func PackageBuildInfo(path string, deps []string) string {
+	target, ok := pkgModule[path]
+	if !ok {
+		return ""
+	}
+
+	mdeps := make(map[module.Version]bool)
+	for _, dep := range deps {
+		if m, ok := pkgModule[dep]; ok && m != target {
+			mdeps[m] = true
+		}
+	}
+	var mods []module.Version
+	for m := range mdeps {
+		mods = append(mods, m)
+	}
+	module.Sort(mods)
+
+	var buf bytes.Buffer
+	writeEntry := func(token string, m module.Version) {
+		mv := m.Version
+		if mv == "" {
+			mv = "(devel)"
+		}
+		fmt.Fprintf(&buf, "%s\t%s\t%s", token, m.Path, mv)
+		if r := Replacement(m); r.Path == "" {
+			fmt.Fprintf(&buf, "\t%s\n", modfetch.Sum(m))
+		} else {
+			fmt.Fprintf(&buf, "\n=>\t%s\t%s\t%s\n", r.Path, r.Version, modfetch.Sum(r))
+		}
+	}
+
+	writeEntry("mod", target)
+	for _, mod := range mods {
+		writeEntry("dep", mod)
+	}
+
+	return buf.String()
+}
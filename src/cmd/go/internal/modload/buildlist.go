@@ -0,0 +1,210 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modload
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/modfetch"
+	"cmd/go/internal/modfile"
+	"cmd/go/internal/module"
+	"cmd/go/internal/mvs"
+	"cmd/go/internal/semver"
+)
+
+// buildList is the list of modules to use for building packages.
+// It is initialized by calling LoadBuildList.
+var buildList []module.Version
+
+// LoadBuildList loads and returns the build list from go.mod.
+// The first element of the list is the main module.
+func LoadBuildList() []module.Version {
+	if buildList != nil {
+		return buildList
+	}
+	InitMod()
+	list, err := mvs.BuildList(Target, Reqs())
+	if err != nil {
+		base.Fatalf("go: %v", err)
+	}
+	buildList = list
+	return buildList
+}
+
+// ReloadBuildList recomputes and returns the build list
+// after the requirements in go.mod have been edited.
+func ReloadBuildList() []module.Version {
+	buildList = nil
+	modDirs = nil
+	return LoadBuildList()
+}
+
+// Reqs returns the current module requirement graph.
+func Reqs() mvs.Reqs {
+	return mvsReqs{}
+}
+
+// mvsReqs implements mvs.Reqs for module semantic versions,
+// applying the main module's exclude and replace directives.
+type mvsReqs struct{}
+
+func (mvsReqs) Required(mod module.Version) ([]module.Version, error) {
+	var list []module.Version
+	if mod == Target {
+		for _, r := range modFile.Require {
+			list = append(list, r.Mod)
+		}
+	} else {
+		f, err := modFileFor(mod)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range f.Require {
+			list = append(list, r.Mod)
+		}
+	}
+
+	// Requirements on excluded versions are satisfied
+	// by the next higher version that is not excluded.
+	for i, r := range list {
+		if !excluded[r] {
+			continue
+		}
+		next, err := nextAllowed(r)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = next
+	}
+	return list, nil
+}
+
+func (mvsReqs) Max(v1, v2 string) string {
+	if v1 != "" && semver.Compare(v1, v2) == -1 {
+		return v2
+	}
+	return v1
+}
+
+// nextAllowed returns the lowest available version of m's
+// module that is higher than m and not excluded by the main module.
+func nextAllowed(m module.Version) (module.Version, error) {
+	versions, err := modfetch.Versions(m.Path)
+	if err != nil {
+		return module.Version{}, err
+	}
+	for _, v := range versions {
+		if semver.Compare(v, m.Version) > 0 && !excluded[module.Version{Path: m.Path, Version: v}] {
+			return module.Version{Path: m.Path, Version: v}, nil
+		}
+	}
+	return module.Version{}, fmt.Errorf("%s@%s excluded and no later version available", m.Path, m.Version)
+}
+
+// Replacement returns the replacement for mod, if any,
+// or else the zero module.Version.
+// A version-specific replacement takes precedence over
+// one that applies to all versions of the module.
+func Replacement(mod module.Version) module.Version {
+	if modFile == nil {
+		return module.Version{}
+	}
+	var found *modfile.Replace
+	for _, r := range modFile.Replace {
+		if r.Old.Path == mod.Path && (r.Old.Version == "" || r.Old.Version == mod.Version) {
+			found = r
+			if r.Old.Version != "" {
+				break
+			}
+		}
+	}
+	if found == nil {
+		return module.Version{}
+	}
+	return found.New
+}
+
+// replacementDir returns the absolute directory named by
+// a directory replacement path, which is interpreted
+// relative to the main module's root.
+func replacementDir(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(ModRoot(), path)
+}
+
+// modFileFor returns the parsed go.mod file for the given
+// dependency module version, following any replacement.
+func modFileFor(mod module.Version) (*modfile.File, error) {
+	var (
+		file string
+		data []byte
+		err  error
+	)
+	r := Replacement(mod)
+	if r.Path == "" {
+		file = mod.Path + "@" + mod.Version + "/go.mod"
+		data, err = modfetch.GoMod(mod.Path, mod.Version)
+	} else if r.Version == "" {
+		file = filepath.Join(replacementDir(r.Path), "go.mod")
+		data, err = ioutil.ReadFile(file)
+		if os.IsNotExist(err) {
+			// A replacement directory without a go.mod
+			// has no requirements.
+			return new(modfile.File), nil
+		}
+	} else {
+		file = r.Path + "@" + r.Version + "/go.mod"
+		data, err = modfetch.GoMod(r.Path, r.Version)
+	}
+	if err != nil {
+		return nil, err
+	}
+	f, err := modfile.Parse(file, data)
+	if err != nil {
+		return nil, err
+	}
+	if r.Path == "" && f.Module != nil && f.Module.Mod.Path != mod.Path {
+		return nil, fmt.Errorf("%s: go.mod has unexpected module path %q", file, f.Module.Mod.Path)
+	}
+	return f, nil
+}
+
+// modDirs caches the results of moduleDir.
+var modDirs map[module.Version]string
+
+// moduleDir returns the directory holding the source
+// for the given module in the build list, downloading it
+// into the module cache if needed.
+func moduleDir(m module.Version) (string, error) {
+	if m == Target {
+		return ModRoot(), nil
+	}
+	if dir, ok := modDirs[m]; ok {
+		return dir, nil
+	}
+	var dir string
+	var err error
+	if r := Replacement(m); r.Path == "" {
+		dir, err = modfetch.Download(m)
+	} else if r.Version == "" {
+		dir = replacementDir(r.Path)
+	} else {
+		dir, err = modfetch.Download(r)
+	}
+	if err != nil {
+		return "", err
+	}
+	if modDirs == nil {
+		modDirs = make(map[module.Version]string)
+	}
+	modDirs[m] = dir
+	return dir, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modload
+
+import "cmd/go/internal/base"
+
+var HelpModules = &base.Command{
+	UsageLine: "modules",
+	Short:     "modules, module versions, and more",
+	Long: `
+A module is a collection of related Go packages.
+Modules are the unit of source code interchange and versioning.
+The go command has direct support for working with modules,
+including recording and resolving dependencies on other modules.
+Modules replace the old GOPATH-based approach to specifying
+which source files are used in a given build.
+
+Module-aware mode
+
+The go command can run in module-aware mode or in legacy GOPATH mode.
+The GO111MODULE environment variable selects between them.
+
+If GO111MODULE=off, the go command never uses module support.
+Instead it looks in vendor directories and GOPATH to find dependencies;
+we now refer to this as "GOPATH mode."
+
+If GO111MODULE=on, the go command requires the use of modules,
+never consulting GOPATH to resolve imports.
+
+If GO111MODULE=auto or is unset, the go command enables module support
+when the current directory is outside GOPATH/src and either the current
+directory or any of its parents contains a file named go.mod.
+
+In module-aware mode, GOPATH no longer defines the meaning of imports
+during a build, but it still stores downloaded dependencies (in
+GOPATH/pkg/mod) and installed commands (in GOPATH/bin, unless GOBIN
+is set).
+
+Defining a module
+
+A module is defined by a tree of Go source files with a go.mod file
+in the tree's root directory. The directory containing the go.mod file
+is called the module root. Typically the module root will also correspond
+to a source code repository root (but in general it need not).
+The module is the set of all Go packages in the module root and its
+subdirectories, but excluding subtrees with their own go.mod files.
+
+The "module path" is the import path prefix corresponding to the module root.
+The go.mod file defines the module path and lists the specific versions
+of other modules that should be used when resolving imports during a build,
+by giving their module paths and versions.
+
+For example, this go.mod declares that the directory containing it is the root
+of the module with path example.com/m, and it also declares that the module
+depends on specific versions of golang.org/x/text and gopkg.in/yaml.v2:
+
+	module example.com/m
+
+	require (
+		golang.org/x/text v0.3.0
+		gopkg.in/yaml.v2 v2.1.0
+	)
+
+The go.mod file is line-oriented, with // comments. Each line holds
+a single directive, made up of a verb followed by arguments:
+
+	module example.com/m          // declares the module path
+	require example.com/a v1.2.3  // requires a minimum version of a module
+	exclude example.com/a v1.2.4  // excludes a module version from use
+	replace example.com/a => ../a // replaces a module with another one
+
+A replace directive may name a specific version on the left-hand side,
+in which case only that version is replaced. The right-hand side is either
+a module path and version or a directory path, which must begin with
+./ or ../ or be an absolute path; a replacement directory's go.mod file,
+if any, supplies the module's requirements. Exclude and replace directives
+apply only in the main module's go.mod and are ignored in dependencies.
+
+Adjacent directives with the same verb can be grouped into a block,
+as in the require block above. The go command reformats go.mod
+when it updates it, as 'go get' does.
+
+The main module and the build list
+
+The "main module" is the module containing the directory where the go command
+is run. The go command finds the module root by looking for a go.mod in the
+current directory, or else the current directory's parent directory,
+or else the parent's parent directory, and so on.
+
+The main module's go.mod file defines the precise set of packages available
+for use by the go command, through require, replace, and exclude statements.
+Dependency modules, found by following require statements, also contribute
+to the definition of that set of packages, but only through their go.mod
+files' require statements: any replace and exclude statements in dependency
+modules are ignored.
+
+The set of modules providing packages to builds is called the "build list".
+The build list initially contains only the main module. Then the go command
+adds to the list the exact module versions required by modules already
+on the list, recursively, until there is nothing left to add to the list.
+If multiple versions of a particular module are added to the list,
+then at the end only the latest version (according to semantic version
+ordering) is kept for use in the build. This algorithm is called
+minimal version selection: it never uses a version newer than some
+module in the build asks for, so builds are reproducible without a
+separate lock file.
+
+The 'go list -m' command prints the build list:
+
+	go list -m all
+
+Module downloading and verification
+
+The go command downloads modules from the module proxy named by
+$GOPROXY and maintains a cache of downloaded modules in GOPATH/pkg/mod.
+See 'go help goproxy' for details about the proxy protocol.
+
+The go command maintains, in the main module's root directory alongside
+go.mod, a file named go.sum containing the expected cryptographic checksums
+of the content of specific module versions. Each line has the form
+
+	<module> <version>[/go.mod] <hash>
+
+where the hash is "h1:" followed by the base64-encoded SHA-256 hash of
+a sorted list of the module's files and their own SHA-256 hashes, or,
+for the /go.mod form, of the version's go.mod file alone.
+
+Each time a dependency is used, its checksum is added to go.sum if
+missing or else required to match the existing entry in go.sum.
+A mismatch is reported as an error and stops the build. The go.sum
+file should be checked in to version control along with go.mod.
+
+Modules and package patterns
+
+In module-aware mode, the package pattern "all" means the packages in
+the main module together with all the packages they import, recursively.
+Patterns containing "..." match packages in the modules of the build list,
+and relative patterns like ./... match packages in the main module.
+`,
+}
@@ -0,0 +1,216 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modload
+
+import (
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cmd/go/internal/cfg"
+	"cmd/go/internal/load"
+	"cmd/go/internal/modinfo"
+	"cmd/go/internal/module"
+)
+
+// pkgModule records the module providing each package
+// found by Lookup.
+var pkgModule = make(map[string]module.Version)
+
+// Lookup returns the directory holding the package with the given
+// import path. It returns an empty directory for packages in the
+// standard library, which package load finds in GOROOT as usual.
+func Lookup(path string) (dir string, err error) {
+	if isStandardPackage(path) {
+		return "", nil
+	}
+	if !HasModRoot() {
+		die()
+	}
+
+	// Find all the modules in the build list that could
+	// provide path. Usually there is exactly one; finding
+	// the package in more than one module is an error.
+	var mods []module.Version
+	var dirs []string
+	for _, m := range LoadBuildList() {
+		if !maybeInModule(path, m.Path) {
+			continue
+		}
+		root, err := moduleDir(m)
+		if err != nil {
+			return "", err
+		}
+		d := filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(path[len(m.Path):], "/")))
+		if fi, err := os.Stat(d); err == nil && fi.IsDir() {
+			mods = append(mods, m)
+			dirs = append(dirs, d)
+		}
+	}
+	switch len(mods) {
+	case 0:
+		return "", fmt.Errorf("cannot find module providing package %s", path)
+	case 1:
+		pkgModule[path] = mods[0]
+		return dirs[0], nil
+	}
+	var where []string
+	for i, m := range mods {
+		where = append(where, fmt.Sprintf("%s %s (%s)", m.Path, m.Version, dirs[i]))
+	}
+	return "", fmt.Errorf("ambiguous import: found %s in multiple modules:\n\t%s", path, strings.Join(where, "\n\t"))
+}
+
+// maybeInModule reports whether, syntactically,
+// a package with the given import path could be supplied
+// by a module with the given module path (mpath).
+func maybeInModule(path, mpath string) bool {
+	return mpath == path ||
+		len(path) > len(mpath) && path[len(mpath)] == '/' && path[:len(mpath)] == mpath
+}
+
+// isStandardPackage reports whether path names
+// a directory in the standard library.
+func isStandardPackage(path string) bool {
+	if i := strings.Index(path, "/"); i >= 0 {
+		if strings.Contains(path[:i], ".") {
+			return false
+		}
+	} else if strings.Contains(path, ".") {
+		return false
+	}
+	fi, err := os.Stat(filepath.Join(cfg.GOROOTsrc, filepath.FromSlash(path)))
+	return err == nil && fi.IsDir()
+}
+
+// DirImportPath returns the import path of the package in
+// the given directory of the main module, or the empty
+// string if the directory is outside the main module.
+func DirImportPath(dir string) string {
+	if !HasModRoot() {
+		return ""
+	}
+	root := ModRoot()
+	if dir == root {
+		return Target.Path
+	}
+	if hasFilePathPrefix(dir, root) {
+		InitMod()
+		return Target.Path + "/" + filepath.ToSlash(dir[len(root)+1:])
+	}
+	return ""
+}
+
+// PackageModuleInfo returns information about the module
+// providing the package with the given import path,
+// or nil if the package is not provided by a module.
+func PackageModuleInfo(path string) *modinfo.ModulePublic {
+	m, ok := pkgModule[path]
+	if !ok {
+		return nil
+	}
+	return moduleInfo(m, false)
+}
+
+// MatchPackages returns the import paths of the packages
+// matching pattern in module mode. The pattern "all" matches
+// the packages in the main module and all their dependencies.
+// Other patterns are matched against the packages in the
+// modules of the build list.
+func MatchPackages(pattern string) []string {
+	if pattern == "std" || pattern == "cmd" {
+		return load.MatchPackages(pattern)
+	}
+	if !HasModRoot() {
+		die()
+	}
+	if pattern == "all" {
+		return allPackages()
+	}
+
+	match := load.MatchPattern(pattern)
+	treeCanMatch := load.TreeCanMatchPattern(pattern)
+	var pkgs []string
+	for _, m := range LoadBuildList() {
+		if !treeCanMatch(m.Path) {
+			continue
+		}
+		root, err := moduleDir(m)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "go: %v\n", err)
+			continue
+		}
+		pkgs = append(pkgs, matchInModule(m.Path, root, match)...)
+	}
+	return pkgs
+}
+
+// matchInModule returns the import paths of the packages
+// in the module tree rooted at root that match.
+func matchInModule(modPath, root string, match func(string) bool) []string {
+	var pkgs []string
+	filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || !fi.IsDir() {
+			return nil
+		}
+		if path != root {
+			// Avoid .foo, _foo, and testdata directory trees,
+			// as well as nested modules.
+			elem := fi.Name()
+			if strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") || elem == "testdata" {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+		name := modPath
+		if path != root {
+			name += "/" + filepath.ToSlash(path[len(root)+1:])
+		}
+		if !match(name) {
+			return nil
+		}
+		if _, err := cfg.BuildContext.ImportDir(path, 0); err != nil {
+			if _, noGo := err.(*build.NoGoError); noGo {
+				return nil
+			}
+		}
+		pkgs = append(pkgs, name)
+		return nil
+	})
+	return pkgs
+}
+
+// allPackages returns the packages in the main module
+// and all the packages they import, recursively.
+func allPackages() []string {
+	InitMod()
+	roots := matchInModule(Target.Path, ModRoot(), func(string) bool { return true })
+	seen := make(map[string]bool)
+	var walk func(*load.Package)
+	walk = func(p *load.Package) {
+		if seen[p.ImportPath] {
+			return
+		}
+		seen[p.ImportPath] = true
+		for _, p1 := range p.Internal.Imports {
+			walk(p1)
+		}
+	}
+	for _, path := range roots {
+		var stk load.ImportStack
+		walk(load.LoadImport(path, ModRoot(), nil, &stk, nil, 0))
+	}
+	var pkgs []string
+	for path := range seen {
+		pkgs = append(pkgs, path)
+	}
+	sort.Strings(pkgs)
+	return pkgs
+}
@@ -0,0 +1,224 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package modload loads the main module's go.mod file,
+// computes the build list, and resolves import paths
+// to directories in module-aware mode.
+package modload
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/cfg"
+	"cmd/go/internal/load"
+	"cmd/go/internal/modfetch"
+	"cmd/go/internal/modfile"
+	"cmd/go/internal/module"
+)
+
+var (
+	// MustUseModules reports whether GO111MODULE=on,
+	// forcing module mode even inside GOPATH/src.
+	MustUseModules bool
+
+	initialized bool
+
+	modRoot  string         // directory holding the main module's go.mod
+	modFile  *modfile.File  // parsed go.mod of the main module
+	Target   module.Version // the main module
+	excluded map[module.Version]bool
+
+	gopath string
+)
+
+// Enabled reports whether module mode is enabled.
+func Enabled() bool {
+	Init()
+	return cfg.ModulesEnabled
+}
+
+// ModRoot returns the root of the main module.
+// It calls base.Fatalf if there is no main module.
+func ModRoot() string {
+	if !HasModRoot() {
+		die()
+	}
+	return modRoot
+}
+
+// HasModRoot reports whether a main module is present.
+func HasModRoot() bool {
+	Init()
+	return modRoot != ""
+}
+
+// Init determines whether module mode is enabled
+// and, if so, locates the main module.
+// It sets cfg.ModulesEnabled and installs the module
+// hooks into package load.
+func Init() {
+	if initialized {
+		return
+	}
+	initialized = true
+
+	env := os.Getenv("GO111MODULE")
+	switch env {
+	default:
+		base.Fatalf("go: unknown environment setting GO111MODULE=%s", env)
+	case "", "auto":
+		// leave MustUseModules alone
+	case "on":
+		MustUseModules = true
+	case "off":
+		return
+	}
+
+	list := filepath.SplitList(cfg.BuildContext.GOPATH)
+	if !MustUseModules {
+		// Inside a GOPATH source tree, stay in GOPATH mode
+		// unless GO111MODULE=on.
+		for _, p := range list {
+			if p != "" && hasFilePathPrefix(base.Cwd, filepath.Join(p, "src")) {
+				return
+			}
+		}
+	}
+	modRoot = findModuleRoot(base.Cwd)
+	if modRoot == "" && !MustUseModules {
+		// No go.mod: stay in GOPATH mode.
+		return
+	}
+
+	if len(list) == 0 || list[0] == "" {
+		base.Fatalf("missing $GOPATH")
+	}
+	gopath = list[0]
+	if _, err := os.Stat(filepath.Join(gopath, "go.mod")); err == nil {
+		base.Fatalf("$GOPATH/go.mod exists but should not")
+	}
+
+	cfg.ModulesEnabled = true
+	modfetch.PkgMod = filepath.Join(gopath, "pkg/mod")
+	if modRoot != "" {
+		modfetch.GoSumFile = filepath.Join(modRoot, "go.sum")
+	}
+	base.AtExit(modfetch.WriteGoSum)
+
+	load.ModLookup = Lookup
+	load.ModPackageModuleInfo = PackageModuleInfo
+	load.ModDirImportPath = DirImportPath
+	load.ModMatchPackages = MatchPackages
+	load.ModBinDir = BinDir
+	load.ModPackageBuildInfo = PackageBuildInfo
+
+	if modRoot != "" {
+		InitMod()
+	}
+}
+
+// die reports that module mode is enabled without a main module.
+func die() {
+	base.Fatalf("go: cannot find main module; see 'go help modules'")
+}
+
+// InitMod reads and parses the main module's go.mod file.
+func InitMod() {
+	if modFile != nil {
+		return
+	}
+	gomod := filepath.Join(ModRoot(), "go.mod")
+	data, err := ioutil.ReadFile(gomod)
+	if err != nil {
+		base.Fatalf("go: %v", err)
+	}
+	f, err := modfile.Parse(gomod, data)
+	if err != nil {
+		base.Fatalf("go: errors parsing go.mod:\n%s", err)
+	}
+	if f.Module == nil {
+		base.Fatalf("go: no module declaration in %s", base.ShortPath(gomod))
+	}
+	modFile = f
+	Target = f.Module.Mod
+	excluded = make(map[module.Version]bool)
+	for _, x := range f.Exclude {
+		excluded[x.Mod] = true
+	}
+}
+
+// ModFilePath returns the path of the main module's go.mod file,
+// or the empty string if there is no main module.
+func ModFilePath() string {
+	if !HasModRoot() {
+		return ""
+	}
+	return filepath.Join(modRoot, "go.mod")
+}
+
+// ModFile returns the parsed go.mod file of the main module.
+func ModFile() *modfile.File {
+	InitMod()
+	return modFile
+}
+
+// WriteGoMod writes the main module's go.mod file
+// back to disk, if it has changed.
+func WriteGoMod() {
+	InitMod()
+	data := modFile.Format()
+	gomod := filepath.Join(modRoot, "go.mod")
+	old, _ := ioutil.ReadFile(gomod)
+	if bytes.Equal(old, data) {
+		return
+	}
+	if err := ioutil.WriteFile(gomod, data, 0666); err != nil {
+		base.Fatalf("go: %v", err)
+	}
+}
+
+// findModuleRoot returns the nearest directory
+// at or above dir that contains a go.mod file,
+// or the empty string if there is none.
+func findModuleRoot(dir string) string {
+	dir = filepath.Clean(dir)
+	for {
+		if fi, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !fi.IsDir() {
+			return dir
+		}
+		d := filepath.Dir(dir)
+		if d == dir {
+			return ""
+		}
+		dir = d
+	}
+}
+
+// hasFilePathPrefix reports whether the filesystem path s begins with the
+// elements in prefix.
+func hasFilePathPrefix(s, prefix string) bool {
+	s = filepath.Clean(s)
+	prefix = filepath.Clean(prefix)
+	if s == prefix {
+		return true
+	}
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	return strings.HasPrefix(s, prefix)
+}
+
+// BinDir returns the directory in which 'go install'
+// places commands built in module mode.
+func BinDir() string {
+	if cfg.GOBIN != "" {
+		return cfg.GOBIN
+	}
+	return filepath.Join(gopath, "bin")
+}
@@ -0,0 +1,148 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modload
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cmd/go/internal/base"
+	"cmd/go/internal/load"
+	"cmd/go/internal/modfetch"
+	"cmd/go/internal/modinfo"
+	"cmd/go/internal/module"
+)
+
+// ListModules returns information about the modules
+// named by args, for 'go list -m'. With no arguments it
+// lists the main module; the argument "all" lists the
+// entire build list. Other arguments are module paths
+// or patterns containing "..." matched against the build list.
+func ListModules(args []string) []*modinfo.ModulePublic {
+	list := LoadBuildList()
+	if len(args) == 0 {
+		return []*modinfo.ModulePublic{moduleInfo(Target, true)}
+	}
+
+	var mods []*modinfo.ModulePublic
+	for _, arg := range args {
+		if strings.Contains(arg, "@") {
+			base.Errorf("go list -m %s: module versions not supported; use a module path", arg)
+			continue
+		}
+		if arg == "all" {
+			for _, m := range list {
+				mods = append(mods, moduleInfo(m, true))
+			}
+			continue
+		}
+		if strings.Contains(arg, "...") {
+			match := load.MatchPattern(arg)
+			matched := false
+			for _, m := range list {
+				if match(m.Path) {
+					matched = true
+					mods = append(mods, moduleInfo(m, true))
+				}
+			}
+			if !matched {
+				fmt.Fprintf(os.Stderr, "warning: pattern %q matched no module dependencies\n", arg)
+			}
+			continue
+		}
+		found := false
+		for _, m := range list {
+			if m.Path == arg {
+				found = true
+				mods = append(mods, moduleInfo(m, true))
+				break
+			}
+		}
+		if !found {
+			base.Errorf("go list -m %s: module not in build list", arg)
+		}
+	}
+	return mods
+}
+
+// moduleInfo returns the public information about the
+// module m in the build list. If withTime is set, it also
+// looks up the time at which the version was created.
+func moduleInfo(m module.Version, withTime bool) *modinfo.ModulePublic {
+	if m == Target {
+		return &modinfo.ModulePublic{
+			Path:  m.Path,
+			Main:  true,
+			Dir:   ModRoot(),
+			GoMod: filepath.Join(ModRoot(), "go.mod"),
+		}
+	}
+
+	info := &modinfo.ModulePublic{
+		Path:     m.Path,
+		Version:  m.Version,
+		Indirect: isIndirect(m),
+	}
+
+	// complete fills in the extra information about
+	// the module version mod, which is m or its replacement.
+	complete := func(mi *modinfo.ModulePublic, mod module.Version) {
+		if withTime {
+			if rev, err := modfetch.Stat(mod.Path, mod.Version); err != nil {
+				mi.Error = &modinfo.ModuleError{Err: err.Error()}
+			} else {
+				mi.Time = &rev.Time
+			}
+		}
+		dir, err := modfetch.DownloadDir(mod)
+		if err == nil {
+			if _, err := os.Stat(dir); err == nil {
+				mi.Dir = dir
+			}
+		}
+		if gomod, err := modfetch.CachePath(mod, "mod"); err == nil {
+			if _, err := os.Stat(gomod); err == nil {
+				mi.GoMod = gomod
+			}
+		}
+	}
+
+	r := Replacement(m)
+	switch {
+	case r.Path == "":
+		complete(info, m)
+	case r.Version == "":
+		dir := replacementDir(r.Path)
+		info.Replace = &modinfo.ModulePublic{
+			Path:  r.Path,
+			Dir:   dir,
+			GoMod: filepath.Join(dir, "go.mod"),
+		}
+		info.Dir = dir
+		info.GoMod = info.Replace.GoMod
+	default:
+		info.Replace = &modinfo.ModulePublic{
+			Path:    r.Path,
+			Version: r.Version,
+		}
+		complete(info.Replace, r)
+		info.Dir = info.Replace.Dir
+		info.GoMod = info.Replace.GoMod
+	}
+	return info
+}
+
+// isIndirect reports whether the main module's go.mod
+// marks its requirement on m as indirect.
+func isIndirect(m module.Version) bool {
+	for _, r := range modFile.Require {
+		if r.Mod.Path == m.Path {
+			return r.Indirect
+		}
+	}
+	return false
+}
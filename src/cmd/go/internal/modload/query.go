@@ -0,0 +1,68 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modload
+
+import (
+	"fmt"
+	"strings"
+
+	"cmd/go/internal/modfetch"
+	"cmd/go/internal/module"
+	"cmd/go/internal/semver"
+)
+
+// Query looks up a version of the module with the given path
+// matching the query, which may be:
+//
+//	- "latest", denoting the latest available release version,
+//	  or the latest pre-release version if there are no releases;
+//	- a full semantic version, like "v1.2.3", denoting that version;
+//	- a semantic version prefix, like "v1" or "v1.2", denoting the
+//	  latest available release with that prefix.
+//
+// Versions excluded by the main module's go.mod are never selected.
+func Query(path, query string) (string, error) {
+	if query != "latest" && !semver.IsValid(query) {
+		return "", fmt.Errorf("invalid version query %q", query)
+	}
+	if semver.IsValid(query) && semver.Canonical(query) == query {
+		// A full version: make sure it exists.
+		if excluded[module.Version{Path: path, Version: query}] {
+			return "", fmt.Errorf("%s@%s excluded by go.mod", path, query)
+		}
+		if _, err := modfetch.Stat(path, query); err != nil {
+			return "", err
+		}
+		return query, nil
+	}
+
+	versions, err := modfetch.Versions(path)
+	if err != nil {
+		return "", err
+	}
+	ok := func(v string) bool {
+		if excluded[module.Version{Path: path, Version: v}] {
+			return false
+		}
+		if query == "latest" {
+			return true
+		}
+		return strings.HasPrefix(v, query+".") && semver.Prerelease(v) == ""
+	}
+	// Prefer the latest release over any pre-release.
+	for i := len(versions) - 1; i >= 0; i-- {
+		if v := versions[i]; ok(v) && semver.Prerelease(v) == "" {
+			return v, nil
+		}
+	}
+	if query == "latest" {
+		for i := len(versions) - 1; i >= 0; i-- {
+			if v := versions[i]; ok(v) {
+				return v, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no matching versions for query %q of module %s", query, path)
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mvs implements Minimal Version Selection.
+//
+// In minimal version selection, each module lists the minimum
+// version it requires of each of its dependencies. The build list
+// for a target module is computed by visiting every module version
+// reachable from the target through those requirements and, for
+// each module path, selecting the highest version visited.
+// The result depends only on the requirement graph, never on which
+// versions happen to be the newest available, so it is reproducible.
+package mvs
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"cmd/go/internal/module"
+)
+
+// A Reqs is the requirement graph on which Minimal Version Selection (MVS) operates.
+type Reqs interface {
+	// Required returns the module versions explicitly required by m itself.
+	// The caller must not modify the returned list.
+	Required(m module.Version) ([]module.Version, error)
+
+	// Max returns the maximum of v1 and v2 (it returns either v1 or v2).
+	//
+	// For all versions v, Max(v, "none") must be v,
+	// and for the target passed as the first argument to MVS functions,
+	// Max(target, v) must be target.
+	//
+	// Note that v1 < v2 can be written Max(v1, v2) != v1
+	// and similarly v1 <= v2 can be written Max(v1, v2) == v2.
+	Max(v1, v2 string) string
+}
+
+// A BuildListError records an error that occurred while loading
+// the requirements of a module, along with the chain of
+// requirements that led to it.
+type BuildListError struct {
+	Stack []module.Version
+	Err   error
+}
+
+func (e *BuildListError) Error() string {
+	var buf bytes.Buffer
+	for _, m := range e.Stack[:len(e.Stack)-1] {
+		fmt.Fprintf(&buf, "%s requires\n\t", m)
+	}
+	fmt.Fprintf(&buf, "%s: %v", e.Stack[len(e.Stack)-1], e.Err)
+	return buf.String()
+}
+
+// BuildList returns the build list for the target module.
+// The first element is the target itself, followed by the
+// selected version of every other module in the graph,
+// sorted by module path.
+func BuildList(target module.Version, reqs Reqs) ([]module.Version, error) {
+	// Explore the requirement graph breadth-first, recording
+	// for each module version the version that first required it,
+	// so that errors can report the chain of requirements.
+	type node struct {
+		m      module.Version
+		parent *node
+	}
+	selected := map[string]string{target.Path: target.Version}
+	seen := map[module.Version]bool{target: true}
+	queue := []*node{{m: target}}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		required, err := reqs.Required(n.m)
+		if err != nil {
+			var stack []module.Version
+			for p := n; p != nil; p = p.parent {
+				stack = append(stack, p.m)
+			}
+			for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+				stack[i], stack[j] = stack[j], stack[i]
+			}
+			return nil, &BuildListError{Stack: stack, Err: err}
+		}
+		for _, r := range required {
+			if r.Version == "none" {
+				continue
+			}
+			if v, ok := selected[r.Path]; !ok || reqs.Max(v, r.Version) != v {
+				selected[r.Path] = r.Version
+			}
+			if !seen[r] {
+				seen[r] = true
+				queue = append(queue, &node{m: r, parent: n})
+			}
+		}
+	}
+
+	// The target's version always wins, even if some
+	// dependency requires a newer version of it.
+	selected[target.Path] = target.Version
+
+	list := []module.Version{target}
+	for path, vers := range selected {
+		if path != target.Path {
+			list = append(list, module.Version{Path: path, Version: vers})
+		}
+	}
+	tail := list[1:]
+	sort.Slice(tail, func(i, j int) bool {
+		return tail[i].Path < tail[j].Path
+	})
+	return list, nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mvs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cmd/go/internal/module"
+)
+
+var buildListTests = []struct {
+	name  string
+	graph string // lines of "m@v: r1@v1 r2@v2"
+	want  string
+}{
+	{
+		name: "blog",
+		graph: `
+A: B@1.2 C@1.2
+B@1.1: D@1.1
+B@1.2: D@1.3
+C@1.1:
+C@1.2: D@1.4
+C@1.3: F@1.1
+D@1.1: E@1.2
+D@1.3: E@1.2
+D@1.4: E@1.2
+E@1.1:
+E@1.2:
+E@1.3:
+F@1.1: G@1.1
+G@1.1: F@1.1
+`,
+		want: "A B@1.2 C@1.2 D@1.4 E@1.2",
+	},
+	{
+		name: "cycle",
+		graph: `
+A: B@1
+B@1: C@1
+C@1: B@2
+B@2: C@1
+`,
+		want: "A B@2 C@1",
+	},
+	{
+		name: "target wins",
+		graph: `
+A: B@1
+B@1: A@2
+A@2: C@1
+C@1:
+`,
+		want: "A B@1 C@1",
+	},
+	{
+		name: "none",
+		graph: `
+A: B@1 C@none
+B@1:
+`,
+		want: "A B@1",
+	},
+}
+
+func TestBuildList(t *testing.T) {
+	for _, tt := range buildListTests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqs := parseGraph(t, tt.graph)
+			list, err := BuildList(module.Version{Path: "A"}, reqs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := formatList(list); got != tt.want {
+				t.Errorf("BuildList = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildListError(t *testing.T) {
+	reqs := parseGraph(t, `
+A: B@1
+B@1: C@1
+`)
+	_, err := BuildList(module.Version{Path: "A"}, reqs)
+	if err == nil {
+		t.Fatal("BuildList succeeded with missing module, want error")
+	}
+	berr, ok := err.(*BuildListError)
+	if !ok {
+		t.Fatalf("BuildList error is %T, want *BuildListError", err)
+	}
+	want := []module.Version{{Path: "A"}, {Path: "B", Version: "1"}, {Path: "C", Version: "1"}}
+	if !reflect.DeepEqual(berr.Stack, want) {
+		t.Errorf("error stack = %v, want %v", berr.Stack, want)
+	}
+	const msg = "A requires\n\tB@1 requires\n\tC@1: missing"
+	if err.Error() != msg {
+		t.Errorf("error = %q, want %q", err, msg)
+	}
+}
+
+// reqsMap implements Reqs using a map, with versions
+// compared as strings.
+type reqsMap map[module.Version][]module.Version
+
+func (r reqsMap) Max(v1, v2 string) string {
+	if v1 == "none" || v2 == "" {
+		return v2
+	}
+	if v2 == "none" || v1 == "" {
+		return v1
+	}
+	if v1 < v2 {
+		return v2
+	}
+	return v1
+}
+
+func (r reqsMap) Required(m module.Version) ([]module.Version, error) {
+	rr, ok := r[m]
+	if !ok {
+		return nil, errors.New("missing")
+	}
+	return rr, nil
+}
+
+func parseGraph(t *testing.T, graph string) reqsMap {
+	reqs := make(reqsMap)
+	for _, line := range strings.Split(graph, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i < 0 {
+			t.Fatalf("bad graph line %q", line)
+		}
+		m := parseVersion(line[:i])
+		reqs[m] = []module.Version{}
+		for _, f := range strings.Fields(line[i+1:]) {
+			reqs[m] = append(reqs[m], parseVersion(f))
+		}
+	}
+	return reqs
+}
+
+func parseVersion(s string) module.Version {
+	if i := strings.Index(s, "@"); i >= 0 {
+		return module.Version{Path: s[:i], Version: s[i+1:]}
+	}
+	return module.Version{Path: s}
+}
+
+func formatList(list []module.Version) string {
+	var out []string
+	for _, m := range list {
+		out = append(out, fmt.Sprint(m))
+	}
+	return strings.Join(out, " ")
+}
@@ -8,6 +8,7 @@ package str
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -139,3 +140,36 @@ func SplitQuotedFields(s string) ([]string, error) {
 	}
 	return f, nil
 }
+
+// JoinQuotedFields joins a list of fields into a string that
+// SplitQuotedFields splits back into the same list, quoting
+// fields that are empty or contain spaces or quotes.
+// A field containing both kinds of quotes and a space cannot
+// be represented and is joined unquoted.
+func JoinQuotedFields(f []string) string {
+	var buf []byte
+	for i, s := range f {
+		if i > 0 {
+			buf = append(buf, ' ')
+		}
+		needQuote := s == "" || s[0] == '"' || s[0] == '\''
+		for j := 0; j < len(s) && !needQuote; j++ {
+			needQuote = isSpaceByte(s[j])
+		}
+		switch {
+		case !needQuote:
+			buf = append(buf, s...)
+		case !strings.Contains(s, "'"):
+			buf = append(buf, '\'')
+			buf = append(buf, s...)
+			buf = append(buf, '\'')
+		case !strings.Contains(s, `"`):
+			buf = append(buf, '"')
+			buf = append(buf, s...)
+			buf = append(buf, '"')
+		default:
+			buf = append(buf, s...)
+		}
+	}
+	return string(buf)
+}
@@ -13,6 +13,8 @@ import (
 	"go/doc"
 	"go/parser"
 	"go/token"
+	"io"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"path"
@@ -21,16 +23,19 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 	"unicode"
 	"unicode/utf8"
 
 	"cmd/go/internal/base"
+	"cmd/go/internal/cache"
 	"cmd/go/internal/cfg"
 	"cmd/go/internal/load"
 	"cmd/go/internal/str"
 	"cmd/go/internal/work"
+	"cmd/internal/test2json"
 )
 
 // Break init loop.
@@ -74,6 +79,22 @@ with source in the current directory, including tests, and runs the tests.
 The package is built in a temporary directory so it does not interfere with the
 non-test installation.
 
+When 'go test' is given an explicit list of packages, it caches the
+results of successful package tests to avoid unnecessary repeated
+running of tests. If the result of a test can be recovered from the
+cache, go test redisplays the previous output instead of running the
+test binary again, and it prints '(cached)' in place of the elapsed
+time in the summary line.
+
+A cached result is reused only when the test binary is the same,
+the flags passed to it are all from the restricted set -cpu, -failfast,
+-list, -parallel, -run, -short, and -v, and the environment variables and
+files the test consulted while running are unchanged. Tests run with
+any other flag, or run without a package list, are not cached.
+To disable test caching for a run, use any test flag or argument
+other than the cacheable flags, or set GOCACHE=off.
+See 'go help cache' for more about the cache.
+
 ` + strings.TrimSpace(testFlag1) + ` See 'go help testflag' for details.
 
 For more about build flags, see 'go help build'.
@@ -105,6 +126,12 @@ In addition to the build flags, the flags handled by 'go test' itself are:
 	    Install packages that are dependencies of the test.
 	    Do not run the test.
 
+	-json
+	    Convert test output to JSON suitable for automated processing.
+	    Each test binary is run as if -v had been given, and its output
+	    is reported as a stream of events for each test.
+	    See 'go doc test2json' for the encoding details.
+
 	-o file
 	    Compile the test binary to the named file.
 	    The test still runs (unless -c or -i is specified).
@@ -193,10 +220,41 @@ const testFlag2 = `
 	    benchmarks should be executed. The default is the current value
 	    of GOMAXPROCS.
 
+	-failfast
+	    Do not start new tests after the first test failure.
+
+	-fuzz regexp
+	    Run the fuzz target matching the regular expression. When specified,
+	    the command line argument must match exactly one package, and regexp
+	    must match exactly one fuzz target within that package. Fuzzing runs
+	    after tests, benchmarks, seed corpora of other fuzz targets, and
+	    examples have completed, and only if they all pass. The package is
+	    built with coverage instrumentation, which guides the generation of
+	    new inputs. Inputs that expand coverage are kept in the build cache
+	    (see 'go help cache') and reused by later runs. An input that causes
+	    a failure is minimized and written to testdata/fuzz/FuzzXXX, where
+	    it becomes part of the seed corpus run by later tests.
+	    The -timeout flag does not apply to fuzzing.
+
+	-fuzzminimizetime t
+	    Run enough iterations of the fuzz target during each minimization
+	    attempt to take t, as specified as a time.Duration (for example,
+	    -fuzzminimizetime 30s). The default is 60s. The special syntax Nx
+	    means to run the fuzz target N times (for example,
+	    -fuzzminimizetime 100x).
+
+	-fuzztime t
+	    Run enough iterations of the fuzz target during fuzzing to take t,
+	    specified as a time.Duration (for example, -fuzztime 1h30s).
+	    The default is to run forever, until a failure is found or the
+	    test is interrupted. The special syntax Nx means to run the fuzz
+	    target N times (for example, -fuzztime 1000x).
+
 	-list regexp
-	    List tests, benchmarks, or examples matching the regular expression.
-	    No tests, benchmarks or examples will be run. This will only
-	    list top-level tests. No subtest or subbenchmarks will be shown.
+	    List tests, benchmarks, fuzz targets, or examples matching the
+	    regular expression. No tests, benchmarks, fuzz targets or examples
+	    will be run. This will only list top-level tests. No subtest or
+	    subbenchmarks will be shown.
 
 	-parallel n
 	    Allow parallel execution of test functions that call t.Parallel.
@@ -208,7 +266,9 @@ const testFlag2 = `
 	    (see 'go help build').
 
 	-run regexp
-	    Run only those tests and examples matching the regular expression.
+	    Run only those tests, examples, and fuzz targets matching the regular
+	    expression. For fuzz targets, the fuzz function is called once for
+	    each input in the seed corpus, as a subtest named after the input.
 	    For tests, the regular expression is split by unbracketed slash (/)
 	    characters into a sequence of regular expressions, and each part
 	    of a test's identifier must match the corresponding element in
@@ -223,6 +283,13 @@ const testFlag2 = `
 	    the Go tree can run a sanity check but not spend time running
 	    exhaustive tests.
 
+	-shuffle off,on,N
+	    Randomize the execution order of tests and benchmarks.
+	    It is off by default. If -shuffle is set to on, then it will seed
+	    the randomizer using the system clock. If -shuffle is set to an
+	    integer N, then N will be used as the seed value. In both cases,
+	    the seed will be reported for reproducibility.
+
 	-timeout d
 	    If a test binary runs longer than duration d, panic.
 	    The default is 10 minutes (10m).
@@ -348,8 +415,8 @@ var HelpTestfunc = &base.Command{
 	UsageLine: "testfunc",
 	Short:     "description of testing functions",
 	Long: `
-The 'go test' command expects to find test, benchmark, and example functions
-in the "*_test.go" files corresponding to the package under test.
+The 'go test' command expects to find test, benchmark, fuzz, and example
+functions in the "*_test.go" files corresponding to the package under test.
 
 A test function is one named TestXXX (where XXX is any alphanumeric string
 not starting with a lower case letter) and should have the signature,
@@ -360,6 +427,10 @@ A benchmark function is one named BenchmarkXXX and should have the signature,
 
 	func BenchmarkXXX(b *testing.B) { ... }
 
+A fuzz target is one named FuzzXXX and should have the signature,
+
+	func FuzzXXX(f *testing.F) { ... }
+
 An example function is similar to a test function but, instead of using
 *testing.T to report success or failure, prints output to os.Stdout.
 If the last comment in the function starts with "Output:" then the output
@@ -399,7 +470,7 @@ Here is another example where the ordering of the output is ignored:
 
 The entire test file is presented as the example when it contains a single
 example function, at least one other function, type, variable, or constant
-declaration, and no test or benchmark functions.
+declaration, and no test, benchmark, or fuzz functions.
 
 See the documentation of the testing package for more information.
 `,
@@ -411,9 +482,11 @@ var (
 	testCoverMode    string          // -covermode flag
 	testCoverPaths   []string        // -coverpkg flag
 	testCoverPkgs    []*load.Package // -coverpkg flag
+	testFuzz         string          // -fuzz flag
 	testO            string          // -o flag
 	testProfile      bool            // some profiling flag
 	testNeedBinary   bool            // profile needs to keep binary around
+	testJSON         bool            // -json flag
 	testV            bool            // -v flag
 	testTimeout      string          // -timeout flag
 	testArgs         []string
@@ -421,6 +494,7 @@ var (
 	testList         bool
 	testStreamOutput bool // show output as it is generated
 	testShowPass     bool // show passing output
+	testCacheable    bool // test results may be cached
 
 	testKillTimeout = 10 * time.Minute
 )
@@ -454,6 +528,9 @@ func runTest(cmd *base.Command, args []string) {
 	if testProfile && len(pkgs) != 1 {
 		base.Fatalf("cannot use test profile flag with multiple packages")
 	}
+	if testFuzz != "" && len(pkgs) != 1 {
+		base.Fatalf("cannot use -fuzz flag with multiple packages")
+	}
 
 	// If a test timeout was given and is parseable, set our kill timeout
 	// to that timeout plus one minute. This is a backup alarm in case
@@ -463,10 +540,23 @@ func runTest(cmd *base.Command, args []string) {
 		testKillTimeout = dt + 1*time.Minute
 	}
 
+	if testFuzz != "" {
+		// Fuzzing runs until it finds a failure, -fuzztime runs out,
+		// or it is interrupted; the test timeout does not limit it.
+		testKillTimeout = 100 * 365 * 24 * time.Hour
+
+		// Keep the inputs the fuzzing engine found interesting
+		// in the build cache, for use by later runs.
+		if dir := cache.DefaultDir(); dir != "off" {
+			dir = filepath.Join(dir, "fuzz", pkgs[0].ImportPath)
+			testArgs = append([]string{"-test.fuzzcachedir=" + dir}, testArgs...)
+		}
+	}
+
 	// show passing test output (after buffering) with -v flag.
 	// must buffer because tests are running in parallel, and
 	// otherwise the output will get mixed.
-	testShowPass = testV || testList
+	testShowPass = testV || testList || testJSON
 
 	// stream test output (no buffering) when no package has
 	// been given on the command line (implicit current directory)
@@ -475,9 +565,16 @@ func runTest(cmd *base.Command, args []string) {
 	// single package under test or if parallelism is set to 1.
 	// In these cases, streaming the output produces the same result
 	// as not streaming, just more immediately.
-	testStreamOutput = len(pkgArgs) == 0 || testBench ||
+	// JSON output is always streamed: every event names its package,
+	// so output from tests running in parallel can be interlaced.
+	testStreamOutput = len(pkgArgs) == 0 || testBench || testJSON || testFuzz != "" ||
 		(testShowPass && (len(pkgs) == 1 || cfg.BuildP == 1))
 
+	// Cache the results of successful test runs only when testing
+	// an explicit list of packages, and only when every flag passed
+	// to the test binary is one whose effect is captured by the cache key.
+	testCacheable = len(pkgArgs) > 0 && isCacheableTestArgs(testArgs)
+
 	// For 'go test -i -o x.test', we want to build x.test. Imply -c to make the logic easier.
 	if cfg.BuildI && testO != "" {
 		testC = true
@@ -579,7 +676,7 @@ func runTest(cmd *base.Command, args []string) {
 	// Prepare build + run + print actions for all packages being tested.
 	for _, p := range pkgs {
 		// sync/atomic import is inserted by the cover tool. See #18486
-		if testCover && testCoverMode == "atomic" {
+		if (testCover || testFuzz != "") && testCoverMode == "atomic" {
 			ensureImport(p, "sync/atomic")
 		}
 
@@ -627,50 +724,6 @@ func runTest(cmd *base.Command, args []string) {
 		}
 	}
 
-	// If we are building any out-of-date packages other
-	// than those under test, warn.
-	okBuild := map[*load.Package]bool{}
-	for _, p := range pkgs {
-		okBuild[p] = true
-	}
-	warned := false
-	for _, a := range work.ActionList(root) {
-		if a.Package == nil || okBuild[a.Package] {
-			continue
-		}
-		okBuild[a.Package] = true // warn at most once
-
-		// Don't warn about packages being rebuilt because of
-		// things like coverage analysis.
-		for _, p1 := range a.Package.Internal.Imports {
-			if p1.Internal.Fake {
-				a.Package.Internal.Fake = true
-			}
-		}
-
-		if a.Func != nil && !okBuild[a.Package] && !a.Package.Internal.Fake && !a.Package.Internal.Local {
-			if !warned {
-				fmt.Fprintf(os.Stderr, "warning: building out-of-date packages:\n")
-				warned = true
-			}
-			fmt.Fprintf(os.Stderr, "\t%s\n", a.Package.ImportPath)
-		}
-	}
-	if warned {
-		args := strings.Join(pkgArgs, " ")
-		if args != "" {
-			args = " " + args
-		}
-		extraOpts := ""
-		if cfg.BuildRace {
-			extraOpts = "-race "
-		}
-		if cfg.BuildMSan {
-			extraOpts = "-msan "
-		}
-		fmt.Fprintf(os.Stderr, "installing these packages with 'go test %s-i%s' will speed future tests.\n\n", extraOpts, args)
-	}
-
 	b.Do(root)
 }
 
@@ -800,7 +853,9 @@ func builderTest(b *work.Builder, p *load.Package) (buildAction, runAction, prin
 	// only for this package and only for this test?
 	// Yes, if -cover is on but -coverpkg has not specified
 	// a list of packages for global coverage.
-	localCover := testCover && testCoverPaths == nil
+	// Fuzzing needs the same instrumentation to guide
+	// the generation of new inputs.
+	localCover := (testCover || testFuzz != "") && testCoverPaths == nil
 
 	// Test package.
 	if len(p.TestGoFiles) > 0 || localCover || p.Name == "main" {
@@ -1157,6 +1212,19 @@ func builderRunTest(b *work.Builder, a *work.Action) error {
 		}
 	}
 
+	var json io.WriteCloser
+	if testJSON {
+		// Everything this action would print, streamed or not,
+		// is converted to JSON events for a.Package. The converter
+		// sees the buffered result last, once the test is done.
+		json = test2json.NewConverter(lockedStdout{}, a.Package.ImportPath, test2json.Timestamp)
+		defer func() {
+			json.Write(a.TestOutput.Bytes())
+			a.TestOutput.Reset()
+			json.Close()
+		}()
+	}
+
 	if a.Failed {
 		// We were unable to build the binary.
 		a.Failed = false
@@ -1165,13 +1233,47 @@ func builderRunTest(b *work.Builder, a *work.Action) error {
 		return nil
 	}
 
+	env := base.EnvForDir(a.Package.Dir, cfg.OrigEnv)
+
+	// If the same test binary already passed with the same flags,
+	// and none of the files and environment variables it consulted
+	// have changed since, replay the recorded result.
+	var c *cache.Cache
+	var testID cache.ActionID
+	var testlogFile string
+	if testCacheable {
+		c = cache.Default()
+	}
+	if c != nil {
+		var ok bool
+		testID, ok = testActionID(a, env)
+		if !ok {
+			c = nil
+		} else if tryCachedTestResult(c, a, testID, env) {
+			return nil
+		}
+	}
+	if c != nil {
+		testlogFile = filepath.Join(filepath.Dir(a.Deps[0].Target), "testlog.txt")
+		args = append(args, "-test.testlogfile="+testlogFile)
+	}
+
 	cmd := exec.Command(args[0], args[1:]...)
 	cmd.Dir = a.Package.Dir
-	cmd.Env = base.EnvForDir(cmd.Dir, cfg.OrigEnv)
-	var buf bytes.Buffer
+	cmd.Env = env
+	var buf, streamed bytes.Buffer
 	if testStreamOutput {
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+		var stdout, stderr io.Writer = os.Stdout, os.Stderr
+		if json != nil {
+			stdout, stderr = json, json
+		}
+		if c != nil {
+			// Keep a copy of the streamed output to record in the cache.
+			stdout = io.MultiWriter(stdout, &streamed)
+			stderr = stdout
+		}
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
 	} else {
 		cmd.Stdout = &buf
 		cmd.Stderr = &buf
@@ -1243,6 +1345,18 @@ func builderRunTest(b *work.Builder, a *work.Action) error {
 			norun = " [no tests to run]"
 		}
 		fmt.Fprintf(a.TestOutput, "ok  \t%s\t%s%s%s\n", a.Package.ImportPath, t, coveragePercentage(out), norun)
+		if c != nil {
+			var result bytes.Buffer
+			if testShowPass {
+				if testStreamOutput {
+					result.Write(streamed.Bytes())
+				} else {
+					result.Write(out)
+				}
+			}
+			fmt.Fprintf(&result, "ok  \t%s\t(cached)%s%s\n", a.Package.ImportPath, coveragePercentage(out), norun)
+			saveTestResult(c, a, testID, env, testlogFile, result.Bytes())
+		}
 		return nil
 	}
 
@@ -1258,6 +1372,253 @@ func builderRunTest(b *work.Builder, a *work.Action) error {
 	return nil
 }
 
+// cacheableTestFlags lists the test binary flags whose only effect
+// is on the test binary's output or on which tests it runs.
+// Results of test runs using only these flags can be cached.
+var cacheableTestFlags = map[string]bool{
+	"-test.cpu":      true,
+	"-test.failfast": true,
+	"-test.list":     true,
+	"-test.parallel": true,
+	"-test.run":      true,
+	"-test.short":    true,
+	"-test.v":        true,
+}
+
+// isCacheableTestArgs reports whether the results of running
+// a test binary with the given arguments may be cached.
+func isCacheableTestArgs(args []string) bool {
+	for _, arg := range args {
+		i := strings.Index(arg, "=")
+		if i < 0 || !cacheableTestFlags[arg[:i]] {
+			return false
+		}
+	}
+	return true
+}
+
+// testRuntimeEnv lists environment variables that the runtime
+// consults directly, without going through package os.
+// Their values are part of the test action ID.
+var testRuntimeEnv = []string{"GODEBUG", "GOGC", "GOMAXPROCS", "GOTRACEBACK"}
+
+// testActionID returns the action ID identifying a run of the
+// test binary built for a, using the current test flags
+// and the environment env.
+func testActionID(a *work.Action, env []string) (cache.ActionID, bool) {
+	binary, err := cache.FileHash(a.Deps[0].Target)
+	if err != nil {
+		return cache.ActionID{}, false
+	}
+	h := cache.NewHash("testResult")
+	fmt.Fprintf(h, "test binary %x\n", binary)
+	fmt.Fprintf(h, "dir %s\n", a.Package.Dir)
+	for _, arg := range testArgs {
+		fmt.Fprintf(h, "arg %q\n", arg)
+	}
+	for _, key := range testRuntimeEnv {
+		val, ok := lookupEnv(env, key)
+		fmt.Fprintf(h, "env %s %v %q\n", key, ok, val)
+	}
+	return h.Sum(), true
+}
+
+// testInputsKey returns the cache key for the output of the
+// test run identified by testID whose inputs hash to inputsID.
+func testInputsKey(testID, inputsID cache.ActionID) cache.ActionID {
+	return cache.Subkey(testID, fmt.Sprintf("inputs:%x", inputsID))
+}
+
+// tryCachedTestResult reports whether the cache holds the result of
+// a previous successful run of the test identified by testID whose
+// inputs are unchanged. If so, the result is written to a.TestOutput.
+func tryCachedTestResult(c *cache.Cache, a *work.Action, testID cache.ActionID, env []string) bool {
+	testlog, _, err := c.GetBytes(testID)
+	if err != nil {
+		if cache.DebugTest {
+			fmt.Fprintf(os.Stderr, "testcache: %s: test ID %x: no previous run\n", a.Package.ImportPath, testID)
+		}
+		return false
+	}
+	inputsID, err := computeTestInputsID(a, testlog, env)
+	if err != nil {
+		if cache.DebugTest {
+			fmt.Fprintf(os.Stderr, "testcache: %s: test ID %x: %v\n", a.Package.ImportPath, testID, err)
+		}
+		return false
+	}
+	data, _, err := c.GetBytes(testInputsKey(testID, inputsID))
+	if err != nil {
+		if cache.DebugTest {
+			fmt.Fprintf(os.Stderr, "testcache: %s: test ID %x: inputs changed\n", a.Package.ImportPath, testID)
+		}
+		return false
+	}
+	a.TestOutput.Write(data)
+	return true
+}
+
+// saveTestResult records in the cache the result of a successful run
+// of the test identified by testID, along with the log of the files
+// and environment variables the test consulted, read from testlogFile.
+// Failing to update the cache is not an error.
+func saveTestResult(c *cache.Cache, a *work.Action, testID cache.ActionID, env []string, testlogFile string, result []byte) {
+	testlog, err := ioutil.ReadFile(testlogFile)
+	if err != nil || !bytes.HasPrefix(testlog, testlogMagic) {
+		// The test binary did not write a log,
+		// for example because TestMain did not call m.Run.
+		if cache.DebugTest {
+			fmt.Fprintf(os.Stderr, "testcache: %s: no test log\n", a.Package.ImportPath)
+		}
+		return
+	}
+	inputsID, err := computeTestInputsID(a, testlog, env)
+	if err != nil {
+		if cache.DebugTest {
+			fmt.Fprintf(os.Stderr, "testcache: %s: %v\n", a.Package.ImportPath, err)
+		}
+		return
+	}
+	// The log and the output may legitimately differ from run to run,
+	// for example in the order of events or in reported times,
+	// so they are not checked in GODEBUG=gocacheverify=1 mode.
+	if _, _, err := c.PutNoVerify(testID, bytes.NewReader(testlog)); err != nil {
+		return
+	}
+	c.PutNoVerify(testInputsKey(testID, inputsID), bytes.NewReader(result))
+}
+
+// testlogMagic is the header written by the test binary
+// at the start of the file named by -test.testlogfile.
+// See testing/internal/testdeps.
+var testlogMagic = []byte("# test log\n")
+
+// computeTestInputsID computes the hash of the current state of the
+// environment variables and files named in testlog, the action log
+// written by a run of the test binary for a.
+// It returns an error if any of those inputs cannot be hashed reliably,
+// in which case the test result must not be cached.
+func computeTestInputsID(a *work.Action, testlog []byte, env []string) (cache.ActionID, error) {
+	testlog = bytes.TrimPrefix(testlog, testlogMagic)
+	h := cache.NewHash("testInputs")
+	pwd := a.Package.Dir
+	for _, line := range bytes.Split(testlog, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		s := string(line)
+		i := strings.Index(s, " ")
+		if i < 0 {
+			return cache.ActionID{}, fmt.Errorf("invalid test log line %q", s)
+		}
+		op, name := s[:i], s[i+1:]
+		switch op {
+		default:
+			return cache.ActionID{}, fmt.Errorf("unknown action %q in test log", op)
+		case "getenv":
+			val, ok := lookupEnv(env, name)
+			fmt.Fprintf(h, "env %s %v %q\n", name, ok, val)
+		case "chdir":
+			pwd = name
+			fmt.Fprintf(h, "chdir %s %x\n", name, hashStat(name))
+		case "stat":
+			if !filepath.IsAbs(name) {
+				name = filepath.Join(pwd, name)
+			}
+			fmt.Fprintf(h, "stat %s %x\n", name, hashStat(name))
+		case "open":
+			if !filepath.IsAbs(name) {
+				name = filepath.Join(pwd, name)
+			}
+			fh, err := hashOpen(name)
+			if err != nil {
+				return cache.ActionID{}, fmt.Errorf("hash %s: %v", name, err)
+			}
+			fmt.Fprintf(h, "open %s %x\n", name, fh)
+		}
+	}
+	return h.Sum(), nil
+}
+
+// modTimeCutoff is how old a file must be for its content
+// to be trusted in a test cache key. A file modified more recently
+// could change again within the granularity of the file system's
+// modification times without the change being noticed.
+const modTimeCutoff = 2 * time.Second
+
+var errFileTooNew = errors.New("file used as input is too new")
+
+// hashOpen returns a hash of the contents of the named file,
+// or of the list of names in the named directory.
+func hashOpen(name string) (cache.ActionID, error) {
+	h := cache.NewHash("open")
+	info, err := os.Stat(name)
+	if err != nil {
+		fmt.Fprintf(h, "err %v\n", err)
+		return h.Sum(), nil
+	}
+	hashWriteStat(h, info)
+	if info.IsDir() {
+		names, err := ioutil.ReadDir(name)
+		if err != nil {
+			fmt.Fprintf(h, "err %v\n", err)
+		}
+		for _, f := range names {
+			fmt.Fprintf(h, "file %s ", f.Name())
+			hashWriteStat(h, f)
+		}
+	} else if info.Mode().IsRegular() {
+		// A file modified very recently may be modified again
+		// without a visible change in its mtime, and its content
+		// may have been hashed before the modification.
+		// Refuse to use it as a cache input.
+		if time.Since(info.ModTime()) < modTimeCutoff {
+			return cache.ActionID{}, errFileTooNew
+		}
+		fh, err := cache.FileHash(name)
+		if err != nil {
+			return cache.ActionID{}, err
+		}
+		fmt.Fprintf(h, "content %x\n", fh)
+	}
+	return h.Sum(), nil
+}
+
+// hashStat returns a hash of the information returned by os.Stat
+// for the named file.
+func hashStat(name string) cache.ActionID {
+	h := cache.NewHash("stat")
+	if info, err := os.Stat(name); err != nil {
+		fmt.Fprintf(h, "err %v\n", err)
+	} else {
+		hashWriteStat(h, info)
+	}
+	if info, err := os.Lstat(name); err != nil {
+		fmt.Fprintf(h, "err %v\n", err)
+	} else {
+		hashWriteStat(h, info)
+	}
+	return h.Sum()
+}
+
+// hashWriteStat writes the parts of info that matter to a test
+// cache key to h.
+func hashWriteStat(h io.Writer, info os.FileInfo) {
+	fmt.Fprintf(h, "stat %d %x %v %v\n", info.Size(), uint64(info.Mode()), info.ModTime(), info.IsDir())
+}
+
+// lookupEnv looks up key in the environment list env,
+// using the last definition of key, as package os does.
+func lookupEnv(env []string, key string) (string, bool) {
+	val, ok := "", false
+	for _, kv := range env {
+		if strings.HasPrefix(kv, key+"=") {
+			val, ok = kv[len(key)+1:], true
+		}
+	}
+	return val, ok
+}
+
 // coveragePercentage returns the coverage results (if enabled) for the
 // test. It uncovers the data by scanning the output from the test run.
 func coveragePercentage(out []byte) string {
@@ -1299,10 +1660,31 @@ func builderPrintTest(b *work.Builder, a *work.Action) error {
 
 // builderNoTest is the action for testing a package with no test files.
 func builderNoTest(b *work.Builder, a *work.Action) error {
-	fmt.Printf("?   \t%s\t[no test files]\n", a.Package.ImportPath)
+	var stdout io.Writer = os.Stdout
+	if testJSON {
+		json := test2json.NewConverter(lockedStdout{}, a.Package.ImportPath, test2json.Timestamp)
+		defer json.Close()
+		stdout = json
+	}
+	fmt.Fprintf(stdout, "?   \t%s\t[no test files]\n", a.Package.ImportPath)
 	return nil
 }
 
+// stdoutMu serializes writes to standard output
+// by the JSON converters of tests running in parallel.
+var stdoutMu sync.Mutex
+
+// lockedStdout is an io.Writer that writes to os.Stdout
+// while holding stdoutMu, so that each Write is not interleaved
+// with the Writes of other lockedStdouts.
+type lockedStdout struct{}
+
+func (lockedStdout) Write(b []byte) (int, error) {
+	stdoutMu.Lock()
+	defer stdoutMu.Unlock()
+	return os.Stdout.Write(b)
+}
+
 // isTestFunc tells whether fn has the type of a testing function. arg
 // specifies the parameter type we look for: B, M or T.
 func isTestFunc(fn *ast.FuncDecl, arg string) bool {
@@ -1384,6 +1766,7 @@ func writeTestmain(out string, t *testFuncs) error {
 type testFuncs struct {
 	Tests       []testFunc
 	Benchmarks  []testFunc
+	FuzzTargets []testFunc
 	Examples    []testFunc
 	TestMain    *testFunc
 	Package     *load.Package
@@ -1395,12 +1778,19 @@ type testFuncs struct {
 	Cover       []coverInfo
 }
 
+// CoverMode returns the coverage mode reported by the test binary.
+// It is empty when the package is instrumented only for fuzzing.
 func (t *testFuncs) CoverMode() string {
+	if !testCover {
+		return ""
+	}
 	return testCoverMode
 }
 
+// CoverEnabled reports whether the test binary
+// registers coverage counters with package testing.
 func (t *testFuncs) CoverEnabled() bool {
-	return testCover
+	return testCover || testFuzz != ""
 }
 
 // ImportPath returns the import path of the package being tested, if it is within GOPATH.
@@ -1476,6 +1866,13 @@ func (t *testFuncs) load(filename, pkg string, doImport, seen *bool) error {
 			}
 			t.Benchmarks = append(t.Benchmarks, testFunc{pkg, name, "", false})
 			*doImport, *seen = true, true
+		case isTest(name, "Fuzz"):
+			err := checkTestFunc(n, "F")
+			if err != nil {
+				return err
+			}
+			t.FuzzTargets = append(t.FuzzTargets, testFunc{pkg, name, "", false})
+			*doImport, *seen = true, true
 		}
 	}
 	ex := doc.Examples(f)
@@ -1538,6 +1935,12 @@ var benchmarks = []testing.InternalBenchmark{
 {{end}}
 }
 
+var fuzzTargets = []testing.InternalFuzzTarget{
+{{range .FuzzTargets}}
+	{"{{.Name}}", {{.Package}}.{{.Name}}},
+{{end}}
+}
+
 var examples = []testing.InternalExample{
 {{range .Examples}}
 	{"{{.Name}}", {{.Package}}.{{.Name}}, {{.Output | printf "%q"}}, {{.Unordered}}},
@@ -1596,7 +1999,7 @@ func main() {
 		CoveredPackages: {{printf "%q" .Covered}},
 	})
 {{end}}
-	m := testing.MainStart(testdeps.TestDeps{}, tests, benchmarks, examples)
+	m := testing.MainStart(testdeps.TestDeps{}, tests, benchmarks, fuzzTargets, examples)
 {{with .TestMain}}
 	{{.Package}}.{{.Name}}(m)
 {{else}}
@@ -33,6 +33,7 @@ var testFlagDefn = []*cmdflag.Defn{
 	{Name: "covermode"},
 	{Name: "coverpkg"},
 	{Name: "exec"},
+	{Name: "json", BoolVar: &testJSON},
 
 	// Passed to 6.out, adding a "test." prefix to the name if necessary: -v becomes -test.v.
 	{Name: "bench", PassToTest: true},
@@ -42,6 +43,10 @@ var testFlagDefn = []*cmdflag.Defn{
 	{Name: "coverprofile", PassToTest: true},
 	{Name: "cpu", PassToTest: true},
 	{Name: "cpuprofile", PassToTest: true},
+	{Name: "failfast", BoolVar: new(bool), PassToTest: true},
+	{Name: "fuzz", PassToTest: true},
+	{Name: "fuzzminimizetime", PassToTest: true},
+	{Name: "fuzztime", PassToTest: true},
 	{Name: "list", PassToTest: true},
 	{Name: "memprofile", PassToTest: true},
 	{Name: "memprofilerate", PassToTest: true},
@@ -53,6 +58,7 @@ var testFlagDefn = []*cmdflag.Defn{
 	{Name: "parallel", PassToTest: true},
 	{Name: "run", PassToTest: true},
 	{Name: "short", BoolVar: new(bool), PassToTest: true},
+	{Name: "shuffle", PassToTest: true},
 	{Name: "timeout", PassToTest: true},
 	{Name: "trace", PassToTest: true},
 	{Name: "v", BoolVar: &testV, PassToTest: true},
@@ -134,6 +140,13 @@ func testFlags(args []string) (packageNames, passToTest []string) {
 			// bool flags.
 			case "c", "i", "v", "cover":
 				cmdflag.SetBool(cmd, f.BoolVar, value)
+			case "json":
+				cmdflag.SetBool(cmd, f.BoolVar, value)
+				if testJSON {
+					// The converter needs the verbose output
+					// to find the start and end of each test.
+					passToTest = append(passToTest, "-test.v=true")
+				}
 			case "o":
 				testO = value
 				testNeedBinary = true
@@ -148,6 +161,8 @@ func testFlags(args []string) (packageNames, passToTest []string) {
 				testBench = true
 			case "list":
 				testList = true
+			case "fuzz":
+				testFuzz = value
 			case "timeout":
 				testTimeout = value
 			case "blockprofile", "cpuprofile", "memprofile", "mutexprofile":
@@ -187,6 +202,11 @@ func testFlags(args []string) (packageNames, passToTest []string) {
 
 	if testCoverMode == "" {
 		testCoverMode = "set"
+		if testFuzz != "" {
+			// The fuzzing engine uses how often each block runs,
+			// not just whether it runs, to tell inputs apart.
+			testCoverMode = "count"
+		}
 		if cfg.BuildRace {
 			// Default coverage mode is atomic when -race is set.
 			testCoverMode = "atomic"
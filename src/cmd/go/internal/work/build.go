@@ -22,6 +22,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -29,6 +30,7 @@ import (
 
 	"cmd/go/internal/base"
 	"cmd/go/internal/buildid"
+	"cmd/go/internal/cache"
 	"cmd/go/internal/cfg"
 	"cmd/go/internal/load"
 	"cmd/go/internal/str"
@@ -93,6 +95,11 @@ and test commands:
 		arguments to pass on each go tool asm invocation.
 	-buildmode mode
 		build mode to use. See 'go help buildmode' for more.
+	-buildvcs
+		whether to stamp binaries with version control information.
+		By default, the revision, commit time and modification status of
+		the Git or Mercurial checkout holding the main package are recorded
+		in the binary. Use -buildvcs=false to omit them.
 	-compiler name
 		name of compiler to use, as in runtime.Compiler (gccgo or gc).
 	-gccgoflags 'arg list'
@@ -212,6 +219,7 @@ func AddBuildFlags(cmd *base.Command) {
 	cmd.Flag.Var((*base.StringsFlag)(&buildAsmflags), "asmflags", "")
 	cmd.Flag.Var(buildCompiler{}, "compiler", "")
 	cmd.Flag.StringVar(&cfg.BuildBuildmode, "buildmode", "default", "")
+	cmd.Flag.BoolVar(&cfg.BuildBuildvcs, "buildvcs", true, "")
 	cmd.Flag.Var((*base.StringsFlag)(&buildGcflags), "gcflags", "")
 	cmd.Flag.Var((*base.StringsFlag)(&buildGccgoflags), "gccgoflags", "")
 	cmd.Flag.StringVar(&cfg.BuildContext.InstallSuffix, "installsuffix", "", "")
@@ -265,6 +273,7 @@ func oneMainPkg(pkgs []*load.Package) []*load.Package {
 var pkgsFilter = func(pkgs []*load.Package) []*load.Package { return pkgs }
 
 func BuildModeInit() {
+	recordBuildFlags()
 	gccgo := cfg.BuildToolchainName == "gccgo"
 	var codegenArg string
 	platform := cfg.Goos + "/" + cfg.Goarch
@@ -405,6 +414,51 @@ func BuildModeInit() {
 
 var runtimeVersion = runtime.Version()
 
+var buildFlagsRecorded bool
+
+// recordBuildFlags records the build flags set on the command line in
+// load.BuildFlagSettings, for the build information stored in commands.
+// It is called by InstrumentInit and BuildModeInit before they add
+// flags of their own.
+func recordBuildFlags() {
+	if buildFlagsRecorded {
+		return
+	}
+	buildFlagsRecorded = true
+
+	add := func(key, value string) {
+		load.BuildFlagSettings = append(load.BuildFlagSettings, load.BuildSetting{Key: key, Value: value})
+	}
+	if len(buildAsmflags) > 0 {
+		add("-asmflags", str.JoinQuotedFields(buildAsmflags))
+	}
+	if cfg.BuildBuildmode != "default" {
+		add("-buildmode", cfg.BuildBuildmode)
+	}
+	add("-compiler", cfg.BuildToolchainName)
+	if len(buildGccgoflags) > 0 {
+		add("-gccgoflags", str.JoinQuotedFields(buildGccgoflags))
+	}
+	if len(buildGcflags) > 0 {
+		add("-gcflags", str.JoinQuotedFields(buildGcflags))
+	}
+	if len(cfg.BuildLdflags) > 0 {
+		add("-ldflags", str.JoinQuotedFields(cfg.BuildLdflags))
+	}
+	if cfg.BuildLinkshared {
+		add("-linkshared", "true")
+	}
+	if cfg.BuildMSan {
+		add("-msan", "true")
+	}
+	if cfg.BuildRace {
+		add("-race", "true")
+	}
+	if len(cfg.BuildContext.BuildTags) > 0 {
+		add("-tags", str.JoinQuotedFields(cfg.BuildContext.BuildTags))
+	}
+}
+
 func runBuild(cmd *base.Command, args []string) {
 	InstrumentInit()
 	BuildModeInit()
@@ -562,6 +616,9 @@ func InstallPackages(args []string, forGet bool) {
 	for _, p := range pkgs {
 		if p.Target == "" && (!p.Standard || p.ImportPath != "unsafe") {
 			switch {
+			case p.Module != nil && p.Name != "main":
+				// Packages in modules are built but not installed.
+				continue
 			case p.Internal.GobinSubdir:
 				base.Errorf("go install: cannot install cross-compiled binaries when GOBIN is set")
 			case p.Internal.Cmdline:
@@ -866,8 +923,8 @@ func (b *Builder) action1(mode BuildMode, depMode BuildMode, p *load.Package, lo
 		return a
 	}
 
-	if p.Internal.Local && p.Internal.Target == "" {
-		// Imported via local path. No permanent target.
+	if (p.Internal.Local || p.Module != nil) && p.Internal.Target == "" {
+		// Imported via local path or from a module. No permanent target.
 		mode = ModeBuild
 	}
 	work := p.Internal.Pkgdir
@@ -1214,6 +1271,11 @@ func (b *Builder) Do(root *Action) {
 	}
 
 	wg.Wait()
+
+	// Delete old entries from the build cache, if it is time to do so.
+	if c := cache.Default(); c != nil && !cfg.BuildN {
+		c.Trim()
+	}
 }
 
 // build is the action for building a single package or command.
@@ -1275,6 +1337,13 @@ func (b *Builder) build(a *Action) (err error) {
 		}
 	}
 
+	// If the build cache holds an archive compiled from
+	// exactly the same inputs, reuse it instead of compiling.
+	actionID, cacheable := b.buildActionID(a)
+	if cacheable && b.useCachedArchive(a, actionID) {
+		return b.link(a, nil)
+	}
+
 	var gofiles, cgofiles, objdirCgofiles, cfiles, sfiles, cxxfiles, objects, cgoObjects, pcCFLAGS, pcLDFLAGS []string
 
 	gofiles = append(gofiles, a.Package.GoFiles...)
@@ -1388,6 +1457,21 @@ func (b *Builder) build(a *Action) (err error) {
 		}
 	}
 
+	// Record the build information of commands built with the gc
+	// toolchain in the binary; see runtime/debug.ReadBuildInfo.
+	if p := a.Package; p.Internal.BuildInfo != "" && !p.Internal.ForceLibrary && cfg.BuildToolchainName == "gc" {
+		prog := load.ModInfoProg(p.Internal.BuildInfo)
+		if cfg.BuildN || cfg.BuildX {
+			b.Showcmd("", "cat >%s << 'EOF' # internal\n%sEOF", objdir+"_gomod_.go", prog)
+		}
+		if !cfg.BuildN {
+			if err := ioutil.WriteFile(objdir+"_gomod_.go", prog, 0666); err != nil {
+				return err
+			}
+		}
+		gofiles = append(gofiles, objdir+"_gomod_.go")
+	}
+
 	// Prepare Go import path list.
 	inc := b.includeArgs("-I", allArchiveActions(a))
 
@@ -1472,18 +1556,152 @@ func (b *Builder) build(a *Action) (err error) {
 		}
 	}
 
+	if cacheable {
+		b.cacheArchive(a, actionID)
+	}
+
 	// Link if needed.
-	if a.Link {
-		// The compiler only cares about direct imports, but the
-		// linker needs the whole dependency tree.
-		all := ActionList(a)
-		all = all[:len(all)-1] // drop a
-		if err := BuildToolchain.ld(b, a, a.Target, all, a.Objpkg, objects); err != nil {
-			return err
+	return b.link(a, objects)
+}
+
+// link links the executable for a, if a is a command.
+func (b *Builder) link(a *Action, objects []string) error {
+	if !a.Link {
+		return nil
+	}
+
+	// The compiler only cares about direct imports, but the
+	// linker needs the whole dependency tree.
+	all := ActionList(a)
+	all = all[:len(all)-1] // drop a
+	return BuildToolchain.ld(b, a, a.Target, all, a.Objpkg, objects)
+}
+
+// buildActionID computes the action ID used to look up the compiled
+// archive for a in the build cache. The ID is a hash of everything that
+// can affect the compiler's output: the toolchain binaries, the target
+// and build flags, the package's source files and the archives of its
+// dependencies. It reports false if the archive for a must not be cached,
+// either because there is no cache or because the build involves inputs
+// that are not reflected in the hash, such as a C toolchain.
+func (b *Builder) buildActionID(a *Action) (cache.ActionID, bool) {
+	p := a.Package
+	if cache.Default() == nil || cfg.BuildN || cfg.BuildToolchainName != "gc" {
+		return cache.ActionID{}, false
+	}
+	if p.UsesCgo() || p.UsesSwig() || cfg.BuildLinkshared || cfg.BuildBuildmode == "shared" {
+		return cache.ActionID{}, false
+	}
+
+	h := cache.NewHash("build " + p.ImportPath)
+	fmt.Fprintf(h, "compile\n")
+	fmt.Fprintf(h, "goos %s goarch %s\n", cfg.Goos, cfg.Goarch)
+	switch cfg.Goarch {
+	case "arm":
+		fmt.Fprintf(h, "GOARM=%s\n", cfg.GOARM)
+	case "386":
+		fmt.Fprintf(h, "GO386=%s\n", cfg.GO386)
+	}
+	fmt.Fprintf(h, "buildmode %s installsuffix %q\n", cfg.BuildBuildmode, cfg.BuildContext.InstallSuffix)
+	fmt.Fprintf(h, "toolexec %q\n", cfg.BuildToolexec)
+	fmt.Fprintf(h, "gcflags %q\n", buildGcflags)
+	fmt.Fprintf(h, "asmflags %q\n", buildAsmflags)
+
+	// The compiler records source file names in its output,
+	// and -trimpath removes only the temporary work directory
+	// from them, so the package directory is part of the key.
+	dir := p.Dir
+	if strings.HasPrefix(dir, b.WorkDir) {
+		dir = "$WORK" + string(filepath.Separator) + dir[len(b.WorkDir):]
+	}
+	fmt.Fprintf(h, "dir %s\n", dir)
+	fmt.Fprintf(h, "import %q name %q std %v\n", p.ImportPath, p.Name, p.Standard)
+	fmt.Fprintf(h, "buildid %s omitdebug %v\n", p.Internal.BuildID, p.Internal.OmitDebug)
+	fmt.Fprintf(h, "localprefix %q\n", p.Internal.LocalPrefix)
+	if p.Internal.BuildInfo != "" && !p.Internal.ForceLibrary {
+		fmt.Fprintf(h, "modinfo %q\n", p.Internal.BuildInfo)
+	}
+	for _, path := range p.Imports {
+		fmt.Fprintf(h, "imports %q\n", path)
+	}
+	if p.Internal.CoverMode != "" {
+		fmt.Fprintf(h, "cover %q\n", p.Internal.CoverMode)
+		var keys []string
+		for key := range p.Internal.CoverVars {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(h, "covervar %q %q\n", key, p.Internal.CoverVars[key].Var)
 		}
 	}
 
-	return nil
+	tools := []string{"compile"}
+	if len(p.SFiles) > 0 {
+		tools = append(tools, "asm")
+	}
+	if len(p.SFiles) > 0 || len(p.SysoFiles) > 0 {
+		tools = append(tools, "pack")
+	}
+	for _, tool := range tools {
+		id, err := cache.FileHash(base.Tool(tool))
+		if err != nil {
+			return cache.ActionID{}, false
+		}
+		fmt.Fprintf(h, "tool %s %x\n", tool, id)
+	}
+
+	for _, list := range [][]string{p.GoFiles, p.SFiles, p.HFiles, p.SysoFiles} {
+		for _, file := range list {
+			id, err := cache.FileHash(filepath.Join(p.Dir, file))
+			if err != nil {
+				return cache.ActionID{}, false
+			}
+			fmt.Fprintf(h, "file %s %x\n", file, id)
+		}
+	}
+
+	for _, a1 := range allArchiveActions(a) {
+		if a1.Package == nil {
+			continue
+		}
+		id, err := cache.FileHash(a1.Target)
+		if err != nil {
+			return cache.ActionID{}, false
+		}
+		fmt.Fprintf(h, "dep %s %x\n", a1.Package.ImportPath, id)
+	}
+
+	return h.Sum(), true
+}
+
+// useCachedArchive reports whether the build cache holds the archive
+// for actionID, in which case it has been copied to a.Objpkg.
+func (b *Builder) useCachedArchive(a *Action, actionID cache.ActionID) bool {
+	file, entry, err := cache.Default().GetFile(actionID)
+	if err != nil {
+		return false
+	}
+	if err := b.copyFile(a, a.Objpkg, file, 0666, true); err != nil {
+		return false
+	}
+	cache.SetFileHash(a.Objpkg, entry.OutputID)
+	return true
+}
+
+// cacheArchive records the archive just written to a.Objpkg
+// in the build cache under actionID.
+// Failing to update the cache is not an error.
+func (b *Builder) cacheArchive(a *Action, actionID cache.ActionID) {
+	f, err := os.Open(a.Objpkg)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	outputID, _, err := cache.Default().Put(actionID, f)
+	if err == nil {
+		cache.SetFileHash(a.Objpkg, outputID)
+	}
 }
 
 // PkgconfigCmd returns a pkg-config binary name
@@ -2224,7 +2442,7 @@ func (gcToolchain) gc(b *Builder, p *load.Package, archive, objdir string, asmhd
 	extFiles := len(p.CgoFiles) + len(p.CFiles) + len(p.CXXFiles) + len(p.MFiles) + len(p.FFiles) + len(p.SFiles) + len(p.SysoFiles) + len(p.SwigFiles) + len(p.SwigCXXFiles)
 	if p.Standard {
 		switch p.ImportPath {
-		case "bytes", "internal/poll", "net", "os", "runtime/pprof", "sync", "syscall", "time":
+		case "bytes", "internal/poll", "net", "os", "runtime/metrics", "runtime/pprof", "runtime/trace", "sync", "syscall", "time":
 			extFiles++
 		}
 	}
@@ -3824,6 +4042,7 @@ func (q *actionQueue) pop() *Action {
 }
 
 func InstrumentInit() {
+	recordBuildFlags()
 	if !cfg.BuildRace && !cfg.BuildMSan {
 		return
 	}
@@ -27,6 +27,9 @@ import (
 	"cmd/go/internal/get"
 	"cmd/go/internal/help"
 	"cmd/go/internal/list"
+	"cmd/go/internal/modfetch"
+	"cmd/go/internal/modget"
+	"cmd/go/internal/modload"
 	"cmd/go/internal/run"
 	"cmd/go/internal/test"
 	"cmd/go/internal/tool"
@@ -56,10 +59,14 @@ func init() {
 
 		help.HelpC,
 		help.HelpBuildmode,
+		help.HelpCache,
 		help.HelpFileType,
 		help.HelpGopath,
 		help.HelpEnvironment,
 		help.HelpImportPath,
+		modload.HelpModules,
+		modget.HelpModuleGet,
+		modfetch.HelpGoproxy,
 		help.HelpPackages,
 		test.HelpTestflag,
 		test.HelpTestfunc,
@@ -121,6 +128,13 @@ func main() {
 		}
 	}
 
+	// Decide between module-aware and GOPATH mode.
+	// In module-aware mode, 'go get' manages module requirements.
+	modload.Init()
+	if cfg.ModulesEnabled {
+		*get.CmdGet = *modget.CmdGet
+	}
+
 	for _, cmd := range base.Commands {
 		if cmd.Name() == args[0] && cmd.Runnable() {
 			cmd.Flag.Usage = func() { cmd.Usage() }
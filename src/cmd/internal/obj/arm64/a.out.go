@@ -294,7 +294,6 @@ const (
 	C_PSAUTO_8   // 0 to 255, 0 mod 8
 	C_PSAUTO     // 0 to 255
 	C_PPAUTO_8   // 0 to 504, 0 mod 8
-	C_PPAUTO     // 0 to 504
 	C_UAUTO4K_8  // 0 to 4095, 0 mod 8
 	C_UAUTO4K_4  // 0 to 4095, 0 mod 4
 	C_UAUTO4K_2  // 0 to 4095, 0 mod 2
@@ -320,7 +319,6 @@ const (
 	C_PSOREG_8
 	C_PSOREG
 	C_PPOREG_8
-	C_PPOREG
 	C_UOREG4K_8
 	C_UOREG4K_4
 	C_UOREG4K_2
@@ -38,7 +38,6 @@ var cnames7 = []string{
 	"PSAUTO_8",
 	"PSAUTO",
 	"PPAUTO_8",
-	"PPAUTO",
 	"UAUTO4K_8",
 	"UAUTO4K_4",
 	"UAUTO4K_2",
@@ -62,7 +61,6 @@ var cnames7 = []string{
 	"PSOREG_8",
 	"PSOREG",
 	"PPOREG_8",
-	"PPOREG",
 	"UOREG4K_8",
 	"UOREG4K_4",
 	"UOREG4K_2",
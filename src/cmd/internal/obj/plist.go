@@ -187,3 +187,30 @@ func (ctxt *Link) Globl(s *LSym, size int64, flag int) {
 		s.Type = objabi.STLSBSS
 	}
 }
+
+// StartUnsafePoint generates a PCDATA Prog after p to mark the
+// beginning of an async-unsafe sequence, which starts immediately
+// after p. It returns the generated Prog.
+func (ctxt *Link) StartUnsafePoint(p *Prog, newprog ProgAlloc) *Prog {
+	pcdata := Appendp(p, newprog)
+	pcdata.As = APCDATA
+	pcdata.From.Type = TYPE_CONST
+	pcdata.From.Offset = objabi.PCDATA_UnsafePoint
+	pcdata.To.Type = TYPE_CONST
+	pcdata.To.Offset = objabi.PCDATA_UnsafePointUnsafe
+	return pcdata
+}
+
+// EndUnsafePoint generates a PCDATA Prog after p to mark the end of
+// an async-unsafe sequence, restoring the unsafe-point value to
+// oldval. The sequence ends right after p. It returns the generated
+// Prog.
+func (ctxt *Link) EndUnsafePoint(p *Prog, newprog ProgAlloc, oldval int64) *Prog {
+	pcdata := Appendp(p, newprog)
+	pcdata.As = APCDATA
+	pcdata.From.Type = TYPE_CONST
+	pcdata.From.Offset = objabi.PCDATA_UnsafePoint
+	pcdata.To.Type = TYPE_CONST
+	pcdata.To.Offset = oldval
+	return pcdata
+}
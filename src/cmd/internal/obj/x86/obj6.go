@@ -906,6 +906,16 @@ func preprocess(ctxt *obj.Link, cursym *obj.LSym, newprog obj.ProgAlloc) {
 			p.Spadj = -2
 			continue
 
+		case AADJSP:
+			// The frame allocation above already has
+			// Spadj set; only account for explicit
+			// ADJSPs in the function body.
+			if p.Spadj == 0 {
+				p.Spadj = int32(p.From.Offset)
+				deltasp += int32(p.From.Offset)
+			}
+			continue
+
 		case obj.ARET:
 			// do nothing
 		}
@@ -13,6 +13,7 @@ package objabi
 const (
 	PCDATA_StackMapIndex       = 0
 	PCDATA_InlTreeIndex        = 1
+	PCDATA_UnsafePoint         = 2
 	FUNCDATA_ArgsPointerMaps   = 0
 	FUNCDATA_LocalsPointerMaps = 1
 	FUNCDATA_InlTree           = 2
@@ -23,3 +24,10 @@ const (
 	// This value is generated by the compiler, assembler, or linker.
 	ArgsSizeUnknown = -0x80000000
 )
+
+// Special PCDATA values.
+const (
+	// PCDATA_UnsafePoint values.
+	PCDATA_UnsafePointSafe   = -1 // Safe for async preemption
+	PCDATA_UnsafePointUnsafe = -2 // Unsafe for async preemption
+)
@@ -35,6 +35,7 @@ import (
 	"cmd/internal/gcprog"
 	"cmd/internal/objabi"
 	"cmd/internal/sys"
+	"encoding/binary"
 	"fmt"
 	"log"
 	"os"
@@ -1427,6 +1428,7 @@ func (ctxt *Link) dodata() {
 
 	// Writable data sections that do not need any specialized handling.
 	writable := []SymKind{
+		SBUILDINFO,
 		SELFSECT,
 		SMACHO,
 		SMACHOGOT,
@@ -2038,6 +2040,42 @@ func (ctxt *Link) textbuildid() {
 	ctxt.Textp[0] = sym
 }
 
+// buildinfo creates the .go.buildinfo section, which holds pointers
+// to the Go version and build information strings of the binary.
+// This section is read by cmd/go ("go version").
+func (ctxt *Link) buildinfo() {
+	if *FlagLinkshared || Buildmode == BuildmodePlugin {
+		// -linkshared and -buildmode=plugin get confused
+		// about the relocations in .go.buildinfo
+		// pointing at the other data sections.
+		// The version information is only available in executables.
+		return
+	}
+
+	s := ctxt.Syms.Lookup(".go.buildinfo", 0)
+	s.Attr |= AttrReachable
+	s.Type = SBUILDINFO
+	s.Align = 16
+	// The \xff is invalid UTF-8, meant to make it less likely
+	// to find one of these accidentally.
+	const prefix = "\xff Go buildinf:" // 14 bytes, plus 2 data bytes filled in below
+	data := make([]byte, 32)
+	copy(data, prefix)
+	data[len(prefix)] = byte(SysArch.PtrSize)
+	data[len(prefix)+1] = 0
+	if SysArch.ByteOrder == binary.BigEndian {
+		data[len(prefix)+1] = 1
+	}
+	s.P = data
+	s.Size = int64(len(s.P))
+	s1 := ctxt.Syms.Lookup("runtime.buildVersion", 0)
+	s2 := ctxt.Syms.Lookup("runtime.modinfo", 0)
+	s.R = []Reloc{
+		{Off: 16, Siz: uint8(SysArch.PtrSize), Type: objabi.R_ADDR, Sym: s1},
+		{Off: 16 + int32(SysArch.PtrSize), Siz: uint8(SysArch.PtrSize), Type: objabi.R_ADDR, Sym: s2},
+	}
+}
+
 // assign addresses to text
 func (ctxt *Link) textaddress() {
 	addsection(&Segtext, ".text", 05)
@@ -1890,6 +1890,7 @@ func (ctxt *Link) doelf() {
 
 	Addstring(shstrtab, "")
 	Addstring(shstrtab, ".text")
+	Addstring(shstrtab, ".go.buildinfo")
 	Addstring(shstrtab, ".noptrdata")
 	Addstring(shstrtab, ".data")
 	Addstring(shstrtab, ".bss")
@@ -1935,6 +1936,7 @@ func (ctxt *Link) doelf() {
 		Addstring(shstrtab, elfRelType+relro_prefix+".itablink")
 		Addstring(shstrtab, elfRelType+relro_prefix+".gosymtab")
 		Addstring(shstrtab, elfRelType+relro_prefix+".gopclntab")
+		Addstring(shstrtab, elfRelType+".go.buildinfo")
 		Addstring(shstrtab, elfRelType+".noptrdata")
 		Addstring(shstrtab, elfRelType+".data")
 		if UseRelro() {
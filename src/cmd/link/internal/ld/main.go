@@ -216,6 +216,7 @@ func Main() {
 	ctxt.findfunctab()
 	ctxt.typelink()
 	ctxt.symtab()
+	ctxt.buildinfo()
 	ctxt.dodata()
 	ctxt.address()
 	ctxt.reloc()
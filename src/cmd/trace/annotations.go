@@ -0,0 +1,713 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// User annotation (task, region and log) analysis.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"internal/trace"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+func init() {
+	http.HandleFunc("/usertasks", httpUserTasks)
+	http.HandleFunc("/usertask", httpUserTask)
+	http.HandleFunc("/userregions", httpUserRegions)
+	http.HandleFunc("/userregion", httpUserRegion)
+}
+
+// annotationAnalysisResult is the result of analyzing user annotations.
+type annotationAnalysisResult struct {
+	tasks   map[uint64]*taskDesc           // tasks by task id
+	regions map[regionTypeID][]*regionDesc // regions by region type
+}
+
+// regionTypeID identifies a region type by the region name
+// and the location where the region started.
+type regionTypeID struct {
+	Frame trace.Frame // top frame of the region start stack
+	Type  string
+}
+
+// taskDesc describes a task instance.
+type taskDesc struct {
+	name       string          // user-provided task name
+	id         uint64          // internal task id
+	create     *trace.Event    // task creation event, nil if created before tracing started
+	end        *trace.Event    // task end event, nil if ended after tracing stopped
+	parent     *taskDesc       // parent task, nil if none
+	children   []*taskDesc     // subtasks
+	regions    []*regionDesc   // regions attached to the task
+	goroutines map[uint64]bool // goroutines that did something for the task
+	events     []*trace.Event  // task creation, logs, region and end events in time order
+}
+
+// regionDesc describes a region instance.
+type regionDesc struct {
+	Name   string
+	TaskID uint64
+	G      uint64
+	Start  *trace.Event // region start event, nil if started before tracing started
+	End    *trace.Event // region end event, nil if ended after tracing stopped
+}
+
+var (
+	annotationsInit   sync.Once
+	annotationsResult annotationAnalysisResult
+	annotationsErr    error
+)
+
+// loadAnnotations parses the trace and analyzes user annotations once.
+func loadAnnotations() (annotationAnalysisResult, error) {
+	annotationsInit.Do(func() {
+		events, err := parseEvents()
+		if err != nil {
+			annotationsErr = err
+			return
+		}
+		annotationsResult = analyzeAnnotations(events)
+	})
+	return annotationsResult, annotationsErr
+}
+
+// analyzeAnnotations collects the task and region information from events.
+func analyzeAnnotations(events []*trace.Event) annotationAnalysisResult {
+	res := annotationAnalysisResult{
+		tasks:   make(map[uint64]*taskDesc),
+		regions: make(map[regionTypeID][]*regionDesc),
+	}
+
+	// task returns the taskDesc for id, creating it if necessary.
+	task := func(id uint64) *taskDesc {
+		if t, ok := res.tasks[id]; ok {
+			return t
+		}
+		t := &taskDesc{id: id, goroutines: make(map[uint64]bool)}
+		res.tasks[id] = t
+		return t
+	}
+	addRegion := func(r *regionDesc) {
+		ev := r.Start
+		if ev == nil {
+			ev = r.End
+		}
+		var frame trace.Frame
+		if len(ev.Stk) > 0 {
+			frame = *ev.Stk[0]
+		}
+		id := regionTypeID{Frame: frame, Type: r.Name}
+		res.regions[id] = append(res.regions[id], r)
+		if r.TaskID != 0 {
+			t := task(r.TaskID)
+			t.regions = append(t.regions, r)
+		}
+	}
+
+	activeRegions := make(map[uint64][]*regionDesc) // goroutine id to stack of active regions
+	for _, ev := range events {
+		switch ev.Type {
+		case trace.EvUserTaskCreate:
+			t := task(ev.Args[0])
+			t.name = ev.SArgs[0]
+			t.create = ev
+			t.goroutines[ev.G] = true
+			t.events = append(t.events, ev)
+			if pid := ev.Args[1]; pid != 0 {
+				parent := task(pid)
+				t.parent = parent
+				parent.children = append(parent.children, t)
+			}
+		case trace.EvUserTaskEnd:
+			t := task(ev.Args[0])
+			t.end = ev
+			t.goroutines[ev.G] = true
+			t.events = append(t.events, ev)
+		case trace.EvUserLog:
+			if id := ev.Args[0]; id != 0 {
+				t := task(id)
+				t.goroutines[ev.G] = true
+				t.events = append(t.events, ev)
+			}
+		case trace.EvUserRegion:
+			id, mode, name := ev.Args[0], ev.Args[1], ev.SArgs[0]
+			if id != 0 {
+				t := task(id)
+				t.goroutines[ev.G] = true
+				t.events = append(t.events, ev)
+			}
+			stack := activeRegions[ev.G]
+			switch mode {
+			case 0: // start
+				activeRegions[ev.G] = append(stack, &regionDesc{Name: name, TaskID: id, G: ev.G, Start: ev})
+			case 1: // end
+				if n := len(stack); n > 0 {
+					r := stack[n-1]
+					r.End = ev
+					activeRegions[ev.G] = stack[:n-1]
+					addRegion(r)
+				} else {
+					// The region started before tracing started.
+					addRegion(&regionDesc{Name: name, TaskID: id, G: ev.G, End: ev})
+				}
+			}
+		}
+	}
+	// Regions that did not end before tracing stopped.
+	for _, stack := range activeRegions {
+		for _, r := range stack {
+			addRegion(r)
+		}
+	}
+	return res
+}
+
+// firstTimestamp returns the timestamp of the first event in the trace.
+func firstTimestamp() int64 {
+	events, _ := parseEvents()
+	if len(events) > 0 {
+		return events[0].Ts
+	}
+	return 0
+}
+
+// lastTimestamp returns the timestamp of the last event in the trace.
+func lastTimestamp() int64 {
+	events, _ := parseEvents()
+	if n := len(events); n > 0 {
+		return events[n-1].Ts
+	}
+	return 0
+}
+
+// complete reports whether both the start and the end of the task
+// are in the trace.
+func (task *taskDesc) complete() bool {
+	return task.create != nil && task.end != nil
+}
+
+// firstTimestamp returns the time the task started, or the start
+// of the trace if the task started before tracing.
+func (task *taskDesc) firstTimestamp() int64 {
+	if task.create != nil {
+		return task.create.Ts
+	}
+	return firstTimestamp()
+}
+
+// lastTimestamp returns the time the task ended, or the end
+// of the trace if the task ended after tracing stopped.
+func (task *taskDesc) lastTimestamp() int64 {
+	if task.end != nil {
+		return task.end.Ts
+	}
+	return lastTimestamp()
+}
+
+func (task *taskDesc) duration() time.Duration {
+	return time.Duration(task.lastTimestamp()-task.firstTimestamp()) * time.Nanosecond
+}
+
+func (r *regionDesc) firstTimestamp() int64 {
+	if r.Start != nil {
+		return r.Start.Ts
+	}
+	return firstTimestamp()
+}
+
+func (r *regionDesc) lastTimestamp() int64 {
+	if r.End != nil {
+		return r.End.Ts
+	}
+	return lastTimestamp()
+}
+
+func (r *regionDesc) duration() time.Duration {
+	return time.Duration(r.lastTimestamp()-r.firstTimestamp()) * time.Nanosecond
+}
+
+// durationHistogram is a histogram of durations with logarithmic buckets.
+type durationHistogram struct {
+	Count                int
+	Buckets              []int
+	MinBucket, MaxBucket int
+}
+
+// Five buckets for every power of 10.
+var logDiv = math.Log(math.Pow(10, 1.0/5))
+
+func (h *durationHistogram) add(d time.Duration) {
+	var bucket int
+	if d > 0 {
+		// Allow for rounding errors so that exact powers of 10
+		// fall into the bucket that starts at them.
+		bucket = int(math.Log(float64(d))/logDiv + 1e-9)
+	}
+	if len(h.Buckets) <= bucket {
+		h.Buckets = append(h.Buckets, make([]int, bucket-len(h.Buckets)+1)...)
+	}
+	h.Buckets[bucket]++
+	if h.Count == 0 || bucket < h.MinBucket {
+		h.MinBucket = bucket
+	}
+	if bucket > h.MaxBucket {
+		h.MaxBucket = bucket
+	}
+	h.Count++
+}
+
+// BucketMin returns the smallest duration that falls into bucket.
+func (h *durationHistogram) BucketMin(bucket int) time.Duration {
+	return time.Duration(math.Exp(float64(bucket)*logDiv) + 0.5)
+}
+
+// niceDuration formats d with a unit that keeps the number short.
+func niceDuration(d time.Duration) string {
+	var rnd time.Duration
+	var unit string
+	switch {
+	case d < 10*time.Microsecond:
+		rnd, unit = time.Nanosecond, "ns"
+	case d < 10*time.Millisecond:
+		rnd, unit = time.Microsecond, "µs"
+	case d < 10*time.Second:
+		rnd, unit = time.Millisecond, "ms"
+	default:
+		rnd, unit = time.Second, "s"
+	}
+	return fmt.Sprintf("%d%s", d/rnd, unit)
+}
+
+// ToHTML renders the histogram as an HTML table. urlmaker returns the
+// link for the bucket covering durations in [min, max).
+func (h *durationHistogram) ToHTML(urlmaker func(min, max time.Duration) string) template.HTML {
+	if h == nil || h.Count == 0 {
+		return template.HTML("")
+	}
+
+	const barWidth = 400
+
+	maxCount := 0
+	for _, count := range h.Buckets {
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+
+	w := new(bytes.Buffer)
+	fmt.Fprintf(w, `<table>`)
+	for i := h.MinBucket; i <= h.MaxBucket; i++ {
+		// Tick label.
+		if h.Buckets[i] > 0 {
+			fmt.Fprintf(w, `<tr><td class="histoTime" align="right"><a href="%s">%s</a></td>`, template.HTMLEscapeString(urlmaker(h.BucketMin(i), h.BucketMin(i+1))), niceDuration(h.BucketMin(i)))
+		} else {
+			fmt.Fprintf(w, `<tr><td class="histoTime" align="right">%s</td>`, niceDuration(h.BucketMin(i)))
+		}
+		// Bucket bar.
+		width := h.Buckets[i] * barWidth / maxCount
+		fmt.Fprintf(w, `<td><div style="width:%dpx;background:blue;position:relative">&nbsp;</div></td>`, width)
+		// Bucket count.
+		fmt.Fprintf(w, `<td align="right"><div style="position:relative">%d</div></td>`, h.Buckets[i])
+		fmt.Fprintf(w, "</tr>\n")
+	}
+	// Final tick label.
+	fmt.Fprintf(w, `<tr><td align="right">%s</td></tr>`, niceDuration(h.BucketMin(h.MaxBucket+1)))
+	fmt.Fprintf(w, `</table>`)
+	return template.HTML(w.String())
+}
+
+// durationFilter parses the optional latmin and latmax parameters of r
+// and returns a predicate selecting durations in [latmin, latmax).
+func durationFilter(r *http.Request) (func(time.Duration) bool, error) {
+	min, max := time.Duration(0), time.Duration(math.MaxInt64)
+	if s := r.FormValue("latmin"); s != "" {
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse latmin parameter '%v': %v", s, err)
+		}
+		min = v
+	}
+	if s := r.FormValue("latmax"); s != "" {
+		v, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse latmax parameter '%v': %v", s, err)
+		}
+		max = v
+	}
+	return func(d time.Duration) bool {
+		return min <= d && d < max
+	}, nil
+}
+
+// taskStats summarizes the tasks of one type.
+type taskStats struct {
+	Type      string
+	Count     int               // complete + incomplete tasks
+	Histogram durationHistogram // latency distribution of complete tasks
+}
+
+func (s *taskStats) UserTaskURL(complete bool) func(min, max time.Duration) string {
+	return func(min, max time.Duration) string {
+		return fmt.Sprintf("/usertask?type=%s&complete=%v&latmin=%v&latmax=%v", url.QueryEscape(s.Type), complete, min, max)
+	}
+}
+
+// httpUserTasks serves the list of task types with their latency distributions.
+func httpUserTasks(w http.ResponseWriter, r *http.Request) {
+	res, err := loadAnnotations()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	stats := make(map[string]*taskStats)
+	for _, task := range res.tasks {
+		s, ok := stats[task.name]
+		if !ok {
+			s = &taskStats{Type: task.name}
+			stats[task.name] = s
+		}
+		s.Count++
+		if task.complete() {
+			s.Histogram.add(task.duration())
+		}
+	}
+	var list []*taskStats
+	for _, s := range stats {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Type < list[j].Type
+	})
+	if err := templUserTaskTypes.Execute(w, list); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+		return
+	}
+}
+
+var templUserTaskTypes = template.Must(template.New("").Parse(`
+<html>
+<body>
+Tasks found in the trace:
+<table border="1" sortable="1">
+<tr>
+<th>Task type</th>
+<th>Count</th>
+<th>Duration distribution (complete tasks)</th>
+</tr>
+{{range $}}
+  <tr>
+    <td>{{.Type}}</td>
+    <td><a href="/usertask?type={{.Type}}">{{.Count}}</a></td>
+    <td>{{.Histogram.ToHTML (.UserTaskURL true)}}</td>
+  </tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
+
+// taskEvent is a single row in the event list of a task.
+type taskEvent struct {
+	WhenString    string // time since the start of the task
+	ElapsedString string // time since the previous event
+	Go            uint64 // goroutine that emitted the event
+	What          string
+}
+
+// taskInstance is a task rendered by httpUserTask.
+type taskInstance struct {
+	ID             uint64
+	WhenString     string // start time of the task in the trace
+	DurationString string
+	Complete       bool
+	Parent         uint64
+	Goroutines     []uint64
+	Events         []taskEvent
+}
+
+// httpUserTask serves the instances of a task type, optionally filtered by
+// completeness and latency.
+func httpUserTask(w http.ResponseWriter, r *http.Request) {
+	res, err := loadAnnotations()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	filter, err := durationFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	typ := r.FormValue("type")
+	onlyComplete := r.FormValue("complete") == "true"
+
+	var tasks []*taskDesc
+	for _, task := range res.tasks {
+		if task.name != typ {
+			continue
+		}
+		if onlyComplete && !task.complete() {
+			continue
+		}
+		if !filter(task.duration()) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].firstTimestamp() < tasks[j].firstTimestamp()
+	})
+
+	var list []taskInstance
+	for _, task := range tasks {
+		ti := taskInstance{
+			ID:             task.id,
+			WhenString:     fmt.Sprintf("%.6fs", float64(task.firstTimestamp())/1e9),
+			DurationString: task.duration().String(),
+			Complete:       task.complete(),
+		}
+		if task.parent != nil {
+			ti.Parent = task.parent.id
+		}
+		for g := range task.goroutines {
+			ti.Goroutines = append(ti.Goroutines, g)
+		}
+		sort.Slice(ti.Goroutines, func(i, j int) bool {
+			return ti.Goroutines[i] < ti.Goroutines[j]
+		})
+		last := task.firstTimestamp()
+		for _, ev := range task.events {
+			ti.Events = append(ti.Events, taskEvent{
+				WhenString:    time.Duration(ev.Ts - task.firstTimestamp()).String(),
+				ElapsedString: time.Duration(ev.Ts - last).String(),
+				Go:            ev.G,
+				What:          describeEvent(ev),
+			})
+			last = ev.Ts
+		}
+		list = append(list, ti)
+	}
+
+	err = templUserTask.Execute(w, struct {
+		Type  string
+		Tasks []taskInstance
+	}{typ, list})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+		return
+	}
+}
+
+// describeEvent returns a short description of a user annotation event.
+func describeEvent(ev *trace.Event) string {
+	switch ev.Type {
+	case trace.EvUserTaskCreate:
+		return fmt.Sprintf("task %q created", ev.SArgs[0])
+	case trace.EvUserTaskEnd:
+		return "task end"
+	case trace.EvUserRegion:
+		if ev.Args[1] == 0 {
+			return fmt.Sprintf("region %q started", ev.SArgs[0])
+		}
+		return fmt.Sprintf("region %q ended", ev.SArgs[0])
+	case trace.EvUserLog:
+		if category := ev.SArgs[0]; category != "" {
+			return fmt.Sprintf("log %s=%q", category, ev.SArgs[1])
+		}
+		return fmt.Sprintf("log %q", ev.SArgs[1])
+	}
+	return ""
+}
+
+var templUserTask = template.Must(template.New("").Parse(`
+<html>
+<body>
+<h2>User Task: {{.Type}}</h2>
+<table border="1">
+<tr>
+<th>When</th>
+<th>Elapsed</th>
+<th>Goroutine ID</th>
+<th>Events</th>
+</tr>
+{{range $t := .Tasks}}
+  <tr>
+    <td>{{.WhenString}}</td>
+    <td>{{.DurationString}}{{if not .Complete}} (incomplete){{end}}</td>
+    <td>
+      {{range .Goroutines}}<a href="/trace?goid={{.}}">{{.}}</a> {{end}}
+    </td>
+    <td>Task {{.ID}}{{if .Parent}} (parent: {{.Parent}}){{end}}</td>
+  </tr>
+  {{range .Events}}
+  <tr>
+    <td align="right">{{.WhenString}}</td>
+    <td align="right">{{.ElapsedString}}</td>
+    <td><a href="/trace?goid={{.Go}}">{{.Go}}</a></td>
+    <td>{{.What}}</td>
+  </tr>
+  {{end}}
+{{end}}
+</table>
+</body>
+</html>
+`))
+
+// regionStats summarizes the regions of one type.
+type regionStats struct {
+	regionTypeID
+	Histogram durationHistogram
+}
+
+func (s *regionStats) UserRegionURL() func(min, max time.Duration) string {
+	return func(min, max time.Duration) string {
+		return fmt.Sprintf("/userregion?type=%s&pc=%x&latmin=%v&latmax=%v", url.QueryEscape(s.Type), s.Frame.PC, min, max)
+	}
+}
+
+// httpUserRegions serves the list of region types with their duration distributions.
+func httpUserRegions(w http.ResponseWriter, r *http.Request) {
+	res, err := loadAnnotations()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var list []*regionStats
+	for id, regions := range res.regions {
+		s := &regionStats{regionTypeID: id}
+		for _, r := range regions {
+			s.Histogram.add(r.duration())
+		}
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Type != list[j].Type {
+			return list[i].Type < list[j].Type
+		}
+		return list[i].Frame.PC < list[j].Frame.PC
+	})
+	if err := templUserRegionTypes.Execute(w, list); err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+		return
+	}
+}
+
+var templUserRegionTypes = template.Must(template.New("").Parse(`
+<html>
+<body>
+<table border="1" sortable="1">
+<tr>
+<th>Region type</th>
+<th>Count</th>
+<th>Duration distribution</th>
+</tr>
+{{range $}}
+  <tr>
+    <td>{{.Type}}<br>{{.Frame.Fn}}<br>{{.Frame.File}}:{{.Frame.Line}}</td>
+    <td><a href="/userregion?type={{.Type}}&pc={{printf "%x" .Frame.PC}}">{{.Histogram.Count}}</a></td>
+    <td>{{.Histogram.ToHTML (.UserRegionURL)}}</td>
+  </tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
+
+// regionInstance is a region rendered by httpUserRegion.
+type regionInstance struct {
+	G              uint64
+	TaskID         uint64
+	WhenString     string
+	DurationString string
+	Incomplete     bool
+}
+
+// httpUserRegion serves the instances of a region type, optionally
+// filtered by duration.
+func httpUserRegion(w http.ResponseWriter, r *http.Request) {
+	res, err := loadAnnotations()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	filter, err := durationFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	typ := r.FormValue("type")
+	pc, err := strconv.ParseUint(r.FormValue("pc"), 16, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to parse pc parameter '%v': %v", r.FormValue("pc"), err), http.StatusBadRequest)
+		return
+	}
+
+	var regions []*regionDesc
+	for id, rs := range res.regions {
+		if id.Type != typ || id.Frame.PC != pc {
+			continue
+		}
+		for _, r := range rs {
+			if filter(r.duration()) {
+				regions = append(regions, r)
+			}
+		}
+	}
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i].firstTimestamp() < regions[j].firstTimestamp()
+	})
+
+	var list []regionInstance
+	for _, r := range regions {
+		list = append(list, regionInstance{
+			G:              r.G,
+			TaskID:         r.TaskID,
+			WhenString:     fmt.Sprintf("%.6fs", float64(r.firstTimestamp())/1e9),
+			DurationString: r.duration().String(),
+			Incomplete:     r.Start == nil || r.End == nil,
+		})
+	}
+	err = templUserRegion.Execute(w, struct {
+		Type    string
+		Regions []regionInstance
+	}{typ, list})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to execute template: %v", err), http.StatusInternalServerError)
+		return
+	}
+}
+
+var templUserRegion = template.Must(template.New("").Parse(`
+<html>
+<body>
+<h2>User Region: {{.Type}}</h2>
+<table border="1" sortable="1">
+<tr>
+<th>Goroutine</th>
+<th>Task</th>
+<th>Start</th>
+<th>Duration</th>
+</tr>
+{{range .Regions}}
+  <tr>
+    <td><a href="/trace?goid={{.G}}">{{.G}}</a></td>
+    <td>{{if .TaskID}}{{.TaskID}}{{end}}</td>
+    <td>{{.WhenString}}</td>
+    <td>{{.DurationString}}{{if .Incomplete}} (incomplete){{end}}</td>
+  </tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
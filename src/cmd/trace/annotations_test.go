@@ -0,0 +1,149 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	traceparser "internal/trace"
+	"reflect"
+	"runtime/trace"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// prog0 starts three goroutines.
+//
+//   goroutine 1: taskless region
+//   goroutine 2: starts task0, do work in task0.region0, starts task1 which ends immediately.
+//   goroutine 3: do work in task0.region1 and task0.region2, ends task0
+func prog0() {
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() { // goroutine 1
+		defer wg.Done()
+		trace.WithRegion(ctx, "taskless.region", func() {
+			trace.Log(ctx, "key0", "val0")
+		})
+	}()
+
+	wg.Add(1)
+	go func() { // goroutine 2
+		defer wg.Done()
+		ctx, task := trace.NewTask(ctx, "task0")
+		trace.WithRegion(ctx, "task0.region0", func() {
+			wg.Add(1)
+			go func() { // goroutine 3
+				defer wg.Done()
+				defer task.End()
+				trace.WithRegion(ctx, "task0.region1", func() {
+					trace.WithRegion(ctx, "task0.region2", func() {
+						trace.Log(ctx, "key2", "val2")
+					})
+					trace.Log(ctx, "key1", "val1")
+				})
+			}()
+		})
+		ctx2, task2 := trace.NewTask(ctx, "task1")
+		trace.Log(ctx2, "key3", "val3")
+		task2.End()
+	}()
+	wg.Wait()
+}
+
+func TestAnalyzeAnnotations(t *testing.T) {
+	events, err := traceProgram(t, prog0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := analyzeAnnotations(events)
+
+	// Check tasks.
+	tasks := make(map[string]*taskDesc)
+	for _, task := range res.tasks {
+		tasks[task.name] = task
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("got %d tasks, want 2: %v", len(tasks), tasks)
+	}
+	task0, task1 := tasks["task0"], tasks["task1"]
+	if task0 == nil || task1 == nil {
+		t.Fatalf("missing tasks: %v", tasks)
+	}
+	if !task0.complete() || !task1.complete() {
+		t.Errorf("tasks are not complete: task0=%v task1=%v", task0.complete(), task1.complete())
+	}
+	if task1.parent != task0 || !reflect.DeepEqual(task0.children, []*taskDesc{task1}) {
+		t.Errorf("task1 is not a subtask of task0")
+	}
+	if got := len(task0.goroutines); got != 2 {
+		t.Errorf("task0 has %d goroutines, want 2", got)
+	}
+	var names []string
+	for _, r := range task0.regions {
+		names = append(names, r.Name)
+	}
+	sort.Strings(names)
+	if want := []string{"task0.region0", "task0.region1", "task0.region2"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("task0 regions = %v, want %v", names, want)
+	}
+
+	// Check regions.
+	names = nil
+	for id, regions := range res.regions {
+		names = append(names, id.Type)
+		for _, r := range regions {
+			if r.Start == nil || r.End == nil {
+				t.Errorf("region %q is incomplete", r.Name)
+			}
+			if r.Start != nil && (len(r.Start.Stk) == 0 || id.Frame != *r.Start.Stk[0]) {
+				t.Errorf("region %q is keyed by frame %+v, want top frame of its start stack", r.Name, id.Frame)
+			}
+		}
+	}
+	sort.Strings(names)
+	if want := []string{"task0.region0", "task0.region1", "task0.region2", "taskless.region"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("region types = %v, want %v", names, want)
+	}
+}
+
+func TestDurationHistogram(t *testing.T) {
+	var h durationHistogram
+	for _, d := range []time.Duration{time.Microsecond, time.Microsecond, time.Millisecond} {
+		h.add(d)
+	}
+	if h.Count != 3 {
+		t.Errorf("Count = %d, want 3", h.Count)
+	}
+	lo, hi := h.Buckets[h.MinBucket], h.Buckets[h.MaxBucket]
+	if lo != 2 || hi != 1 {
+		t.Errorf("min bucket has %d entries, max bucket has %d entries; want 2 and 1", lo, hi)
+	}
+	if min := h.BucketMin(h.MinBucket); min > time.Microsecond || h.BucketMin(h.MinBucket+1) <= time.Microsecond {
+		t.Errorf("min bucket starts at %v, does not cover 1µs", min)
+	}
+}
+
+// traceProgram runs the given function while tracing is enabled
+// and returns the parsed trace events.
+func traceProgram(t *testing.T, f func()) ([]*traceparser.Event, error) {
+	buf := new(bytes.Buffer)
+	if err := trace.Start(buf); err != nil {
+		return nil, err
+	}
+	f()
+	trace.Stop()
+
+	events, err := traceparser.Parse(buf, "")
+	if err == traceparser.ErrTimeOrder {
+		t.Skipf("skipping due to golang.org/issue/16755 (timestamps are unreliable): %v", err)
+	}
+	return events, err
+}
@@ -27,6 +27,11 @@ Supported profile types are:
 Then, you can use the pprof tool to analyze the profile:
 	go tool pprof TYPE.pprof
 
+Tasks, regions and log messages recorded with the annotation API of
+package runtime/trace are summarized on the 'User-defined tasks' and
+'User-defined regions' pages, which show latency distributions per
+task type and duration distributions per region type.
+
 Note that while the various profiles available when launching
 'go tool trace' work on every browser, the trace viewer itself
 (the 'view trace' page) comes from the Chrome/Chromium project
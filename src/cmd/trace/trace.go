@@ -533,6 +533,8 @@ func generateTrace(params *traceParams) (ViewerData, error) {
 			ctx.emitInstant(ev, "syscall")
 		case trace.EvGoSysExit:
 			ctx.emitArrow(ev, "sysexit")
+		case trace.EvUserLog:
+			ctx.emitInstant(ev, describeEvent(ev))
 		}
 		// Emit any counter updates.
 		ctx.emitThreadCounters(ev)
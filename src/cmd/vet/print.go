@@ -411,6 +411,7 @@ const (
 	argFloat
 	argComplex
 	argPointer
+	argError
 	anyType printfArgType = ^0
 )
 
@@ -453,6 +454,7 @@ var printVerbs = []printVerb{
 	{'T', "-", anyType},
 	{'U', "-#", argRune | argInt},
 	{'v', allFlags, anyType},
+	{'w', allFlags, argError},
 	{'x', sharpNumFlag, argRune | argInt | argString},
 	{'X', sharpNumFlag, argRune | argInt | argString},
 }
@@ -483,6 +485,10 @@ func (f *File) okPrintfArg(call *ast.CallExpr, state *formatState) (ok bool) {
 		f.Badf(call.Pos(), "unrecognized printf verb %q", state.verb)
 		return false
 	}
+	if state.verb == 'w' && strings.ToLower(state.name) != "errorf" {
+		f.Badf(call.Pos(), "%s call has error-wrapping directive %%w", state.name)
+		return false
+	}
 	for _, flag := range state.flags {
 		if !strings.ContainsRune(v.flags, rune(flag)) {
 			f.Badf(call.Pos(), "unrecognized printf flag for verb %q: %q", state.verb, flag)
@@ -43,3 +43,4 @@ func wrapper(x int)
 
 func f15271() (x uint32)
 func f17584(x float32, y complex64)
+func noframe()
@@ -233,6 +233,12 @@ func PrintfTests() {
 	Errorf(1, "%d", 3)    // OK
 	Errorf(1, "%d", "hi") // ERROR "arg .hi. for printf verb %d of wrong type: string"
 
+	// Error wrapping with %w
+	err := fmt.Errorf("wrap: %w", io.EOF) // OK
+	_ = fmt.Errorf("wrap: %w", "hi")      // ERROR "arg .hi. for printf verb %w of wrong type: string"
+	fmt.Printf("wrap: %w", err)           // ERROR "Printf call has error-wrapping directive %w"
+	_ = fmt.Sprintf("wrap: %w", err)      // ERROR "Sprintf call has error-wrapping directive %w"
+
 	// Multiple string arguments before variadic args
 	errorf("WARNING", "foobar")            // OK
 	errorf("INFO", "s=%s, n=%d", "foo", 1) // OK
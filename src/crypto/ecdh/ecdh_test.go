@@ -0,0 +1,341 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecdh_test
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"testing"
+)
+
+var curves = []ecdh.Curve{ecdh.P256(), ecdh.P384(), ecdh.P521(), ecdh.X25519()}
+
+func ellipticCurve(c ecdh.Curve) elliptic.Curve {
+	switch c {
+	case ecdh.P256():
+		return elliptic.P256()
+	case ecdh.P384():
+		return elliptic.P384()
+	case ecdh.P521():
+		return elliptic.P521()
+	}
+	return nil
+}
+
+func TestECDH(t *testing.T) {
+	for _, curve := range curves {
+		t.Run(fmt.Sprint(curve), func(t *testing.T) {
+			aliceKey, err := curve.GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bobKey, err := curve.GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			alicePubKey, err := curve.NewPublicKey(aliceKey.PublicKey().Bytes())
+			if err != nil {
+				t.Error(err)
+			}
+			if !bytes.Equal(aliceKey.PublicKey().Bytes(), alicePubKey.Bytes()) {
+				t.Error("encoded and decoded public keys are different")
+			}
+			if !aliceKey.PublicKey().Equal(alicePubKey) {
+				t.Error("encoded and decoded public keys are different")
+			}
+
+			alicePrivKey, err := curve.NewPrivateKey(aliceKey.Bytes())
+			if err != nil {
+				t.Error(err)
+			}
+			if !bytes.Equal(aliceKey.Bytes(), alicePrivKey.Bytes()) {
+				t.Error("encoded and decoded private keys are different")
+			}
+			if !aliceKey.Equal(alicePrivKey) {
+				t.Error("encoded and decoded private keys are different")
+			}
+			if aliceKey.Equal(bobKey) {
+				t.Error("different private keys are equal")
+			}
+
+			bobSecret, err := bobKey.ECDH(aliceKey.PublicKey())
+			if err != nil {
+				t.Fatal(err)
+			}
+			aliceSecret, err := aliceKey.ECDH(bobKey.PublicKey())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(bobSecret, aliceSecret) {
+				t.Error("two ECDH computations came out different")
+			}
+		})
+	}
+}
+
+// TestNISTAgainstElliptic checks the results of this package against the
+// variable-time arithmetic of crypto/elliptic.
+func TestNISTAgainstElliptic(t *testing.T) {
+	for _, curve := range curves[:3] {
+		t.Run(fmt.Sprint(curve), func(t *testing.T) {
+			c := ellipticCurve(curve)
+			params := c.Params()
+			for i := 0; i < 8; i++ {
+				priv, x, y, err := elliptic.GenerateKey(c, rand.Reader)
+				if err != nil {
+					t.Fatal(err)
+				}
+				key, err := curve.NewPrivateKey(priv)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got, want := key.PublicKey().Bytes(), elliptic.Marshal(c, x, y); !bytes.Equal(got, want) {
+					t.Fatalf("public key mismatch:\ngot  %x\nwant %x", got, want)
+				}
+
+				_, peerX, peerY, err := elliptic.GenerateKey(c, rand.Reader)
+				if err != nil {
+					t.Fatal(err)
+				}
+				peer, err := curve.NewPublicKey(elliptic.Marshal(c, peerX, peerY))
+				if err != nil {
+					t.Fatal(err)
+				}
+				secret, err := key.ECDH(peer)
+				if err != nil {
+					t.Fatal(err)
+				}
+				sx, _ := params.ScalarMult(peerX, peerY, priv)
+				if want := scalarBytes(sx, (params.BitSize+7)/8); !bytes.Equal(secret, want) {
+					t.Fatalf("shared secret mismatch:\ngot  %x\nwant %x", secret, want)
+				}
+
+				// Also check scalars at the edges of the valid range.
+				low := scalarBytes(big.NewInt(int64(i+1)), len(priv))
+				high := scalarBytes(new(big.Int).Sub(params.N, big.NewInt(int64(i+1))), len(priv))
+				for _, scalar := range [][]byte{low, high} {
+					key, err := curve.NewPrivateKey(scalar)
+					if err != nil {
+						t.Fatal(err)
+					}
+					x, y := params.ScalarBaseMult(scalar)
+					if got, want := key.PublicKey().Bytes(), elliptic.Marshal(c, x, y); !bytes.Equal(got, want) {
+						t.Fatalf("public key mismatch for scalar %x:\ngot  %x\nwant %x", scalar, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func scalarBytes(n *big.Int, size int) []byte {
+	b := make([]byte, size)
+	nBytes := n.Bytes()
+	copy(b[size-len(nBytes):], nBytes)
+	return b
+}
+
+func hexDecode(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal("invalid hex string:", s)
+	}
+	return b
+}
+
+func TestX25519Vector(t *testing.T) {
+	// RFC 7748, Section 6.1.
+	alicePriv := hexDecode(t, "77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	alicePub := hexDecode(t, "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a")
+	bobPriv := hexDecode(t, "5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb")
+	bobPub := hexDecode(t, "de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f")
+	shared := hexDecode(t, "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742")
+
+	alice, err := ecdh.X25519().NewPrivateKey(alicePriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := alice.PublicKey().Bytes(); !bytes.Equal(got, alicePub) {
+		t.Errorf("public key mismatch: got %x, want %x", got, alicePub)
+	}
+	bob, err := ecdh.X25519().NewPublicKey(bobPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := alice.ECDH(bob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(secret, shared) {
+		t.Errorf("shared secret mismatch: got %x, want %x", secret, shared)
+	}
+
+	bobKey, err := ecdh.X25519().NewPrivateKey(bobPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bobKey.PublicKey().Bytes(); !bytes.Equal(got, bobPub) {
+		t.Errorf("public key mismatch: got %x, want %x", got, bobPub)
+	}
+}
+
+func TestX25519LowOrderPoint(t *testing.T) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zero, err := ecdh.X25519().NewPublicKey(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := key.ECDH(zero); err == nil {
+		t.Error("ECDH with a low order point succeeded")
+	}
+}
+
+func TestInvalidPublicKeys(t *testing.T) {
+	for _, curve := range curves[:3] {
+		t.Run(fmt.Sprint(curve), func(t *testing.T) {
+			c := ellipticCurve(curve)
+			params := c.Params()
+			byteLen := (params.BitSize + 7) / 8
+			key, err := curve.GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			valid := key.PublicKey().Bytes()
+
+			offCurve := append([]byte{}, valid...)
+			offCurve[len(offCurve)-1] ^= 1
+			compressed := append([]byte{2 + valid[len(valid)-1]&1}, valid[1:1+byteLen]...)
+			// (x, y + p) satisfies the curve equation modulo p.
+			x := new(big.Int).SetBytes(valid[1 : 1+byteLen])
+			y := new(big.Int).SetBytes(valid[1+byteLen:])
+			var unreduced []byte
+			if yPlusP := new(big.Int).Add(y, params.P); yPlusP.BitLen() <= 8*byteLen {
+				unreduced = append([]byte{4}, scalarBytes(x, byteLen)...)
+				unreduced = append(unreduced, scalarBytes(yPlusP, byteLen)...)
+			}
+
+			for name, input := range map[string][]byte{
+				"empty":      {},
+				"infinity":   {0},
+				"compressed": compressed,
+				"off-curve":  offCurve,
+				"truncated":  valid[:len(valid)-1],
+				"extended":   append(append([]byte{}, valid...), 0),
+				"unreduced":  unreduced,
+			} {
+				if input == nil {
+					continue
+				}
+				if _, err := curve.NewPublicKey(input); err == nil {
+					t.Errorf("%s: NewPublicKey succeeded", name)
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidPrivateKeys(t *testing.T) {
+	for _, curve := range curves[:3] {
+		t.Run(fmt.Sprint(curve), func(t *testing.T) {
+			n := ellipticCurve(curve).Params().N
+			size := (n.BitLen() + 7) / 8
+			for name, input := range map[string][]byte{
+				"zero":      make([]byte, size),
+				"order":     scalarBytes(n, size),
+				"order+1":   scalarBytes(new(big.Int).Add(n, big.NewInt(1)), size),
+				"all-ones":  bytes.Repeat([]byte{0xff}, size),
+				"truncated": make([]byte, size-1),
+			} {
+				if _, err := curve.NewPrivateKey(input); err == nil {
+					t.Errorf("%s: NewPrivateKey succeeded", name)
+				}
+			}
+		})
+	}
+	if _, err := ecdh.X25519().NewPrivateKey(make([]byte, 31)); err == nil {
+		t.Error("X25519: NewPrivateKey succeeded for a short key")
+	}
+}
+
+func TestMismatchedCurves(t *testing.T) {
+	p256Key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x25519Key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p256Key.ECDH(x25519Key.PublicKey()); err == nil {
+		t.Error("ECDH between different curves succeeded")
+	}
+	if p256Key.PublicKey().Equal(x25519Key.PublicKey()) {
+		t.Error("public keys on different curves are equal")
+	}
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(b []byte) (int, error) {
+	for i := range b {
+		b[i] = 0
+	}
+	return len(b), nil
+}
+
+// TestGenerateKeyMatchesElliptic checks that GenerateKey consumes randomness
+// like crypto/elliptic.GenerateKey, which existing deterministic tests rely on.
+func TestGenerateKeyMatchesElliptic(t *testing.T) {
+	for _, curve := range curves[:3] {
+		for _, r := range []func() io.Reader{
+			func() io.Reader { return zeroReader{} },
+			func() io.Reader { return bytes.NewReader(bytes.Repeat([]byte{0xff, 0x01, 0x37}, 100)) },
+		} {
+			key, err := curve.GenerateKey(r())
+			if err != nil {
+				t.Fatal(err)
+			}
+			priv, _, _, err := elliptic.GenerateKey(ellipticCurve(curve), r())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(key.Bytes(), priv) {
+				t.Errorf("%v: got key %x, want %x", curve, key.Bytes(), priv)
+			}
+		}
+	}
+}
+
+func BenchmarkECDH(b *testing.B) {
+	for _, curve := range curves {
+		b.Run(fmt.Sprint(curve), func(b *testing.B) {
+			key, err := curve.GenerateKey(rand.Reader)
+			if err != nil {
+				b.Fatal(err)
+			}
+			peer, err := curve.GenerateKey(rand.Reader)
+			if err != nil {
+				b.Fatal(err)
+			}
+			peerPub := peer.PublicKey()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := key.ECDH(peerPub); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
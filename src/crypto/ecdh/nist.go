@@ -0,0 +1,253 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecdh
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+)
+
+// nistArith is the point arithmetic behind a nistCurve. Points are passed
+// around in their uncompressed SEC 1 encoding and scalars as fixed length
+// big-endian integers in [1, n-1].
+type nistArith interface {
+	// scalarBaseMult returns scalar × G.
+	scalarBaseMult(scalar []byte) ([]byte, error)
+	// scalarMult returns scalar × point.
+	scalarMult(point, scalar []byte) ([]byte, error)
+	// checkPoint returns an error if point is not the encoding of a point
+	// on the curve.
+	checkPoint(point []byte) error
+}
+
+type nistCurve struct {
+	name        string
+	arith       nistArith
+	scalarOrder []byte // the order of the base point, big-endian
+	bitMask     byte   // mask for the excess bits of the first scalar byte
+	byteLen     int    // the length of a field element
+}
+
+func (c *nistCurve) String() string {
+	return c.name
+}
+
+var errInvalidPrivateKey = errors.New("crypto/ecdh: invalid private key")
+
+// GenerateKey samples a scalar in the same way as crypto/elliptic.GenerateKey,
+// so that both produce the same key from the same random stream.
+func (c *nistCurve) GenerateKey(rand io.Reader) (*PrivateKey, error) {
+	key := make([]byte, len(c.scalarOrder))
+	for {
+		if _, err := io.ReadFull(rand, key); err != nil {
+			return nil, err
+		}
+
+		// We have to mask off any excess bits in the case that the size of the
+		// underlying field is not a whole number of bytes, which makes this
+		// function consistent with crypto/elliptic.GenerateKey.
+		key[0] &= c.bitMask
+		// In tests, rand will return all zeros and NewPrivateKey will reject
+		// the zero key as it generates the identity as a public key. This also
+		// makes this function consistent with crypto/elliptic.GenerateKey.
+		key[1] ^= 0x42
+
+		k, err := c.NewPrivateKey(key)
+		if err == errInvalidPrivateKey {
+			continue
+		}
+		return k, err
+	}
+}
+
+func (c *nistCurve) NewPrivateKey(key []byte) (*PrivateKey, error) {
+	if len(key) != len(c.scalarOrder) {
+		return nil, errors.New("crypto/ecdh: invalid private key size")
+	}
+	if isZero(key) || !isLess(key, c.scalarOrder) {
+		return nil, errInvalidPrivateKey
+	}
+	return &PrivateKey{
+		curve:      c,
+		privateKey: append([]byte{}, key...),
+	}, nil
+}
+
+func (c *nistCurve) privateKeyToPublicKey(key *PrivateKey) *PublicKey {
+	if key.curve != c {
+		panic("crypto/ecdh: internal error: converting the wrong key type")
+	}
+	publicKey, err := c.arith.scalarBaseMult(key.privateKey)
+	if err != nil {
+		// This can't happen, as NewPrivateKey rejects scalars that are zero
+		// or not lower than the order of the base point.
+		panic("crypto/ecdh: internal error: nist ec ScalarBaseMult failed for a fixed-size input")
+	}
+	return &PublicKey{
+		curve:     key.curve,
+		publicKey: publicKey,
+	}
+}
+
+// isZero returns whether a is all zeroes in constant time.
+func isZero(a []byte) bool {
+	var acc byte
+	for _, b := range a {
+		acc |= b
+	}
+	return acc == 0
+}
+
+// isLess returns whether a < b, where a and b are big-endian buffers of the
+// same length, in constant time.
+func isLess(a, b []byte) bool {
+	if len(a) != len(b) {
+		panic("crypto/ecdh: internal error: mismatched isLess inputs")
+	}
+
+	// Subtract b from a byte by byte, starting from the least significant
+	// end, and return whether the final result borrowed.
+	var borrow uint32
+	for i := len(a) - 1; i >= 0; i-- {
+		d := uint32(a[i]) - uint32(b[i]) - borrow
+		borrow = (d >> 8) & 1
+	}
+	return borrow == 1
+}
+
+func (c *nistCurve) NewPublicKey(key []byte) (*PublicKey, error) {
+	// Reject the point at infinity and compressed encodings.
+	if len(key) == 0 || key[0] != 4 {
+		return nil, errors.New("crypto/ecdh: invalid public key")
+	}
+	if err := c.arith.checkPoint(key); err != nil {
+		return nil, err
+	}
+	return &PublicKey{
+		curve:     c,
+		publicKey: append([]byte{}, key...),
+	}, nil
+}
+
+func (c *nistCurve) ecdh(local *PrivateKey, remote *PublicKey) ([]byte, error) {
+	// In practice this can't fail: NewPublicKey rejects invalid points and
+	// the point at infinity, and NewPrivateKey rejects invalid scalars and
+	// the zero value. In a prime order group such as the NIST curves, the
+	// result can only be the point at infinity if one of the inputs is.
+	p, err := c.arith.scalarMult(remote.publicKey, local.privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return p[1 : 1+c.byteLen], nil
+}
+
+// p256Arith implements nistArith with the constant-time P-256
+// implementation of crypto/elliptic.
+type p256Arith struct {
+	curve elliptic.Curve
+}
+
+func (a p256Arith) scalarBaseMult(scalar []byte) ([]byte, error) {
+	x, y := a.curve.ScalarBaseMult(scalar)
+	return a.marshal(x, y)
+}
+
+func (a p256Arith) scalarMult(point, scalar []byte) ([]byte, error) {
+	if err := a.checkPoint(point); err != nil {
+		return nil, err
+	}
+	x, y := elliptic.Unmarshal(a.curve, point)
+	x, y = a.curve.ScalarMult(x, y, scalar)
+	return a.marshal(x, y)
+}
+
+func (a p256Arith) marshal(x, y *big.Int) ([]byte, error) {
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, errors.New("crypto/ecdh: point is the point at infinity")
+	}
+	return elliptic.Marshal(a.curve, x, y), nil
+}
+
+func (a p256Arith) checkPoint(point []byte) error {
+	params := a.curve.Params()
+	byteLen := (params.BitSize + 7) / 8
+	if len(point) != 1+2*byteLen || point[0] != 4 {
+		return errors.New("crypto/ecdh: invalid point encoding")
+	}
+	// elliptic.Unmarshal doesn't check that the coordinates are reduced.
+	x := new(big.Int).SetBytes(point[1 : 1+byteLen])
+	y := new(big.Int).SetBytes(point[1+byteLen:])
+	if x.Cmp(params.P) >= 0 || y.Cmp(params.P) >= 0 {
+		return errors.New("crypto/ecdh: invalid field element")
+	}
+	if !a.curve.IsOnCurve(x, y) {
+		return errors.New("crypto/ecdh: invalid point: not on the curve")
+	}
+	return nil
+}
+
+var initonce sync.Once
+
+var (
+	p256 = &nistCurve{name: "P-256"}
+	p384 = &nistCurve{name: "P-384"}
+	p521 = &nistCurve{name: "P-521"}
+)
+
+func initAll() {
+	initNISTCurve(p256, elliptic.P256(), p256Arith{elliptic.P256()})
+	initNISTCurve(p384, elliptic.P384(), newWeierstrassCurve(elliptic.P384().Params()))
+	initNISTCurve(p521, elliptic.P521(), newWeierstrassCurve(elliptic.P521().Params()))
+}
+
+func initNISTCurve(c *nistCurve, curve elliptic.Curve, arith nistArith) {
+	params := curve.Params()
+	c.arith = arith
+	c.byteLen = (params.BitSize + 7) / 8
+	bitSize := params.N.BitLen()
+	c.scalarOrder = make([]byte, (bitSize+7)/8)
+	n := params.N.Bytes()
+	copy(c.scalarOrder[len(c.scalarOrder)-len(n):], n)
+	c.bitMask = 0xff
+	if bitSize%8 != 0 {
+		c.bitMask = byte(1)<<uint(bitSize%8) - 1
+	}
+}
+
+// P256 returns a Curve which implements NIST P-256 (FIPS 186-3, section D.2.3),
+// also known as secp256r1 or prime256v1.
+//
+// The scalar multiplications are performed by the constant-time P-256
+// implementation of crypto/elliptic.
+//
+// Multiple invocations of this function will return the same value, which can
+// be used for equality checks and switch statements.
+func P256() Curve {
+	initonce.Do(initAll)
+	return p256
+}
+
+// P384 returns a Curve which implements NIST P-384 (FIPS 186-3, section D.2.4),
+// also known as secp384r1.
+//
+// Multiple invocations of this function will return the same value, which can
+// be used for equality checks and switch statements.
+func P384() Curve {
+	initonce.Do(initAll)
+	return p384
+}
+
+// P521 returns a Curve which implements NIST P-521 (FIPS 186-3, section D.2.5),
+// also known as secp521r1.
+//
+// Multiple invocations of this function will return the same value, which can
+// be used for equality checks and switch statements.
+func P521() Curve {
+	initonce.Do(initAll)
+	return p521
+}
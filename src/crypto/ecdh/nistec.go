@@ -0,0 +1,478 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecdh
+
+// This file contains a constant-time, 32-bit implementation of the short
+// Weierstrass curves y² = x³ - 3x + b used by P-384 and P-521.
+//
+// Field elements are kept in the Montgomery domain as little-endian 32-bit
+// limbs and are always fully reduced. Points use projective coordinates and
+// the complete addition formulas of Renes, Costello and Batina, "Complete
+// addition formulas for prime order elliptic curves",
+// https://eprint.iacr.org/2015/1060, so no operation needs to branch on the
+// identity or on equal inputs. Scalar multiplication uses a fixed 4-bit
+// window with a masked table lookup.
+
+import (
+	"crypto/elliptic"
+	"crypto/subtle"
+	"errors"
+	"math/big"
+)
+
+// maxLimbs is the number of 32-bit limbs needed for the largest supported
+// field, that of P-521.
+const maxLimbs = (521 + 31) / 32
+
+// fieldElement is an element of a nistField. Only the first len(f.p) limbs
+// are used.
+type fieldElement [maxLimbs]uint32
+
+// nistField implements arithmetic modulo an odd prime p using Montgomery
+// multiplication with R = 2^(32·len(p)).
+type nistField struct {
+	p       []uint32 // the modulus, as little-endian limbs
+	pInv    uint32   // -p⁻¹ mod 2³²
+	one     fieldElement
+	rr      fieldElement // R² mod p, used to enter the Montgomery domain
+	pMinus2 []byte       // the inversion exponent, big-endian
+	byteLen int
+}
+
+func newNISTField(p *big.Int) *nistField {
+	n := (p.BitLen() + 31) / 32
+	f := &nistField{
+		p:       make([]uint32, n),
+		byteLen: (p.BitLen() + 7) / 8,
+	}
+	var pLimbs fieldElement
+	limbsFromBig(&pLimbs, p, n)
+	copy(f.p, pLimbs[:n])
+
+	// Newton's iteration doubles the number of correct low bits of the
+	// inverse at each step, starting from one bit.
+	inv := uint32(1)
+	for i := 0; i < 5; i++ {
+		inv *= 2 - f.p[0]*inv
+	}
+	f.pInv = -inv
+
+	r := new(big.Int).Lsh(big.NewInt(1), uint(32*n))
+	limbsFromBig(&f.one, new(big.Int).Mod(r, p), n)
+	limbsFromBig(&f.rr, new(big.Int).Mod(new(big.Int).Mul(r, r), p), n)
+	f.pMinus2 = new(big.Int).Sub(p, big.NewInt(2)).Bytes()
+	return f
+}
+
+// limbsFromBig sets out to the n least significant limbs of x.
+func limbsFromBig(out *fieldElement, x *big.Int, n int) {
+	buf := make([]byte, 4*n)
+	xBytes := x.Bytes()
+	copy(buf[len(buf)-len(xBytes):], xBytes)
+	limbsFromBytes(out, buf)
+}
+
+// limbsFromBytes sets out to the big-endian value in buf, whose length must
+// be a multiple of four.
+func limbsFromBytes(out *fieldElement, buf []byte) {
+	*out = fieldElement{}
+	for i := 0; i < len(buf)/4; i++ {
+		j := len(buf) - 4*(i+1)
+		out[i] = uint32(buf[j])<<24 | uint32(buf[j+1])<<16 | uint32(buf[j+2])<<8 | uint32(buf[j+3])
+	}
+}
+
+// set decodes a big-endian field element in the Montgomery domain. It
+// returns an error if buf is not the canonical encoding of an element. It
+// doesn't run in constant time and must only be used on public values.
+func (f *nistField) set(out *fieldElement, buf []byte) error {
+	if len(buf) != f.byteLen {
+		return errors.New("crypto/ecdh: invalid field element length")
+	}
+	padded := make([]byte, 4*len(f.p))
+	copy(padded[len(padded)-len(buf):], buf)
+	var x fieldElement
+	limbsFromBytes(&x, padded)
+	for i := len(f.p) - 1; i >= 0; i-- {
+		if x[i] < f.p[i] {
+			break
+		}
+		if x[i] > f.p[i] || i == 0 {
+			return errors.New("crypto/ecdh: invalid field element")
+		}
+	}
+	f.mul(out, &x, &f.rr)
+	return nil
+}
+
+// bytes returns the big-endian encoding of x, which is in the Montgomery
+// domain.
+func (f *nistField) bytes(x *fieldElement) []byte {
+	var one, t fieldElement
+	one[0] = 1
+	f.mul(&t, x, &one)
+	padded := make([]byte, 4*len(f.p))
+	for i := 0; i < len(f.p); i++ {
+		j := len(padded) - 4*(i+1)
+		padded[j] = byte(t[i] >> 24)
+		padded[j+1] = byte(t[i] >> 16)
+		padded[j+2] = byte(t[i] >> 8)
+		padded[j+3] = byte(t[i])
+	}
+	return padded[len(padded)-f.byteLen:]
+}
+
+// selectElement sets out to a if cond is 1 and to b if cond is 0.
+func (f *nistField) selectElement(out, a, b *fieldElement, cond uint32) {
+	mask := -cond
+	for i := range f.p {
+		out[i] = a[i]&mask | b[i]&^mask
+	}
+}
+
+// isZero returns 1 if x is zero and 0 otherwise.
+func (f *nistField) isZero(x *fieldElement) uint32 {
+	var acc uint32
+	for i := range f.p {
+		acc |= x[i]
+	}
+	return ((acc | -acc) >> 31) ^ 1
+}
+
+// add sets out = a + b.
+func (f *nistField) add(out, a, b *fieldElement) {
+	var t, d fieldElement
+	var carry, borrow uint32
+	for i := range f.p {
+		s := uint64(a[i]) + uint64(b[i]) + uint64(carry)
+		t[i] = uint32(s)
+		carry = uint32(s >> 32)
+	}
+	for i := range f.p {
+		s := uint64(t[i]) - uint64(f.p[i]) - uint64(borrow)
+		d[i] = uint32(s)
+		borrow = uint32(s>>32) & 1
+	}
+	// The sum is at least p if it overflowed the limbs or if subtracting p
+	// didn't borrow.
+	f.selectElement(out, &d, &t, carry|(borrow^1))
+}
+
+// sub sets out = a - b.
+func (f *nistField) sub(out, a, b *fieldElement) {
+	var t fieldElement
+	var borrow uint32
+	for i := range f.p {
+		s := uint64(a[i]) - uint64(b[i]) - uint64(borrow)
+		t[i] = uint32(s)
+		borrow = uint32(s>>32) & 1
+	}
+	// Add p back if the subtraction borrowed.
+	mask := -borrow
+	var carry uint32
+	for i := range f.p {
+		s := uint64(t[i]) + uint64(f.p[i]&mask) + uint64(carry)
+		out[i] = uint32(s)
+		carry = uint32(s >> 32)
+	}
+}
+
+// mul sets out = a × b × R⁻¹, using the Coarsely Integrated Operand Scanning
+// method.
+func (f *nistField) mul(out, a, b *fieldElement) {
+	n := len(f.p)
+	var t [maxLimbs + 2]uint32
+	for i := 0; i < n; i++ {
+		var c uint64
+		for j := 0; j < n; j++ {
+			s := uint64(t[j]) + uint64(a[j])*uint64(b[i]) + c
+			t[j] = uint32(s)
+			c = s >> 32
+		}
+		s := uint64(t[n]) + c
+		t[n] = uint32(s)
+		t[n+1] = uint32(s >> 32)
+
+		m := t[0] * f.pInv
+		s = uint64(t[0]) + uint64(m)*uint64(f.p[0])
+		c = s >> 32
+		for j := 1; j < n; j++ {
+			s = uint64(t[j]) + uint64(m)*uint64(f.p[j]) + c
+			t[j-1] = uint32(s)
+			c = s >> 32
+		}
+		s = uint64(t[n]) + c
+		t[n-1] = uint32(s)
+		t[n] = t[n+1] + uint32(s>>32)
+	}
+
+	// The result is less than 2p; subtract p if it's at least p.
+	var r, d fieldElement
+	copy(r[:n], t[:n])
+	var borrow uint32
+	for i := 0; i < n; i++ {
+		s := uint64(r[i]) - uint64(f.p[i]) - uint64(borrow)
+		d[i] = uint32(s)
+		borrow = uint32(s>>32) & 1
+	}
+	f.selectElement(out, &d, &r, t[n]|(borrow^1))
+}
+
+// square sets out = a² × R⁻¹.
+func (f *nistField) square(out, a *fieldElement) {
+	f.mul(out, a, a)
+}
+
+// invert sets out = a⁻¹, computed as a^(p-2). The exponent is public, so
+// branching on its bits doesn't leak anything about a. The inverse of zero
+// is zero.
+func (f *nistField) invert(out, a *fieldElement) {
+	z := f.one
+	for _, b := range f.pMinus2 {
+		for bit := 7; bit >= 0; bit-- {
+			f.square(&z, &z)
+			if (b>>uint(bit))&1 == 1 {
+				f.mul(&z, &z, a)
+			}
+		}
+	}
+	*out = z
+}
+
+// nistPoint is a point in projective coordinates (X:Y:Z), representing the
+// affine point (X/Z, Y/Z). The identity is (0:1:0).
+type nistPoint struct {
+	x, y, z fieldElement
+}
+
+// weierstrassCurve implements nistArith for a prime order curve with a = -3.
+type weierstrassCurve struct {
+	f       *nistField
+	b       fieldElement
+	gen     nistPoint
+	byteLen int
+}
+
+func newWeierstrassCurve(params *elliptic.CurveParams) *weierstrassCurve {
+	f := newNISTField(params.P)
+	c := &weierstrassCurve{f: f, byteLen: f.byteLen}
+	n := len(f.p)
+	var t fieldElement
+	limbsFromBig(&t, params.B, n)
+	f.mul(&c.b, &t, &f.rr)
+	limbsFromBig(&t, params.Gx, n)
+	f.mul(&c.gen.x, &t, &f.rr)
+	limbsFromBig(&t, params.Gy, n)
+	f.mul(&c.gen.y, &t, &f.rr)
+	c.gen.z = f.one
+	return c
+}
+
+func (c *weierstrassCurve) identity() nistPoint {
+	return nistPoint{y: c.f.one}
+}
+
+// setBytes decodes an uncompressed point and checks that it is on the curve.
+func (c *weierstrassCurve) setBytes(p *nistPoint, buf []byte) error {
+	if len(buf) != 1+2*c.byteLen || buf[0] != 4 {
+		return errors.New("crypto/ecdh: invalid point encoding")
+	}
+	f := c.f
+	var x, y fieldElement
+	if err := f.set(&x, buf[1:1+c.byteLen]); err != nil {
+		return err
+	}
+	if err := f.set(&y, buf[1+c.byteLen:]); err != nil {
+		return err
+	}
+
+	// y² = x³ - 3x + b
+	var lhs, rhs, threeX fieldElement
+	f.square(&lhs, &y)
+	f.square(&rhs, &x)
+	f.mul(&rhs, &rhs, &x)
+	f.add(&threeX, &x, &x)
+	f.add(&threeX, &threeX, &x)
+	f.sub(&rhs, &rhs, &threeX)
+	f.add(&rhs, &rhs, &c.b)
+	f.sub(&lhs, &lhs, &rhs)
+	if f.isZero(&lhs) != 1 {
+		return errors.New("crypto/ecdh: invalid point: not on the curve")
+	}
+
+	p.x, p.y, p.z = x, y, f.one
+	return nil
+}
+
+// bytes returns the uncompressed encoding of p, or an error if p is the
+// identity.
+func (c *weierstrassCurve) bytes(p *nistPoint) ([]byte, error) {
+	f := c.f
+	if f.isZero(&p.z) == 1 {
+		return nil, errors.New("crypto/ecdh: point is the point at infinity")
+	}
+	var zinv, x, y fieldElement
+	f.invert(&zinv, &p.z)
+	f.mul(&x, &p.x, &zinv)
+	f.mul(&y, &p.y, &zinv)
+	out := make([]byte, 1, 1+2*c.byteLen)
+	out[0] = 4
+	out = append(out, f.bytes(&x)...)
+	return append(out, f.bytes(&y)...), nil
+}
+
+// add sets q = p1 + p2. It uses Algorithm 4 of the paper, which is complete
+// and works for any pair of inputs, including equal ones and the identity.
+func (c *weierstrassCurve) add(q, p1, p2 *nistPoint) {
+	f := c.f
+	var t0, t1, t2, t3, t4, x3, y3, z3 fieldElement
+	f.mul(&t0, &p1.x, &p2.x) // t0 := X1 * X2
+	f.mul(&t1, &p1.y, &p2.y) // t1 := Y1 * Y2
+	f.mul(&t2, &p1.z, &p2.z) // t2 := Z1 * Z2
+	f.add(&t3, &p1.x, &p1.y) // t3 := X1 + Y1
+	f.add(&t4, &p2.x, &p2.y) // t4 := X2 + Y2
+	f.mul(&t3, &t3, &t4)     // t3 := t3 * t4
+	f.add(&t4, &t0, &t1)     // t4 := t0 + t1
+	f.sub(&t3, &t3, &t4)     // t3 := t3 - t4
+	f.add(&t4, &p1.y, &p1.z) // t4 := Y1 + Z1
+	f.add(&x3, &p2.y, &p2.z) // X3 := Y2 + Z2
+	f.mul(&t4, &t4, &x3)     // t4 := t4 * X3
+	f.add(&x3, &t1, &t2)     // X3 := t1 + t2
+	f.sub(&t4, &t4, &x3)     // t4 := t4 - X3
+	f.add(&x3, &p1.x, &p1.z) // X3 := X1 + Z1
+	f.add(&y3, &p2.x, &p2.z) // Y3 := X2 + Z2
+	f.mul(&x3, &x3, &y3)     // X3 := X3 * Y3
+	f.add(&y3, &t0, &t2)     // Y3 := t0 + t2
+	f.sub(&y3, &x3, &y3)     // Y3 := X3 - Y3
+	f.mul(&z3, &c.b, &t2)    // Z3 := b * t2
+	f.sub(&x3, &y3, &z3)     // X3 := Y3 - Z3
+	f.add(&z3, &x3, &x3)     // Z3 := X3 + X3
+	f.add(&x3, &x3, &z3)     // X3 := X3 + Z3
+	f.sub(&z3, &t1, &x3)     // Z3 := t1 - X3
+	f.add(&x3, &t1, &x3)     // X3 := t1 + X3
+	f.mul(&y3, &c.b, &y3)    // Y3 := b * Y3
+	f.add(&t1, &t2, &t2)     // t1 := t2 + t2
+	f.add(&t2, &t1, &t2)     // t2 := t1 + t2
+	f.sub(&y3, &y3, &t2)     // Y3 := Y3 - t2
+	f.sub(&y3, &y3, &t0)     // Y3 := Y3 - t0
+	f.add(&t1, &y3, &y3)     // t1 := Y3 + Y3
+	f.add(&y3, &t1, &y3)     // Y3 := t1 + Y3
+	f.add(&t1, &t0, &t0)     // t1 := t0 + t0
+	f.add(&t0, &t1, &t0)     // t0 := t1 + t0
+	f.sub(&t0, &t0, &t2)     // t0 := t0 - t2
+	f.mul(&t1, &t4, &y3)     // t1 := t4 * Y3
+	f.mul(&t2, &t0, &y3)     // t2 := t0 * Y3
+	f.mul(&y3, &x3, &z3)     // Y3 := X3 * Z3
+	f.add(&y3, &y3, &t2)     // Y3 := Y3 + t2
+	f.mul(&x3, &t3, &x3)     // X3 := t3 * X3
+	f.sub(&x3, &x3, &t1)     // X3 := X3 - t1
+	f.mul(&z3, &t4, &z3)     // Z3 := t4 * Z3
+	f.mul(&t1, &t3, &t0)     // t1 := t3 * t0
+	f.add(&z3, &z3, &t1)     // Z3 := Z3 + t1
+	q.x, q.y, q.z = x3, y3, z3
+}
+
+// double sets q = p + p, using Algorithm 6 of the paper.
+func (c *weierstrassCurve) double(q, p *nistPoint) {
+	f := c.f
+	var t0, t1, t2, t3, x3, y3, z3 fieldElement
+	f.square(&t0, &p.x)    // t0 := X ^ 2
+	f.square(&t1, &p.y)    // t1 := Y ^ 2
+	f.square(&t2, &p.z)    // t2 := Z ^ 2
+	f.mul(&t3, &p.x, &p.y) // t3 := X * Y
+	f.add(&t3, &t3, &t3)   // t3 := t3 + t3
+	f.mul(&z3, &p.x, &p.z) // Z3 := X * Z
+	f.add(&z3, &z3, &z3)   // Z3 := Z3 + Z3
+	f.mul(&y3, &c.b, &t2)  // Y3 := b * t2
+	f.sub(&y3, &y3, &z3)   // Y3 := Y3 - Z3
+	f.add(&x3, &y3, &y3)   // X3 := Y3 + Y3
+	f.add(&y3, &x3, &y3)   // Y3 := X3 + Y3
+	f.sub(&x3, &t1, &y3)   // X3 := t1 - Y3
+	f.add(&y3, &t1, &y3)   // Y3 := t1 + Y3
+	f.mul(&y3, &x3, &y3)   // Y3 := X3 * Y3
+	f.mul(&x3, &x3, &t3)   // X3 := X3 * t3
+	f.add(&t3, &t2, &t2)   // t3 := t2 + t2
+	f.add(&t2, &t2, &t3)   // t2 := t2 + t3
+	f.mul(&z3, &c.b, &z3)  // Z3 := b * Z3
+	f.sub(&z3, &z3, &t2)   // Z3 := Z3 - t2
+	f.sub(&z3, &z3, &t0)   // Z3 := Z3 - t0
+	f.add(&t3, &z3, &z3)   // t3 := Z3 + Z3
+	f.add(&z3, &z3, &t3)   // Z3 := Z3 + t3
+	f.add(&t3, &t0, &t0)   // t3 := t0 + t0
+	f.add(&t0, &t3, &t0)   // t0 := t3 + t0
+	f.sub(&t0, &t0, &t2)   // t0 := t0 - t2
+	f.mul(&t0, &t0, &z3)   // t0 := t0 * Z3
+	f.add(&y3, &y3, &t0)   // Y3 := Y3 + t0
+	f.mul(&t0, &p.y, &p.z) // t0 := Y * Z
+	f.add(&t0, &t0, &t0)   // t0 := t0 + t0
+	f.mul(&z3, &t0, &z3)   // Z3 := t0 * Z3
+	f.sub(&x3, &x3, &z3)   // X3 := X3 - Z3
+	f.mul(&z3, &t0, &t1)   // Z3 := t0 * t1
+	f.add(&z3, &z3, &z3)   // Z3 := Z3 + Z3
+	f.add(&z3, &z3, &z3)   // Z3 := Z3 + Z3
+	q.x, q.y, q.z = x3, y3, z3
+}
+
+// selectPoint sets q to table[n] in constant time.
+func (c *weierstrassCurve) selectPoint(q *nistPoint, table *[16]nistPoint, n uint8) {
+	*q = nistPoint{}
+	for i := range table {
+		cond := uint32(subtle.ConstantTimeByteEq(uint8(i), n))
+		c.f.selectElement(&q.x, &table[i].x, &q.x, cond)
+		c.f.selectElement(&q.y, &table[i].y, &q.y, cond)
+		c.f.selectElement(&q.z, &table[i].z, &q.z, cond)
+	}
+}
+
+// scalarMultPoint sets q = scalar × p, where scalar is big-endian.
+func (c *weierstrassCurve) scalarMultPoint(q, p *nistPoint, scalar []byte) {
+	var table [16]nistPoint
+	table[0] = c.identity()
+	table[1] = *p
+	for i := 2; i < 16; i += 2 {
+		c.double(&table[i], &table[i/2])
+		c.add(&table[i+1], &table[i], p)
+	}
+
+	r := c.identity()
+	var t nistPoint
+	for i, b := range scalar {
+		// The first four doublings would only double the identity.
+		if i != 0 {
+			for j := 0; j < 4; j++ {
+				c.double(&r, &r)
+			}
+		}
+		c.selectPoint(&t, &table, b>>4)
+		c.add(&r, &r, &t)
+
+		for j := 0; j < 4; j++ {
+			c.double(&r, &r)
+		}
+		c.selectPoint(&t, &table, b&0xf)
+		c.add(&r, &r, &t)
+	}
+	*q = r
+}
+
+func (c *weierstrassCurve) scalarBaseMult(scalar []byte) ([]byte, error) {
+	var q nistPoint
+	c.scalarMultPoint(&q, &c.gen, scalar)
+	return c.bytes(&q)
+}
+
+func (c *weierstrassCurve) scalarMult(point, scalar []byte) ([]byte, error) {
+	var p, q nistPoint
+	if err := c.setBytes(&p, point); err != nil {
+		return nil, err
+	}
+	c.scalarMultPoint(&q, &p, scalar)
+	return c.bytes(&q)
+}
+
+func (c *weierstrassCurve) checkPoint(point []byte) error {
+	var p nistPoint
+	return c.setBytes(&p, point)
+}
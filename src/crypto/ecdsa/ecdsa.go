@@ -20,6 +20,7 @@ import (
 	"crypto"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/ecdh"
 	"crypto/elliptic"
 	"crypto/sha512"
 	"encoding/asn1"
@@ -64,6 +65,88 @@ func (priv *PrivateKey) Public() crypto.PublicKey {
 	return &priv.PublicKey
 }
 
+// ECDH returns k as a ecdh.PublicKey. It returns an error if the key is
+// invalid according to the definition of ecdh.Curve.NewPublicKey, or if the
+// Curve is not supported by crypto/ecdh.
+func (k *PublicKey) ECDH() (*ecdh.PublicKey, error) {
+	c := curveToECDH(k.Curve)
+	if c == nil {
+		return nil, errors.New("ecdsa: unsupported curve by crypto/ecdh")
+	}
+	if !k.Curve.IsOnCurve(k.X, k.Y) {
+		return nil, errors.New("ecdsa: invalid public key")
+	}
+	return c.NewPublicKey(elliptic.Marshal(k.Curve, k.X, k.Y))
+}
+
+// ECDH returns k as a ecdh.PrivateKey. It returns an error if the key is
+// invalid according to the definition of ecdh.Curve.NewPrivateKey, or if the
+// Curve is not supported by crypto/ecdh.
+func (k *PrivateKey) ECDH() (*ecdh.PrivateKey, error) {
+	c := curveToECDH(k.Curve)
+	if c == nil {
+		return nil, errors.New("ecdsa: unsupported curve by crypto/ecdh")
+	}
+	size := (k.Curve.Params().N.BitLen() + 7) / 8
+	if k.D.Sign() < 0 || k.D.BitLen() > size*8 {
+		return nil, errors.New("ecdsa: invalid private key")
+	}
+	d := k.D.Bytes()
+	key := make([]byte, size)
+	copy(key[size-len(d):], d)
+	return c.NewPrivateKey(key)
+}
+
+// PublicKeyFromECDH returns k as a PublicKey. It returns an error if k is
+// not on one of the NIST curves, such as an X25519 key.
+func PublicKeyFromECDH(k *ecdh.PublicKey) (*PublicKey, error) {
+	c := curveFromECDH(k.Curve())
+	if c == nil {
+		return nil, errors.New("ecdsa: unsupported curve")
+	}
+	x, y := elliptic.Unmarshal(c, k.Bytes())
+	if x == nil {
+		return nil, errors.New("ecdsa: invalid public key")
+	}
+	return &PublicKey{Curve: c, X: x, Y: y}, nil
+}
+
+// PrivateKeyFromECDH returns k as a PrivateKey. It returns an error if k is
+// not on one of the NIST curves, such as an X25519 key.
+func PrivateKeyFromECDH(k *ecdh.PrivateKey) (*PrivateKey, error) {
+	pub, err := PublicKeyFromECDH(k.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{PublicKey: *pub, D: new(big.Int).SetBytes(k.Bytes())}, nil
+}
+
+func curveToECDH(c elliptic.Curve) ecdh.Curve {
+	switch c {
+	case elliptic.P256():
+		return ecdh.P256()
+	case elliptic.P384():
+		return ecdh.P384()
+	case elliptic.P521():
+		return ecdh.P521()
+	default:
+		return nil
+	}
+}
+
+func curveFromECDH(c ecdh.Curve) elliptic.Curve {
+	switch c {
+	case ecdh.P256():
+		return elliptic.P256()
+	case ecdh.P384():
+		return elliptic.P384()
+	case ecdh.P521():
+		return elliptic.P521()
+	default:
+		return nil
+	}
+}
+
 // Sign signs msg with priv, reading randomness from rand. This method is
 // intended to support keys where the private part is kept in, for example, a
 // hardware module. Common uses should use the Sign function in this package
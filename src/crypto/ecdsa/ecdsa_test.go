@@ -6,7 +6,9 @@ package ecdsa
 
 import (
 	"bufio"
+	"bytes"
 	"compress/bzip2"
+	"crypto/ecdh"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha1"
@@ -42,6 +44,50 @@ func TestKeyGeneration(t *testing.T) {
 	testKeyGeneration(t, elliptic.P521(), "p521")
 }
 
+func testECDHConversion(t *testing.T, c elliptic.Curve, tag string) {
+	priv, err := GenerateKey(c, rand.Reader)
+	if err != nil {
+		t.Fatalf("%s: error: %s", tag, err)
+	}
+	ecdhPriv, err := priv.ECDH()
+	if err != nil {
+		t.Fatalf("%s: ECDH: %s", tag, err)
+	}
+	ecdhPub, err := priv.PublicKey.ECDH()
+	if err != nil {
+		t.Fatalf("%s: ECDH: %s", tag, err)
+	}
+	if !ecdhPriv.PublicKey().Equal(ecdhPub) {
+		t.Errorf("%s: converted public keys don't match", tag)
+	}
+	if want := elliptic.Marshal(c, priv.X, priv.Y); !bytes.Equal(ecdhPub.Bytes(), want) {
+		t.Errorf("%s: converted public key is %x, want %x", tag, ecdhPub.Bytes(), want)
+	}
+
+	back, err := PrivateKeyFromECDH(ecdhPriv)
+	if err != nil {
+		t.Fatalf("%s: PrivateKeyFromECDH: %s", tag, err)
+	}
+	if back.Curve != c || back.D.Cmp(priv.D) != 0 || back.X.Cmp(priv.X) != 0 || back.Y.Cmp(priv.Y) != 0 {
+		t.Errorf("%s: round-tripped private key doesn't match", tag)
+	}
+}
+
+func TestECDHConversion(t *testing.T) {
+	testECDHConversion(t, elliptic.P256(), "p256")
+	testECDHConversion(t, elliptic.P384(), "p384")
+	testECDHConversion(t, elliptic.P521(), "p521")
+
+	priv, _ := GenerateKey(elliptic.P224(), rand.Reader)
+	if _, err := priv.ECDH(); err == nil {
+		t.Error("p224: ECDH succeeded for an unsupported curve")
+	}
+	x25519Key, _ := ecdh.X25519().GenerateKey(rand.Reader)
+	if _, err := PrivateKeyFromECDH(x25519Key); err == nil {
+		t.Error("x25519: PrivateKeyFromECDH succeeded")
+	}
+}
+
 func BenchmarkSignP256(b *testing.B) {
 	b.ResetTimer()
 	p256 := elliptic.P256()
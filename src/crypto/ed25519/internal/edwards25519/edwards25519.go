@@ -0,0 +1,254 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package edwards25519 implements group logic for the twisted Edwards curve
+//
+//     -x^2 + y^2 = 1 + -(121665/121666)*x^2*y^2
+//
+// This is better known as the Edwards curve equivalent to Curve25519, and is
+// the curve used by the Ed25519 signature scheme.
+package edwards25519
+
+import (
+	"crypto/subtle"
+	"errors"
+)
+
+// Point represents a point on the edwards25519 curve in extended coordinates
+// (X:Y:Z:T), where x = X/Z, y = Y/Z and x*y = T/Z. The zero value is not a
+// valid point; use NewIdentityPoint or NewGeneratorPoint.
+type Point struct {
+	x, y, z, t fieldElement
+}
+
+var (
+	// d is the curve constant -121665/121666.
+	d = feFromLittleEndian([32]byte{
+		0xa3, 0x78, 0x59, 0x13, 0xca, 0x4d, 0xeb, 0x75, 0xab, 0xd8, 0x41, 0x41, 0x4d, 0x0a, 0x70, 0x00,
+		0x98, 0xe8, 0x79, 0x77, 0x79, 0x40, 0xc7, 0x8c, 0x73, 0xfe, 0x6f, 0x2b, 0xee, 0x6c, 0x03, 0x52})
+	// d2 is 2*d.
+	d2 = feFromLittleEndian([32]byte{
+		0x59, 0xf1, 0xb2, 0x26, 0x94, 0x9b, 0xd6, 0xeb, 0x56, 0xb1, 0x83, 0x82, 0x9a, 0x14, 0xe0, 0x00,
+		0x30, 0xd1, 0xf3, 0xee, 0xf2, 0x80, 0x8e, 0x19, 0xe7, 0xfc, 0xdf, 0x56, 0xdc, 0xd9, 0x06, 0x24})
+	// sqrtM1 is a square root of -1, 2^((p-1)/4).
+	sqrtM1 = feFromLittleEndian([32]byte{
+		0xb0, 0xa0, 0x0e, 0x4a, 0x27, 0x1b, 0xee, 0xc4, 0x78, 0xe4, 0x2f, 0xad, 0x06, 0x18, 0x43, 0x2f,
+		0xa7, 0xd7, 0xfb, 0x3d, 0x99, 0x00, 0x4d, 0x2b, 0x0b, 0xdf, 0xc1, 0x4f, 0x80, 0x24, 0x83, 0x2b})
+	// gx and gy are the coordinates of the canonical generator.
+	gx = feFromLittleEndian([32]byte{
+		0x1a, 0xd5, 0x25, 0x8f, 0x60, 0x2d, 0x56, 0xc9, 0xb2, 0xa7, 0x25, 0x95, 0x60, 0xc7, 0x2c, 0x69,
+		0x5c, 0xdc, 0xd6, 0xfd, 0x31, 0xe2, 0xa4, 0xc0, 0xfe, 0x53, 0x6e, 0xcd, 0xd3, 0x36, 0x69, 0x21})
+	gy = feFromLittleEndian([32]byte{
+		0x58, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66,
+		0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66, 0x66})
+)
+
+func feFromLittleEndian(b [32]byte) fieldElement {
+	var v fieldElement
+	feFromBytes(&v, &b)
+	return v
+}
+
+// NewIdentityPoint returns a new Point set to the identity.
+func NewIdentityPoint() *Point {
+	return &Point{x: feZero, y: feOne, z: feOne, t: feZero}
+}
+
+// NewGeneratorPoint returns a new Point set to the canonical generator.
+func NewGeneratorPoint() *Point {
+	p := &Point{x: gx, y: gy, z: feOne}
+	feMul(&p.t, &gx, &gy)
+	return p
+}
+
+// Set sets v = u, and returns v.
+func (v *Point) Set(u *Point) *Point {
+	*v = *u
+	return v
+}
+
+// Add sets v = p + q, and returns v.
+//
+// The formula is the unified addition of Hisil, Wong, Carter and Dawson,
+// "Twisted Edwards Curves Revisited", Section 3.1, which is complete on this
+// curve, so it also works for doubling and for the identity.
+func (v *Point) Add(p, q *Point) *Point {
+	var a, b, c, dd, t, e, f, g, h fieldElement
+	feSub(&a, &p.y, &p.x)
+	feSub(&t, &q.y, &q.x)
+	feMul(&a, &a, &t)
+	feAdd(&b, &p.x, &p.y)
+	feAdd(&t, &q.x, &q.y)
+	feMul(&b, &b, &t)
+	feMul(&c, &p.t, &q.t)
+	feMul(&c, &c, &d2)
+	feMul(&dd, &p.z, &q.z)
+	feAdd(&dd, &dd, &dd)
+	feSub(&e, &b, &a)
+	feSub(&f, &dd, &c)
+	feAdd(&g, &dd, &c)
+	feAdd(&h, &b, &a)
+
+	feMul(&v.x, &e, &f)
+	feMul(&v.y, &h, &g)
+	feMul(&v.z, &g, &f)
+	feMul(&v.t, &e, &h)
+	return v
+}
+
+// Negate sets v = -p, and returns v.
+func (v *Point) Negate(p *Point) *Point {
+	feNeg(&v.x, &p.x)
+	v.y = p.y
+	v.z = p.z
+	feNeg(&v.t, &p.t)
+	return v
+}
+
+// Bytes returns the canonical 32-byte encoding of v, according to RFC 8032,
+// Section 5.1.2.
+func (v *Point) Bytes() []byte {
+	var zInv, x, y fieldElement
+	feInvert(&zInv, &v.z)
+	feMul(&x, &v.x, &zInv)
+	feMul(&y, &v.y, &zInv)
+
+	var out [32]byte
+	feToBytes(&out, &y)
+	out[31] |= byte(feIsNegative(&x) << 7)
+	return out[:]
+}
+
+var errInvalidEncoding = errors.New("edwards25519: invalid point encoding")
+
+// SetBytes sets v = x, where x is a 32-byte encoding of v. If x does not
+// represent a valid point on the curve, SetBytes returns nil and an error and
+// the receiver is unchanged. Otherwise, SetBytes returns v.
+//
+// Following RFC 8032, Section 5.1.3, non-canonical encodings of y and the
+// encoding of x = 0 with the sign bit set are rejected. SetBytes doesn't run
+// in constant time and must only be used on public values.
+func (v *Point) SetBytes(x []byte) (*Point, error) {
+	if len(x) != 32 {
+		return nil, errInvalidEncoding
+	}
+	var in [32]byte
+	copy(in[:], x)
+
+	var y fieldElement
+	feFromBytes(&y, &in)
+	var canonical [32]byte
+	feToBytes(&canonical, &y)
+	if canonical[31] != in[31]&0x7f || string(canonical[:31]) != string(in[:31]) {
+		return nil, errInvalidEncoding
+	}
+
+	// -x² + y² = 1 + dx²y², so x² = (y² - 1) / (dy² + 1) = u / w.
+	var u, w, y2 fieldElement
+	feSquare(&y2, &y)
+	feSub(&u, &y2, &feOne)
+	feMul(&w, &y2, &d)
+	feAdd(&w, &w, &feOne)
+
+	// x = uw³(uw⁷)^((p-5)/8), possibly times sqrt(-1). See RFC 8032,
+	// Section 5.1.3.
+	var w2, w3, w7, xx, t fieldElement
+	feSquare(&w2, &w)
+	feMul(&w3, &w2, &w)
+	feSquare(&w7, &w3)
+	feMul(&w7, &w7, &w)
+	feMul(&t, &u, &w7)
+	fePow22523(&t, &t)
+	feMul(&t, &t, &u)
+	feMul(&xx, &t, &w3)
+
+	var check fieldElement
+	feSquare(&check, &xx)
+	feMul(&check, &check, &w)
+	if !feEqual(&check, &u) {
+		feMul(&xx, &xx, &sqrtM1)
+		feSquare(&check, &xx)
+		feMul(&check, &check, &w)
+		if !feEqual(&check, &u) {
+			return nil, errInvalidEncoding
+		}
+	}
+
+	sign := int64(in[31] >> 7)
+	if sign == 1 && feEqual(&xx, &feZero) {
+		return nil, errInvalidEncoding
+	}
+	if feIsNegative(&xx) != sign {
+		feNeg(&xx, &xx)
+	}
+
+	v.x = xx
+	v.y = y
+	v.z = feOne
+	feMul(&v.t, &xx, &y)
+	return v, nil
+}
+
+// Equal returns 1 if v is equivalent to u, and 0 otherwise. It doesn't run
+// in constant time.
+func (v *Point) Equal(u *Point) int {
+	var t1, t2 fieldElement
+	feMul(&t1, &v.x, &u.z)
+	feMul(&t2, &u.x, &v.z)
+	if !feEqual(&t1, &t2) {
+		return 0
+	}
+	feMul(&t1, &v.y, &u.z)
+	feMul(&t2, &u.y, &v.z)
+	if !feEqual(&t1, &t2) {
+		return 0
+	}
+	return 1
+}
+
+// selectPoint sets v to table[n] in constant time.
+func (v *Point) selectPoint(table *[16]Point, n byte) {
+	*v = *NewIdentityPoint()
+	for i := range table {
+		cond := int64(subtle.ConstantTimeByteEq(byte(i), n))
+		feSelect(&v.x, &table[i].x, cond)
+		feSelect(&v.y, &table[i].y, cond)
+		feSelect(&v.z, &table[i].z, cond)
+		feSelect(&v.t, &table[i].t, cond)
+	}
+}
+
+// ScalarMult sets v = s * q, and returns v. The scalar s is a 32-byte
+// little-endian integer and doesn't need to be reduced.
+//
+// The multiplication uses a fixed 4-bit window and a masked table lookup, so
+// it runs in constant time with respect to s.
+func (v *Point) ScalarMult(s *[32]byte, q *Point) *Point {
+	var table [16]Point
+	table[0] = *NewIdentityPoint()
+	for i := 1; i < 16; i++ {
+		table[i].Add(&table[i-1], q)
+	}
+
+	r := NewIdentityPoint()
+	var t Point
+	for i := 31; i >= 0; i-- {
+		for _, n := range [2]byte{s[i] >> 4, s[i] & 0xf} {
+			// Doubling the identity in the first window is a no-op, but
+			// it's cheap and keeps the loop uniform.
+			for j := 0; j < 4; j++ {
+				r.Add(r, r)
+			}
+			t.selectPoint(&table, n)
+			r.Add(r, &t)
+		}
+	}
+	return v.Set(r)
+}
+
+// ScalarBaseMult sets v = s * B, where B is the canonical generator, and
+// returns v.
+func (v *Point) ScalarBaseMult(s *[32]byte) *Point {
+	return v.ScalarMult(s, NewGeneratorPoint())
+}
@@ -0,0 +1,280 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+// This file contains a constant-time implementation of arithmetic modulo
+// p = 2^255 - 19.
+
+// fieldElement represents an element of GF(2^255 - 19) in radix 2^25.5: the
+// value is t[0] + t[1]·2^26 + t[2]·2^51 + t[3]·2^77 + ... + t[9]·2^230, with
+// even limbs nominally 26 bits wide and odd limbs 25 bits wide. Limbs are
+// stored in int64s so that the partial products of a multiplication can be
+// accumulated before carrying.
+//
+// Every operation leaves its result carried, with limbs in [0, 2^26], which
+// keeps the products in feMul and feSquare well below 2^63.
+type fieldElement [10]int64
+
+var (
+	feZero = fieldElement{}
+	feOne  = fieldElement{1}
+)
+
+// limbBits returns the nominal width of limb i.
+func limbBits(i int) uint {
+	return 26 - uint(i&1)
+}
+
+// carry propagates the excess of every limb into the next one, folding the
+// carry out of the top limb back into the bottom one, as 2^255 = 19 mod p.
+func (v *fieldElement) carry() {
+	for i := 0; i < 10; i++ {
+		bits := limbBits(i)
+		c := v[i] >> bits
+		v[i] -= c << bits
+		if i < 9 {
+			v[i+1] += c
+		} else {
+			v[0] += 19 * c
+		}
+	}
+	c := v[0] >> 26
+	v[0] -= c << 26
+	v[1] += c
+}
+
+// feAdd sets out = a + b.
+func feAdd(out, a, b *fieldElement) {
+	for i := range out {
+		out[i] = a[i] + b[i]
+	}
+	out.carry()
+}
+
+// feSub sets out = a - b.
+func feSub(out, a, b *fieldElement) {
+	for i := range out {
+		out[i] = a[i] - b[i]
+	}
+	out.carry()
+}
+
+// feNeg sets out = -a.
+func feNeg(out, a *fieldElement) {
+	feSub(out, &feZero, a)
+}
+
+// feMul sets out = a * b.
+//
+// Limb i of a times limb j of b lands in limb i+j, scaled by two when both i
+// and j are odd to account for the half bits of radix 2^25.5, and by 19 when
+// i+j wraps past limb 9.
+func feMul(out, a, b *fieldElement) {
+	f0, f1, f2, f3, f4, f5, f6, f7, f8, f9 := a[0], a[1], a[2], a[3], a[4], a[5], a[6], a[7], a[8], a[9]
+	g0, g1, g2, g3, g4, g5, g6, g7, g8, g9 := b[0], b[1], b[2], b[3], b[4], b[5], b[6], b[7], b[8], b[9]
+	g1_2 := 2 * g1
+	g1_38 := 38 * g1
+	g2_19 := 19 * g2
+	g3_2 := 2 * g3
+	g3_19 := 19 * g3
+	g3_38 := 38 * g3
+	g4_19 := 19 * g4
+	g5_2 := 2 * g5
+	g5_19 := 19 * g5
+	g5_38 := 38 * g5
+	g6_19 := 19 * g6
+	g7_2 := 2 * g7
+	g7_19 := 19 * g7
+	g7_38 := 38 * g7
+	g8_19 := 19 * g8
+	g9_19 := 19 * g9
+	g9_38 := 38 * g9
+	h0 := f0*g0 + f1*g9_38 + f2*g8_19 + f3*g7_38 + f4*g6_19 + f5*g5_38 + f6*g4_19 + f7*g3_38 + f8*g2_19 + f9*g1_38
+	h1 := f0*g1 + f1*g0 + f2*g9_19 + f3*g8_19 + f4*g7_19 + f5*g6_19 + f6*g5_19 + f7*g4_19 + f8*g3_19 + f9*g2_19
+	h2 := f0*g2 + f1*g1_2 + f2*g0 + f3*g9_38 + f4*g8_19 + f5*g7_38 + f6*g6_19 + f7*g5_38 + f8*g4_19 + f9*g3_38
+	h3 := f0*g3 + f1*g2 + f2*g1 + f3*g0 + f4*g9_19 + f5*g8_19 + f6*g7_19 + f7*g6_19 + f8*g5_19 + f9*g4_19
+	h4 := f0*g4 + f1*g3_2 + f2*g2 + f3*g1_2 + f4*g0 + f5*g9_38 + f6*g8_19 + f7*g7_38 + f8*g6_19 + f9*g5_38
+	h5 := f0*g5 + f1*g4 + f2*g3 + f3*g2 + f4*g1 + f5*g0 + f6*g9_19 + f7*g8_19 + f8*g7_19 + f9*g6_19
+	h6 := f0*g6 + f1*g5_2 + f2*g4 + f3*g3_2 + f4*g2 + f5*g1_2 + f6*g0 + f7*g9_38 + f8*g8_19 + f9*g7_38
+	h7 := f0*g7 + f1*g6 + f2*g5 + f3*g4 + f4*g3 + f5*g2 + f6*g1 + f7*g0 + f8*g9_19 + f9*g8_19
+	h8 := f0*g8 + f1*g7_2 + f2*g6 + f3*g5_2 + f4*g4 + f5*g3_2 + f6*g2 + f7*g1_2 + f8*g0 + f9*g9_38
+	h9 := f0*g9 + f1*g8 + f2*g7 + f3*g6 + f4*g5 + f5*g4 + f6*g3 + f7*g2 + f8*g1 + f9*g0
+
+	*out = fieldElement{h0, h1, h2, h3, h4, h5, h6, h7, h8, h9}
+	out.carry()
+}
+
+// feSquare sets out = a². It computes the same products as feMul, merging
+// the symmetric ones.
+func feSquare(out, a *fieldElement) {
+	f0, f1, f2, f3, f4, f5, f6, f7, f8, f9 := a[0], a[1], a[2], a[3], a[4], a[5], a[6], a[7], a[8], a[9]
+	f1_2 := 2 * f1
+	f2_2 := 2 * f2
+	f3_2 := 2 * f3
+	f3_4 := 4 * f3
+	f4_2 := 2 * f4
+	f5_2 := 2 * f5
+	f5_4 := 4 * f5
+	f5_38 := 38 * f5
+	f6_2 := 2 * f6
+	f6_19 := 19 * f6
+	f6_38 := 38 * f6
+	f7_2 := 2 * f7
+	f7_4 := 4 * f7
+	f7_38 := 38 * f7
+	f7_76 := 76 * f7
+	f8_2 := 2 * f8
+	f8_19 := 19 * f8
+	f8_38 := 38 * f8
+	f9_2 := 2 * f9
+	f9_38 := 38 * f9
+	f9_76 := 76 * f9
+	h0 := f0*f0 + f1*f9_76 + f2*f8_38 + f3*f7_76 + f4*f6_38 + f5*f5_38
+	h1 := f0*f1_2 + f2*f9_38 + f3*f8_38 + f4*f7_38 + f5*f6_38
+	h2 := f0*f2_2 + f1*f1_2 + f3*f9_76 + f4*f8_38 + f5*f7_76 + f6*f6_19
+	h3 := f0*f3_2 + f1*f2_2 + f4*f9_38 + f5*f8_38 + f6*f7_38
+	h4 := f0*f4_2 + f1*f3_4 + f2*f2 + f5*f9_76 + f6*f8_38 + f7*f7_38
+	h5 := f0*f5_2 + f1*f4_2 + f2*f3_2 + f6*f9_38 + f7*f8_38
+	h6 := f0*f6_2 + f1*f5_4 + f2*f4_2 + f3*f3_2 + f7*f9_76 + f8*f8_19
+	h7 := f0*f7_2 + f1*f6_2 + f2*f5_2 + f3*f4_2 + f8*f9_38
+	h8 := f0*f8_2 + f1*f7_4 + f2*f6_2 + f3*f5_4 + f4*f4 + f9*f9_38
+	h9 := f0*f9_2 + f1*f8_2 + f2*f7_2 + f3*f6_2 + f4*f5_2
+
+	*out = fieldElement{h0, h1, h2, h3, h4, h5, h6, h7, h8, h9}
+	out.carry()
+}
+
+// feSelect sets out to a if cond is 1 and leaves it unchanged if cond is 0,
+// in constant time.
+func feSelect(out, a *fieldElement, cond int64) {
+	mask := -cond
+	for i := range out {
+		out[i] ^= mask & (out[i] ^ a[i])
+	}
+}
+
+// feToBytes sets out to the canonical little-endian encoding of a.
+func feToBytes(out *[32]byte, a *fieldElement) {
+	t := *a
+	t.carry()
+
+	// Now t < 2p. Compute q = 1 if t >= p by checking whether t + 19
+	// overflows 255 bits, and subtract q*p = q*2^255 - 19q.
+	q := (t[0] + 19) >> 26
+	for i := 1; i < 10; i++ {
+		q = (t[i] + q) >> limbBits(i)
+	}
+	t[0] += 19 * q
+	for i := 0; i < 9; i++ {
+		bits := limbBits(i)
+		c := t[i] >> bits
+		t[i] -= c << bits
+		t[i+1] += c
+	}
+	t[9] &= 1<<25 - 1
+
+	var acc uint64
+	var accBits uint
+	n := 0
+	for i := 0; i < 10; i++ {
+		acc |= uint64(t[i]) << accBits
+		accBits += limbBits(i)
+		for accBits >= 8 {
+			out[n] = byte(acc)
+			acc >>= 8
+			accBits -= 8
+			n++
+		}
+	}
+	out[n] = byte(acc)
+}
+
+// feFromBytes sets out to the value of the little-endian encoding in, ignoring
+// its most significant bit. The value is not required to be reduced.
+func feFromBytes(out *fieldElement, in *[32]byte) {
+	var acc uint64
+	var accBits uint
+	n := 0
+	for i := 0; i < 10; i++ {
+		bits := limbBits(i)
+		for accBits < bits && n < 32 {
+			acc |= uint64(in[n]) << accBits
+			accBits += 8
+			n++
+		}
+		out[i] = int64(acc & (1<<bits - 1))
+		acc >>= bits
+		accBits -= bits
+	}
+}
+
+// feIsNegative returns 1 if a is negative, that is, if the least significant
+// bit of its canonical encoding is set, and 0 otherwise.
+func feIsNegative(a *fieldElement) int64 {
+	var b [32]byte
+	feToBytes(&b, a)
+	return int64(b[0] & 1)
+}
+
+// feEqual reports whether a and b represent the same element. It doesn't run
+// in constant time.
+func feEqual(a, b *fieldElement) bool {
+	var aBytes, bBytes [32]byte
+	feToBytes(&aBytes, a)
+	feToBytes(&bBytes, b)
+	return aBytes == bBytes
+}
+
+// feSquareN sets out = a^(2^n).
+func feSquareN(out, a *fieldElement, n int) {
+	feSquare(out, a)
+	for i := 1; i < n; i++ {
+		feSquare(out, out)
+	}
+}
+
+// fePow2250 returns a^(2^250 - 1) and a^11, the common prefix of the
+// addition chains of feInvert and fePow22523.
+func fePow2250(a *fieldElement) (z250, z11 fieldElement) {
+	var z2, z9, t, z5, z10, z20, z50, z100 fieldElement
+	feSquare(&z2, a)          // 2
+	feSquareN(&t, &z2, 2)     // 8
+	feMul(&z9, &t, a)         // 9
+	feMul(&z11, &z2, &z9)     // 11
+	feSquare(&t, &z11)        // 22
+	feMul(&z5, &t, &z9)       // 2^5 - 1
+	feSquareN(&t, &z5, 5)     // 2^10 - 2^5
+	feMul(&z10, &t, &z5)      // 2^10 - 1
+	feSquareN(&t, &z10, 10)   // 2^20 - 2^10
+	feMul(&z20, &t, &z10)     // 2^20 - 1
+	feSquareN(&t, &z20, 20)   // 2^40 - 2^20
+	feMul(&t, &t, &z20)       // 2^40 - 1
+	feSquareN(&t, &t, 10)     // 2^50 - 2^10
+	feMul(&z50, &t, &z10)     // 2^50 - 1
+	feSquareN(&t, &z50, 50)   // 2^100 - 2^50
+	feMul(&z100, &t, &z50)    // 2^100 - 1
+	feSquareN(&t, &z100, 100) // 2^200 - 2^100
+	feMul(&t, &t, &z100)      // 2^200 - 1
+	feSquareN(&t, &t, 50)     // 2^250 - 2^50
+	feMul(&z250, &t, &z50)    // 2^250 - 1
+	return z250, z11
+}
+
+// feInvert sets out = 1/a = a^(p-2) = a^(2^255 - 21). The inverse of zero
+// is zero.
+func feInvert(out, a *fieldElement) {
+	z250, z11 := fePow2250(a)
+	var t fieldElement
+	feSquareN(&t, &z250, 5) // 2^255 - 2^5
+	feMul(out, &t, &z11)    // 2^255 - 21
+}
+
+// fePow22523 sets out = a^((p-5)/8) = a^(2^252 - 3).
+func fePow22523(out, a *fieldElement) {
+	z250, _ := fePow2250(a)
+	var t fieldElement
+	feSquareN(&t, &z250, 2) // 2^252 - 4
+	feMul(out, &t, a)       // 2^252 - 3
+}
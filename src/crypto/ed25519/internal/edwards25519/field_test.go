@@ -0,0 +1,145 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+var p = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+func feFromBig(x *big.Int) fieldElement {
+	var b [32]byte
+	xb := x.Bytes()
+	for i := range xb {
+		b[i] = xb[len(xb)-1-i]
+	}
+	return feFromLittleEndian(b)
+}
+
+func feToBig(v *fieldElement) *big.Int {
+	var b [32]byte
+	feToBytes(&b, v)
+	var be [32]byte
+	for i := range b {
+		be[i] = b[31-i]
+	}
+	return new(big.Int).SetBytes(be[:])
+}
+
+// randomFieldValue returns a random value in [0, p), biased towards the
+// edges of the range where carries and reductions are most likely to go wrong.
+func randomFieldValue(r *rand.Rand) *big.Int {
+	x := new(big.Int).Rand(r, p)
+	switch r.Intn(4) {
+	case 0:
+		x.SetInt64(r.Int63n(32))
+	case 1:
+		x.Sub(p, big.NewInt(1+r.Int63n(32)))
+	}
+	return x
+}
+
+func TestFieldArithmetic(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		x, y := randomFieldValue(r), randomFieldValue(r)
+		fx, fy := feFromBig(x), feFromBig(y)
+
+		var got fieldElement
+		check := func(op string, want *big.Int) {
+			t.Helper()
+			want.Mod(want, p)
+			if g := feToBig(&got); g.Cmp(want) != 0 {
+				t.Fatalf("%s(%x, %x) = %x, want %x", op, x, y, g, want)
+			}
+		}
+
+		feAdd(&got, &fx, &fy)
+		check("add", new(big.Int).Add(x, y))
+		feSub(&got, &fx, &fy)
+		check("sub", new(big.Int).Sub(x, y))
+		feNeg(&got, &fx)
+		check("neg", new(big.Int).Neg(x))
+		feMul(&got, &fx, &fy)
+		check("mul", new(big.Int).Mul(x, y))
+		feSquare(&got, &fx)
+		check("square", new(big.Int).Mul(x, x))
+		feInvert(&got, &fx)
+		check("invert", new(big.Int).Exp(x, new(big.Int).Sub(p, big.NewInt(2)), p))
+		fePow22523(&got, &fx)
+		e := new(big.Int).Sub(p, big.NewInt(5))
+		check("pow22523", new(big.Int).Exp(x, e.Rsh(e, 3), p))
+	}
+}
+
+func TestFieldBytes(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 1000; i++ {
+		var in [32]byte
+		r.Read(in[:])
+		var v fieldElement
+		feFromBytes(&v, &in)
+		var out [32]byte
+		feToBytes(&out, &v)
+
+		// The top bit is ignored, and values in [p, 2^255) are reduced.
+		in[31] &= 0x7f
+		var be [32]byte
+		for i := range in {
+			be[i] = in[31-i]
+		}
+		want := new(big.Int).SetBytes(be[:])
+		want.Mod(want, p)
+		if got := feToBig(&v); got.Cmp(want) != 0 {
+			t.Fatalf("feFromBytes(%x) = %x, want %x", in, got, want)
+		}
+		if want.Cmp(new(big.Int).SetBytes(be[:])) == 0 && !bytes.Equal(in[:], out[:]) {
+			t.Fatalf("round trip of %x = %x", in, out)
+		}
+	}
+
+	// p + 1 and 2^255 - 1 are non-canonical encodings of 1 and 18.
+	for _, tt := range []struct {
+		low  byte
+		want [32]byte
+	}{
+		{0xee, [32]byte{1}},
+		{0xff, [32]byte{18}},
+	} {
+		var in [32]byte
+		for i := range in {
+			in[i] = 0xff
+		}
+		in[0], in[31] = tt.low, 0x7f
+		var v fieldElement
+		feFromBytes(&v, &in)
+		var out [32]byte
+		feToBytes(&out, &v)
+		if out != tt.want {
+			t.Errorf("feToBytes(feFromBytes(%x)) = %x, want %x", in, out, tt.want)
+		}
+	}
+}
+
+func BenchmarkFeMul(b *testing.B) {
+	x, y := d, sqrtM1
+	for i := 0; i < b.N; i++ {
+		feMul(&x, &x, &y)
+	}
+}
+
+func BenchmarkScalarBaseMult(b *testing.B) {
+	var s [32]byte
+	s[0] = 42
+	s[31] = 0x0f
+	p := new(Point)
+	for i := 0; i < b.N; i++ {
+		p.ScalarBaseMult(&s)
+	}
+}
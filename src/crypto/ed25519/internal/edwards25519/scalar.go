@@ -0,0 +1,82 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package edwards25519
+
+// This file implements arithmetic modulo the order of the prime subgroup,
+// l = 2^252 + 27742317777372353535851937790883648493, on 32-byte
+// little-endian scalars.
+
+// order is l as a little-endian sequence of bytes.
+var order = [32]int64{
+	0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x10,
+}
+
+// modL sets out to x mod l, where x is a little-endian integer with one
+// signed byte-sized digit per element.
+func modL(out *[32]byte, x *[64]int64) {
+	for i := 63; i >= 32; i-- {
+		var carry int64
+		j := i - 32
+		for ; j < i-12; j++ {
+			x[j] += carry - 16*x[i]*order[j-(i-32)]
+			carry = (x[j] + 128) >> 8
+			x[j] -= carry << 8
+		}
+		x[j] += carry
+		x[i] = 0
+	}
+
+	var carry int64
+	for j := 0; j < 32; j++ {
+		x[j] += carry - (x[31]>>4)*order[j]
+		carry = x[j] >> 8
+		x[j] &= 255
+	}
+	for j := 0; j < 32; j++ {
+		x[j] -= carry * order[j]
+	}
+	for i := 0; i < 32; i++ {
+		x[i+1] += x[i] >> 8
+		out[i] = byte(x[i])
+	}
+}
+
+// ScReduce sets out = s mod l, where s is a 64-byte little-endian integer.
+func ScReduce(out *[32]byte, s *[64]byte) {
+	var x [64]int64
+	for i := range s {
+		x[i] = int64(s[i])
+	}
+	modL(out, &x)
+}
+
+// ScMulAdd sets out = (a * b + c) mod l.
+func ScMulAdd(out, a, b, c *[32]byte) {
+	var x [64]int64
+	for i := 0; i < 32; i++ {
+		x[i] = int64(c[i])
+	}
+	for i := 0; i < 32; i++ {
+		for j := 0; j < 32; j++ {
+			x[i+j] += int64(a[i]) * int64(b[j])
+		}
+	}
+	modL(out, &x)
+}
+
+// ScMinimal reports whether the little-endian scalar s is lower than l, that
+// is, whether it is the canonical encoding of a reduced scalar.
+func ScMinimal(s *[32]byte) bool {
+	for i := 31; i >= 0; i-- {
+		switch {
+		case int64(s[i]) < order[i]:
+			return true
+		case int64(s[i]) > order[i]:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tls
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"hash"
+	"io"
+)
+
+// typeAndHashFromSignatureAndHash returns the signature type and hash
+// function of a TLS 1.2 SignatureAndHashAlgorithm or, equivalently, of a TLS
+// 1.3 SignatureScheme.
+func typeAndHashFromSignatureAndHash(sigHash signatureAndHash) (sigType uint8, hashFunc crypto.Hash, err error) {
+	switch SignatureScheme(sigHash.hash)<<8 | SignatureScheme(sigHash.signature) {
+	case PSSWithSHA256:
+		return signatureRSAPSS, crypto.SHA256, nil
+	case PSSWithSHA384:
+		return signatureRSAPSS, crypto.SHA384, nil
+	case PSSWithSHA512:
+		return signatureRSAPSS, crypto.SHA512, nil
+	case Ed25519:
+		// Ed25519 signs the message itself, so there is no hash.
+		return signatureEd25519, crypto.Hash(0), nil
+	}
+
+	switch sigHash.signature {
+	case signatureRSA, signatureECDSA:
+	default:
+		return 0, 0, errors.New("tls: unsupported signature algorithm")
+	}
+	hashFunc, err = lookupTLSHash(sigHash.hash)
+	if err != nil {
+		return 0, 0, err
+	}
+	return sigHash.signature, hashFunc, nil
+}
+
+// verifyHandshakeSignature verifies a signature against pre-hashed handshake
+// contents, or against the unhashed message for Ed25519.
+func verifyHandshakeSignature(sigType uint8, pubkey crypto.PublicKey, hashFunc crypto.Hash, digest, sig []byte) error {
+	switch sigType {
+	case signatureEd25519:
+		pubKey, ok := pubkey.(ed25519.PublicKey)
+		if !ok {
+			return errors.New("tls: Ed25519 signing requires an Ed25519 public key")
+		}
+		if !ed25519.Verify(pubKey, digest, sig) {
+			return errors.New("tls: Ed25519 verification failure")
+		}
+	case signatureECDSA:
+		pubKey, ok := pubkey.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("tls: ECDSA signing requires a ECDSA public key")
+		}
+		ecdsaSig := new(ecdsaSignature)
+		if _, err := asn1.Unmarshal(sig, ecdsaSig); err != nil {
+			return err
+		}
+		if ecdsaSig.R.Sign() <= 0 || ecdsaSig.S.Sign() <= 0 {
+			return errors.New("tls: ECDSA signature contained zero or negative values")
+		}
+		if !ecdsa.Verify(pubKey, digest, ecdsaSig.R, ecdsaSig.S) {
+			return errors.New("tls: ECDSA verification failure")
+		}
+	case signatureRSA:
+		pubKey, ok := pubkey.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("tls: RSA signing requires a RSA public key")
+		}
+		if err := rsa.VerifyPKCS1v15(pubKey, hashFunc, digest, sig); err != nil {
+			return err
+		}
+	case signatureRSAPSS:
+		pubKey, ok := pubkey.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("tls: RSA signing requires a RSA public key")
+		}
+		signOpts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}
+		if err := rsa.VerifyPSS(pubKey, hashFunc, digest, sig, signOpts); err != nil {
+			return err
+		}
+	default:
+		return errors.New("tls: unknown signature algorithm")
+	}
+	return nil
+}
+
+// signHandshake signs pre-hashed handshake contents with priv.
+func signHandshake(rand io.Reader, priv crypto.Signer, sigType uint8, hashFunc crypto.Hash, digest []byte) ([]byte, error) {
+	var signOpts crypto.SignerOpts = hashFunc
+	if sigType == signatureRSAPSS {
+		signOpts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hashFunc}
+	}
+	return priv.Sign(rand, digest, signOpts)
+}
+
+const (
+	serverSignatureContext = "TLS 1.3, server CertificateVerify\x00"
+	clientSignatureContext = "TLS 1.3, client CertificateVerify\x00"
+)
+
+var signaturePadding = []byte{
+	0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20,
+	0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20,
+	0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20,
+	0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20,
+	0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20,
+	0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20,
+	0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20,
+	0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20,
+}
+
+// signedMessageDigest returns the hashed content covered by a TLS 1.3
+// CertificateVerify signature. See RFC 8446, section 4.4.3. If hashFunc is
+// zero, as it is for Ed25519, the content is returned unhashed.
+func signedMessageDigest(hashFunc crypto.Hash, context string, transcript hash.Hash) []byte {
+	if hashFunc == 0 {
+		var b bytes.Buffer
+		b.Write(signaturePadding)
+		b.WriteString(context)
+		b.Write(transcript.Sum(nil))
+		return b.Bytes()
+	}
+	h := hashFunc.New()
+	h.Write(signaturePadding)
+	h.Write([]byte(context))
+	h.Write(transcript.Sum(nil))
+	return h.Sum(nil)
+}
+
+// signatureAndHashesForKeyTLS13 returns the TLS 1.3 signature schemes that
+// can be used with the given public key, in preference order. TLS 1.3 drops
+// PKCS #1 v1.5 signatures and binds each ECDSA scheme to a curve.
+func signatureAndHashesForKeyTLS13(pub crypto.PublicKey) []signatureAndHash {
+	switch pub := pub.(type) {
+	case ed25519.PublicKey:
+		return []signatureAndHash{{0x08, signatureEd25519}}
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			return []signatureAndHash{{hashSHA256, signatureECDSA}}
+		case elliptic.P384():
+			return []signatureAndHash{{hashSHA384, signatureECDSA}}
+		case elliptic.P521():
+			return []signatureAndHash{{hashSHA512, signatureECDSA}}
+		}
+	case *rsa.PublicKey:
+		// RSA-PSS with a salt as long as the hash needs a modulus of at
+		// least twice the hash size plus two bytes, so small keys can't
+		// use every scheme, or any at all.
+		var sigHashes []signatureAndHash
+		for _, sigHash := range []signatureAndHash{{0x08, 0x04}, {0x08, 0x05}, {0x08, 0x06}} {
+			_, hashFunc, _ := typeAndHashFromSignatureAndHash(sigHash)
+			if (pub.N.BitLen()+7)/8 >= 2*hashFunc.Size()+2 {
+				sigHashes = append(sigHashes, sigHash)
+			}
+		}
+		return sigHashes
+	}
+	return nil
+}
+
+// pickSignatureAndHashTLS13 selects a signature scheme for signing a TLS 1.3
+// CertificateVerify with the given key, given the peer's supported list.
+func pickSignatureAndHashTLS13(pub crypto.PublicKey, peerAlgs []signatureAndHash) (signatureAndHash, bool) {
+	for _, sigHash := range signatureAndHashesForKeyTLS13(pub) {
+		if isSupportedSignatureAndHash(sigHash, peerAlgs) {
+			return sigHash, true
+		}
+	}
+	return signatureAndHash{}, false
+}
+
+// isSupportedSignatureAndHashTLS13 reports whether sigHash, received in a
+// TLS 1.3 CertificateVerify, is one that we advertise and that matches the
+// given public key.
+func isSupportedSignatureAndHashTLS13(sigHash signatureAndHash, pub crypto.PublicKey) bool {
+	return isSupportedSignatureAndHash(sigHash, supportedSignatureAlgorithmsTLS13) &&
+		isSupportedSignatureAndHash(sigHash, signatureAndHashesForKeyTLS13(pub))
+}
@@ -26,6 +26,7 @@ const (
 	VersionTLS10 = 0x0301
 	VersionTLS11 = 0x0302
 	VersionTLS12 = 0x0303
+	VersionTLS13 = 0x0304
 )
 
 const (
@@ -35,9 +36,14 @@ const (
 	maxHandshake    = 65536        // maximum handshake we support (protocol max is 16 MB)
 
 	minVersion = VersionTLS10
-	maxVersion = VersionTLS12
+	maxVersion = VersionTLS13
 )
 
+// maxSessionTicketLifetime is the maximum allowed lifetime of a TLS 1.3
+// session ticket, and the lifetime we set for all tickets we send. See RFC
+// 8446, section 4.6.1.
+const maxSessionTicketLifetime = 7 * 24 * time.Hour
+
 // TLS record types.
 type recordType uint8
 
@@ -50,19 +56,22 @@ const (
 
 // TLS handshake message types.
 const (
-	typeHelloRequest       uint8 = 0
-	typeClientHello        uint8 = 1
-	typeServerHello        uint8 = 2
-	typeNewSessionTicket   uint8 = 4
-	typeCertificate        uint8 = 11
-	typeServerKeyExchange  uint8 = 12
-	typeCertificateRequest uint8 = 13
-	typeServerHelloDone    uint8 = 14
-	typeCertificateVerify  uint8 = 15
-	typeClientKeyExchange  uint8 = 16
-	typeFinished           uint8 = 20
-	typeCertificateStatus  uint8 = 22
-	typeNextProtocol       uint8 = 67 // Not IANA assigned
+	typeHelloRequest        uint8 = 0
+	typeClientHello         uint8 = 1
+	typeServerHello         uint8 = 2
+	typeNewSessionTicket    uint8 = 4
+	typeEncryptedExtensions uint8 = 8
+	typeCertificate         uint8 = 11
+	typeServerKeyExchange   uint8 = 12
+	typeCertificateRequest  uint8 = 13
+	typeServerHelloDone     uint8 = 14
+	typeCertificateVerify   uint8 = 15
+	typeClientKeyExchange   uint8 = 16
+	typeFinished            uint8 = 20
+	typeCertificateStatus   uint8 = 22
+	typeKeyUpdate           uint8 = 24
+	typeNextProtocol        uint8 = 67  // Not IANA assigned
+	typeMessageHash         uint8 = 254 // synthetic message, see RFC 8446, section 4.4.1
 )
 
 // TLS compression types.
@@ -72,16 +81,24 @@ const (
 
 // TLS extension numbers
 const (
-	extensionServerName          uint16 = 0
-	extensionStatusRequest       uint16 = 5
-	extensionSupportedCurves     uint16 = 10
-	extensionSupportedPoints     uint16 = 11
-	extensionSignatureAlgorithms uint16 = 13
-	extensionALPN                uint16 = 16
-	extensionSCT                 uint16 = 18 // https://tools.ietf.org/html/rfc6962#section-6
-	extensionSessionTicket       uint16 = 35
-	extensionNextProtoNeg        uint16 = 13172 // not IANA assigned
-	extensionRenegotiationInfo   uint16 = 0xff01
+	extensionServerName              uint16 = 0
+	extensionStatusRequest           uint16 = 5
+	extensionSupportedCurves         uint16 = 10
+	extensionSupportedPoints         uint16 = 11
+	extensionSignatureAlgorithms     uint16 = 13
+	extensionALPN                    uint16 = 16
+	extensionSCT                     uint16 = 18 // https://tools.ietf.org/html/rfc6962#section-6
+	extensionSessionTicket           uint16 = 35
+	extensionPreSharedKey            uint16 = 41
+	extensionEarlyData               uint16 = 42
+	extensionSupportedVersions       uint16 = 43
+	extensionCookie                  uint16 = 44
+	extensionPSKModes                uint16 = 45
+	extensionCertificateAuthorities  uint16 = 47
+	extensionSignatureAlgorithmsCert uint16 = 50
+	extensionKeyShare                uint16 = 51
+	extensionNextProtoNeg            uint16 = 13172 // not IANA assigned
+	extensionRenegotiationInfo       uint16 = 0xff01
 )
 
 // TLS signaling cipher suite values
@@ -106,6 +123,35 @@ const (
 	pointFormatUncompressed uint8 = 0
 )
 
+// TLS 1.3 PSK Key Exchange Modes (RFC 8446, section 4.2.9)
+const (
+	pskModePlain uint8 = 0
+	pskModeDHE   uint8 = 1
+)
+
+// TLS 1.3 KeyUpdateRequest values (RFC 8446, section 4.6.3)
+const (
+	keyUpdateNotRequested uint8 = 0
+	keyUpdateRequested    uint8 = 1
+)
+
+// helloRetryRequestRandom is the value of the ServerHello random field that
+// marks the message as a HelloRetryRequest. See RFC 8446, section 4.1.3.
+var helloRetryRequestRandom = []byte{
+	0xCF, 0x21, 0xAD, 0x74, 0xE5, 0x9A, 0x61, 0x11,
+	0xBE, 0x1D, 0x8C, 0x02, 0x1E, 0x65, 0xB8, 0x91,
+	0xC2, 0xA2, 0x11, 0x16, 0x7A, 0xBB, 0x8C, 0x5E,
+	0x07, 0x9E, 0x09, 0xE2, 0xC8, 0xA8, 0x33, 0x9C,
+}
+
+// Downgrade protection sentinels, written by a TLS 1.3 capable server into
+// the last eight bytes of the ServerHello random when it negotiates an older
+// version. See RFC 8446, section 4.1.3.
+const (
+	downgradeCanaryTLS12 = "DOWNGRD\x01"
+	downgradeCanaryTLS11 = "DOWNGRD\x00"
+)
+
 // TLS CertificateStatusType (RFC 3546)
 const (
 	statusTypeOCSP uint8 = 1
@@ -131,6 +177,7 @@ const (
 	hashSHA1   uint8 = 2
 	hashSHA256 uint8 = 4
 	hashSHA384 uint8 = 5
+	hashSHA512 uint8 = 6
 )
 
 // Signature algorithms for TLS 1.2 (See RFC 5246, section A.4.1)
@@ -139,6 +186,16 @@ const (
 	signatureECDSA uint8 = 3
 )
 
+// signatureEd25519 is the TLS 1.2 SignatureAlgorithm code point of Ed25519,
+// from RFC 8422, section 5.1.3. This package only negotiates Ed25519 in TLS
+// 1.3, where it is the {0x08, 0x07} SignatureScheme.
+const signatureEd25519 uint8 = 7
+
+// signatureRSAPSS is the internal signature type of the RSASSA-PSS
+// SignatureSchemes. It has no TLS 1.2 SignatureAlgorithm code point: on the
+// wire those schemes are encoded as {0x08, 0x04-0x06}.
+const signatureRSAPSS uint8 = 0xf8
+
 // signatureAndHash mirrors the TLS 1.2, SignatureAndHashAlgorithm struct. See
 // RFC 5246, section A.4.1.
 type signatureAndHash struct {
@@ -157,6 +214,26 @@ var supportedSignatureAlgorithms = []signatureAndHash{
 	{hashSHA1, signatureECDSA},
 }
 
+// supportedSignatureAlgorithmsTLS13 is advertised instead of
+// supportedSignatureAlgorithms by a ClientHello that offers TLS 1.3, which
+// requires RSASSA-PSS for RSA certificates and ECDSA with SHA-512 for P-521
+// ones, and adds Ed25519. A TLS 1.2 server may select any of these and the
+// client verifies them accordingly; see verifyHandshakeSignature.
+var supportedSignatureAlgorithmsTLS13 = []signatureAndHash{
+	{0x08, 0x04}, // PSSWithSHA256
+	{hashSHA256, signatureECDSA},
+	{0x08, 0x05}, // PSSWithSHA384
+	{hashSHA384, signatureECDSA},
+	{0x08, 0x06}, // PSSWithSHA512
+	{hashSHA512, signatureECDSA},
+	{0x08, signatureEd25519}, // Ed25519
+	{hashSHA256, signatureRSA},
+	{hashSHA384, signatureRSA},
+	{hashSHA512, signatureRSA},
+	{hashSHA1, signatureRSA},
+	{hashSHA1, signatureECDSA},
+}
+
 // ConnectionState records basic TLS details about the connection.
 type ConnectionState struct {
 	Version                     uint16                // TLS version used by the connection (e.g. VersionTLS12)
@@ -176,7 +253,8 @@ type ConnectionState struct {
 	// because resumption does not include enough context (see
 	// https://mitls.org/pages/attacks/3SHAKE#channelbindings). This will
 	// change in future versions of Go once the TLS master-secret fix has
-	// been standardized and implemented.
+	// been standardized and implemented. It is also nil for TLS 1.3
+	// connections, for which tls-unique is not defined.
 	TLSUnique []byte
 }
 
@@ -198,9 +276,14 @@ type ClientSessionState struct {
 	sessionTicket      []uint8               // Encrypted ticket used for session resumption with server
 	vers               uint16                // SSL/TLS version negotiated for the session
 	cipherSuite        uint16                // Ciphersuite negotiated for the session
-	masterSecret       []byte                // MasterSecret generated by client on a full handshake
+	masterSecret       []byte                // Full handshake MasterSecret, or TLS 1.3 resumption PSK
 	serverCertificates []*x509.Certificate   // Certificate chain presented by the server
 	verifiedChains     [][]*x509.Certificate // Certificate chains we built for verification
+
+	// TLS 1.3 fields.
+	receivedAt time.Time // When the session ticket was received from the server
+	useBy      time.Time // Expiration time of the session ticket, from its lifetime
+	ageAdd     uint32    // Random value used to obscure the ticket age
 }
 
 // ClientSessionCache is a cache of ClientSessionState objects that can be used
@@ -234,6 +317,9 @@ const (
 	ECDSAWithP256AndSHA256 SignatureScheme = 0x0403
 	ECDSAWithP384AndSHA384 SignatureScheme = 0x0503
 	ECDSAWithP521AndSHA512 SignatureScheme = 0x0603
+
+	// EdDSA algorithms.
+	Ed25519 SignatureScheme = 0x0807
 )
 
 // ClientHelloInfo contains information from a ClientHello message in order to
@@ -416,6 +502,16 @@ type Config struct {
 	// the verifiedChains argument will always be nil.
 	VerifyPeerCertificate func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error
 
+	// VerifyOCSPStaple, if true, makes a client check the OCSP response
+	// stapled by the server against the verified chain. The handshake is
+	// aborted if the response can't be parsed, isn't signed by the
+	// issuer of the server's certificate or a responder it delegated to,
+	// isn't current, or doesn't report the certificate as good. A missing
+	// response is only an error if the certificate requires one with the
+	// TLS Feature extension (RFC 7633). The check is skipped when
+	// InsecureSkipVerify is set.
+	VerifyOCSPStaple bool
+
 	// RootCAs defines the set of root certificate authorities
 	// that clients use when verifying server certificates.
 	// If RootCAs is nil, TLS uses the host's root CA set.
@@ -447,8 +543,9 @@ type Config struct {
 	// This should be used only for testing.
 	InsecureSkipVerify bool
 
-	// CipherSuites is a list of supported cipher suites. If CipherSuites
-	// is nil, TLS uses a list of suites supported by the implementation.
+	// CipherSuites is a list of supported cipher suites for TLS versions up
+	// to TLS 1.2. If CipherSuites is nil, TLS uses a list of suites
+	// supported by the implementation.
 	CipherSuites []uint16
 
 	// PreferServerCipherSuites controls whether the server selects the
@@ -462,7 +559,8 @@ type Config struct {
 	SessionTicketsDisabled bool
 
 	// SessionTicketKey is used by TLS servers to provide session
-	// resumption. See RFC 5077. If zero, it will be filled with
+	// resumption. See RFC 5077 and the PSK mode of RFC 8446. If zero, it
+	// will be filled with
 	// random data before the first server handshake.
 	//
 	// If multiple servers are terminating connections for the same host
@@ -481,7 +579,10 @@ type Config struct {
 
 	// MaxVersion contains the maximum SSL/TLS version that is acceptable.
 	// If zero, then the maximum version supported by this package is used,
-	// which is currently TLS 1.2.
+	// which is currently TLS 1.3.
+	//
+	// TLS 1.3 cipher suites are not configurable: CipherSuites only applies
+	// to earlier versions.
 	MaxVersion uint16
 
 	// CurvePreferences contains the elliptic curves that will be used in
@@ -563,6 +664,7 @@ func (c *Config) Clone() *Config {
 		GetClientCertificate:        c.GetClientCertificate,
 		GetConfigForClient:          c.GetConfigForClient,
 		VerifyPeerCertificate:       c.VerifyPeerCertificate,
+		VerifyOCSPStaple:            c.VerifyOCSPStaple,
 		RootCAs:                     c.RootCAs,
 		NextProtos:                  c.NextProtos,
 		ServerName:                  c.ServerName,
@@ -694,19 +796,53 @@ func (c *Config) curvePreferences() []CurveID {
 	return c.CurvePreferences
 }
 
-// mutualVersion returns the protocol version to use given the advertised
-// version of the peer.
-func (c *Config) mutualVersion(vers uint16) (uint16, bool) {
-	minVersion := c.minVersion()
-	maxVersion := c.maxVersion()
+var supportedVersions = []uint16{
+	VersionTLS13,
+	VersionTLS12,
+	VersionTLS11,
+	VersionTLS10,
+	VersionSSL30,
+}
 
-	if vers < minVersion {
-		return 0, false
+// supportedVersions returns the protocol versions enabled by c, in
+// preference order.
+func (c *Config) supportedVersions() []uint16 {
+	versions := make([]uint16, 0, len(supportedVersions))
+	for _, v := range supportedVersions {
+		if v < c.minVersion() || v > c.maxVersion() {
+			continue
+		}
+		versions = append(versions, v)
 	}
-	if vers > maxVersion {
-		vers = maxVersion
+	return versions
+}
+
+// supportedVersionsFromMax returns the versions implied by a legacy version
+// field, which only signals the maximum supported version. TLS 1.3 can't be
+// negotiated this way, so it's never included.
+func supportedVersionsFromMax(maxVersion uint16) []uint16 {
+	versions := make([]uint16, 0, len(supportedVersions))
+	for _, v := range supportedVersions {
+		if v > maxVersion || v >= VersionTLS13 {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+// mutualVersion returns the protocol version to use given the versions
+// advertised by the peer, in the peer's order of preference.
+func (c *Config) mutualVersion(peerVersions []uint16) (uint16, bool) {
+	supportedVersions := c.supportedVersions()
+	for _, peerVersion := range peerVersions {
+		for _, v := range supportedVersions {
+			if v == peerVersion {
+				return v, true
+			}
+		}
 	}
-	return vers, true
+	return 0, false
 }
 
 // getCertificate returns the best certificate for the given ClientHelloInfo,
@@ -773,14 +909,24 @@ func (c *Config) BuildNameToCertificate() {
 	}
 }
 
-// writeKeyLog logs client random and master secret if logging was enabled by
-// setting c.KeyLogWriter.
-func (c *Config) writeKeyLog(clientRandom, masterSecret []byte) error {
+// Labels for the lines written to Config.KeyLogWriter. The TLS 1.3 ones name
+// the traffic secrets of RFC 8446, section 7.1.
+const (
+	keyLogLabelTLS12           = "CLIENT_RANDOM"
+	keyLogLabelClientHandshake = "CLIENT_HANDSHAKE_TRAFFIC_SECRET"
+	keyLogLabelServerHandshake = "SERVER_HANDSHAKE_TRAFFIC_SECRET"
+	keyLogLabelClientTraffic   = "CLIENT_TRAFFIC_SECRET_0"
+	keyLogLabelServerTraffic   = "SERVER_TRAFFIC_SECRET_0"
+)
+
+// writeKeyLog logs client random and a secret under the given label if
+// logging was enabled by setting c.KeyLogWriter.
+func (c *Config) writeKeyLog(label string, clientRandom, secret []byte) error {
 	if c.KeyLogWriter == nil {
 		return nil
 	}
 
-	logLine := []byte(fmt.Sprintf("CLIENT_RANDOM %x %x\n", clientRandom, masterSecret))
+	logLine := []byte(fmt.Sprintf("%s %x %x\n", label, clientRandom, secret))
 
 	writerMutex.Lock()
 	_, err := c.KeyLogWriter.Write(logLine)
@@ -905,8 +1051,9 @@ func defaultConfig() *Config {
 }
 
 var (
-	once                   sync.Once
-	varDefaultCipherSuites []uint16
+	once                        sync.Once
+	varDefaultCipherSuites      []uint16
+	varDefaultCipherSuitesTLS13 []uint16
 )
 
 func defaultCipherSuites() []uint16 {
@@ -914,8 +1061,29 @@ func defaultCipherSuites() []uint16 {
 	return varDefaultCipherSuites
 }
 
+// defaultCipherSuitesTLS13 returns the TLS 1.3 cipher suites in the order
+// they are offered by clients and preferred by servers.
+func defaultCipherSuitesTLS13() []uint16 {
+	once.Do(initDefaultCipherSuites)
+	return varDefaultCipherSuitesTLS13
+}
+
 func initDefaultCipherSuites() {
 	var topCipherSuites []uint16
+	if cipherhw.AESGCMSupport() {
+		varDefaultCipherSuitesTLS13 = []uint16{
+			TLS_AES_128_GCM_SHA256,
+			TLS_CHACHA20_POLY1305_SHA256,
+			TLS_AES_256_GCM_SHA384,
+		}
+	} else {
+		varDefaultCipherSuitesTLS13 = []uint16{
+			TLS_CHACHA20_POLY1305_SHA256,
+			TLS_AES_128_GCM_SHA256,
+			TLS_AES_256_GCM_SHA384,
+		}
+	}
+
 	if cipherhw.AESGCMSupport() {
 		// If AES-GCM hardware is provided then prioritise AES-GCM
 		// cipher suites.
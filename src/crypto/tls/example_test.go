@@ -111,5 +111,8 @@ func ExampleConfig_keyLogWriter() {
 	// preferences.
 
 	// Output:
-	// CLIENT_RANDOM 0000000000000000000000000000000000000000000000000000000000000000 baca0df460a688e44ce018b025183cc2353ae01f89755ef766eedd3ecc302888ee3b3a22962e45f48c20df15a98c0e80
+	// CLIENT_HANDSHAKE_TRAFFIC_SECRET 0000000000000000000000000000000000000000000000000000000000000000 e5a3cc36163c596758d2b8a8f9fec08c848ce4217a14ae5db41f4434c211234f
+	// SERVER_HANDSHAKE_TRAFFIC_SECRET 0000000000000000000000000000000000000000000000000000000000000000 495554b41e04e91b1a23b29a46efe78c6c4c11499ca9104be0cda68272e1bec7
+	// CLIENT_TRAFFIC_SECRET_0 0000000000000000000000000000000000000000000000000000000000000000 cd5eb1e5d14f46e3baf29ac4fcbf45159e700db3427cb8adf59ce1d4c745d0e1
+	// SERVER_TRAFFIC_SECRET_0 0000000000000000000000000000000000000000000000000000000000000000 ab4d3253f1a2988b8d163ea1c4d799f657d1725fc588bcd983c1b6069f0604a7
 }
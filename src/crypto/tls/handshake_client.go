@@ -7,16 +7,21 @@ package tls
 import (
 	"bytes"
 	"crypto"
+	"crypto/ecdh"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/subtle"
 	"crypto/x509"
+	"crypto/x509/ocsp"
+	"encoding/asn1"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type clientHandshakeState struct {
@@ -29,26 +34,39 @@ type clientHandshakeState struct {
 	session      *ClientSessionState
 }
 
-func makeClientHello(config *Config) (*clientHelloMsg, error) {
+func (c *Conn) makeClientHello() (*clientHelloMsg, *ecdh.PrivateKey, error) {
+	config := c.config
 	if len(config.ServerName) == 0 && !config.InsecureSkipVerify {
-		return nil, errors.New("tls: either ServerName or InsecureSkipVerify must be specified in the tls.Config")
+		return nil, nil, errors.New("tls: either ServerName or InsecureSkipVerify must be specified in the tls.Config")
 	}
 
 	nextProtosLength := 0
 	for _, proto := range config.NextProtos {
 		if l := len(proto); l == 0 || l > 255 {
-			return nil, errors.New("tls: invalid NextProtos value")
+			return nil, nil, errors.New("tls: invalid NextProtos value")
 		} else {
 			nextProtosLength += 1 + l
 		}
 	}
-
 	if nextProtosLength > 0xffff {
-		return nil, errors.New("tls: NextProtos values too large")
+		return nil, nil, errors.New("tls: NextProtos values too large")
+	}
+
+	supportedVersions := config.supportedVersions()
+	if len(supportedVersions) == 0 {
+		return nil, nil, errors.New("tls: no supported versions satisfy MinVersion and MaxVersion")
+	}
+
+	clientHelloVersion := supportedVersions[0]
+	// The version at the beginning of the ClientHello was capped at TLS 1.2
+	// for compatibility reasons. The supported_versions extension is used
+	// to negotiate versions now. See RFC 8446, section 4.2.1.
+	if clientHelloVersion > VersionTLS12 {
+		clientHelloVersion = VersionTLS12
 	}
 
 	hello := &clientHelloMsg{
-		vers:                         config.maxVersion(),
+		vers:                         clientHelloVersion,
 		compressionMethods:           []uint8{compressionNone},
 		random:                       make([]byte, 32),
 		ocspStapling:                 true,
@@ -60,6 +78,11 @@ func makeClientHello(config *Config) (*clientHelloMsg, error) {
 		secureRenegotiationSupported: true,
 		alpnProtocols:                config.NextProtos,
 	}
+
+	if c.handshakes > 0 {
+		hello.secureRenegotiation = c.clientFinished[:]
+	}
+
 	possibleCipherSuites := config.cipherSuites()
 	hello.cipherSuites = make([]uint16, 0, len(possibleCipherSuites))
 
@@ -81,14 +104,40 @@ NextCipherSuite:
 
 	_, err := io.ReadFull(config.rand(), hello.random)
 	if err != nil {
-		return nil, errors.New("tls: short read from Rand: " + err.Error())
+		return nil, nil, errors.New("tls: short read from Rand: " + err.Error())
 	}
 
 	if hello.vers >= VersionTLS12 {
 		hello.signatureAndHashes = supportedSignatureAlgorithms
 	}
 
-	return hello, nil
+	// TLS 1.3 is never offered in a renegotiation, which it doesn't support.
+	var key *ecdh.PrivateKey
+	if supportedVersions[0] == VersionTLS13 && c.handshakes == 0 {
+		hello.supportedVersions = supportedVersions
+		hello.cipherSuites = append(append([]uint16(nil), defaultCipherSuitesTLS13()...), hello.cipherSuites...)
+		hello.signatureAndHashes = supportedSignatureAlgorithmsTLS13
+
+		// A non-empty legacy session ID makes the handshake look like a
+		// TLS 1.2 resumption to middleboxes. See RFC 8446, Appendix D.4.
+		hello.sessionId = make([]byte, 32)
+		if _, err := io.ReadFull(config.rand(), hello.sessionId); err != nil {
+			return nil, nil, errors.New("tls: short read from Rand: " + err.Error())
+		}
+
+		curveID := config.curvePreferences()[0]
+		if _, ok := curveForCurveID(curveID); !ok {
+			return nil, nil, errors.New("tls: CurvePreferences includes unsupported curve")
+		}
+		key, err = generateECDHEKey(config.rand(), curveID)
+		if err != nil {
+			return nil, nil, err
+		}
+		hello.keyShares = []keyShare{{group: curveID, data: key.PublicKey().Bytes()}}
+		hello.pskModes = []uint8{pskModeDHE}
+	}
+
+	return hello, key, nil
 }
 
 // c.out.Mutex <= L; c.handshakeMutex <= L.
@@ -101,109 +150,186 @@ func (c *Conn) clientHandshake() error {
 	// need to be reset.
 	c.didResume = false
 
-	hello, err := makeClientHello(c.config)
+	hello, ecdheKey, err := c.makeClientHello()
 	if err != nil {
 		return err
 	}
 
-	if c.handshakes > 0 {
-		hello.secureRenegotiation = c.clientFinished[:]
+	cacheKey, session, earlySecret, binderKey, err := c.loadSession(hello)
+	if err != nil {
+		return err
 	}
 
-	var session *ClientSessionState
-	var cacheKey string
-	sessionCache := c.config.ClientSessionCache
-	if c.config.SessionTicketsDisabled {
-		sessionCache = nil
+	if _, err := c.writeRecord(recordTypeHandshake, hello.marshal()); err != nil {
+		return err
 	}
 
-	if sessionCache != nil {
-		hello.ticketSupported = true
+	msg, err := c.readHandshake()
+	if err != nil {
+		return err
 	}
 
-	// Session resumption is not allowed if renegotiating because
-	// renegotiation is primarily used to allow a client to send a client
-	// certificate, which would be skipped if session resumption occurred.
-	if sessionCache != nil && c.handshakes == 0 {
-		// Try to resume a previously negotiated TLS session, if
-		// available.
-		cacheKey = clientSessionCacheKey(c.conn.RemoteAddr(), c.config)
-		candidateSession, ok := sessionCache.Get(cacheKey)
-		if ok {
-			// Check that the ciphersuite/version used for the
-			// previous session are still valid.
-			cipherSuiteOk := false
-			for _, id := range hello.cipherSuites {
-				if id == candidateSession.cipherSuite {
-					cipherSuiteOk = true
-					break
-				}
-			}
+	serverHello, ok := msg.(*serverHelloMsg)
+	if !ok {
+		c.sendAlert(alertUnexpectedMessage)
+		return unexpectedMessageError(serverHello, msg)
+	}
 
-			versOk := candidateSession.vers >= c.config.minVersion() &&
-				candidateSession.vers <= c.config.maxVersion()
-			if versOk && cipherSuiteOk {
-				session = candidateSession
-			}
-		}
+	if err := c.pickTLSVersion(hello, serverHello); err != nil {
+		return err
 	}
 
-	if session != nil {
-		hello.sessionTicket = session.sessionTicket
-		// A random session ID is used to detect when the
-		// server accepted the ticket and is resuming a session
-		// (see RFC 5077).
-		hello.sessionId = make([]byte, 16)
-		if _, err := io.ReadFull(c.config.rand(), hello.sessionId); err != nil {
-			return errors.New("tls: short read from Rand: " + err.Error())
+	if c.vers == VersionTLS13 {
+		hs := &clientHandshakeStateTLS13{
+			c:           c,
+			serverHello: serverHello,
+			hello:       hello,
+			ecdheKey:    ecdheKey,
+			session:     session,
+			earlySecret: earlySecret,
+			binderKey:   binderKey,
 		}
+
+		// In TLS 1.3, session tickets are delivered after the handshake.
+		return hs.handshake()
+	}
+
+	// If we are negotiating a protocol version that's lower than what we
+	// support, check for the server downgrade canaries.
+	// See RFC 8446, section 4.1.3.
+	maxVers := c.config.maxVersion()
+	tls12Downgrade := string(serverHello.random[24:]) == downgradeCanaryTLS12
+	tls11Downgrade := string(serverHello.random[24:]) == downgradeCanaryTLS11
+	if maxVers == VersionTLS13 && c.vers <= VersionTLS12 && (tls12Downgrade || tls11Downgrade) ||
+		maxVers == VersionTLS12 && c.vers <= VersionTLS11 && tls11Downgrade {
+		c.sendAlert(alertIllegalParameter)
+		return errors.New("tls: downgrade attempt detected, possibly due to a MitM attack or a broken middlebox")
 	}
 
 	hs := &clientHandshakeState{
-		c:       c,
-		hello:   hello,
-		session: session,
+		c:           c,
+		serverHello: serverHello,
+		hello:       hello,
+		session:     session,
 	}
 
-	if err = hs.handshake(); err != nil {
+	if err := hs.handshake(); err != nil {
 		return err
 	}
 
 	// If we had a successful handshake and hs.session is different from
-	// the one already cached - cache a new one
-	if sessionCache != nil && hs.session != nil && session != hs.session {
-		sessionCache.Put(cacheKey, hs.session)
+	// the one already cached - cache a new one.
+	if cacheKey != "" && hs.session != nil && session != hs.session {
+		c.config.ClientSessionCache.Put(cacheKey, hs.session)
 	}
 
 	return nil
 }
 
-// Does the handshake, either a full one or resumes old session.
-// Requires hs.c, hs.hello, and, optionally, hs.session to be set.
-func (hs *clientHandshakeState) handshake() error {
-	c := hs.c
+// loadSession looks up a session to resume in the ClientSessionCache and
+// adds the corresponding ticket to hello. For TLS 1.3 sessions it also
+// computes the PSK binder and returns the early secret and binder key.
+func (c *Conn) loadSession(hello *clientHelloMsg) (cacheKey string,
+	session *ClientSessionState, earlySecret, binderKey []byte, err error) {
+	if c.config.SessionTicketsDisabled || c.config.ClientSessionCache == nil {
+		return "", nil, nil, nil, nil
+	}
 
-	// send ClientHello
-	if _, err := c.writeRecord(recordTypeHandshake, hs.hello.marshal()); err != nil {
-		return err
+	hello.ticketSupported = true
+
+	// Session resumption is not allowed if renegotiating because
+	// renegotiation is primarily used to allow a client to send a client
+	// certificate, which would be skipped if session resumption occurred.
+	if c.handshakes != 0 {
+		return "", nil, nil, nil, nil
 	}
 
-	msg, err := c.readHandshake()
-	if err != nil {
-		return err
+	// Try to resume a previously negotiated TLS session, if available.
+	cacheKey = clientSessionCacheKey(c.conn.RemoteAddr(), c.config)
+	session, ok := c.config.ClientSessionCache.Get(cacheKey)
+	if !ok {
+		return cacheKey, nil, nil, nil, nil
 	}
 
-	var ok bool
-	if hs.serverHello, ok = msg.(*serverHelloMsg); !ok {
-		c.sendAlert(alertUnexpectedMessage)
-		return unexpectedMessageError(hs.serverHello, msg)
+	// Check that version used for the previous session is still valid.
+	versOk := false
+	for _, v := range hello.supportedVersions {
+		if v == session.vers {
+			versOk = true
+			break
+		}
+	}
+	if len(hello.supportedVersions) == 0 {
+		versOk = session.vers >= c.config.minVersion() &&
+			session.vers <= c.config.maxVersion()
+	}
+	if !versOk {
+		return cacheKey, nil, nil, nil, nil
 	}
 
-	if err = hs.pickTLSVersion(); err != nil {
-		return err
+	// Check that the cipher suite used for the previous session is still
+	// offered.
+	cipherSuiteOk := false
+	for _, id := range hello.cipherSuites {
+		if id == session.cipherSuite {
+			cipherSuiteOk = true
+			break
+		}
+	}
+	if !cipherSuiteOk {
+		return cacheKey, nil, nil, nil, nil
 	}
 
-	if err = hs.pickCipherSuite(); err != nil {
+	if session.vers != VersionTLS13 {
+		hello.sessionTicket = session.sessionTicket
+		// A random session ID is used to detect when the server
+		// accepted the ticket and is resuming a session (see RFC
+		// 5077). In TLS 1.3 the legacy session ID is already set.
+		if hello.sessionId == nil {
+			hello.sessionId = make([]byte, 16)
+			if _, err := io.ReadFull(c.config.rand(), hello.sessionId); err != nil {
+				return "", nil, nil, nil, errors.New("tls: short read from Rand: " + err.Error())
+			}
+		}
+		return cacheKey, session, nil, nil, nil
+	}
+
+	// Check that the session ticket is not expired.
+	if c.config.time().After(session.useBy) {
+		return cacheKey, nil, nil, nil, nil
+	}
+
+	cipherSuite := cipherSuiteTLS13ByID(session.cipherSuite)
+	if cipherSuite == nil {
+		return cacheKey, nil, nil, nil, nil
+	}
+
+	// Set the pre_shared_key extension. See RFC 8446, section 4.2.11.1.
+	ticketAge := uint32(c.config.time().Sub(session.receivedAt) / time.Millisecond)
+	identity := pskIdentity{
+		label:               session.sessionTicket,
+		obfuscatedTicketAge: ticketAge + session.ageAdd,
+	}
+	hello.pskIdentities = []pskIdentity{identity}
+	hello.pskBinders = [][]byte{make([]byte, cipherSuite.hash.Size())}
+
+	// Compute the PSK binders. See RFC 8446, section 4.2.11.2.
+	earlySecret = cipherSuite.extract(session.masterSecret, nil)
+	binderKey = cipherSuite.deriveSecret(earlySecret, resumptionBinderLabel, nil)
+	transcript := cipherSuite.hash.New()
+	transcript.Write(hello.marshalWithoutBinders())
+	pskBinders := [][]byte{cipherSuite.finishedHash(binderKey, transcript)}
+	hello.updateBinders(pskBinders)
+
+	return cacheKey, session, earlySecret, binderKey, nil
+}
+
+// Does the handshake, either a full one or resumes old session. Requires hs.c,
+// hs.hello, hs.serverHello, and, optionally, hs.session to be set.
+func (hs *clientHandshakeState) handshake() error {
+	c := hs.c
+
+	if err := hs.pickCipherSuite(); err != nil {
 		return err
 	}
 
@@ -271,16 +397,30 @@ func (hs *clientHandshakeState) handshake() error {
 	return nil
 }
 
-func (hs *clientHandshakeState) pickTLSVersion() error {
-	vers, ok := hs.c.config.mutualVersion(hs.serverHello.vers)
+func (c *Conn) pickTLSVersion(hello *clientHelloMsg, serverHello *serverHelloMsg) error {
+	peerVersion := serverHello.vers
+	if serverHello.supportedVersion != 0 {
+		peerVersion = serverHello.supportedVersion
+	}
+
+	// The supported_versions extension can only select TLS 1.3 or later,
+	// and only if we offered it. See RFC 8446, section 4.2.1.
+	offered13 := len(hello.supportedVersions) > 0
+	if serverHello.supportedVersion != 0 && (peerVersion < VersionTLS13 || !offered13) ||
+		peerVersion == VersionTLS13 && !offered13 {
+		c.sendAlert(alertIllegalParameter)
+		return fmt.Errorf("tls: server selected an invalid version %x", peerVersion)
+	}
+
+	vers, ok := c.config.mutualVersion([]uint16{peerVersion})
 	if !ok || vers < VersionTLS10 {
 		// TLS 1.0 is the minimum version supported as a client.
-		hs.c.sendAlert(alertProtocolVersion)
-		return fmt.Errorf("tls: server selected unsupported protocol version %x", hs.serverHello.vers)
+		c.sendAlert(alertProtocolVersion)
+		return fmt.Errorf("tls: server selected unsupported protocol version %x", peerVersion)
 	}
 
-	hs.c.vers = vers
-	hs.c.haveVers = true
+	c.vers = vers
+	c.haveVers = true
 
 	return nil
 }
@@ -312,53 +452,9 @@ func (hs *clientHandshakeState) doFullHandshake() error {
 	if c.handshakes == 0 {
 		// If this is the first handshake on a connection, process and
 		// (optionally) verify the server's certificates.
-		certs := make([]*x509.Certificate, len(certMsg.certificates))
-		for i, asn1Data := range certMsg.certificates {
-			cert, err := x509.ParseCertificate(asn1Data)
-			if err != nil {
-				c.sendAlert(alertBadCertificate)
-				return errors.New("tls: failed to parse certificate from server: " + err.Error())
-			}
-			certs[i] = cert
-		}
-
-		if !c.config.InsecureSkipVerify {
-			opts := x509.VerifyOptions{
-				Roots:         c.config.RootCAs,
-				CurrentTime:   c.config.time(),
-				DNSName:       c.config.ServerName,
-				Intermediates: x509.NewCertPool(),
-			}
-
-			for i, cert := range certs {
-				if i == 0 {
-					continue
-				}
-				opts.Intermediates.AddCert(cert)
-			}
-			c.verifiedChains, err = certs[0].Verify(opts)
-			if err != nil {
-				c.sendAlert(alertBadCertificate)
-				return err
-			}
-		}
-
-		if c.config.VerifyPeerCertificate != nil {
-			if err := c.config.VerifyPeerCertificate(certMsg.certificates, c.verifiedChains); err != nil {
-				c.sendAlert(alertBadCertificate)
-				return err
-			}
-		}
-
-		switch certs[0].PublicKey.(type) {
-		case *rsa.PublicKey, *ecdsa.PublicKey:
-			break
-		default:
-			c.sendAlert(alertUnsupportedCertificate)
-			return fmt.Errorf("tls: server's certificate contains an unsupported type of public key: %T", certs[0].PublicKey)
+		if err := c.verifyServerCertificate(certMsg.certificates); err != nil {
+			return err
 		}
-
-		c.peerCertificates = certs
 	} else {
 		// This is a renegotiation handshake. We require that the
 		// server's identity (i.e. leaf certificate) is unchanged and
@@ -389,6 +485,10 @@ func (hs *clientHandshakeState) doFullHandshake() error {
 		}
 	}
 
+	if err := c.verifyOCSPStaple(); err != nil {
+		return err
+	}
+
 	msg, err = c.readHandshake()
 	if err != nil {
 		return err
@@ -418,7 +518,7 @@ func (hs *clientHandshakeState) doFullHandshake() error {
 		certRequested = true
 		hs.finishedHash.Write(certReq.marshal())
 
-		if chainToSend, err = hs.getCertificate(certReq); err != nil {
+		if chainToSend, err = c.getClientCertificate(certReq); err != nil {
 			c.sendAlert(alertInternalError)
 			return err
 		}
@@ -505,7 +605,7 @@ func (hs *clientHandshakeState) doFullHandshake() error {
 	}
 
 	hs.masterSecret = masterFromPreMasterSecret(c.vers, hs.suite, preMasterSecret, hs.hello.random, hs.serverHello.random)
-	if err := c.config.writeKeyLog(hs.hello.random, hs.masterSecret); err != nil {
+	if err := c.config.writeKeyLog(keyLogLabelTLS12, hs.hello.random, hs.masterSecret); err != nil {
 		c.sendAlert(alertInternalError)
 		return errors.New("tls: failed to write to key log: " + err.Error())
 	}
@@ -515,6 +615,137 @@ func (hs *clientHandshakeState) doFullHandshake() error {
 	return nil
 }
 
+// verifyServerCertificate parses and verifies the provided chain, setting
+// c.verifiedChains and c.peerCertificates or sending the appropriate alert.
+func (c *Conn) verifyServerCertificate(certificates [][]byte) error {
+	certs := make([]*x509.Certificate, len(certificates))
+	for i, asn1Data := range certificates {
+		cert, err := x509.ParseCertificate(asn1Data)
+		if err != nil {
+			c.sendAlert(alertBadCertificate)
+			return errors.New("tls: failed to parse certificate from server: " + err.Error())
+		}
+		certs[i] = cert
+	}
+
+	if !c.config.InsecureSkipVerify {
+		opts := x509.VerifyOptions{
+			Roots:         c.config.RootCAs,
+			CurrentTime:   c.config.time(),
+			DNSName:       c.config.ServerName,
+			Intermediates: x509.NewCertPool(),
+		}
+
+		for i, cert := range certs {
+			if i == 0 {
+				continue
+			}
+			opts.Intermediates.AddCert(cert)
+		}
+		var err error
+		c.verifiedChains, err = certs[0].Verify(opts)
+		if err != nil {
+			c.sendAlert(alertBadCertificate)
+			return err
+		}
+	}
+
+	if c.config.VerifyPeerCertificate != nil {
+		if err := c.config.VerifyPeerCertificate(certificates, c.verifiedChains); err != nil {
+			c.sendAlert(alertBadCertificate)
+			return err
+		}
+	}
+
+	switch certs[0].PublicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		break
+	case ed25519.PublicKey:
+		// Ed25519 is only negotiated in TLS 1.3.
+		if c.vers < VersionTLS13 {
+			c.sendAlert(alertUnsupportedCertificate)
+			return errors.New("tls: server's Ed25519 certificate requires TLS 1.3")
+		}
+	default:
+		c.sendAlert(alertUnsupportedCertificate)
+		return fmt.Errorf("tls: server's certificate contains an unsupported type of public key: %T", certs[0].PublicKey)
+	}
+
+	c.peerCertificates = certs
+
+	return nil
+}
+
+// oidExtensionTLSFeature is the TLS Feature extension from RFC 7633.
+var oidExtensionTLSFeature = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 24}
+
+// mustStaple reports whether cert carries a TLS Feature extension that
+// requires the status_request extension, also known as OCSP Must-Staple.
+func mustStaple(cert *x509.Certificate) bool {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidExtensionTLSFeature) {
+			continue
+		}
+		var features []int
+		if rest, err := asn1.Unmarshal(ext.Value, &features); err != nil || len(rest) != 0 {
+			// A malformed extension is treated as requiring a staple.
+			return true
+		}
+		for _, feature := range features {
+			if feature == int(extensionStatusRequest) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// verifyOCSPStaple checks the stapled OCSP response, if any, against the
+// verified chain when Config.VerifyOCSPStaple is set, sending the
+// appropriate alert on failure.
+func (c *Conn) verifyOCSPStaple() error {
+	if !c.config.VerifyOCSPStaple || len(c.verifiedChains) == 0 {
+		return nil
+	}
+
+	chain := c.verifiedChains[0]
+	leaf, issuer := chain[0], chain[0]
+	if len(chain) > 1 {
+		issuer = chain[1]
+	}
+
+	if len(c.ocspResponse) == 0 {
+		if mustStaple(leaf) {
+			c.sendAlert(alertBadCertStatusResponse)
+			return errors.New("tls: server's certificate requires a stapled OCSP response but none was provided")
+		}
+		return nil
+	}
+
+	resp, err := ocsp.ParseResponseForCert(c.ocspResponse, leaf, issuer)
+	if err != nil {
+		c.sendAlert(alertBadCertStatusResponse)
+		return errors.New("tls: invalid stapled OCSP response: " + err.Error())
+	}
+
+	now := c.config.time()
+	if now.Before(resp.ThisUpdate) || (!resp.NextUpdate.IsZero() && now.After(resp.NextUpdate)) {
+		c.sendAlert(alertBadCertStatusResponse)
+		return errors.New("tls: stapled OCSP response is not current")
+	}
+
+	switch resp.Status {
+	case ocsp.Good:
+		return nil
+	case ocsp.Revoked:
+		c.sendAlert(alertCertificateRevoked)
+		return errors.New("tls: server's certificate was revoked at " + resp.RevokedAt.String())
+	default:
+		c.sendAlert(alertBadCertStatusResponse)
+		return errors.New("tls: stapled OCSP response reports an unknown certificate status")
+	}
+}
+
 func (hs *clientHandshakeState) establishKeys() error {
 	c := hs.c
 
@@ -717,10 +948,8 @@ const (
 	tls11SignatureSchemesNumRSA = 4
 )
 
-func (hs *clientHandshakeState) getCertificate(certReq *certificateRequestMsg) (*Certificate, error) {
-	c := hs.c
-
-	var rsaAvail, ecdsaAvail bool
+func (c *Conn) getClientCertificate(certReq *certificateRequestMsg) (*Certificate, error) {
+	var rsaAvail, ecdsaAvail, ed25519Avail bool
 	for _, certType := range certReq.certificateTypes {
 		switch certType {
 		case certTypeRSASign:
@@ -729,6 +958,11 @@ func (hs *clientHandshakeState) getCertificate(certReq *certificateRequestMsg) (
 			ecdsaAvail = true
 		}
 	}
+	// Ed25519 has no certificate type. We only use it in TLS 1.3, if the
+	// server lists it in signature_algorithms.
+	if c.vers >= VersionTLS13 {
+		ed25519Avail = isSupportedSignatureAndHash(signatureAndHash{0x08, signatureEd25519}, certReq.signatureAndHashes)
+	}
 
 	if c.config.GetClientCertificate != nil {
 		var signatureSchemes []SignatureScheme
@@ -774,7 +1008,7 @@ func (hs *clientHandshakeState) getCertificate(certReq *certificateRequestMsg) (
 	// Issuer is in certReq.certificateAuthorities
 findCert:
 	for i, chain := range c.config.Certificates {
-		if !rsaAvail && !ecdsaAvail {
+		if !rsaAvail && !ecdsaAvail && !ed25519Avail {
 			continue
 		}
 
@@ -793,6 +1027,7 @@ findCert:
 			switch {
 			case rsaAvail && x509Cert.PublicKeyAlgorithm == x509.RSA:
 			case ecdsaAvail && x509Cert.PublicKeyAlgorithm == x509.ECDSA:
+			case ed25519Avail && x509Cert.PublicKeyAlgorithm == x509.Ed25519:
 			default:
 				continue findCert
 			}
@@ -7,8 +7,13 @@ package tls
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"crypto/x509/ocsp"
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/pem"
@@ -682,7 +687,7 @@ func TestClientResumption(t *testing.T) {
 	}
 
 	testResumeState := func(test string, didResume bool) {
-		_, hs, err := testHandshake(clientConfig, serverConfig)
+		_, hs, err := testHandshake(t, clientConfig, serverConfig)
 		if err != nil {
 			t.Fatalf("%s: handshake failed: %s", test, err)
 		}
@@ -1241,6 +1246,176 @@ func TestVerifyPeerCertificate(t *testing.T) {
 	}
 }
 
+func TestVerifyOCSPStaple(t *testing.T) {
+	now := time.Unix(1476984729, 0)
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "OCSP Test CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ca)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	createLeaf := func(extensions []pkix.Extension) *x509.Certificate {
+		template := &x509.Certificate{
+			SerialNumber:    big.NewInt(2),
+			Subject:         pkix.Name{CommonName: "example.golang"},
+			DNSNames:        []string{"example.golang"},
+			NotBefore:       now.Add(-time.Hour),
+			NotAfter:        now.Add(time.Hour),
+			KeyUsage:        x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			ExtraExtensions: extensions,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &leafKey.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf
+	}
+	leaf := createLeaf(nil)
+	// A TLS Feature extension listing status_request (5).
+	mustStapleLeaf := createLeaf([]pkix.Extension{{
+		Id:    asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 24},
+		Value: []byte{0x30, 0x03, 0x02, 0x01, 0x05},
+	}})
+
+	createResponse := func(status int, nextUpdate time.Time) []byte {
+		template := ocsp.Response{
+			Status:       status,
+			SerialNumber: leaf.SerialNumber,
+			ThisUpdate:   now.Add(-time.Minute),
+			NextUpdate:   nextUpdate,
+		}
+		if status == ocsp.Revoked {
+			template.RevokedAt = now.Add(-time.Minute)
+			template.RevocationReason = ocsp.KeyCompromise
+		}
+		resp, err := ocsp.CreateResponse(ca, ca, template, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	tests := []struct {
+		name      string
+		leaf      *x509.Certificate
+		staple    []byte
+		disabled  bool
+		wantErr   string
+		wantAlert alert
+	}{
+		{name: "good", leaf: leaf, staple: createResponse(ocsp.Good, now.Add(time.Hour))},
+		{name: "no staple", leaf: leaf},
+		{name: "disabled", leaf: leaf, staple: createResponse(ocsp.Revoked, now.Add(time.Hour)), disabled: true},
+		{
+			name:      "revoked",
+			leaf:      leaf,
+			staple:    createResponse(ocsp.Revoked, now.Add(time.Hour)),
+			wantErr:   "was revoked",
+			wantAlert: alertCertificateRevoked,
+		},
+		{
+			name:      "unknown",
+			leaf:      leaf,
+			staple:    createResponse(ocsp.Unknown, now.Add(time.Hour)),
+			wantErr:   "unknown certificate status",
+			wantAlert: alertBadCertStatusResponse,
+		},
+		{
+			name:      "expired",
+			leaf:      leaf,
+			staple:    createResponse(ocsp.Good, now.Add(-time.Second)),
+			wantErr:   "not current",
+			wantAlert: alertBadCertStatusResponse,
+		},
+		{
+			name:      "malformed",
+			leaf:      leaf,
+			staple:    []byte{0x30, 0x00},
+			wantErr:   "invalid stapled OCSP response",
+			wantAlert: alertBadCertStatusResponse,
+		},
+		{
+			name:      "must staple",
+			leaf:      mustStapleLeaf,
+			wantErr:   "requires a stapled OCSP response",
+			wantAlert: alertBadCertStatusResponse,
+		},
+	}
+
+	for _, version := range []uint16{VersionTLS12, VersionTLS13} {
+		for _, test := range tests {
+			c, s := localPipe(t)
+			done := make(chan error)
+
+			go func() {
+				config := testConfig.Clone()
+				config.MaxVersion = version
+				config.Time = func() time.Time { return now }
+				config.Certificates = []Certificate{{
+					Certificate: [][]byte{test.leaf.Raw},
+					PrivateKey:  leafKey,
+					OCSPStaple:  test.staple,
+				}}
+				err := Server(s, config).Handshake()
+				s.Close()
+				done <- err
+			}()
+
+			config := testConfig.Clone()
+			config.MaxVersion = version
+			config.ServerName = "example.golang"
+			config.RootCAs = rootCAs
+			config.InsecureSkipVerify = false
+			config.VerifyOCSPStaple = !test.disabled
+			config.Time = func() time.Time { return now }
+			clientErr := Client(c, config).Handshake()
+			c.Close()
+			serverErr := <-done
+
+			if test.wantErr == "" {
+				if clientErr != nil {
+					t.Errorf("%x/%s: client handshake failed: %v", version, test.name, clientErr)
+				}
+				continue
+			}
+			if clientErr == nil || !strings.Contains(clientErr.Error(), test.wantErr) {
+				t.Errorf("%x/%s: got client error %v, wanted one containing %q", version, test.name, clientErr, test.wantErr)
+			}
+			if opErr, ok := serverErr.(*net.OpError); !ok || opErr.Err != test.wantAlert {
+				t.Errorf("%x/%s: got server error %v, wanted alert %v", version, test.name, serverErr, test.wantAlert)
+			}
+		}
+	}
+}
+
 // brokenConn wraps a net.Conn and causes all Writes after a certain number to
 // fail with brokenConnErr.
 type brokenConn struct {
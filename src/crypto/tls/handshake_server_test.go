@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rsa"
 	"crypto/x509"
@@ -16,6 +17,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math/big"
 	"net"
 	"os"
@@ -313,13 +315,17 @@ func TestClose(t *testing.T) {
 	}
 }
 
-func testHandshake(clientConfig, serverConfig *Config) (serverState, clientState ConnectionState, err error) {
-	c, s := net.Pipe()
+func testHandshake(t *testing.T, clientConfig, serverConfig *Config) (serverState, clientState ConnectionState, err error) {
+	c, s := localPipe(t)
 	done := make(chan bool)
 	go func() {
 		cli := Client(c, clientConfig)
-		cli.Handshake()
-		clientState = cli.ConnectionState()
+		if cli.Handshake() == nil {
+			clientState = cli.ConnectionState()
+			// Read until the server closes the connection, so that any
+			// TLS 1.3 session tickets reach the client session cache.
+			ioutil.ReadAll(cli)
+		}
 		c.Close()
 		done <- true
 	}()
@@ -327,6 +333,7 @@ func testHandshake(clientConfig, serverConfig *Config) (serverState, clientState
 	err = server.Handshake()
 	if err == nil {
 		serverState = server.ConnectionState()
+		server.Close()
 	}
 	s.Close()
 	<-done
@@ -341,7 +348,7 @@ func TestVersion(t *testing.T) {
 	clientConfig := &Config{
 		InsecureSkipVerify: true,
 	}
-	state, _, err := testHandshake(clientConfig, serverConfig)
+	state, _, err := testHandshake(t, clientConfig, serverConfig)
 	if err != nil {
 		t.Fatalf("handshake failed: %s", err)
 	}
@@ -360,7 +367,7 @@ func TestCipherSuitePreference(t *testing.T) {
 		CipherSuites:       []uint16{TLS_RSA_WITH_AES_128_CBC_SHA, TLS_RSA_WITH_RC4_128_SHA},
 		InsecureSkipVerify: true,
 	}
-	state, _, err := testHandshake(clientConfig, serverConfig)
+	state, _, err := testHandshake(t, clientConfig, serverConfig)
 	if err != nil {
 		t.Fatalf("handshake failed: %s", err)
 	}
@@ -370,7 +377,7 @@ func TestCipherSuitePreference(t *testing.T) {
 	}
 
 	serverConfig.PreferServerCipherSuites = true
-	state, _, err = testHandshake(clientConfig, serverConfig)
+	state, _, err = testHandshake(t, clientConfig, serverConfig)
 	if err != nil {
 		t.Fatalf("handshake failed: %s", err)
 	}
@@ -391,7 +398,7 @@ func TestSCTHandshake(t *testing.T) {
 	clientConfig := &Config{
 		InsecureSkipVerify: true,
 	}
-	_, state, err := testHandshake(clientConfig, serverConfig)
+	_, state, err := testHandshake(t, clientConfig, serverConfig)
 	if err != nil {
 		t.Fatalf("handshake failed: %s", err)
 	}
@@ -420,13 +427,13 @@ func TestCrossVersionResume(t *testing.T) {
 
 	// Establish a session at TLS 1.1.
 	clientConfig.MaxVersion = VersionTLS11
-	_, _, err := testHandshake(clientConfig, serverConfig)
+	_, _, err := testHandshake(t, clientConfig, serverConfig)
 	if err != nil {
 		t.Fatalf("handshake failed: %s", err)
 	}
 
 	// The client session cache now contains a TLS 1.1 session.
-	state, _, err := testHandshake(clientConfig, serverConfig)
+	state, _, err := testHandshake(t, clientConfig, serverConfig)
 	if err != nil {
 		t.Fatalf("handshake failed: %s", err)
 	}
@@ -436,7 +443,7 @@ func TestCrossVersionResume(t *testing.T) {
 
 	// Test that the server will decline to resume at a lower version.
 	clientConfig.MaxVersion = VersionTLS10
-	state, _, err = testHandshake(clientConfig, serverConfig)
+	state, _, err = testHandshake(t, clientConfig, serverConfig)
 	if err != nil {
 		t.Fatalf("handshake failed: %s", err)
 	}
@@ -445,7 +452,7 @@ func TestCrossVersionResume(t *testing.T) {
 	}
 
 	// The client session cache now contains a TLS 1.0 session.
-	state, _, err = testHandshake(clientConfig, serverConfig)
+	state, _, err = testHandshake(t, clientConfig, serverConfig)
 	if err != nil {
 		t.Fatalf("handshake failed: %s", err)
 	}
@@ -455,7 +462,7 @@ func TestCrossVersionResume(t *testing.T) {
 
 	// Test that the server will decline to resume at a higher version.
 	clientConfig.MaxVersion = VersionTLS11
-	state, _, err = testHandshake(clientConfig, serverConfig)
+	state, _, err = testHandshake(t, clientConfig, serverConfig)
 	if err != nil {
 		t.Fatalf("handshake failed: %s", err)
 	}
@@ -464,6 +471,330 @@ func TestCrossVersionResume(t *testing.T) {
 	}
 }
 
+func TestTLS13Handshake(t *testing.T) {
+	defaultSuites := defaultCipherSuitesTLS13()
+	defer func() { varDefaultCipherSuitesTLS13 = defaultSuites }()
+
+	for _, suite := range []uint16{TLS_AES_128_GCM_SHA256, TLS_AES_256_GCM_SHA384, TLS_CHACHA20_POLY1305_SHA256} {
+		for _, cert := range []Certificate{
+			{Certificate: [][]byte{testRSACertificate}, PrivateKey: testRSAPrivateKey},
+			{Certificate: [][]byte{testECDSACertificate}, PrivateKey: testECDSAPrivateKey},
+			{Certificate: [][]byte{testP256Certificate}, PrivateKey: testP256PrivateKey},
+			{Certificate: [][]byte{testEd25519Certificate}, PrivateKey: testEd25519PrivateKey},
+		} {
+			serverConfig := &Config{
+				Certificates: []Certificate{cert},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			clientConfig := &Config{
+				InsecureSkipVerify: true,
+				NextProtos:         []string{"http/1.1"},
+			}
+			// The TLS 1.3 suites are not configurable, so steer the
+			// negotiation through the server preference order.
+			serverConfig.PreferServerCipherSuites = true
+			varDefaultCipherSuitesTLS13 = append([]uint16{suite}, defaultSuites...)
+
+			serverState, clientState, err := testHandshake(t, clientConfig, serverConfig)
+			if err != nil {
+				t.Fatalf("%x: handshake failed: %s", suite, err)
+			}
+			for _, state := range []ConnectionState{serverState, clientState} {
+				if state.Version != VersionTLS13 {
+					t.Errorf("%x: got version %x, expected %x", suite, state.Version, VersionTLS13)
+				}
+				if state.CipherSuite != suite {
+					t.Errorf("got cipher suite %x, expected %x", state.CipherSuite, suite)
+				}
+				if state.NegotiatedProtocol != "http/1.1" {
+					t.Errorf("%x: got protocol %q, expected %q", suite, state.NegotiatedProtocol, "http/1.1")
+				}
+				if state.TLSUnique != nil {
+					t.Errorf("%x: TLSUnique is set for a TLS 1.3 connection", suite)
+				}
+			}
+			if len(clientState.PeerCertificates) != 1 {
+				t.Errorf("%x: got %d server certificates, expected 1", suite, len(clientState.PeerCertificates))
+			}
+		}
+	}
+}
+
+func TestTLS13HelloRetryRequest(t *testing.T) {
+	serverConfig := &Config{
+		Certificates:     testConfig.Certificates,
+		CurvePreferences: []CurveID{CurveP256},
+	}
+	clientConfig := &Config{
+		InsecureSkipVerify: true,
+		CurvePreferences:   []CurveID{X25519, CurveP256},
+		ClientSessionCache: NewLRUClientSessionCache(1),
+		ServerName:         "servername",
+	}
+	for _, didResume := range []bool{false, true} {
+		state, _, err := testHandshake(t, clientConfig, serverConfig)
+		if err != nil {
+			t.Fatalf("handshake failed: %s", err)
+		}
+		if state.Version != VersionTLS13 {
+			t.Fatalf("got version %x, expected %x", state.Version, VersionTLS13)
+		}
+		if state.DidResume != didResume {
+			t.Fatalf("resumed: %v, expected: %v", state.DidResume, didResume)
+		}
+	}
+
+	// The client must reject groups it didn't advertise.
+	serverConfig.CurvePreferences = []CurveID{CurveP384}
+	clientConfig.ClientSessionCache = nil
+	if _, _, err := testHandshake(t, clientConfig, serverConfig); err == nil {
+		t.Fatalf("handshake succeeded without a mutual group")
+	}
+}
+
+func TestTLS13Resumption(t *testing.T) {
+	serverConfig := &Config{
+		Certificates: testConfig.Certificates,
+	}
+	clientConfig := &Config{
+		InsecureSkipVerify: true,
+		ClientSessionCache: NewLRUClientSessionCache(1),
+		ServerName:         "servername",
+	}
+
+	testResumeState := func(test string, didResume bool) {
+		t.Helper()
+		serverState, clientState, err := testHandshake(t, clientConfig, serverConfig)
+		if err != nil {
+			t.Fatalf("%s: handshake failed: %s", test, err)
+		}
+		if serverState.DidResume != didResume || clientState.DidResume != didResume {
+			t.Fatalf("%s: resumed: %v/%v, expected: %v", test, serverState.DidResume, clientState.DidResume, didResume)
+		}
+		if serverState.Version != VersionTLS13 {
+			t.Fatalf("%s: got version %x, expected %x", test, serverState.Version, VersionTLS13)
+		}
+		if len(clientState.PeerCertificates) == 0 {
+			t.Fatalf("%s: no server certificates after handshake", test)
+		}
+	}
+
+	testResumeState("Handshake", false)
+	testResumeState("Resume", true)
+
+	// Tickets encrypted with a rotated key can still be used, and tickets
+	// encrypted with an unknown key are ignored.
+	var key1, key2, key3 [32]byte
+	key1[0], key2[0], key3[0] = 1, 2, 3
+	serverConfig.SetSessionTicketKeys([][32]byte{key1})
+	testResumeState("NewKey", false)
+	serverConfig.SetSessionTicketKeys([][32]byte{key2, key1})
+	testResumeState("ResumeWithOldKey", true)
+	serverConfig.SetSessionTicketKeys([][32]byte{key3})
+	testResumeState("InvalidTicketKey", false)
+	testResumeState("ResumeWithNewKey", true)
+
+	// Expired tickets are not offered.
+	clientConfig.Time = func() time.Time { return time.Now().Add(maxSessionTicketLifetime + time.Hour) }
+	testResumeState("Expired", false)
+	clientConfig.Time = nil
+
+	// Resumption at TLS 1.2 doesn't use TLS 1.3 sessions.
+	clientConfig.MaxVersion = VersionTLS12
+	testResumeState12 := func(test string, didResume bool) {
+		state, _, err := testHandshake(t, clientConfig, serverConfig)
+		if err != nil {
+			t.Fatalf("%s: handshake failed: %s", test, err)
+		}
+		if state.DidResume != didResume {
+			t.Fatalf("%s: resumed: %v, expected: %v", test, state.DidResume, didResume)
+		}
+	}
+	testResumeState12("DowngradeHandshake", false)
+	testResumeState12("DowngradeResume", true)
+
+	clientConfig.MaxVersion = 0
+	testResumeState("UpgradeHandshake", false)
+
+	serverConfig.SessionTicketsDisabled = true
+	testResumeState("TicketsDisabled", false)
+}
+
+func TestTLS13ClientAuth(t *testing.T) {
+	for _, cert := range []Certificate{
+		{Certificate: [][]byte{testRSACertificate}, PrivateKey: testRSAPrivateKey},
+		{Certificate: [][]byte{testP256Certificate}, PrivateKey: testP256PrivateKey},
+		{Certificate: [][]byte{testEd25519Certificate}, PrivateKey: testEd25519PrivateKey},
+	} {
+		serverConfig := &Config{
+			Certificates: testConfig.Certificates,
+			ClientAuth:   RequireAnyClientCert,
+		}
+		clientConfig := &Config{
+			InsecureSkipVerify: true,
+			Certificates:       []Certificate{cert},
+			ClientSessionCache: NewLRUClientSessionCache(1),
+			ServerName:         "servername",
+		}
+		for _, didResume := range []bool{false, true} {
+			state, _, err := testHandshake(t, clientConfig, serverConfig)
+			if err != nil {
+				t.Fatalf("handshake failed: %s", err)
+			}
+			if state.DidResume != didResume {
+				t.Fatalf("resumed: %v, expected: %v", state.DidResume, didResume)
+			}
+			if len(state.PeerCertificates) != 1 || !bytes.Equal(state.PeerCertificates[0].Raw, cert.Certificate[0]) {
+				t.Fatalf("server did not receive the client certificate")
+			}
+		}
+	}
+
+	serverConfig := &Config{
+		Certificates: testConfig.Certificates,
+		ClientAuth:   RequireAnyClientCert,
+	}
+	clientConfig := &Config{
+		InsecureSkipVerify: true,
+	}
+	_, _, err := testHandshake(t, clientConfig, serverConfig)
+	if err == nil || !strings.Contains(err.Error(), "client didn't provide a certificate") {
+		t.Fatalf("got error %v, expected a missing certificate error", err)
+	}
+}
+
+// Ed25519 certificates are only supported in TLS 1.3.
+func TestEd25519RequiresTLS13(t *testing.T) {
+	ed25519Cert := Certificate{Certificate: [][]byte{testEd25519Certificate}, PrivateKey: testEd25519PrivateKey}
+
+	serverConfig := &Config{
+		Certificates: []Certificate{ed25519Cert},
+		MaxVersion:   VersionTLS12,
+	}
+	clientConfig := &Config{
+		InsecureSkipVerify: true,
+	}
+	if _, _, err := testHandshake(t, clientConfig, serverConfig); err == nil {
+		t.Error("TLS 1.2 handshake with an Ed25519 server certificate succeeded")
+	}
+
+	serverConfig = &Config{
+		Certificates: testConfig.Certificates,
+		ClientAuth:   RequireAnyClientCert,
+	}
+	clientConfig = &Config{
+		InsecureSkipVerify: true,
+		Certificates:       []Certificate{ed25519Cert},
+		MaxVersion:         VersionTLS12,
+	}
+	if _, _, err := testHandshake(t, clientConfig, serverConfig); err == nil {
+		t.Error("TLS 1.2 handshake with an Ed25519 client certificate succeeded")
+	}
+}
+
+func TestTLS13KeyUpdate(t *testing.T) {
+	c, s := localPipe(t)
+	serverConfig := &Config{
+		Certificates: testConfig.Certificates,
+	}
+	clientConfig := &Config{
+		InsecureSkipVerify: true,
+	}
+
+	done := make(chan error)
+	go func() {
+		srv := Server(s, serverConfig)
+		defer srv.Close()
+		buf := make([]byte, 5)
+		for i := 0; i < 2; i++ {
+			if _, err := io.ReadFull(srv, buf); err != nil {
+				done <- err
+				return
+			}
+			if _, err := srv.Write(buf); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	cli := Client(c, clientConfig)
+	defer cli.Close()
+	if err := cli.Handshake(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Send a KeyUpdate requesting a reply and switch to the next traffic
+	// secret, as handleKeyUpdate does for the peer.
+	suite := cipherSuiteTLS13ByID(cli.cipherSuite)
+	cli.out.Lock()
+	_, err := cli.writeRecordLocked(recordTypeHandshake, (&keyUpdateMsg{updateRequested: true}).marshal())
+	cli.out.setTrafficSecret(suite, suite.nextTrafficSecret(cli.out.trafficSecret))
+	cli.out.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 5)
+	for _, msg := range []string{"hello", "world"} {
+		if _, err := cli.Write([]byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadFull(cli, buf); err != nil {
+			t.Fatal(err)
+		}
+		if string(buf) != msg {
+			t.Fatalf("got %q, expected %q", buf, msg)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTLS13DowngradeCanary(t *testing.T) {
+	// A TLS 1.3 capable server signals a TLS 1.2 negotiation in its random,
+	// which a client that only supports TLS 1.2 must not reject.
+	serverConfig := &Config{
+		Certificates: testConfig.Certificates,
+	}
+	clientConfig := &Config{
+		InsecureSkipVerify: true,
+		MaxVersion:         VersionTLS12,
+	}
+	state, _, err := testHandshake(t, clientConfig, serverConfig)
+	if err != nil {
+		t.Fatalf("handshake failed: %s", err)
+	}
+	if state.Version != VersionTLS12 {
+		t.Fatalf("got version %x, expected %x", state.Version, VersionTLS12)
+	}
+
+	// A client that offered TLS 1.3 must reject it, as the server could
+	// have negotiated TLS 1.3 if the ClientHello hadn't been tampered with.
+	serverConfig.MaxVersion = VersionTLS12
+	serverConfig.Rand = downgradeCanaryRand{}
+	clientConfig.MaxVersion = VersionTLS13
+	_, _, err = testHandshake(t, clientConfig, serverConfig)
+	if err == nil || !strings.Contains(err.Error(), "illegal parameter") {
+		t.Fatalf("got error %v, expected the client to detect the downgrade canary", err)
+	}
+}
+
+// downgradeCanaryRand makes every read look like a ServerHello random
+// carrying the TLS 1.2 downgrade canary.
+type downgradeCanaryRand struct{}
+
+func (downgradeCanaryRand) Read(b []byte) (int, error) {
+	var random [32]byte
+	copy(random[24:], downgradeCanaryTLS12)
+	for i := range b {
+		b[i] = random[i%len(random)] + byte(i/len(random))
+	}
+	return len(b), nil
+}
+
 // Note: see comment in handshake_test.go for details of how the reference
 // tests work.
 
@@ -1381,6 +1712,8 @@ var testSNICertificate = fromHex("0441883421114c81480804c430820237308201a0a00302
 
 var testP256Certificate = fromHex("308201693082010ea00302010202105012dc24e1124ade4f3e153326ff27bf300a06082a8648ce3d04030230123110300e060355040a130741636d6520436f301e170d3137303533313232343934375a170d3138303533313232343934375a30123110300e060355040a130741636d6520436f3059301306072a8648ce3d020106082a8648ce3d03010703420004c02c61c9b16283bbcc14956d886d79b358aa614596975f78cece787146abf74c2d5dc578c0992b4f3c631373479ebf3892efe53d21c4f4f1cc9a11c3536b7f75a3463044300e0603551d0f0101ff0404030205a030130603551d25040c300a06082b06010505070301300c0603551d130101ff04023000300f0603551d1104083006820474657374300a06082a8648ce3d0403020349003046022100963712d6226c7b2bef41512d47e1434131aaca3ba585d666c924df71ac0448b3022100f4d05c725064741aef125f243cdbccaa2a5d485927831f221c43023bd5ae471a")
 
+var testEd25519Certificate = fromHex("308201273081daa00302010202103c38f2e80997b46eca4e40a8c356a38a300506032b657030123110300e060355040a130741636d6520436f301e170d3139303130313030303030305a170d3238313232393030303030305a30123110300e060355040a130741636d6520436f302a300506032b6570032100674315a5660431b228337e94337cee16cff44f9f3c069814072db9a90b40b975a3463044300e0603551d0f0101ff0404030205a030130603551d25040c300a06082b06010505070301300c0603551d130101ff04023000300f0603551d1104083006820474657374300506032b65700341008425b22d4e53335b49e7186599dc9fafecebe1b21e19955ab4a720e69de29e4502171a2db6d5f41bdddf64cc2067694ee608a3e63c67b707e678c0b842f24a00")
+
 var testRSAPrivateKey = &rsa.PrivateKey{
 	PublicKey: rsa.PublicKey{
 		N: bigFromString("153980389784927331788354528594524332344709972855165340650588877572729725338415474372475094155672066328274535240275856844648695200875763869073572078279316458648124537905600131008790701752441155668003033945258023841165089852359980273279085783159654751552359397986180318708491098942831252291841441726305535546071"),
@@ -1402,4 +1735,6 @@ var testECDSAPrivateKey = &ecdsa.PrivateKey{
 	D: bigFromString("5477294338614160138026852784385529180817726002953041720191098180813046231640184669647735805135001309477695746518160084669446643325196003346204701381388769751"),
 }
 
+var testEd25519PrivateKey = ed25519.PrivateKey(fromHex("e680e3a83e079c4bb2015180684439511c557ff003d8aa0fceb5c599d5f6b8c3674315a5660431b228337e94337cee16cff44f9f3c069814072db9a90b40b975"))
+
 var testP256PrivateKey, _ = x509.ParseECPrivateKey(fromHex("30770201010420012f3b52bc54c36ba3577ad45034e2e8efe1e6999851284cb848725cfe029991a00a06082a8648ce3d030107a14403420004c02c61c9b16283bbcc14956d886d79b358aa614596975f78cece787146abf74c2d5dc578c0992b4f3c631373479ebf3892efe53d21c4f4f1cc9a11c3536b7f75"))
@@ -224,3 +224,31 @@ func tempFile(contents string) string {
 	file.Close()
 	return path
 }
+
+// localPipe returns a pair of connected TCP connections. Unlike net.Pipe,
+// writes are buffered by the kernel, so a TLS 1.3 server can send its
+// session tickets without the client reading them first.
+func localPipe(t testing.TB) (net.Conn, net.Conn) {
+	ln := newLocalListener(t)
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			accepted <- nil
+			return
+		}
+		accepted <- c
+	}()
+
+	c1, err := net.Dial(ln.Addr().Network(), ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2 := <-accepted
+	if c2 == nil {
+		t.Fatal("failed to accept local connection")
+	}
+	return c1, c2
+}
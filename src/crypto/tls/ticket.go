@@ -129,8 +129,106 @@ func (s *sessionState) unmarshal(data []byte) bool {
 	return len(data) == 0
 }
 
-func (c *Conn) encryptTicket(state *sessionState) ([]byte, error) {
-	serialized := state.marshal()
+// sessionStateTLS13 is the content of a TLS 1.3 session ticket. It's
+// marshaled with a leading VersionTLS13, which sets it apart from the
+// sessionState of earlier versions.
+type sessionStateTLS13 struct {
+	cipherSuite      uint16
+	createdAt        uint64 // seconds since the Unix epoch
+	resumptionSecret []byte // resumption_master_secret, see RFC 8446, section 7.1
+	certificates     [][]byte
+}
+
+func (s *sessionStateTLS13) equal(i interface{}) bool {
+	s1, ok := i.(*sessionStateTLS13)
+	if !ok {
+		return false
+	}
+
+	return s.cipherSuite == s1.cipherSuite &&
+		s.createdAt == s1.createdAt &&
+		bytes.Equal(s.resumptionSecret, s1.resumptionSecret) &&
+		eqByteSlices(s.certificates, s1.certificates)
+}
+
+func (s *sessionStateTLS13) marshal() []byte {
+	length := 2 + 2 + 8 + 1 + len(s.resumptionSecret) + 2
+	for _, cert := range s.certificates {
+		length += 4 + len(cert)
+	}
+
+	ret := make([]byte, length)
+	x := ret
+	vers := uint16(VersionTLS13)
+	x[0] = byte(vers >> 8)
+	x[1] = byte(vers)
+	x[2] = byte(s.cipherSuite >> 8)
+	x[3] = byte(s.cipherSuite)
+	for i := 0; i < 8; i++ {
+		x[4+i] = byte(s.createdAt >> uint(56-8*i))
+	}
+	x[12] = byte(len(s.resumptionSecret))
+	x = x[13:]
+	copy(x, s.resumptionSecret)
+	x = x[len(s.resumptionSecret):]
+
+	x[0] = byte(len(s.certificates) >> 8)
+	x[1] = byte(len(s.certificates))
+	x = x[2:]
+
+	for _, cert := range s.certificates {
+		x[0] = byte(len(cert) >> 24)
+		x[1] = byte(len(cert) >> 16)
+		x[2] = byte(len(cert) >> 8)
+		x[3] = byte(len(cert))
+		copy(x[4:], cert)
+		x = x[4+len(cert):]
+	}
+
+	return ret
+}
+
+func (s *sessionStateTLS13) unmarshal(data []byte) bool {
+	if len(data) < 13 || uint16(data[0])<<8|uint16(data[1]) != VersionTLS13 {
+		return false
+	}
+
+	s.cipherSuite = uint16(data[2])<<8 | uint16(data[3])
+	s.createdAt = 0
+	for i := 0; i < 8; i++ {
+		s.createdAt = s.createdAt<<8 | uint64(data[4+i])
+	}
+	secretLen := int(data[12])
+	data = data[13:]
+	if secretLen == 0 || len(data) < secretLen+2 {
+		return false
+	}
+	s.resumptionSecret = data[:secretLen]
+	data = data[secretLen:]
+
+	numCerts := int(data[0])<<8 | int(data[1])
+	data = data[2:]
+
+	s.certificates = make([][]byte, numCerts)
+	for i := range s.certificates {
+		if len(data) < 4 {
+			return false
+		}
+		certLen := int(data[0])<<24 | int(data[1])<<16 | int(data[2])<<8 | int(data[3])
+		data = data[4:]
+		if certLen < 0 || len(data) < certLen {
+			return false
+		}
+		s.certificates[i] = data[:certLen]
+		data = data[certLen:]
+	}
+
+	return len(data) == 0
+}
+
+// encryptTicket encrypts and authenticates a serialized session state with
+// the current session ticket key.
+func (c *Conn) encryptTicket(serialized []byte) ([]byte, error) {
 	encrypted := make([]byte, ticketKeyNameLen+aes.BlockSize+len(serialized)+sha256.Size)
 	keyName := encrypted[:ticketKeyNameLen]
 	iv := encrypted[ticketKeyNameLen : ticketKeyNameLen+aes.BlockSize]
@@ -154,7 +252,11 @@ func (c *Conn) encryptTicket(state *sessionState) ([]byte, error) {
 	return encrypted, nil
 }
 
-func (c *Conn) decryptTicket(encrypted []byte) (*sessionState, bool) {
+// decryptTicket authenticates and decrypts a session ticket, returning the
+// serialized session state, or nil if the ticket is not valid. usedOldKey is
+// true if the ticket was encrypted with an older key and thus should be
+// refreshed.
+func (c *Conn) decryptTicket(encrypted []byte) (plaintext []byte, usedOldKey bool) {
 	if c.config.SessionTicketsDisabled ||
 		len(encrypted) < ticketKeyNameLen+aes.BlockSize+sha256.Size {
 		return nil, false
@@ -191,10 +293,10 @@ func (c *Conn) decryptTicket(encrypted []byte) (*sessionState, bool) {
 		return nil, false
 	}
 	ciphertext := encrypted[ticketKeyNameLen+aes.BlockSize : len(encrypted)-sha256.Size]
-	plaintext := ciphertext
+	// Decrypt into a new buffer: the ticket is part of the ClientHello,
+	// which in TLS 1.3 is still needed for the PSK binder and transcript.
+	plaintext = make([]byte, len(ciphertext))
 	cipher.NewCTR(block, iv).XORKeyStream(plaintext, ciphertext)
 
-	state := &sessionState{usedOldKey: keyIndex > 0}
-	ok := state.unmarshal(plaintext)
-	return state, ok
+	return plaintext, keyIndex > 0
 }
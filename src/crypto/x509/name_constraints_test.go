@@ -0,0 +1,317 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package x509
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// constraintsSpec lists the permitted and excluded name constraints of a CA
+// certificate. Each entry has a type prefix of "dns:", "ip:", "email:" or
+// "uri:".
+type constraintsSpec struct {
+	ok  []string
+	bad []string
+}
+
+type nameConstraintsTest struct {
+	name         string
+	root         constraintsSpec
+	intermediate *constraintsSpec
+	// leafSANs lists the subject alternative names of the leaf, with the
+	// same prefixes as constraintsSpec. If empty, the leaf has no SAN
+	// extension and leafCN is used as its common name.
+	leafSANs       []string
+	leafCN         string
+	dnsName        string
+	maxComparisons int
+	expectedError  string
+}
+
+var nameConstraintsTests = []nameConstraintsTest{
+	{
+		name:     "no constraints",
+		leafSANs: []string{"dns:foo.com", "ip:1.2.3.4", "email:foo@foo.com", "uri:https://foo.com/"},
+	},
+	{
+		name:     "permitted DNS subdomain",
+		root:     constraintsSpec{ok: []string{"dns:example.com"}},
+		leafSANs: []string{"dns:foo.example.com", "dns:example.com"},
+	},
+	{
+		name:          "DNS name outside permitted domain",
+		root:          constraintsSpec{ok: []string{"dns:example.com"}},
+		leafSANs:      []string{"dns:foo.example.com", "dns:foo.example.org"},
+		expectedError: "\"foo.example.org\" is not permitted",
+	},
+	{
+		name:          "DNS name with leading period constraint",
+		root:          constraintsSpec{ok: []string{"dns:.example.com"}},
+		leafSANs:      []string{"dns:example.com"},
+		expectedError: "\"example.com\" is not permitted",
+	},
+	{
+		name:          "excluded DNS name",
+		root:          constraintsSpec{ok: []string{"dns:example.com"}, bad: []string{"dns:bar.example.com"}},
+		leafSANs:      []string{"dns:foo.bar.example.com"},
+		expectedError: "\"foo.bar.example.com\" is excluded",
+	},
+	{
+		name:     "DNS constraints don't restrict IP addresses",
+		root:     constraintsSpec{ok: []string{"dns:example.com"}},
+		leafSANs: []string{"dns:example.com", "ip:1.2.3.4"},
+	},
+	{
+		name:     "permitted IPv4 range",
+		root:     constraintsSpec{ok: []string{"ip:10.0.0.0/8"}},
+		leafSANs: []string{"ip:10.1.2.3"},
+	},
+	{
+		name:          "IPv4 address outside permitted range",
+		root:          constraintsSpec{ok: []string{"ip:10.0.0.0/8"}},
+		leafSANs:      []string{"ip:192.168.1.1"},
+		expectedError: "\"192.168.1.1\" is not permitted",
+	},
+	{
+		name:          "IPv6 address with only IPv4 ranges permitted",
+		root:          constraintsSpec{ok: []string{"ip:10.0.0.0/8"}},
+		leafSANs:      []string{"ip:2001:db8::1"},
+		expectedError: "\"2001:db8::1\" is not permitted",
+	},
+	{
+		name:          "excluded IPv6 range",
+		root:          constraintsSpec{bad: []string{"ip:2001:db8::/32"}},
+		leafSANs:      []string{"ip:2001:db9::1", "ip:2001:db8:1::1"},
+		expectedError: "\"2001:db8:1::1\" is excluded",
+	},
+	{
+		name:     "email address in permitted domain",
+		root:     constraintsSpec{ok: []string{"email:example.com"}},
+		leafSANs: []string{"email:foo@example.com", "email:\"foo bar\"@example.com"},
+	},
+	{
+		name:          "email address in subdomain only constraint",
+		root:          constraintsSpec{ok: []string{"email:.example.com"}},
+		leafSANs:      []string{"email:foo@mail.example.com", "email:foo@example.com"},
+		expectedError: "\"foo@example.com\" is not permitted",
+	},
+	{
+		name:          "email address not matching mailbox constraint",
+		root:          constraintsSpec{ok: []string{"email:foo@example.com"}},
+		leafSANs:      []string{"email:foo@EXAMPLE.com", "email:bar@example.com"},
+		expectedError: "\"bar@example.com\" is not permitted",
+	},
+	{
+		name:          "unparsable email address",
+		root:          constraintsSpec{ok: []string{"email:example.com"}},
+		leafSANs:      []string{"email:foo..bar@example.com"},
+		expectedError: "cannot parse rfc822Name",
+	},
+	{
+		name:     "URI in permitted domain",
+		root:     constraintsSpec{ok: []string{"uri:.example.com"}},
+		leafSANs: []string{"uri:https://svc.example.com:8443/path"},
+	},
+	{
+		name:          "excluded URI",
+		root:          constraintsSpec{bad: []string{"uri:example.com"}},
+		leafSANs:      []string{"uri:spiffe://example.com/workload"},
+		expectedError: "\"spiffe://example.com/workload\" is excluded",
+	},
+	{
+		name:          "URI with IP host",
+		root:          constraintsSpec{ok: []string{"uri:example.com"}},
+		leafSANs:      []string{"uri:https://1.2.3.4/"},
+		expectedError: "cannot be matched against constraints",
+	},
+	{
+		name:          "constraints on intermediate",
+		intermediate:  &constraintsSpec{ok: []string{"ip:192.168.0.0/16"}},
+		leafSANs:      []string{"ip:192.169.0.1"},
+		expectedError: "\"192.169.0.1\" is not permitted",
+	},
+	{
+		name:          "constraints on root below unconstrained intermediate",
+		root:          constraintsSpec{bad: []string{"dns:example.com"}},
+		intermediate:  &constraintsSpec{},
+		leafSANs:      []string{"dns:www.example.com"},
+		expectedError: "\"www.example.com\" is excluded",
+	},
+	{
+		name:          "legacy common name",
+		root:          constraintsSpec{ok: []string{"dns:example.com"}},
+		leafCN:        "foo.example.org",
+		dnsName:       "foo.example.org",
+		expectedError: "\"foo.example.org\" is not permitted",
+	},
+	{
+		name:           "too many comparisons",
+		root:           constraintsSpec{ok: []string{"dns:example.org", "dns:example.com"}},
+		leafSANs:       []string{"dns:example.com"},
+		maxComparisons: 1,
+		expectedError:  "too many name constraints",
+	},
+}
+
+func addConstraintsToTemplate(constraints constraintsSpec, template *Certificate) {
+	add := func(specs []string, dns, emails, uris *[]string, ips *[]*net.IPNet) {
+		for _, spec := range specs {
+			switch {
+			case strings.HasPrefix(spec, "dns:"):
+				*dns = append(*dns, spec[4:])
+			case strings.HasPrefix(spec, "email:"):
+				*emails = append(*emails, spec[6:])
+			case strings.HasPrefix(spec, "uri:"):
+				*uris = append(*uris, spec[4:])
+			case strings.HasPrefix(spec, "ip:"):
+				*ips = append(*ips, parseCIDR(spec[3:]))
+			default:
+				panic("unknown constraint " + spec)
+			}
+		}
+	}
+
+	add(constraints.ok, &template.PermittedDNSDomains, &template.PermittedEmailAddresses, &template.PermittedURIDomains, &template.PermittedIPRanges)
+	add(constraints.bad, &template.ExcludedDNSDomains, &template.ExcludedEmailAddresses, &template.ExcludedURIDomains, &template.ExcludedIPRanges)
+}
+
+func makeConstraintsTestCert(t *testing.T, template, parent *Certificate, key, parentKey *ecdsa.PrivateKey) *Certificate {
+	template.NotBefore = time.Unix(1000, 0)
+	template.NotAfter = time.Unix(100000, 0)
+
+	der, err := CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestNameConstraintsVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, test := range nameConstraintsTests {
+		rootTemplate := &Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: fmt.Sprintf("Root %d", i)},
+			BasicConstraintsValid: true,
+			IsCA: true,
+		}
+		addConstraintsToTemplate(test.root, rootTemplate)
+		root := makeConstraintsTestCert(t, rootTemplate, rootTemplate, key, key)
+
+		roots := NewCertPool()
+		roots.AddCert(root)
+		intermediates := NewCertPool()
+
+		parent := root
+		if test.intermediate != nil {
+			intermediateTemplate := &Certificate{
+				SerialNumber:          big.NewInt(2),
+				Subject:               pkix.Name{CommonName: fmt.Sprintf("Intermediate %d", i)},
+				BasicConstraintsValid: true,
+				IsCA: true,
+			}
+			addConstraintsToTemplate(*test.intermediate, intermediateTemplate)
+			parent = makeConstraintsTestCert(t, intermediateTemplate, root, key, key)
+			intermediates.AddCert(parent)
+		}
+
+		leafTemplate := &Certificate{
+			SerialNumber: big.NewInt(3),
+			Subject:      pkix.Name{CommonName: test.leafCN},
+		}
+		for _, san := range test.leafSANs {
+			switch {
+			case strings.HasPrefix(san, "dns:"):
+				leafTemplate.DNSNames = append(leafTemplate.DNSNames, san[4:])
+			case strings.HasPrefix(san, "email:"):
+				leafTemplate.EmailAddresses = append(leafTemplate.EmailAddresses, san[6:])
+			case strings.HasPrefix(san, "uri:"):
+				leafTemplate.URIs = append(leafTemplate.URIs, parseURI(san[4:]))
+			case strings.HasPrefix(san, "ip:"):
+				leafTemplate.IPAddresses = append(leafTemplate.IPAddresses, net.ParseIP(san[3:]))
+			default:
+				t.Fatalf("#%d (%s): unknown SAN %q", i, test.name, san)
+			}
+		}
+		leaf := makeConstraintsTestCert(t, leafTemplate, parent, key, key)
+
+		_, err := leaf.Verify(VerifyOptions{
+			DNSName:                  test.dnsName,
+			Roots:                    roots,
+			Intermediates:            intermediates,
+			CurrentTime:              time.Unix(1500, 0),
+			KeyUsages:                []ExtKeyUsage{ExtKeyUsageAny},
+			MaxConstraintComparisons: test.maxComparisons,
+		})
+
+		if len(test.expectedError) == 0 {
+			if err != nil {
+				t.Errorf("#%d (%s): unexpected failure: %s", i, test.name, err)
+			}
+			continue
+		}
+
+		if err == nil {
+			t.Errorf("#%d (%s): unexpected success, wanted error containing %q", i, test.name, test.expectedError)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.expectedError) {
+			t.Errorf("#%d (%s): got error %q, wanted error containing %q", i, test.name, err, test.expectedError)
+		}
+		if _, ok := err.(CertificateInvalidError); !ok {
+			t.Errorf("#%d (%s): got error of type %T, want CertificateInvalidError", i, test.name, err)
+		}
+	}
+}
+
+func TestParseRFC2821Mailbox(t *testing.T) {
+	tests := []struct {
+		in            string
+		local, domain string
+		ok            bool
+	}{
+		{"foo@example.com", "foo", "example.com", true},
+		{"foo.bar@example.com", "foo.bar", "example.com", true},
+		{"\"foo bar\"@example.com", "foo bar", "example.com", true},
+		{"\"foo\\\"bar\"@example.com", "foo\"bar", "example.com", true},
+		{"", "", "", false},
+		{"foo", "", "", false},
+		{"@example.com", "", "", false},
+		{".foo@example.com", "", "", false},
+		{"foo.@example.com", "", "", false},
+		{"foo..bar@example.com", "", "", false},
+		{"\"foo@example.com", "", "", false},
+		{"foo@", "", "", false},
+		{"foo@example..com", "", "", false},
+	}
+
+	for _, test := range tests {
+		mailbox, ok := parseRFC2821Mailbox(test.in)
+		if ok != test.ok {
+			t.Errorf("parseRFC2821Mailbox(%q) ok = %t, want %t", test.in, ok, test.ok)
+			continue
+		}
+		if ok && (mailbox.local != test.local || mailbox.domain != test.domain) {
+			t.Errorf("parseRFC2821Mailbox(%q) = %q, %q; want %q, %q", test.in, mailbox.local, mailbox.domain, test.local, test.domain)
+		}
+	}
+}
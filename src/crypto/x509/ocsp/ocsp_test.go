@@ -0,0 +1,403 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ocsp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// createTestCert returns a certificate for pub with the given subject,
+// signed by parent and parentKey. If parent is nil, the certificate is a
+// self-signed CA certificate.
+func createTestCert(t *testing.T, serial int64, cn string, pub crypto.PublicKey, parent *x509.Certificate, parentKey crypto.Signer, extKeyUsage []x509.ExtKeyUsage) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  extKeyUsage,
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent = template
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func generateECDSAKey(t *testing.T) *ecdsa.PrivateKey {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priv
+}
+
+func TestRequestRoundTrip(t *testing.T) {
+	issuerKey := generateECDSAKey(t)
+	issuer := createTestCert(t, 1, "Issuer", issuerKey.Public(), nil, issuerKey, nil)
+	leaf := createTestCert(t, 4242, "Leaf", generateECDSAKey(t).Public(), issuer, issuerKey, nil)
+
+	for _, hashFunc := range []crypto.Hash{0, crypto.SHA1, crypto.SHA256, crypto.SHA384, crypto.SHA512} {
+		der, err := CreateRequest(leaf, issuer, &RequestOptions{Hash: hashFunc})
+		if err != nil {
+			t.Fatalf("%v: CreateRequest: %s", hashFunc, err)
+		}
+		req, err := ParseRequest(der)
+		if err != nil {
+			t.Fatalf("%v: ParseRequest: %s", hashFunc, err)
+		}
+
+		want := hashFunc
+		if want == 0 {
+			want = crypto.SHA1
+		}
+		if req.HashAlgorithm != want {
+			t.Errorf("%v: got hash %v, want %v", hashFunc, req.HashAlgorithm, want)
+		}
+		if req.SerialNumber.Cmp(leaf.SerialNumber) != 0 {
+			t.Errorf("%v: got serial %v, want %v", hashFunc, req.SerialNumber, leaf.SerialNumber)
+		}
+		nameHash, keyHash, err := issuerHashes(issuer, want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(req.IssuerNameHash, nameHash) || !bytes.Equal(req.IssuerKeyHash, keyHash) {
+			t.Errorf("%v: issuer hashes don't match", hashFunc)
+		}
+
+		remarshaled, err := req.Marshal()
+		if err != nil {
+			t.Fatalf("%v: Marshal: %s", hashFunc, err)
+		}
+		if !bytes.Equal(remarshaled, der) {
+			t.Errorf("%v: Marshal doesn't round-trip", hashFunc)
+		}
+	}
+}
+
+func TestResponseRoundTrip(t *testing.T) {
+	issuerKey := generateECDSAKey(t)
+	issuer := createTestCert(t, 1, "Issuer", issuerKey.Public(), nil, issuerKey, nil)
+
+	responderKey := generateECDSAKey(t)
+	responder := createTestCert(t, 2, "Responder", responderKey.Public(), issuer, issuerKey, []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning})
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edResponder := createTestCert(t, 3, "Ed25519 Responder", edKey.Public(), issuer, issuerKey, []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning})
+
+	thisUpdate := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	extension := pkix.Extension{
+		Id:    asn1.ObjectIdentifier{1, 2, 3},
+		Value: []byte{0x05, 0x00},
+	}
+
+	tests := []struct {
+		name          string
+		status        int
+		responderCert *x509.Certificate
+		responderKey  crypto.Signer
+		embed         bool
+		hash          crypto.Hash
+	}{
+		{name: "good", status: Good, responderCert: issuer, responderKey: issuerKey},
+		{name: "revoked", status: Revoked, responderCert: issuer, responderKey: issuerKey, hash: crypto.SHA256},
+		{name: "unknown", status: Unknown, responderCert: issuer, responderKey: issuerKey, hash: crypto.SHA512},
+		{name: "delegated", status: Good, responderCert: responder, responderKey: responderKey, embed: true},
+		{name: "embedded issuer", status: Good, responderCert: issuer, responderKey: issuerKey, embed: true},
+		{name: "ed25519", status: Revoked, responderCert: edResponder, responderKey: edKey, embed: true},
+	}
+
+	for _, test := range tests {
+		template := Response{
+			Status:           test.status,
+			SerialNumber:     big.NewInt(4242),
+			ProducedAt:       thisUpdate.Add(time.Minute),
+			ThisUpdate:       thisUpdate,
+			NextUpdate:       thisUpdate.Add(24 * time.Hour),
+			IssuerHash:       test.hash,
+			ExtraExtensions:  []pkix.Extension{extension},
+			RevocationReason: Unspecified,
+		}
+		if test.status == Revoked {
+			template.RevokedAt = thisUpdate.Add(-time.Hour)
+			template.RevocationReason = KeyCompromise
+		}
+		if test.embed {
+			template.Certificate = test.responderCert
+		}
+
+		der, err := CreateResponse(issuer, test.responderCert, template, test.responderKey)
+		if err != nil {
+			t.Errorf("%s: CreateResponse: %s", test.name, err)
+			continue
+		}
+		resp, err := ParseResponse(der, issuer)
+		if err != nil {
+			t.Errorf("%s: ParseResponse: %s", test.name, err)
+			continue
+		}
+
+		if resp.Status != template.Status {
+			t.Errorf("%s: got status %d, want %d", test.name, resp.Status, template.Status)
+		}
+		if resp.SerialNumber.Cmp(template.SerialNumber) != 0 {
+			t.Errorf("%s: got serial %v, want %v", test.name, resp.SerialNumber, template.SerialNumber)
+		}
+		if !resp.ProducedAt.Equal(template.ProducedAt) || !resp.ThisUpdate.Equal(template.ThisUpdate) || !resp.NextUpdate.Equal(template.NextUpdate) {
+			t.Errorf("%s: times don't match: got %v, %v, %v", test.name, resp.ProducedAt, resp.ThisUpdate, resp.NextUpdate)
+		}
+		if !resp.RevokedAt.Equal(template.RevokedAt) || resp.RevocationReason != template.RevocationReason {
+			t.Errorf("%s: got revocation %v (%d), want %v (%d)", test.name, resp.RevokedAt, resp.RevocationReason, template.RevokedAt, template.RevocationReason)
+		}
+		wantHash := test.hash
+		if wantHash == 0 {
+			wantHash = crypto.SHA1
+		}
+		if resp.IssuerHash != wantHash {
+			t.Errorf("%s: got issuer hash %v, want %v", test.name, resp.IssuerHash, wantHash)
+		}
+		if !bytes.Equal(resp.RawResponderName, test.responderCert.RawSubject) {
+			t.Errorf("%s: responder name doesn't match", test.name)
+		}
+		if !reflect.DeepEqual(resp.Extensions, template.ExtraExtensions) {
+			t.Errorf("%s: got extensions %v, want %v", test.name, resp.Extensions, template.ExtraExtensions)
+		}
+		if test.embed {
+			if resp.Certificate == nil || !bytes.Equal(resp.Certificate.Raw, test.responderCert.Raw) {
+				t.Errorf("%s: embedded certificate doesn't match", test.name)
+			}
+		} else {
+			if resp.Certificate != nil {
+				t.Errorf("%s: unexpected embedded certificate", test.name)
+			}
+			if err := resp.CheckSignatureFrom(issuer); err != nil {
+				t.Errorf("%s: CheckSignatureFrom: %s", test.name, err)
+			}
+		}
+	}
+}
+
+func TestResponseVerificationErrors(t *testing.T) {
+	issuerKey := generateECDSAKey(t)
+	issuer := createTestCert(t, 1, "Issuer", issuerKey.Public(), nil, issuerKey, nil)
+
+	otherKey := generateECDSAKey(t)
+	other := createTestCert(t, 1, "Other Issuer", otherKey.Public(), nil, otherKey, nil)
+
+	responderKey := generateECDSAKey(t)
+	unauthorized := createTestCert(t, 2, "Responder", responderKey.Public(), issuer, issuerKey, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth})
+	foreign := createTestCert(t, 3, "Responder", responderKey.Public(), other, otherKey, []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning})
+
+	leaf := createTestCert(t, 4242, "Leaf", generateECDSAKey(t).Public(), issuer, issuerKey, nil)
+
+	template := Response{
+		Status:       Good,
+		SerialNumber: leaf.SerialNumber,
+		ThisUpdate:   time.Now(),
+	}
+
+	tests := []struct {
+		name          string
+		issuer        *x509.Certificate
+		responderCert *x509.Certificate
+		responderKey  crypto.Signer
+		embed         bool
+		extensions    []pkix.Extension
+		wantErr       string
+	}{
+		{
+			name:          "wrong issuer",
+			issuer:        other,
+			responderCert: issuer,
+			responderKey:  issuerKey,
+			wantErr:       "bad OCSP signature",
+		},
+		{
+			name:          "wrong signer",
+			issuer:        issuer,
+			responderCert: issuer,
+			responderKey:  otherKey,
+			wantErr:       "bad OCSP signature",
+		},
+		{
+			name:          "responder without OCSP signing",
+			issuer:        issuer,
+			responderCert: unauthorized,
+			responderKey:  responderKey,
+			embed:         true,
+			wantErr:       "not authorized for OCSP signing",
+		},
+		{
+			name:          "responder from another issuer",
+			issuer:        issuer,
+			responderCert: foreign,
+			responderKey:  responderKey,
+			embed:         true,
+			wantErr:       "bad OCSP signature",
+		},
+		{
+			name:          "critical extension",
+			issuer:        issuer,
+			responderCert: issuer,
+			responderKey:  issuerKey,
+			extensions:    []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 2, 3}, Critical: true, Value: []byte{0x05, 0x00}}},
+			wantErr:       "unsupported critical extension",
+		},
+	}
+
+	for _, test := range tests {
+		template := template
+		template.ExtraExtensions = test.extensions
+		if test.embed {
+			template.Certificate = test.responderCert
+		}
+		der, err := CreateResponse(issuer, test.responderCert, template, test.responderKey)
+		if err != nil {
+			t.Fatalf("%s: CreateResponse: %s", test.name, err)
+		}
+		_, err = ParseResponseForCert(der, leaf, test.issuer)
+		if err == nil {
+			t.Errorf("%s: expected error containing %q", test.name, test.wantErr)
+			continue
+		}
+		if _, ok := err.(ParseError); !ok || !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s: got error %q, want a ParseError containing %q", test.name, err, test.wantErr)
+		}
+	}
+
+	// A response issued by the right key about a different issuer's
+	// certificate must not be accepted.
+	der, err := CreateResponse(other, issuer, template, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseResponse(der, issuer); err == nil || !strings.Contains(err.Error(), "not for a certificate from the supplied issuer") {
+		t.Errorf("got error %v for a response about another issuer", err)
+	}
+
+	// A response that doesn't cover the certificate must not be accepted.
+	otherLeaf := createTestCert(t, 4343, "Leaf", generateECDSAKey(t).Public(), issuer, issuerKey, nil)
+	der, err = CreateResponse(issuer, issuer, template, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseResponseForCert(der, otherLeaf, issuer); err == nil {
+		t.Error("expected error for a response about another certificate")
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	for _, status := range []ResponseStatus{Malformed, InternalError, TryLater, SignatureRequired, Unauthorized} {
+		der, err := asn1.Marshal(struct {
+			Status asn1.Enumerated
+		}{asn1.Enumerated(status)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = ParseResponse(der, nil)
+		respErr, ok := err.(ResponseError)
+		if !ok {
+			t.Errorf("%v: got error %v, want a ResponseError", status, err)
+			continue
+		}
+		if respErr.Status != status {
+			t.Errorf("got status %v, want %v", respErr.Status, status)
+		}
+	}
+}
+
+// The following response was generated by OpenSSL's ocsp command for the
+// certificate with serial number 0x1092, issued by ocspTestIssuerPEM, and
+// signed by the issuer itself, which is included in the response.
+const ocspTestResponseHex = "308202540a0100a082024d3082024906092b06010505073001010482023a3082" +
+	"02363081a6a10f300d310b3009060355040313024341180f3230323631303136" +
+	"3132353835375a308181307f3057300d060960864801650304020105000420ac" +
+	"1910e13d8ea3bea0add22510aecb9844e405399d314e45c5c39023dcea45b504" +
+	"20a1ec535e477b7144c76b9888f758513bb23ce56f7cb7dc66301609b5c4eb8a" +
+	"00020210928000180f32303236313031363132353835375aa011180f32303236" +
+	"313031373132353835375a300a06082a8648ce3d040302034800304502205629" +
+	"b7f46dff0b0132a8389125f2914648994f301708a5c6ead281bc756a01230221" +
+	"00e9f3c4397aa44d5cf74c36c6a76e9c3849625a9fc91e906e6717012f75d436" +
+	"6ea08201333082012f3082012b3081d2a003020102020101300a06082a8648ce" +
+	"3d040302300d310b3009060355040313024341301e170d323631303136313135" +
+	"3835355a170d3236313031363133353835355a300d310b300906035504031302" +
+	"43413059301306072a8648ce3d020106082a8648ce3d03010703420004aa0236" +
+	"225bc2d314e1f1b624b3d1439b39e7ac5f3cee417448290abeb3fdeb1b0348c6" +
+	"edd06f2ac8956350847e884d074bd0071fc5cca5a6cb6d0ae4b9d9da25a32330" +
+	"21300e0603551d0f0101ff040403020204300f0603551d130101ff0405300301" +
+	"01ff300a06082a8648ce3d0403020348003045022100a3536138d02ef3304e64" +
+	"0da5bde4dd8161bdbeb9b3748212f09c5e8fc087a73d022009ff2fe838a99f64" +
+	"a10b63d7c1d578775a9ae34cbda158f4a67c4690c68b4b33"
+
+const ocspTestIssuerPEM = `-----BEGIN CERTIFICATE-----
+MIIBKzCB0qADAgECAgEBMAoGCCqGSM49BAMCMA0xCzAJBgNVBAMTAkNBMB4XDTI2
+MTAxNjExNTg1NVoXDTI2MTAxNjEzNTg1NVowDTELMAkGA1UEAxMCQ0EwWTATBgcq
+hkjOPQIBBggqhkjOPQMBBwNCAASqAjYiW8LTFOHxtiSz0UObOeesXzzuQXRIKQq+
+s/3rGwNIxu3QbyrIlWNQhH6ITQdL0AcfxcylpsttCuS52doloyMwITAOBgNVHQ8B
+Af8EBAMCAgQwDwYDVR0TAQH/BAUwAwEB/zAKBggqhkjOPQQDAgNIADBFAiEAo1Nh
+ONAu8zBOZA2lveTdgWG9vrmzdIIS8Jxej8CHpz0CIAn/L+g4qZ9koQtj18HVeHda
+muNMvaFY9KZ8RpDGi0sz
+-----END CERTIFICATE-----`
+
+func TestParseOpenSSLResponse(t *testing.T) {
+	block, _ := pem.Decode([]byte(ocspTestIssuerPEM))
+	issuer, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := hex.DecodeString(ocspTestResponseHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := ParseResponse(der, issuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != Good {
+		t.Errorf("got status %d, want Good", resp.Status)
+	}
+	if resp.SerialNumber.Cmp(big.NewInt(0x1092)) != 0 {
+		t.Errorf("got serial %x, want 1092", resp.SerialNumber)
+	}
+	if resp.IssuerHash != crypto.SHA256 {
+		t.Errorf("got issuer hash %v, want SHA-256", resp.IssuerHash)
+	}
+	if resp.Certificate == nil || !bytes.Equal(resp.Certificate.Raw, issuer.Raw) {
+		t.Error("expected the issuer to be embedded in the response")
+	}
+	wantThisUpdate := time.Date(2026, 10, 16, 12, 58, 57, 0, time.UTC)
+	if !resp.ThisUpdate.Equal(wantThisUpdate) || !resp.NextUpdate.Equal(wantThisUpdate.Add(24*time.Hour)) {
+		t.Errorf("got update times %v and %v", resp.ThisUpdate, resp.NextUpdate)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"crypto"
 	"crypto/dsa"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rsa"
 	_ "crypto/sha1"
@@ -27,7 +28,9 @@ import (
 	"io"
 	"math/big"
 	"net"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -41,11 +44,11 @@ type pkixPublicKey struct {
 // ParsePKIXPublicKey parses a DER encoded public key. These values are
 // typically found in PEM blocks with "BEGIN PUBLIC KEY".
 //
-// Supported key types include RSA, DSA, and ECDSA. Unknown key
+// Supported key types include RSA, DSA, ECDSA, and Ed25519. Unknown key
 // types result in an error.
 //
 // On success, pub will be of type *rsa.PublicKey, *dsa.PublicKey,
-// or *ecdsa.PublicKey.
+// *ecdsa.PublicKey, or ed25519.PublicKey.
 func ParsePKIXPublicKey(derBytes []byte) (pub interface{}, err error) {
 	var pki publicKeyInfo
 	if rest, err := asn1.Unmarshal(derBytes, &pki); err != nil {
@@ -87,8 +90,11 @@ func marshalPublicKey(pub interface{}) (publicKeyBytes []byte, publicKeyAlgorith
 			return
 		}
 		publicKeyAlgorithm.Parameters.FullBytes = paramBytes
+	case ed25519.PublicKey:
+		publicKeyBytes = pub
+		publicKeyAlgorithm.Algorithm = oidPublicKeyEd25519
 	default:
-		return nil, pkix.AlgorithmIdentifier{}, errors.New("x509: only RSA and ECDSA public keys supported")
+		return nil, pkix.AlgorithmIdentifier{}, errors.New("x509: only RSA, ECDSA and Ed25519 public keys supported")
 	}
 
 	return publicKeyBytes, publicKeyAlgorithm, nil
@@ -183,6 +189,7 @@ const (
 	SHA256WithRSAPSS
 	SHA384WithRSAPSS
 	SHA512WithRSAPSS
+	PureEd25519
 )
 
 func (algo SignatureAlgorithm) isRSAPSS() bool {
@@ -210,6 +217,7 @@ var signatureAlgoName = [...]string{
 	ECDSAWithSHA256:  "ECDSA-SHA256",
 	ECDSAWithSHA384:  "ECDSA-SHA384",
 	ECDSAWithSHA512:  "ECDSA-SHA512",
+	PureEd25519:      "Ed25519",
 }
 
 func (algo SignatureAlgorithm) String() string {
@@ -226,12 +234,14 @@ const (
 	RSA
 	DSA
 	ECDSA
+	Ed25519
 )
 
 var publicKeyAlgoName = [...]string{
-	RSA:   "RSA",
-	DSA:   "DSA",
-	ECDSA: "ECDSA",
+	RSA:     "RSA",
+	DSA:     "DSA",
+	ECDSA:   "ECDSA",
+	Ed25519: "Ed25519",
 }
 
 func (algo PublicKeyAlgorithm) String() string {
@@ -290,6 +300,11 @@ func (algo PublicKeyAlgorithm) String() string {
 //
 // ecdsa-with-SHA512 OBJECT IDENTIFIER ::= { iso(1) member-body(2)
 //    us(840) ansi-X9-62(10045) signatures(4) ecdsa-with-SHA2(3) 4 }
+//
+//
+// RFC 8410 3 Curve25519 and Curve448 Algorithm Identifiers
+//
+// id-Ed25519   OBJECT IDENTIFIER ::= { 1 3 101 112 }
 
 var (
 	oidSignatureMD2WithRSA      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 2}
@@ -305,6 +320,7 @@ var (
 	oidSignatureECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
 	oidSignatureECDSAWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 3}
 	oidSignatureECDSAWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 4}
+	oidSignatureEd25519         = asn1.ObjectIdentifier{1, 3, 101, 112}
 
 	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
 	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
@@ -340,6 +356,7 @@ var signatureAlgorithmDetails = []struct {
 	{ECDSAWithSHA256, oidSignatureECDSAWithSHA256, ECDSA, crypto.SHA256},
 	{ECDSAWithSHA384, oidSignatureECDSAWithSHA384, ECDSA, crypto.SHA384},
 	{ECDSAWithSHA512, oidSignatureECDSAWithSHA512, ECDSA, crypto.SHA512},
+	{PureEd25519, oidSignatureEd25519, Ed25519, crypto.Hash(0) /* no pre-hashing */},
 }
 
 // pssParameters reflects the parameters in an AlgorithmIdentifier that
@@ -400,6 +417,14 @@ func rsaPSSParameters(hashFunc crypto.Hash) asn1.RawValue {
 }
 
 func getSignatureAlgorithmFromAI(ai pkix.AlgorithmIdentifier) SignatureAlgorithm {
+	if ai.Algorithm.Equal(oidSignatureEd25519) {
+		// RFC 8410, Section 3
+		// > For all of the OIDs, the parameters MUST be absent.
+		if len(ai.Parameters.FullBytes) != 0 {
+			return UnknownSignatureAlgorithm
+		}
+	}
+
 	if !ai.Algorithm.Equal(oidSignatureRSAPSS) {
 		for _, details := range signatureAlgorithmDetails {
 			if ai.Algorithm.Equal(details.oid) {
@@ -463,10 +488,15 @@ func getSignatureAlgorithmFromAI(ai pkix.AlgorithmIdentifier) SignatureAlgorithm
 //
 // id-ecPublicKey OBJECT IDENTIFIER ::= {
 //       iso(1) member-body(2) us(840) ansi-X9-62(10045) keyType(2) 1 }
+//
+// RFC 8410, Section 3
+//
+// id-Ed25519   OBJECT IDENTIFIER ::= { 1 3 101 112 }
 var (
-	oidPublicKeyRSA   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
-	oidPublicKeyDSA   = asn1.ObjectIdentifier{1, 2, 840, 10040, 4, 1}
-	oidPublicKeyECDSA = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidPublicKeyRSA     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidPublicKeyDSA     = asn1.ObjectIdentifier{1, 2, 840, 10040, 4, 1}
+	oidPublicKeyECDSA   = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidPublicKeyEd25519 = oidSignatureEd25519
 )
 
 func getPublicKeyAlgorithmFromOID(oid asn1.ObjectIdentifier) PublicKeyAlgorithm {
@@ -477,6 +507,8 @@ func getPublicKeyAlgorithmFromOID(oid asn1.ObjectIdentifier) PublicKeyAlgorithm
 		return DSA
 	case oid.Equal(oidPublicKeyECDSA):
 		return ECDSA
+	case oid.Equal(oidPublicKeyEd25519):
+		return Ed25519
 	}
 	return UnknownPublicKeyAlgorithm
 }
@@ -713,11 +745,22 @@ type Certificate struct {
 	DNSNames       []string
 	EmailAddresses []string
 	IPAddresses    []net.IP
+	URIs           []*url.URL
 
-	// Name constraints
+	// Name constraints. DNS and URI domain constraints match the domain
+	// itself and any subdomain, unless they have a leading period, in which
+	// case they only match subdomains. Email constraints are either a full
+	// mailbox, or a domain constraint that is matched against the domain
+	// part of the address. See RFC 5280, 4.2.1.10.
 	PermittedDNSDomainsCritical bool // if true then the name constraints are marked critical.
 	PermittedDNSDomains         []string
 	ExcludedDNSDomains          []string
+	PermittedIPRanges           []*net.IPNet
+	ExcludedIPRanges            []*net.IPNet
+	PermittedEmailAddresses     []string
+	ExcludedEmailAddresses      []string
+	PermittedURIDomains         []string
+	ExcludedURIDomains          []string
 
 	// CRL Distribution Points
 	CRLDistributionPoints []string
@@ -753,6 +796,13 @@ func (c *Certificate) hasSANExtension() bool {
 	return oidInExtensions(oidExtensionSubjectAltName, c.Extensions)
 }
 
+func (c *Certificate) hasNameConstraints() bool {
+	return len(c.PermittedDNSDomains) > 0 || len(c.ExcludedDNSDomains) > 0 ||
+		len(c.PermittedIPRanges) > 0 || len(c.ExcludedIPRanges) > 0 ||
+		len(c.PermittedEmailAddresses) > 0 || len(c.ExcludedEmailAddresses) > 0 ||
+		len(c.PermittedURIDomains) > 0 || len(c.ExcludedURIDomains) > 0
+}
+
 // Entrust have a broken root certificate (CN=Entrust.net Certification
 // Authority (2048)) which isn't marked as a CA certificate and is thus invalid
 // according to PKIX.
@@ -850,19 +900,23 @@ func checkSignature(algo SignatureAlgorithm, signed, signature []byte, publicKey
 		hashType = crypto.SHA384
 	case SHA512WithRSA, SHA512WithRSAPSS, ECDSAWithSHA512:
 		hashType = crypto.SHA512
+	case PureEd25519:
+		// Ed25519 signs the message itself, not a digest of it.
 	case MD2WithRSA, MD5WithRSA:
 		return InsecureAlgorithmError(algo)
 	default:
 		return ErrUnsupportedAlgorithm
 	}
 
-	if !hashType.Available() {
-		return ErrUnsupportedAlgorithm
+	digest := signed
+	if hashType != 0 {
+		if !hashType.Available() {
+			return ErrUnsupportedAlgorithm
+		}
+		h := hashType.New()
+		h.Write(signed)
+		digest = h.Sum(nil)
 	}
-	h := hashType.New()
-
-	h.Write(signed)
-	digest := h.Sum(nil)
 
 	switch pub := publicKey.(type) {
 	case *rsa.PublicKey:
@@ -899,6 +953,14 @@ func checkSignature(algo SignatureAlgorithm, signed, signature []byte, publicKey
 			return errors.New("x509: ECDSA verification failure")
 		}
 		return
+	case ed25519.PublicKey:
+		if algo != PureEd25519 {
+			return ErrUnsupportedAlgorithm
+		}
+		if !ed25519.Verify(pub, digest, signature) {
+			return errors.New("x509: Ed25519 verification failure")
+		}
+		return
 	}
 	return ErrUnsupportedAlgorithm
 }
@@ -933,7 +995,7 @@ type nameConstraints struct {
 }
 
 type generalSubtree struct {
-	Name string `asn1:"tag:2,optional,ia5"`
+	Name asn1.RawValue
 }
 
 // RFC 5280, 4.2.2.1
@@ -1039,12 +1101,35 @@ func parsePublicKey(algo PublicKeyAlgorithm, keyData *publicKeyInfo) (interface{
 			Y:     y,
 		}
 		return pub, nil
+	case Ed25519:
+		// RFC 8410, Section 3
+		// > For all of the OIDs, the parameters MUST be absent.
+		if len(keyData.Algorithm.Parameters.FullBytes) != 0 {
+			return nil, errors.New("x509: Ed25519 key encoded with illegal parameters")
+		}
+		if len(asn1Data) != ed25519.PublicKeySize {
+			return nil, errors.New("x509: wrong Ed25519 public key size")
+		}
+		pub := make([]byte, ed25519.PublicKeySize)
+		copy(pub, asn1Data)
+		return ed25519.PublicKey(pub), nil
 	default:
 		return nil, nil
 	}
 }
 
-func parseSANExtension(value []byte) (dnsNames, emailAddresses []string, ipAddresses []net.IP, err error) {
+// GeneralName tags used in subject alternative names and name constraints.
+// See RFC 5280, 4.2.1.6.
+const (
+	nameTypeEmail = 1
+	nameTypeDNS   = 2
+	nameTypeURI   = 6
+	nameTypeIP    = 7
+)
+
+// forEachSAN calls callback with the tag and contents of each GeneralName in
+// the given GeneralNames sequence.
+func forEachSAN(extension []byte, callback func(tag int, data []byte) error) error {
 	// RFC 5280, 4.2.1.6
 
 	// SubjectAltName ::= GeneralNames
@@ -1062,44 +1147,205 @@ func parseSANExtension(value []byte) (dnsNames, emailAddresses []string, ipAddre
 	//      iPAddress                       [7]     OCTET STRING,
 	//      registeredID                    [8]     OBJECT IDENTIFIER }
 	var seq asn1.RawValue
-	var rest []byte
-	if rest, err = asn1.Unmarshal(value, &seq); err != nil {
-		return
+	if rest, err := asn1.Unmarshal(extension, &seq); err != nil {
+		return err
 	} else if len(rest) != 0 {
-		err = errors.New("x509: trailing data after X.509 extension")
-		return
+		return errors.New("x509: trailing data after X.509 extension")
 	}
 	if !seq.IsCompound || seq.Tag != 16 || seq.Class != 0 {
-		err = asn1.StructuralError{Msg: "bad SAN sequence"}
-		return
+		return asn1.StructuralError{Msg: "bad SAN sequence"}
 	}
 
-	rest = seq.Bytes
+	rest := seq.Bytes
 	for len(rest) > 0 {
 		var v asn1.RawValue
+		var err error
 		rest, err = asn1.Unmarshal(rest, &v)
 		if err != nil {
-			return
+			return err
+		}
+		if err := callback(v.Tag, v.Bytes); err != nil {
+			return err
 		}
-		switch v.Tag {
-		case 1:
-			emailAddresses = append(emailAddresses, string(v.Bytes))
-		case 2:
-			dnsNames = append(dnsNames, string(v.Bytes))
-		case 7:
-			switch len(v.Bytes) {
+	}
+
+	return nil
+}
+
+func parseSANExtension(value []byte) (dnsNames, emailAddresses []string, ipAddresses []net.IP, uris []*url.URL, err error) {
+	err = forEachSAN(value, func(tag int, data []byte) error {
+		switch tag {
+		case nameTypeEmail:
+			emailAddresses = append(emailAddresses, string(data))
+		case nameTypeDNS:
+			dnsNames = append(dnsNames, string(data))
+		case nameTypeURI:
+			uri, err := url.Parse(string(data))
+			if err != nil {
+				return fmt.Errorf("x509: cannot parse URI %q: %s", string(data), err)
+			}
+			if len(uri.Host) > 0 {
+				if _, ok := domainToReverseLabels(uri.Hostname()); !ok {
+					return fmt.Errorf("x509: cannot parse URI %q: invalid domain", string(data))
+				}
+			}
+			uris = append(uris, uri)
+		case nameTypeIP:
+			switch len(data) {
 			case net.IPv4len, net.IPv6len:
-				ipAddresses = append(ipAddresses, v.Bytes)
+				ipAddresses = append(ipAddresses, data)
 			default:
-				err = errors.New("x509: certificate contained IP address of length " + strconv.Itoa(len(v.Bytes)))
-				return
+				return errors.New("x509: certificate contained IP address of length " + strconv.Itoa(len(data)))
 			}
 		}
-	}
+
+		return nil
+	})
 
 	return
 }
 
+// isValidIPMask reports whether mask consists of zero or more 1 bits,
+// followed by zero bits.
+func isValidIPMask(mask []byte) bool {
+	seenZero := false
+
+	for _, b := range mask {
+		if seenZero {
+			if b != 0 {
+				return false
+			}
+			continue
+		}
+
+		switch b {
+		case 0x00, 0x80, 0xc0, 0xe0, 0xf0, 0xf8, 0xfc, 0xfe:
+			seenZero = true
+		case 0xff:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseNameConstraintsExtension sets the name constraint fields of out from
+// the NameConstraints extension e. It reports whether e is critical and
+// contains constraints of a type this package can't enforce.
+func parseNameConstraintsExtension(out *Certificate, e pkix.Extension) (unhandled bool, err error) {
+	// RFC 5280, 4.2.1.10
+
+	// NameConstraints ::= SEQUENCE {
+	//      permittedSubtrees       [0]     GeneralSubtrees OPTIONAL,
+	//      excludedSubtrees        [1]     GeneralSubtrees OPTIONAL }
+	//
+	// GeneralSubtrees ::= SEQUENCE SIZE (1..MAX) OF GeneralSubtree
+	//
+	// GeneralSubtree ::= SEQUENCE {
+	//      base                    GeneralName,
+	//      minimum         [0]     BaseDistance DEFAULT 0,
+	//      maximum         [1]     BaseDistance OPTIONAL }
+	//
+	// BaseDistance ::= INTEGER (0..MAX)
+
+	var constraints nameConstraints
+	if rest, err := asn1.Unmarshal(e.Value, &constraints); err != nil {
+		return false, err
+	} else if len(rest) != 0 {
+		return false, errors.New("x509: trailing data after X.509 NameConstraints")
+	}
+
+	if len(constraints.Permitted) == 0 && len(constraints.Excluded) == 0 {
+		// RFC 5280 requires at least one of the two to be present.
+		return false, errors.New("x509: empty name constraints extension")
+	}
+
+	getValues := func(subtrees []generalSubtree) (dnsNames []string, ips []*net.IPNet, emails, uriDomains []string, err error) {
+		for _, subtree := range subtrees {
+			base := subtree.Name
+			if base.Class != asn1.ClassContextSpecific {
+				return nil, nil, nil, nil, errors.New("x509: invalid NameConstraints extension")
+			}
+
+			switch base.Tag {
+			case nameTypeDNS:
+				domain := string(base.Bytes)
+				trimmedDomain := strings.TrimPrefix(domain, ".")
+				if len(trimmedDomain) > 0 {
+					if _, ok := domainToReverseLabels(trimmedDomain); !ok {
+						return nil, nil, nil, nil, fmt.Errorf("x509: failed to parse dnsName constraint %q", domain)
+					}
+				}
+				dnsNames = append(dnsNames, domain)
+
+			case nameTypeIP:
+				l := len(base.Bytes)
+				var ip, mask []byte
+
+				switch l {
+				case 2 * net.IPv4len, 2 * net.IPv6len:
+					ip = base.Bytes[:l/2]
+					mask = base.Bytes[l/2:]
+					if !isValidIPMask(mask) {
+						return nil, nil, nil, nil, fmt.Errorf("x509: IP constraint contained invalid mask %x", mask)
+					}
+				default:
+					return nil, nil, nil, nil, fmt.Errorf("x509: IP constraint contained value of length %d", l)
+				}
+
+				ips = append(ips, &net.IPNet{IP: net.IP(ip), Mask: net.IPMask(mask)})
+
+			case nameTypeEmail:
+				constraint := string(base.Bytes)
+
+				// If the constraint contains an @ then it specifies an
+				// exact mailbox name.
+				if strings.Contains(constraint, "@") {
+					if _, ok := parseRFC2821Mailbox(constraint); !ok {
+						return nil, nil, nil, nil, fmt.Errorf("x509: failed to parse rfc822Name constraint %q", constraint)
+					}
+				} else {
+					// Otherwise it's a domain name.
+					domain := strings.TrimPrefix(constraint, ".")
+					if _, ok := domainToReverseLabels(domain); !ok {
+						return nil, nil, nil, nil, fmt.Errorf("x509: failed to parse rfc822Name constraint %q", constraint)
+					}
+				}
+				emails = append(emails, constraint)
+
+			case nameTypeURI:
+				domain := string(base.Bytes)
+
+				if net.ParseIP(domain) != nil {
+					return nil, nil, nil, nil, fmt.Errorf("x509: failed to parse URI constraint %q: cannot be IP address", domain)
+				}
+
+				trimmedDomain := strings.TrimPrefix(domain, ".")
+				if _, ok := domainToReverseLabels(trimmedDomain); !ok {
+					return nil, nil, nil, nil, fmt.Errorf("x509: failed to parse URI constraint %q", domain)
+				}
+				uriDomains = append(uriDomains, domain)
+
+			default:
+				unhandled = true
+			}
+		}
+
+		return dnsNames, ips, emails, uriDomains, nil
+	}
+
+	if out.PermittedDNSDomains, out.PermittedIPRanges, out.PermittedEmailAddresses, out.PermittedURIDomains, err = getValues(constraints.Permitted); err != nil {
+		return false, err
+	}
+	if out.ExcludedDNSDomains, out.ExcludedIPRanges, out.ExcludedEmailAddresses, out.ExcludedURIDomains, err = getValues(constraints.Excluded); err != nil {
+		return false, err
+	}
+	out.PermittedDNSDomainsCritical = e.Critical
+
+	return unhandled && e.Critical, nil
+}
+
 func parseCertificate(in *certificate) (*Certificate, error) {
 	out := new(Certificate)
 	out.Raw = in.Raw
@@ -1179,61 +1425,22 @@ func parseCertificate(in *certificate) (*Certificate, error) {
 				out.MaxPathLenZero = out.MaxPathLen == 0
 				// TODO: map out.MaxPathLen to 0 if it has the -1 default value? (Issue 19285)
 			case 17:
-				out.DNSNames, out.EmailAddresses, out.IPAddresses, err = parseSANExtension(e.Value)
+				out.DNSNames, out.EmailAddresses, out.IPAddresses, out.URIs, err = parseSANExtension(e.Value)
 				if err != nil {
 					return nil, err
 				}
 
-				if len(out.DNSNames) == 0 && len(out.EmailAddresses) == 0 && len(out.IPAddresses) == 0 {
+				if len(out.DNSNames) == 0 && len(out.EmailAddresses) == 0 && len(out.IPAddresses) == 0 && len(out.URIs) == 0 {
 					// If we didn't parse anything then we do the critical check, below.
 					unhandled = true
 				}
 
 			case 30:
-				// RFC 5280, 4.2.1.10
-
-				// NameConstraints ::= SEQUENCE {
-				//      permittedSubtrees       [0]     GeneralSubtrees OPTIONAL,
-				//      excludedSubtrees        [1]     GeneralSubtrees OPTIONAL }
-				//
-				// GeneralSubtrees ::= SEQUENCE SIZE (1..MAX) OF GeneralSubtree
-				//
-				// GeneralSubtree ::= SEQUENCE {
-				//      base                    GeneralName,
-				//      minimum         [0]     BaseDistance DEFAULT 0,
-				//      maximum         [1]     BaseDistance OPTIONAL }
-				//
-				// BaseDistance ::= INTEGER (0..MAX)
-
-				var constraints nameConstraints
-				if rest, err := asn1.Unmarshal(e.Value, &constraints); err != nil {
+				unhandled, err = parseNameConstraintsExtension(out, e)
+				if err != nil {
 					return nil, err
-				} else if len(rest) != 0 {
-					return nil, errors.New("x509: trailing data after X.509 NameConstraints")
 				}
 
-				getDNSNames := func(subtrees []generalSubtree, isCritical bool) (dnsNames []string, err error) {
-					for _, subtree := range subtrees {
-						if len(subtree.Name) == 0 {
-							if isCritical {
-								return nil, UnhandledCriticalExtension{}
-							}
-							continue
-						}
-						dnsNames = append(dnsNames, subtree.Name)
-					}
-
-					return dnsNames, nil
-				}
-
-				if out.PermittedDNSDomains, err = getDNSNames(constraints.Permitted, e.Critical); err != nil {
-					return out, err
-				}
-				if out.ExcludedDNSDomains, err = getDNSNames(constraints.Excluded, e.Critical); err != nil {
-					return out, err
-				}
-				out.PermittedDNSDomainsCritical = e.Critical
-
 			case 31:
 				// RFC 5280, 4.2.1.13
 
@@ -1467,13 +1674,13 @@ func oidInExtensions(oid asn1.ObjectIdentifier, extensions []pkix.Extension) boo
 
 // marshalSANs marshals a list of addresses into a the contents of an X.509
 // SubjectAlternativeName extension.
-func marshalSANs(dnsNames, emailAddresses []string, ipAddresses []net.IP) (derBytes []byte, err error) {
+func marshalSANs(dnsNames, emailAddresses []string, ipAddresses []net.IP, uris []*url.URL) (derBytes []byte, err error) {
 	var rawValues []asn1.RawValue
 	for _, name := range dnsNames {
-		rawValues = append(rawValues, asn1.RawValue{Tag: 2, Class: 2, Bytes: []byte(name)})
+		rawValues = append(rawValues, asn1.RawValue{Tag: nameTypeDNS, Class: asn1.ClassContextSpecific, Bytes: []byte(name)})
 	}
 	for _, email := range emailAddresses {
-		rawValues = append(rawValues, asn1.RawValue{Tag: 1, Class: 2, Bytes: []byte(email)})
+		rawValues = append(rawValues, asn1.RawValue{Tag: nameTypeEmail, Class: asn1.ClassContextSpecific, Bytes: []byte(email)})
 	}
 	for _, rawIP := range ipAddresses {
 		// If possible, we always want to encode IPv4 addresses in 4 bytes.
@@ -1481,7 +1688,10 @@ func marshalSANs(dnsNames, emailAddresses []string, ipAddresses []net.IP) (derBy
 		if ip == nil {
 			ip = rawIP
 		}
-		rawValues = append(rawValues, asn1.RawValue{Tag: 7, Class: 2, Bytes: ip})
+		rawValues = append(rawValues, asn1.RawValue{Tag: nameTypeIP, Class: asn1.ClassContextSpecific, Bytes: ip})
+	}
+	for _, uri := range uris {
+		rawValues = append(rawValues, asn1.RawValue{Tag: nameTypeURI, Class: asn1.ClassContextSpecific, Bytes: []byte(uri.String())})
 	}
 	return asn1.Marshal(rawValues)
 }
@@ -1592,10 +1802,10 @@ func buildExtensions(template *Certificate, authorityKeyId []byte) (ret []pkix.E
 		n++
 	}
 
-	if (len(template.DNSNames) > 0 || len(template.EmailAddresses) > 0 || len(template.IPAddresses) > 0) &&
+	if (len(template.DNSNames) > 0 || len(template.EmailAddresses) > 0 || len(template.IPAddresses) > 0 || len(template.URIs) > 0) &&
 		!oidInExtensions(oidExtensionSubjectAltName, template.ExtraExtensions) {
 		ret[n].Id = oidExtensionSubjectAltName
-		ret[n].Value, err = marshalSANs(template.DNSNames, template.EmailAddresses, template.IPAddresses)
+		ret[n].Value, err = marshalSANs(template.DNSNames, template.EmailAddresses, template.IPAddresses, template.URIs)
 		if err != nil {
 			return
 		}
@@ -1616,22 +1826,46 @@ func buildExtensions(template *Certificate, authorityKeyId []byte) (ret []pkix.E
 		n++
 	}
 
-	if (len(template.PermittedDNSDomains) > 0 || len(template.ExcludedDNSDomains) > 0) &&
+	if template.hasNameConstraints() &&
 		!oidInExtensions(oidExtensionNameConstraints, template.ExtraExtensions) {
+		for _, ipNet := range append(append([]*net.IPNet{}, template.PermittedIPRanges...), template.ExcludedIPRanges...) {
+			if ipNet.IP.Mask(ipNet.Mask) == nil {
+				return nil, fmt.Errorf("x509: IP constraint %s has mismatched address and mask lengths", ipNet)
+			}
+		}
+
 		ret[n].Id = oidExtensionNameConstraints
 		ret[n].Critical = template.PermittedDNSDomainsCritical
 
-		var out nameConstraints
-
-		out.Permitted = make([]generalSubtree, len(template.PermittedDNSDomains))
-		for i, permitted := range template.PermittedDNSDomains {
-			out.Permitted[i] = generalSubtree{Name: permitted}
-		}
-		out.Excluded = make([]generalSubtree, len(template.ExcludedDNSDomains))
-		for i, excluded := range template.ExcludedDNSDomains {
-			out.Excluded[i] = generalSubtree{Name: excluded}
+		subtrees := func(dns []string, ips []*net.IPNet, emails, uriDomains []string) (subtrees []generalSubtree) {
+			add := func(tag int, value []byte) {
+				subtrees = append(subtrees, generalSubtree{
+					Name: asn1.RawValue{Tag: tag, Class: asn1.ClassContextSpecific, Bytes: value},
+				})
+			}
+			for _, name := range dns {
+				add(nameTypeDNS, []byte(name))
+			}
+			for _, ipNet := range ips {
+				// The address is followed by the mask, both in the same
+				// length. Mask converts IPv4 addresses to four bytes if
+				// the mask has four bytes.
+				maskedIP := ipNet.IP.Mask(ipNet.Mask)
+				add(nameTypeIP, append(append([]byte{}, maskedIP...), ipNet.Mask...))
+			}
+			for _, email := range emails {
+				add(nameTypeEmail, []byte(email))
+			}
+			for _, uriDomain := range uriDomains {
+				add(nameTypeURI, []byte(uriDomain))
+			}
+			return subtrees
 		}
 
+		var out nameConstraints
+		out.Permitted = subtrees(template.PermittedDNSDomains, template.PermittedIPRanges, template.PermittedEmailAddresses, template.PermittedURIDomains)
+		out.Excluded = subtrees(template.ExcludedDNSDomains, template.ExcludedIPRanges, template.ExcludedEmailAddresses, template.ExcludedURIDomains)
+
 		ret[n].Value, err = asn1.Marshal(out)
 		if err != nil {
 			return
@@ -1706,8 +1940,12 @@ func signingParamsForPublicKey(pub interface{}, requestedSigAlgo SignatureAlgori
 			err = errors.New("x509: unknown elliptic curve")
 		}
 
+	case ed25519.PublicKey:
+		pubType = Ed25519
+		sigAlgo.Algorithm = oidSignatureEd25519
+
 	default:
-		err = errors.New("x509: only RSA and ECDSA keys supported")
+		err = errors.New("x509: only RSA, ECDSA and Ed25519 keys supported")
 	}
 
 	if err != nil {
@@ -1726,7 +1964,7 @@ func signingParamsForPublicKey(pub interface{}, requestedSigAlgo SignatureAlgori
 				return
 			}
 			sigAlgo.Algorithm, hashFunc = details.oid, details.hash
-			if hashFunc == 0 {
+			if hashFunc == 0 && pubType != Ed25519 {
 				err = errors.New("x509: cannot sign with hash function requested")
 				return
 			}
@@ -1747,10 +1985,12 @@ func signingParamsForPublicKey(pub interface{}, requestedSigAlgo SignatureAlgori
 
 // CreateCertificate creates a new certificate based on a template.
 // The following members of template are used: AuthorityKeyId,
-// BasicConstraintsValid, DNSNames, ExcludedDNSDomains, ExtKeyUsage,
+// BasicConstraintsValid, DNSNames, ExcludedDNSDomains,
+// ExcludedEmailAddresses, ExcludedIPRanges, ExcludedURIDomains, ExtKeyUsage,
 // IsCA, KeyUsage, MaxPathLen, MaxPathLenZero, NotAfter, NotBefore,
-// PermittedDNSDomains, PermittedDNSDomainsCritical, SerialNumber,
-// SignatureAlgorithm, Subject, SubjectKeyId, and UnknownExtKeyUsage.
+// PermittedDNSDomains, PermittedDNSDomainsCritical, PermittedEmailAddresses,
+// PermittedIPRanges, PermittedURIDomains, SerialNumber, SignatureAlgorithm,
+// Subject, SubjectKeyId, UnknownExtKeyUsage, and URIs.
 //
 // The certificate is signed by parent. If parent is equal to template then the
 // certificate is self-signed. The parameter pub is the public key of the
@@ -1759,7 +1999,7 @@ func signingParamsForPublicKey(pub interface{}, requestedSigAlgo SignatureAlgori
 // The returned slice is the certificate in DER encoding.
 //
 // All keys types that are implemented via crypto.Signer are supported (This
-// includes *rsa.PublicKey and *ecdsa.PublicKey.)
+// includes *rsa.PublicKey, *ecdsa.PublicKey and ed25519.PrivateKey.)
 //
 // The AuthorityKeyId will be taken from the SubjectKeyId of parent, if any,
 // unless the resulting certificate is self-signed. Otherwise the value from
@@ -1823,9 +2063,12 @@ func CreateCertificate(rand io.Reader, template, parent *Certificate, pub, priv
 
 	c.Raw = tbsCertContents
 
-	h := hashFunc.New()
-	h.Write(tbsCertContents)
-	digest := h.Sum(nil)
+	digest := tbsCertContents
+	if hashFunc != 0 {
+		h := hashFunc.New()
+		h.Write(tbsCertContents)
+		digest = h.Sum(nil)
+	}
 
 	var signerOpts crypto.SignerOpts
 	signerOpts = hashFunc
@@ -1861,6 +2104,9 @@ var pemType = "X509 CRL"
 // encoded CRLs will appear where they should be DER encoded, so this function
 // will transparently handle PEM encoding as long as there isn't any leading
 // garbage.
+//
+// To parse a CRL into a RevocationList, with its extensions decoded, use
+// ParseRevocationList instead.
 func ParseCRL(crlBytes []byte) (*pkix.CertificateList, error) {
 	if bytes.HasPrefix(crlBytes, pemCRLPrefix) {
 		block, _ := pem.Decode(crlBytes)
@@ -1884,6 +2130,10 @@ func ParseDERCRL(derBytes []byte) (*pkix.CertificateList, error) {
 
 // CreateCRL returns a DER encoded CRL, signed by this Certificate, that
 // contains the given list of revoked certificates.
+//
+// CreateCRL doesn't set a CRL number, which RFC 5280 requires, and offers no
+// control over the CRL extensions. Use CreateRevocationList instead to
+// generate a conforming X.509 v2 CRL.
 func (c *Certificate) CreateCRL(rand io.Reader, priv interface{}, revokedCerts []pkix.RevokedCertificate, now, expiry time.Time) (crlBytes []byte, err error) {
 	key, ok := priv.(crypto.Signer)
 	if !ok {
@@ -1927,9 +2177,12 @@ func (c *Certificate) CreateCRL(rand io.Reader, priv interface{}, revokedCerts [
 		return
 	}
 
-	h := hashFunc.New()
-	h.Write(tbsCertListContents)
-	digest := h.Sum(nil)
+	digest := tbsCertListContents
+	if hashFunc != 0 {
+		h := hashFunc.New()
+		h.Write(tbsCertListContents)
+		digest = h.Sum(nil)
+	}
 
 	var signature []byte
 	signature, err = key.Sign(rand, digest, hashFunc)
@@ -1944,6 +2197,403 @@ func (c *Certificate) CreateCRL(rand io.Reader, priv interface{}, revokedCerts [
 	})
 }
 
+// RFC 5280, 5.2 and 5.3
+var (
+	oidExtensionCRLNumber         = asn1.ObjectIdentifier{2, 5, 29, 20}
+	oidExtensionReasonCode        = asn1.ObjectIdentifier{2, 5, 29, 21}
+	oidExtensionInvalidityDate    = asn1.ObjectIdentifier{2, 5, 29, 24}
+	oidExtensionDeltaCRLIndicator = asn1.ObjectIdentifier{2, 5, 29, 27}
+)
+
+// These structures reflect the ASN.1 structure of X.509 CRLs. Unlike the
+// ones in crypto/x509/pkix, they preserve the raw encoding of the issuer and
+// of each entry.
+type certificateList struct {
+	Raw                asn1.RawContent
+	TBSCertList        tbsCertificateList
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	SignatureValue     asn1.BitString
+}
+
+type tbsCertificateList struct {
+	Raw                 asn1.RawContent
+	Version             int `asn1:"optional,default:0"`
+	Signature           pkix.AlgorithmIdentifier
+	Issuer              asn1.RawValue
+	ThisUpdate          time.Time
+	NextUpdate          time.Time            `asn1:"optional"`
+	RevokedCertificates []revokedCertificate `asn1:"optional"`
+	Extensions          []pkix.Extension     `asn1:"tag:0,optional,explicit"`
+}
+
+type revokedCertificate struct {
+	Raw            asn1.RawContent
+	SerialNumber   *big.Int
+	RevocationTime time.Time
+	Extensions     []pkix.Extension `asn1:"optional"`
+}
+
+// RevocationListEntry represents an entry in the revokedCertificates sequence
+// of a CRL.
+type RevocationListEntry struct {
+	// Raw contains the raw bytes of the revokedCertificates entry. It is set
+	// when parsing a CRL and ignored when generating one.
+	Raw []byte
+
+	// SerialNumber is the serial number of the revoked certificate.
+	SerialNumber *big.Int
+	// RevocationTime is the time at which the certificate was revoked.
+	RevocationTime time.Time
+	// ReasonCode is the reason for the revocation, using the values of the
+	// CRLReason enumeration of RFC 5280, 5.3.1. A zero value, unspecified,
+	// omits the reasonCode extension, as RFC 5280 recommends.
+	ReasonCode int
+	// InvalidityDate, if not zero, is the time at which the key is known or
+	// suspected to have been compromised. See RFC 5280, 5.3.2.
+	InvalidityDate time.Time
+
+	// Extensions contains raw entry extensions. When parsing a CRL, this
+	// can be used to extract extensions that are not parsed by this package.
+	// It is ignored when generating a CRL; see ExtraExtensions.
+	Extensions []pkix.Extension
+	// ExtraExtensions contains extensions to be copied, raw, into the entry.
+	// Values override any extensions that would otherwise be produced based
+	// on the other fields. It is ignored when parsing a CRL; see Extensions.
+	ExtraExtensions []pkix.Extension
+}
+
+// RevocationList represents an X.509 v2 certificate revocation list, as
+// specified in RFC 5280.
+type RevocationList struct {
+	Raw                  []byte // Complete ASN.1 DER content (CRL, signature algorithm and signature).
+	RawTBSRevocationList []byte // tbsCertList part of raw ASN.1 DER content.
+	RawIssuer            []byte // DER encoded issuer.
+
+	// Issuer and AuthorityKeyId are set when parsing a CRL. When generating
+	// one, they are taken from the issuer certificate.
+	Issuer         pkix.Name
+	AuthorityKeyId []byte
+
+	Signature          []byte
+	SignatureAlgorithm SignatureAlgorithm
+
+	// RevokedCertificates lists the revoked certificates.
+	RevokedCertificates []RevocationListEntry
+
+	// Number is the CRL number, a monotonically increasing sequence number
+	// for a given CRL scope and issuer. It must be set when generating a CRL
+	// and can't be longer than 20 octets. See RFC 5280, 5.2.3.
+	Number *big.Int
+	// ThisUpdate is the issue date of this CRL.
+	ThisUpdate time.Time
+	// NextUpdate is the date by which the next CRL will be issued. It must
+	// not be before ThisUpdate.
+	NextUpdate time.Time
+
+	// BaseCRLNumber, if not nil, marks this CRL as a delta CRL with the
+	// critical delta CRL indicator extension, and is the number of the
+	// complete CRL that it updates. See RFC 5280, 5.2.4. Complete CRLs can
+	// point to their delta CRLs with a freshest CRL extension, which can be
+	// supplied through ExtraExtensions.
+	BaseCRLNumber *big.Int
+
+	// Extensions contains raw X.509 extensions. When parsing a CRL, this can
+	// be used to extract extensions that are not parsed by this package. It
+	// is ignored when generating a CRL; see ExtraExtensions.
+	Extensions []pkix.Extension
+	// ExtraExtensions contains extensions to be copied, raw, into the CRL.
+	// Values override any extensions that would otherwise be produced based
+	// on the other fields. It is ignored when parsing a CRL; see Extensions.
+	ExtraExtensions []pkix.Extension
+}
+
+// checkCRLNumber reports an error if n can't be encoded as a CRL number, a
+// non-negative INTEGER of at most 20 octets.
+func checkCRLNumber(n *big.Int, name string) error {
+	if n.Sign() < 0 {
+		return fmt.Errorf("x509: %s must not be negative", name)
+	}
+	// The INTEGER encoding needs a leading zero byte if the top bit is set.
+	if b := n.Bytes(); len(b) > 20 || len(b) == 20 && b[0]&0x80 != 0 {
+		return fmt.Errorf("x509: %s exceeds 20 octets", name)
+	}
+	return nil
+}
+
+// CreateRevocationList creates a new X.509 v2 certificate revocation list,
+// according to RFC 5280, based on template.
+//
+// The CRL is signed by priv, which should be the private key associated with
+// the public key in the issuer certificate. The issuer must have the crlSign
+// key usage bit set and a subject key identifier, which is used for the
+// authority key identifier extension of the CRL. The issuer distinguished
+// name of the CRL is copied from the raw subject of the issuer.
+//
+// The following members of template are used: BaseCRLNumber,
+// ExtraExtensions, NextUpdate, Number, RevokedCertificates,
+// SignatureAlgorithm and ThisUpdate.
+//
+// The returned slice is the CRL in DER encoding.
+func CreateRevocationList(rand io.Reader, template *RevocationList, issuer *Certificate, priv crypto.Signer) ([]byte, error) {
+	if template == nil {
+		return nil, errors.New("x509: template can not be nil")
+	}
+	if issuer == nil {
+		return nil, errors.New("x509: issuer can not be nil")
+	}
+	if priv == nil {
+		return nil, errors.New("x509: signer can not be nil")
+	}
+	if issuer.KeyUsage&KeyUsageCRLSign == 0 {
+		return nil, errors.New("x509: issuer must have the crlSign key usage bit set")
+	}
+	if len(issuer.SubjectKeyId) == 0 {
+		return nil, errors.New("x509: issuer certificate doesn't contain a subject key identifier")
+	}
+	if template.NextUpdate.Before(template.ThisUpdate) {
+		return nil, errors.New("x509: template.ThisUpdate is after template.NextUpdate")
+	}
+	if template.Number == nil {
+		return nil, errors.New("x509: template contains nil Number field")
+	}
+	if err := checkCRLNumber(template.Number, "CRL number"); err != nil {
+		return nil, err
+	}
+	if template.BaseCRLNumber != nil {
+		if err := checkCRLNumber(template.BaseCRLNumber, "base CRL number"); err != nil {
+			return nil, err
+		}
+	}
+
+	hashFunc, signatureAlgorithm, err := signingParamsForPublicKey(priv.Public(), template.SignatureAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	revokedCerts := make([]revokedCertificate, len(template.RevokedCertificates))
+	for i, rc := range template.RevokedCertificates {
+		if rc.SerialNumber == nil {
+			return nil, errors.New("x509: revoked certificate entry contains nil SerialNumber field")
+		}
+		revokedCerts[i] = revokedCertificate{
+			SerialNumber: rc.SerialNumber,
+			// Force revocation times to UTC per RFC 5280.
+			RevocationTime: rc.RevocationTime.UTC(),
+		}
+
+		if rc.ReasonCode != 0 && !oidInExtensions(oidExtensionReasonCode, rc.ExtraExtensions) {
+			value, err := asn1.Marshal(asn1.Enumerated(rc.ReasonCode))
+			if err != nil {
+				return nil, err
+			}
+			revokedCerts[i].Extensions = append(revokedCerts[i].Extensions, pkix.Extension{Id: oidExtensionReasonCode, Value: value})
+		}
+		if !rc.InvalidityDate.IsZero() && !oidInExtensions(oidExtensionInvalidityDate, rc.ExtraExtensions) {
+			// RFC 5280, 5.3.2: InvalidityDate ::= GeneralizedTime
+			value, err := asn1.Marshal(asn1.RawValue{
+				Tag:   asn1.TagGeneralizedTime,
+				Bytes: []byte(rc.InvalidityDate.UTC().Format("20060102150405Z0700")),
+			})
+			if err != nil {
+				return nil, err
+			}
+			revokedCerts[i].Extensions = append(revokedCerts[i].Extensions, pkix.Extension{Id: oidExtensionInvalidityDate, Value: value})
+		}
+		revokedCerts[i].Extensions = append(revokedCerts[i].Extensions, rc.ExtraExtensions...)
+	}
+
+	var extensions []pkix.Extension
+	if !oidInExtensions(oidExtensionAuthorityKeyId, template.ExtraExtensions) {
+		value, err := asn1.Marshal(authKeyId{Id: issuer.SubjectKeyId})
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionAuthorityKeyId, Value: value})
+	}
+	if !oidInExtensions(oidExtensionCRLNumber, template.ExtraExtensions) {
+		value, err := asn1.Marshal(template.Number)
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionCRLNumber, Value: value})
+	}
+	if template.BaseCRLNumber != nil && !oidInExtensions(oidExtensionDeltaCRLIndicator, template.ExtraExtensions) {
+		value, err := asn1.Marshal(template.BaseCRLNumber)
+		if err != nil {
+			return nil, err
+		}
+		// RFC 5280, 5.2.4: the delta CRL indicator is a critical extension.
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionDeltaCRLIndicator, Critical: true, Value: value})
+	}
+	extensions = append(extensions, template.ExtraExtensions...)
+
+	rawIssuer, err := subjectBytes(issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	tbsCertList := tbsCertificateList{
+		Version:             1, // v2
+		Signature:           signatureAlgorithm,
+		Issuer:              asn1.RawValue{FullBytes: rawIssuer},
+		ThisUpdate:          template.ThisUpdate.UTC(),
+		NextUpdate:          template.NextUpdate.UTC(),
+		RevokedCertificates: revokedCerts,
+		Extensions:          extensions,
+	}
+
+	tbsCertListContents, err := asn1.Marshal(tbsCertList)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := tbsCertListContents
+	if hashFunc != 0 {
+		h := hashFunc.New()
+		h.Write(tbsCertListContents)
+		digest = h.Sum(nil)
+	}
+
+	var signerOpts crypto.SignerOpts = hashFunc
+	if template.SignatureAlgorithm.isRSAPSS() {
+		signerOpts = &rsa.PSSOptions{
+			SaltLength: rsa.PSSSaltLengthEqualsHash,
+			Hash:       hashFunc,
+		}
+	}
+
+	signature, err := priv.Sign(rand, digest, signerOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(certificateList{
+		TBSCertList:        tbsCertList,
+		SignatureAlgorithm: signatureAlgorithm,
+		SignatureValue:     asn1.BitString{Bytes: signature, BitLength: len(signature) * 8},
+	})
+}
+
+// ParseRevocationList parses a X509 v2 Certificate Revocation List from the
+// given ASN.1 DER data.
+//
+// The authority key identifier, CRL number, delta CRL indicator and, for each
+// entry, the reason code and invalidity date extensions are decoded into the
+// corresponding fields. All extensions, including those, are also returned
+// raw in Extensions.
+func ParseRevocationList(der []byte) (*RevocationList, error) {
+	var crl certificateList
+	if rest, err := asn1.Unmarshal(der, &crl); err != nil {
+		return nil, err
+	} else if len(rest) != 0 {
+		return nil, errors.New("x509: trailing data after CRL")
+	}
+
+	tbs := &crl.TBSCertList
+	if tbs.Version != 0 && tbs.Version != 1 {
+		return nil, fmt.Errorf("x509: unsupported CRL version %d", tbs.Version+1)
+	}
+	if !tbs.Signature.Algorithm.Equal(crl.SignatureAlgorithm.Algorithm) ||
+		!bytes.Equal(tbs.Signature.Parameters.FullBytes, crl.SignatureAlgorithm.Parameters.FullBytes) {
+		return nil, errors.New("x509: inner and outer signature algorithm identifiers don't match")
+	}
+
+	rl := &RevocationList{
+		Raw:                  crl.Raw,
+		RawTBSRevocationList: tbs.Raw,
+		RawIssuer:            tbs.Issuer.FullBytes,
+		Signature:            crl.SignatureValue.RightAlign(),
+		SignatureAlgorithm:   getSignatureAlgorithmFromAI(crl.SignatureAlgorithm),
+		ThisUpdate:           tbs.ThisUpdate,
+		NextUpdate:           tbs.NextUpdate,
+		Extensions:           tbs.Extensions,
+	}
+
+	var issuer pkix.RDNSequence
+	if rest, err := asn1.Unmarshal(tbs.Issuer.FullBytes, &issuer); err != nil {
+		return nil, err
+	} else if len(rest) != 0 {
+		return nil, errors.New("x509: trailing data after X.509 CRL issuer")
+	}
+	rl.Issuer.FillFromRDNSequence(&issuer)
+
+	for _, e := range tbs.Extensions {
+		switch {
+		case e.Id.Equal(oidExtensionAuthorityKeyId):
+			var a authKeyId
+			if rest, err := asn1.Unmarshal(e.Value, &a); err != nil {
+				return nil, err
+			} else if len(rest) != 0 {
+				return nil, errors.New("x509: trailing data after X.509 authority key-id")
+			}
+			rl.AuthorityKeyId = a.Id
+		case e.Id.Equal(oidExtensionCRLNumber):
+			if rest, err := asn1.Unmarshal(e.Value, &rl.Number); err != nil {
+				return nil, err
+			} else if len(rest) != 0 {
+				return nil, errors.New("x509: trailing data after X.509 CRL number")
+			}
+		case e.Id.Equal(oidExtensionDeltaCRLIndicator):
+			if rest, err := asn1.Unmarshal(e.Value, &rl.BaseCRLNumber); err != nil {
+				return nil, err
+			} else if len(rest) != 0 {
+				return nil, errors.New("x509: trailing data after X.509 delta CRL indicator")
+			}
+		}
+	}
+
+	if len(tbs.RevokedCertificates) > 0 {
+		rl.RevokedCertificates = make([]RevocationListEntry, len(tbs.RevokedCertificates))
+	}
+	for i, rc := range tbs.RevokedCertificates {
+		entry := &rl.RevokedCertificates[i]
+		entry.Raw = rc.Raw
+		entry.SerialNumber = rc.SerialNumber
+		entry.RevocationTime = rc.RevocationTime
+		entry.Extensions = rc.Extensions
+
+		for _, e := range rc.Extensions {
+			switch {
+			case e.Id.Equal(oidExtensionReasonCode):
+				var reason asn1.Enumerated
+				if rest, err := asn1.Unmarshal(e.Value, &reason); err != nil {
+					return nil, err
+				} else if len(rest) != 0 {
+					return nil, errors.New("x509: trailing data after X.509 CRL reason code")
+				}
+				entry.ReasonCode = int(reason)
+			case e.Id.Equal(oidExtensionInvalidityDate):
+				if rest, err := asn1.UnmarshalWithParams(e.Value, &entry.InvalidityDate, "generalized"); err != nil {
+					return nil, err
+				} else if len(rest) != 0 {
+					return nil, errors.New("x509: trailing data after X.509 CRL invalidity date")
+				}
+			}
+		}
+	}
+
+	return rl, nil
+}
+
+// CheckSignatureFrom verifies that the signature on rl is a valid signature
+// from issuer.
+func (rl *RevocationList) CheckSignatureFrom(parent *Certificate) error {
+	if parent.Version == 3 && !parent.BasicConstraintsValid ||
+		parent.BasicConstraintsValid && !parent.IsCA {
+		return ConstraintViolationError{}
+	}
+
+	if parent.KeyUsage != 0 && parent.KeyUsage&KeyUsageCRLSign == 0 {
+		return ConstraintViolationError{}
+	}
+
+	if parent.PublicKeyAlgorithm == UnknownPublicKeyAlgorithm {
+		return ErrUnsupportedAlgorithm
+	}
+
+	return parent.CheckSignature(rl.SignatureAlgorithm, rl.RawTBSRevocationList, rl.Signature)
+}
+
 // CertificateRequest represents a PKCS #10, certificate signature request.
 type CertificateRequest struct {
 	Raw                      []byte // Complete ASN.1 DER content (CSR, signature algorithm and signature).
@@ -1981,6 +2631,7 @@ type CertificateRequest struct {
 	DNSNames       []string
 	EmailAddresses []string
 	IPAddresses    []net.IP
+	URIs           []*url.URL
 }
 
 // These structures reflect the ASN.1 structure of X.509 certificate
@@ -2072,13 +2723,13 @@ func parseCSRExtensions(rawAttributes []asn1.RawValue) ([]pkix.Extension, error)
 
 // CreateCertificateRequest creates a new certificate request based on a
 // template. The following members of template are used: Attributes, DNSNames,
-// EmailAddresses, ExtraExtensions, IPAddresses, SignatureAlgorithm, and
-// Subject. The private key is the private key of the signer.
+// EmailAddresses, ExtraExtensions, IPAddresses, SignatureAlgorithm, Subject,
+// and URIs. The private key is the private key of the signer.
 //
 // The returned slice is the certificate request in DER encoding.
 //
 // All keys types that are implemented via crypto.Signer are supported (This
-// includes *rsa.PublicKey and *ecdsa.PublicKey.)
+// includes *rsa.PublicKey, *ecdsa.PublicKey and ed25519.PrivateKey.)
 func CreateCertificateRequest(rand io.Reader, template *CertificateRequest, priv interface{}) (csr []byte, err error) {
 	key, ok := priv.(crypto.Signer)
 	if !ok {
@@ -2101,9 +2752,9 @@ func CreateCertificateRequest(rand io.Reader, template *CertificateRequest, priv
 
 	var extensions []pkix.Extension
 
-	if (len(template.DNSNames) > 0 || len(template.EmailAddresses) > 0 || len(template.IPAddresses) > 0) &&
+	if (len(template.DNSNames) > 0 || len(template.EmailAddresses) > 0 || len(template.IPAddresses) > 0 || len(template.URIs) > 0) &&
 		!oidInExtensions(oidExtensionSubjectAltName, template.ExtraExtensions) {
-		sanBytes, err := marshalSANs(template.DNSNames, template.EmailAddresses, template.IPAddresses)
+		sanBytes, err := marshalSANs(template.DNSNames, template.EmailAddresses, template.IPAddresses, template.URIs)
 		if err != nil {
 			return nil, err
 		}
@@ -2206,9 +2857,12 @@ func CreateCertificateRequest(rand io.Reader, template *CertificateRequest, priv
 	}
 	tbsCSR.Raw = tbsCSRContents
 
-	h := hashFunc.New()
-	h.Write(tbsCSRContents)
-	digest := h.Sum(nil)
+	digest := tbsCSRContents
+	if hashFunc != 0 {
+		h := hashFunc.New()
+		h.Write(tbsCSRContents)
+		digest = h.Sum(nil)
+	}
 
 	var signature []byte
 	signature, err = key.Sign(rand, digest, hashFunc)
@@ -2278,7 +2932,7 @@ func parseCertificateRequest(in *certificateRequest) (*CertificateRequest, error
 
 	for _, extension := range out.Extensions {
 		if extension.Id.Equal(oidExtensionSubjectAltName) {
-			out.DNSNames, out.EmailAddresses, out.IPAddresses, err = parseSANExtension(extension.Value)
+			out.DNSNames, out.EmailAddresses, out.IPAddresses, out.URIs, err = parseSANExtension(extension.Value)
 			if err != nil {
 				return nil, err
 			}
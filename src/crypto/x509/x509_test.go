@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"crypto/dsa"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
@@ -22,6 +23,7 @@ import (
 	"internal/testenv"
 	"math/big"
 	"net"
+	"net/url"
 	"os/exec"
 	"reflect"
 	"runtime"
@@ -339,6 +341,11 @@ func TestCreateSelfSignedCertificate(t *testing.T) {
 		t.Fatalf("Failed to generate ECDSA key: %s", err)
 	}
 
+	ed25519Pub, ed25519Priv, err := ed25519.GenerateKey(random)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %s", err)
+	}
+
 	tests := []struct {
 		name      string
 		pub, priv interface{}
@@ -352,6 +359,7 @@ func TestCreateSelfSignedCertificate(t *testing.T) {
 		{"RSAPSS/RSAPSS", &testPrivateKey.PublicKey, testPrivateKey, true, SHA256WithRSAPSS},
 		{"ECDSA/RSAPSS", &ecdsaPriv.PublicKey, testPrivateKey, false, SHA256WithRSAPSS},
 		{"RSAPSS/ECDSA", &testPrivateKey.PublicKey, ecdsaPriv, false, ECDSAWithSHA384},
+		{"Ed25519", ed25519Pub, ed25519Priv, true, PureEd25519},
 	}
 
 	testExtKeyUsage := []ExtKeyUsage{ExtKeyUsageClientAuth, ExtKeyUsageServerAuth}
@@ -402,10 +410,17 @@ func TestCreateSelfSignedCertificate(t *testing.T) {
 			DNSNames:       []string{"test.example.com"},
 			EmailAddresses: []string{"gopher@golang.org"},
 			IPAddresses:    []net.IP{net.IPv4(127, 0, 0, 1).To4(), net.ParseIP("2001:4860:0:2001::68")},
-
-			PolicyIdentifiers:   []asn1.ObjectIdentifier{[]int{1, 2, 3}},
-			PermittedDNSDomains: []string{".example.com", "example.com"},
-			ExcludedDNSDomains:  []string{"bar.example.com"},
+			URIs:           []*url.URL{parseURI("https://foo.com/wibble#foo")},
+
+			PolicyIdentifiers:       []asn1.ObjectIdentifier{[]int{1, 2, 3}},
+			PermittedDNSDomains:     []string{".example.com", "example.com"},
+			ExcludedDNSDomains:      []string{"bar.example.com"},
+			PermittedIPRanges:       []*net.IPNet{parseCIDR("192.168.1.1/16"), parseCIDR("1.2.3.4/8")},
+			ExcludedIPRanges:        []*net.IPNet{parseCIDR("2001:db8::/48")},
+			PermittedEmailAddresses: []string{"foo@example.com"},
+			ExcludedEmailAddresses:  []string{".example.com", "example.com"},
+			PermittedURIDomains:     []string{".bar.com", "bar.com"},
+			ExcludedURIDomains:      []string{".bar2.com", "bar2.com"},
 
 			CRLDistributionPoints: []string{"http://crl1.example.com/ca1.crl", "http://crl2.example.com/ca1.crl"},
 
@@ -447,6 +462,34 @@ func TestCreateSelfSignedCertificate(t *testing.T) {
 			t.Errorf("%s: failed to parse name constraint exclusions: %#v", test.name, cert.ExcludedDNSDomains)
 		}
 
+		if len(cert.PermittedIPRanges) != 2 || cert.PermittedIPRanges[0].String() != "192.168.0.0/16" || cert.PermittedIPRanges[1].String() != "1.0.0.0/8" {
+			t.Errorf("%s: failed to parse IP constraints: %#v", test.name, cert.PermittedIPRanges)
+		}
+
+		if len(cert.ExcludedIPRanges) != 1 || cert.ExcludedIPRanges[0].String() != "2001:db8::/48" {
+			t.Errorf("%s: failed to parse IP constraint exclusions: %#v", test.name, cert.ExcludedIPRanges)
+		}
+
+		if len(cert.PermittedEmailAddresses) != 1 || cert.PermittedEmailAddresses[0] != "foo@example.com" {
+			t.Errorf("%s: failed to parse permitted email addresses: %#v", test.name, cert.PermittedEmailAddresses)
+		}
+
+		if len(cert.ExcludedEmailAddresses) != 2 || cert.ExcludedEmailAddresses[0] != ".example.com" || cert.ExcludedEmailAddresses[1] != "example.com" {
+			t.Errorf("%s: failed to parse excluded email addresses: %#v", test.name, cert.ExcludedEmailAddresses)
+		}
+
+		if len(cert.PermittedURIDomains) != 2 || cert.PermittedURIDomains[0] != ".bar.com" || cert.PermittedURIDomains[1] != "bar.com" {
+			t.Errorf("%s: failed to parse permitted URIs: %#v", test.name, cert.PermittedURIDomains)
+		}
+
+		if len(cert.ExcludedURIDomains) != 2 || cert.ExcludedURIDomains[0] != ".bar2.com" || cert.ExcludedURIDomains[1] != "bar2.com" {
+			t.Errorf("%s: failed to parse excluded URIs: %#v", test.name, cert.ExcludedURIDomains)
+		}
+
+		if len(cert.URIs) != 1 || cert.URIs[0].String() != "https://foo.com/wibble#foo" {
+			t.Errorf("%s: URIs didn't match: %#v", test.name, cert.URIs)
+		}
+
 		if cert.Subject.CommonName != commonName {
 			t.Errorf("%s: subject wasn't correctly copied from the template. Got %s, want %s", test.name, cert.Subject.CommonName, commonName)
 		}
@@ -699,6 +742,38 @@ func TestECDSA(t *testing.T) {
 	}
 }
 
+// Self-signed certificate using Ed25519, generated with
+//   openssl req -x509 -newkey ed25519 -nodes -subj "/CN=Ed25519 test" -days 3650
+const ed25519CertPem = `-----BEGIN CERTIFICATE-----
+MIIBQjCB9aADAgECAhQ31S7rfiQ7YnwZfARHmAbcuaAd8jAFBgMrZXAwFzEVMBMG
+A1UEAwwMRWQyNTUxOSB0ZXN0MB4XDTI2MTAxNjEyNDA1NVoXDTM2MTAxMzEyNDA1
+NVowFzEVMBMGA1UEAwwMRWQyNTUxOSB0ZXN0MCowBQYDK2VwAyEAh3TKXOeuSuEg
+KDaJthIgsK2XqyqQPlM4v4ExvxCdb8+jUzBRMB0GA1UdDgQWBBTNUz49TRdHY+fp
+Np1n8QD4OALd0DAfBgNVHSMEGDAWgBTNUz49TRdHY+fpNp1n8QD4OALd0DAPBgNV
+HRMBAf8EBTADAQH/MAUGAytlcANBAHwCrN0Jj8ncScMu2GzupRxphbW2pbFmU+7y
+bnU3Gx9ZAKcOWC81Aq/GRTZHDAp96fGpXrX05VJIZANI90Am+gQ=
+-----END CERTIFICATE-----`
+
+func TestEd25519SelfSigned(t *testing.T) {
+	pemBlock, _ := pem.Decode([]byte(ed25519CertPem))
+	cert, err := ParseCertificate(pemBlock.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %s", err)
+	}
+	if sa := cert.SignatureAlgorithm; sa != PureEd25519 {
+		t.Errorf("signature algorithm is %v, want %v", sa, PureEd25519)
+	}
+	if parsedKey, ok := cert.PublicKey.(ed25519.PublicKey); !ok {
+		t.Errorf("wanted an Ed25519 public key but found: %#v", parsedKey)
+	}
+	if pka := cert.PublicKeyAlgorithm; pka != Ed25519 {
+		t.Errorf("public key algorithm is %v, want Ed25519", pka)
+	}
+	if err = cert.CheckSignatureFrom(cert); err != nil {
+		t.Errorf("certificate verification failed: %s", err)
+	}
+}
+
 // Self-signed certificate using DSA with SHA1
 var dsaCertPem = `-----BEGIN CERTIFICATE-----
 MIIEDTCCA82gAwIBAgIJALHPghaoxeDhMAkGByqGSM44BAMweTELMAkGA1UEBhMC
@@ -899,6 +974,252 @@ func TestCRLCreation(t *testing.T) {
 	}
 }
 
+func TestRevocationListRoundTrip(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuerTemplate := &Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CRL Issuer"},
+		NotBefore:             time.Unix(1000, 0),
+		NotAfter:              time.Unix(100000, 0),
+		KeyUsage:              KeyUsageCertSign | KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:         true,
+		SubjectKeyId: []byte{1, 2, 3, 4},
+	}
+	issuerDER, err := CreateCertificate(rand.Reader, issuerTemplate, issuerTemplate, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, err := ParseCertificate(issuerDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loc := time.FixedZone("Oz/Atlantis", int((2 * time.Hour).Seconds()))
+	now := time.Unix(1000, 0).In(loc)
+	extraExtension := pkix.Extension{Id: asn1.ObjectIdentifier{1, 2, 3, 4}, Value: []byte{0x05, 0x00}}
+
+	template := &RevocationList{
+		Number:     big.NewInt(5),
+		ThisUpdate: now,
+		NextUpdate: now.Add(time.Hour),
+		RevokedCertificates: []RevocationListEntry{
+			{
+				SerialNumber:   big.NewInt(1),
+				RevocationTime: now,
+			},
+			{
+				SerialNumber:   big.NewInt(42),
+				RevocationTime: now,
+				ReasonCode:     1, // keyCompromise
+				InvalidityDate: time.Unix(500, 0),
+			},
+		},
+		ExtraExtensions: []pkix.Extension{extraExtension},
+	}
+
+	der, err := CreateRevocationList(rand.Reader, template, issuer, priv)
+	if err != nil {
+		t.Fatalf("CreateRevocationList: %s", err)
+	}
+	rl, err := ParseRevocationList(der)
+	if err != nil {
+		t.Fatalf("ParseRevocationList: %s", err)
+	}
+
+	if !bytes.Equal(rl.Raw, der) {
+		t.Error("Raw doesn't match the input")
+	}
+	if !bytes.Equal(rl.RawIssuer, issuer.RawSubject) {
+		t.Error("RawIssuer doesn't match the issuer's RawSubject")
+	}
+	if rl.Issuer.CommonName != "Test CRL Issuer" {
+		t.Errorf("Issuer.CommonName = %q", rl.Issuer.CommonName)
+	}
+	if !bytes.Equal(rl.AuthorityKeyId, issuer.SubjectKeyId) {
+		t.Errorf("AuthorityKeyId = %x, want %x", rl.AuthorityKeyId, issuer.SubjectKeyId)
+	}
+	if rl.SignatureAlgorithm != ECDSAWithSHA256 {
+		t.Errorf("SignatureAlgorithm = %v, want %v", rl.SignatureAlgorithm, ECDSAWithSHA256)
+	}
+	if rl.Number == nil || rl.Number.Cmp(template.Number) != 0 {
+		t.Errorf("Number = %v, want %v", rl.Number, template.Number)
+	}
+	if rl.BaseCRLNumber != nil {
+		t.Errorf("BaseCRLNumber = %v, want nil", rl.BaseCRLNumber)
+	}
+	if !rl.ThisUpdate.Equal(now) || rl.ThisUpdate.Location() != time.UTC {
+		t.Errorf("ThisUpdate = %v, want %v", rl.ThisUpdate, now.UTC())
+	}
+	if !rl.NextUpdate.Equal(template.NextUpdate) {
+		t.Errorf("NextUpdate = %v, want %v", rl.NextUpdate, template.NextUpdate)
+	}
+	if !oidInExtensions(extraExtension.Id, rl.Extensions) {
+		t.Error("ExtraExtensions not found in Extensions")
+	}
+
+	if len(rl.RevokedCertificates) != 2 {
+		t.Fatalf("got %d revoked certificates, want 2", len(rl.RevokedCertificates))
+	}
+	for i, want := range template.RevokedCertificates {
+		got := rl.RevokedCertificates[i]
+		if got.SerialNumber.Cmp(want.SerialNumber) != 0 {
+			t.Errorf("#%d: SerialNumber = %v, want %v", i, got.SerialNumber, want.SerialNumber)
+		}
+		if !got.RevocationTime.Equal(want.RevocationTime) {
+			t.Errorf("#%d: RevocationTime = %v, want %v", i, got.RevocationTime, want.RevocationTime)
+		}
+		if got.ReasonCode != want.ReasonCode {
+			t.Errorf("#%d: ReasonCode = %d, want %d", i, got.ReasonCode, want.ReasonCode)
+		}
+		if !got.InvalidityDate.Equal(want.InvalidityDate) {
+			t.Errorf("#%d: InvalidityDate = %v, want %v", i, got.InvalidityDate, want.InvalidityDate)
+		}
+	}
+	if len(rl.RevokedCertificates[0].Extensions) != 0 {
+		t.Errorf("unexpected extensions in entry without reason code: %v", rl.RevokedCertificates[0].Extensions)
+	}
+
+	if err := rl.CheckSignatureFrom(issuer); err != nil {
+		t.Errorf("CheckSignatureFrom: %s", err)
+	}
+	rl.RawTBSRevocationList[len(rl.RawTBSRevocationList)-1] ^= 1
+	if err := rl.CheckSignatureFrom(issuer); err == nil {
+		t.Error("CheckSignatureFrom accepted a modified CRL")
+	}
+
+	// The CRL must still be readable by the older API.
+	if _, err := ParseDERCRL(der); err != nil {
+		t.Errorf("ParseDERCRL: %s", err)
+	}
+
+	// A delta CRL carries a critical delta CRL indicator.
+	template.Number = big.NewInt(6)
+	template.BaseCRLNumber = big.NewInt(5)
+	der, err = CreateRevocationList(rand.Reader, template, issuer, priv)
+	if err != nil {
+		t.Fatalf("CreateRevocationList for delta CRL: %s", err)
+	}
+	rl, err = ParseRevocationList(der)
+	if err != nil {
+		t.Fatalf("ParseRevocationList for delta CRL: %s", err)
+	}
+	if rl.BaseCRLNumber == nil || rl.BaseCRLNumber.Cmp(template.BaseCRLNumber) != 0 {
+		t.Errorf("BaseCRLNumber = %v, want %v", rl.BaseCRLNumber, template.BaseCRLNumber)
+	}
+	for _, e := range rl.Extensions {
+		if e.Id.Equal(oidExtensionDeltaCRLIndicator) && !e.Critical {
+			t.Error("delta CRL indicator isn't critical")
+		}
+	}
+}
+
+func TestCreateRevocationListErrors(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := &Certificate{
+		KeyUsage:     KeyUsageCRLSign,
+		SubjectKeyId: []byte{1, 2, 3},
+	}
+	now := time.Unix(1000, 0)
+
+	tests := []struct {
+		name     string
+		template *RevocationList
+		issuer   *Certificate
+		wantErr  string
+	}{
+		{
+			name:    "nil template",
+			issuer:  issuer,
+			wantErr: "template can not be nil",
+		},
+		{
+			name:     "nil issuer",
+			template: &RevocationList{Number: big.NewInt(1)},
+			wantErr:  "issuer can not be nil",
+		},
+		{
+			name:     "issuer without crlSign",
+			template: &RevocationList{Number: big.NewInt(1)},
+			issuer:   &Certificate{KeyUsage: KeyUsageCertSign, SubjectKeyId: []byte{1}},
+			wantErr:  "crlSign",
+		},
+		{
+			name:     "issuer without subject key id",
+			template: &RevocationList{Number: big.NewInt(1)},
+			issuer:   &Certificate{KeyUsage: KeyUsageCRLSign},
+			wantErr:  "subject key identifier",
+		},
+		{
+			name: "next update before this update",
+			template: &RevocationList{
+				Number:     big.NewInt(1),
+				ThisUpdate: now,
+				NextUpdate: now.Add(-time.Hour),
+			},
+			issuer:  issuer,
+			wantErr: "after template.NextUpdate",
+		},
+		{
+			name:     "nil number",
+			template: &RevocationList{},
+			issuer:   issuer,
+			wantErr:  "nil Number",
+		},
+		{
+			name:     "negative number",
+			template: &RevocationList{Number: big.NewInt(-1)},
+			issuer:   issuer,
+			wantErr:  "must not be negative",
+		},
+		{
+			name:     "long number",
+			template: &RevocationList{Number: new(big.Int).Lsh(big.NewInt(1), 159)},
+			issuer:   issuer,
+			wantErr:  "exceeds 20 octets",
+		},
+		{
+			name: "entry without serial number",
+			template: &RevocationList{
+				Number:              big.NewInt(1),
+				RevokedCertificates: []RevocationListEntry{{RevocationTime: now}},
+			},
+			issuer:  issuer,
+			wantErr: "nil SerialNumber",
+		},
+	}
+	for _, test := range tests {
+		_, err := CreateRevocationList(rand.Reader, test.template, test.issuer, priv)
+		if err == nil {
+			t.Errorf("%s: CreateRevocationList succeeded, want error containing %q", test.name, test.wantErr)
+		} else if !strings.Contains(err.Error(), test.wantErr) {
+			t.Errorf("%s: got error %q, want error containing %q", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func parseCIDR(s string) *net.IPNet {
+	_, net, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return net
+}
+
+func parseURI(s string) *url.URL {
+	uri, err := url.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return uri
+}
+
 func fromBase64(in string) []byte {
 	out := make([]byte, base64.StdEncoding.DecodedLen(len(in)))
 	n, err := base64.StdEncoding.Decode(out, []byte(in))
@@ -1010,6 +1331,7 @@ func TestCreateCertificateRequest(t *testing.T) {
 			DNSNames:           []string{"test.example.com"},
 			EmailAddresses:     []string{"gopher@golang.org"},
 			IPAddresses:        []net.IP{net.IPv4(127, 0, 0, 1).To4(), net.ParseIP("2001:4860:0:2001::68")},
+			URIs:               []*url.URL{parseURI("spiffe://example.com/workload")},
 		}
 
 		derBytes, err := CreateCertificateRequest(random, &template, test.priv)
@@ -1040,6 +1362,8 @@ func TestCreateCertificateRequest(t *testing.T) {
 			t.Errorf("%s: output email addresses and template email addresses don't match", test.name)
 		} else if len(out.IPAddresses) != len(template.IPAddresses) {
 			t.Errorf("%s: output IP addresses and template IP addresses names don't match", test.name)
+		} else if len(out.URIs) != 1 || out.URIs[0].String() != template.URIs[0].String() {
+			t.Errorf("%s: output URIs and template URIs don't match", test.name)
 		}
 	}
 }
@@ -1059,7 +1383,7 @@ func marshalAndParseCSR(t *testing.T, template *CertificateRequest) *Certificate
 }
 
 func TestCertificateRequestOverrides(t *testing.T) {
-	sanContents, err := marshalSANs([]string{"foo.example.com"}, nil, nil)
+	sanContents, err := marshalSANs([]string{"foo.example.com"}, nil, nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1116,7 +1440,7 @@ func TestCertificateRequestOverrides(t *testing.T) {
 		t.Errorf("bad attributes: %#v\n", csr.Attributes)
 	}
 
-	sanContents2, err := marshalSANs([]string{"foo2.example.com"}, nil, nil)
+	sanContents2, err := marshalSANs([]string{"foo2.example.com"}, nil, nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
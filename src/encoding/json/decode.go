@@ -16,6 +16,7 @@ import (
 	"reflect"
 	"runtime"
 	"strconv"
+	"strings"
 	"unicode"
 	"unicode/utf16"
 	"unicode/utf8"
@@ -49,6 +50,8 @@ import (
 // keys to the keys used by Marshal (either the struct field name or its tag),
 // preferring an exact match but also accepting a case-insensitive match.
 // Unmarshal will only set exported fields of the struct.
+// By default, object keys which don't have a corresponding struct field
+// are ignored (see Decoder.DisallowUnknownFields for an alternative).
 //
 // To unmarshal JSON into an interface value,
 // Unmarshal stores one of these in the interface value:
@@ -132,7 +135,7 @@ type UnmarshalTypeError struct {
 	Type   reflect.Type // type of Go value it could not be assigned to
 	Offset int64        // error occurred after reading Offset bytes
 	Struct string       // name of the struct type containing the field
-	Field  string       // name of the field holding the Go value
+	Field  string       // the full path from root node to the field
 }
 
 func (e *UnmarshalTypeError) Error() string {
@@ -272,16 +275,20 @@ func isValidNumber(s string) bool {
 
 // decodeState represents the state while decoding a JSON value.
 type decodeState struct {
-	data         []byte
-	off          int // read offset in data
-	scan         scanner
-	nextscan     scanner  // for calls to nextValue
-	errorContext struct { // provides context for type errors
-		Struct string
-		Field  string
-	}
-	savedError error
-	useNumber  bool
+	data                  []byte
+	off                   int // read offset in data
+	scan                  scanner
+	nextscan              scanner // for calls to nextValue
+	errorContext          errorContext
+	savedError            error
+	useNumber             bool
+	disallowUnknownFields bool
+}
+
+// An errorContext provides context for type errors during decoding.
+type errorContext struct {
+	Struct     string   // name of the struct type containing the field
+	FieldStack []string // names of the fields from the root to the current value
 }
 
 // errPhase is used for errors that should not happen unless
@@ -294,7 +301,7 @@ func (d *decodeState) init(data []byte) *decodeState {
 	d.off = 0
 	d.savedError = nil
 	d.errorContext.Struct = ""
-	d.errorContext.Field = ""
+	d.errorContext.FieldStack = d.errorContext.FieldStack[:0]
 	return d
 }
 
@@ -313,11 +320,11 @@ func (d *decodeState) saveError(err error) {
 
 // addErrorContext returns a new error enhanced with information from d.errorContext
 func (d *decodeState) addErrorContext(err error) error {
-	if d.errorContext.Struct != "" || d.errorContext.Field != "" {
+	if d.errorContext.Struct != "" || len(d.errorContext.FieldStack) > 0 {
 		switch err := err.(type) {
 		case *UnmarshalTypeError:
 			err.Struct = d.errorContext.Struct
-			err.Field = d.errorContext.Field
+			err.Field = strings.Join(d.errorContext.FieldStack, ".")
 			return err
 		}
 	}
@@ -658,6 +665,7 @@ func (d *decodeState) object(v reflect.Value) {
 	}
 
 	var mapElem reflect.Value
+	origErrorContext := d.errorContext
 
 	for {
 		// Read opening " of string key or closing }.
@@ -716,8 +724,10 @@ func (d *decodeState) object(v reflect.Value) {
 					}
 					subv = subv.Field(i)
 				}
-				d.errorContext.Field = f.name
+				d.errorContext.FieldStack = append(d.errorContext.FieldStack, f.name)
 				d.errorContext.Struct = v.Type().Name()
+			} else if d.disallowUnknownFields {
+				d.saveError(fmt.Errorf("json: unknown field %q", d.fieldPath(key)))
 			}
 		}
 
@@ -742,6 +752,12 @@ func (d *decodeState) object(v reflect.Value) {
 			d.value(subv)
 		}
 
+		// Reset errorContext to its original state.
+		// Keep the same underlying array for FieldStack, to reuse the
+		// space and avoid unnecessary allocs.
+		d.errorContext.FieldStack = d.errorContext.FieldStack[:len(origErrorContext.FieldStack)]
+		d.errorContext.Struct = origErrorContext.Struct
+
 		// Write value back to map;
 		// if using struct, subv points into struct already.
 		if v.Kind() == reflect.Map {
@@ -787,10 +803,16 @@ func (d *decodeState) object(v reflect.Value) {
 		if op != scanObjectValue {
 			d.error(errPhase)
 		}
+	}
+}
 
-		d.errorContext.Struct = ""
-		d.errorContext.Field = ""
+// fieldPath returns the path from the root of the value being decoded
+// to the object key, with the names of the enclosing fields separated by dots.
+func (d *decodeState) fieldPath(key []byte) string {
+	if len(d.errorContext.FieldStack) == 0 {
+		return string(key)
 	}
+	return strings.Join(d.errorContext.FieldStack, ".") + "." + string(key)
 }
 
 // literal consumes a literal from d.data[d.off-1:], decoding into the value v.
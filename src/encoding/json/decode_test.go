@@ -372,12 +372,13 @@ func (b *intWithPtrMarshalText) UnmarshalText(data []byte) error {
 }
 
 type unmarshalTest struct {
-	in        string
-	ptr       interface{}
-	out       interface{}
-	err       error
-	useNumber bool
-	golden    bool
+	in                    string
+	ptr                   interface{}
+	out                   interface{}
+	err                   error
+	useNumber             bool
+	golden                bool
+	disallowUnknownFields bool
 }
 
 type B struct {
@@ -767,7 +768,7 @@ var unmarshalTests = []unmarshalTest{
 		err: &UnmarshalTypeError{
 			Value:  "string",
 			Struct: "V",
-			Field:  "F2",
+			Field:  "V.F2",
 			Type:   reflect.TypeOf(int32(0)),
 			Offset: 20,
 		},
@@ -778,11 +779,48 @@ var unmarshalTests = []unmarshalTest{
 		err: &UnmarshalTypeError{
 			Value:  "string",
 			Struct: "V",
-			Field:  "F2",
+			Field:  "V.F2",
 			Type:   reflect.TypeOf(int32(0)),
 			Offset: 30,
 		},
 	},
+	{
+		in:  `{"V": {"F4": {"V": {"F2": "hello"}}}}`,
+		ptr: new(VOuter),
+		err: &UnmarshalTypeError{
+			Value:  "string",
+			Struct: "V",
+			Field:  "V.F4.V.F2",
+			Type:   reflect.TypeOf(int32(0)),
+			Offset: 33,
+		},
+	},
+
+	// DisallowUnknownFields reports the path to the unknown field.
+	{
+		in:  `{"X": "x", "Y": 1, "Z": 2}`,
+		ptr: new(T),
+		err: fmt.Errorf("json: unknown field %q", "Z"),
+		disallowUnknownFields: true,
+	},
+	{
+		in:  `{"V": {"F1": 1, "F5": 2}}`,
+		ptr: new(VOuter),
+		err: fmt.Errorf("json: unknown field %q", "V.F5"),
+		disallowUnknownFields: true,
+	},
+	{
+		in:  `{"V": {"F4": {"V": {"F2": 1}, "W": 3}}}`,
+		ptr: new(VOuter),
+		err: fmt.Errorf("json: unknown field %q", "V.F4.W"),
+		disallowUnknownFields: true,
+	},
+	{
+		in:  `{"V": {"F1": {"F5": 1}, "F3": 2}}`,
+		ptr: new(VOuter),
+		out: VOuter{V: V{F1: map[string]interface{}{"F5": float64(1)}, F3: Number("2")}},
+		disallowUnknownFields: true,
+	},
 
 	// issue 15146.
 	// invalid inputs in wrongStringTests below.
@@ -911,6 +949,9 @@ func TestUnmarshal(t *testing.T) {
 		if tt.useNumber {
 			dec.UseNumber()
 		}
+		if tt.disallowUnknownFields {
+			dec.DisallowUnknownFields()
+		}
 		if err := dec.Decode(v.Interface()); !reflect.DeepEqual(err, tt.err) {
 			t.Errorf("#%d: %v, want %v", i, err, tt.err)
 			continue
@@ -7,7 +7,9 @@ package json
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"strings"
 )
 
 // A Decoder reads and decodes JSON values from an input stream.
@@ -35,6 +37,13 @@ func NewDecoder(r io.Reader) *Decoder {
 // Number instead of as a float64.
 func (dec *Decoder) UseNumber() { dec.d.useNumber = true }
 
+// DisallowUnknownFields causes the Decoder to return an error when the destination
+// is a struct and the input contains object keys which do not match any
+// non-ignored, exported fields in the destination.
+// The error names the unknown key by its path from the top-level value,
+// such as "server.prot".
+func (dec *Decoder) DisallowUnknownFields() { dec.d.disallowUnknownFields = true }
+
 // Decode reads the next JSON-encoded value from its
 // input and stores it in the value pointed to by v.
 //
@@ -173,6 +182,9 @@ type Encoder struct {
 	indentBuf    *bytes.Buffer
 	indentPrefix string
 	indentValue  string
+
+	tokenState int
+	tokenStack []int
 }
 
 // NewEncoder returns a new encoder that writes to w.
@@ -185,16 +197,30 @@ func NewEncoder(w io.Writer) *Encoder {
 //
 // See the documentation for Marshal for details about the
 // conversion of Go values to JSON.
+//
+// Between calls to EncodeToken that open and close an array or object,
+// Encode writes v as the next element of the array or as the value
+// for the object key written by the previous call to EncodeToken.
 func (enc *Encoder) Encode(v interface{}) error {
 	if enc.err != nil {
 		return enc.err
 	}
+	if !enc.tokenValueAllowed() {
+		return enc.tokenError("value")
+	}
 	e := newEncodeState()
 	err := e.marshal(v, encOpts{escapeHTML: enc.escapeHTML})
 	if err != nil {
 		return err
 	}
 
+	if enc.tokenState != tokenTopValue {
+		// v is part of an array or object being written by EncodeToken.
+		err = enc.tokenWriteValue(e.Bytes())
+		encodeStatePool.Put(e)
+		return err
+	}
+
 	// Terminate each value with a newline.
 	// This makes the output look a little nicer
 	// when debugging, and some kind of space
@@ -230,6 +256,184 @@ func (enc *Encoder) SetIndent(prefix, indent string) {
 	enc.indentValue = indent
 }
 
+func (enc *Encoder) indenting() bool {
+	return enc.indentPrefix != "" || enc.indentValue != ""
+}
+
+// EncodeToken writes the given JSON token to the stream.
+// It returns an error if the delimiters [ ] { } are not properly used
+// or if t is not one of the types listed in the documentation for Token.
+//
+// EncodeToken is the counterpart of Decoder.Token. It allows an array
+// or object to be written piece by piece, without holding all of it
+// in memory: after an opening delimiter, each token or call to Encode
+// adds an element to the array, or alternately a key and a value to
+// the object, until the matching closing delimiter. A string token
+// where an object key is expected is written as the key.
+// Commas and colons are inserted as needed. Once the top-level value
+// is complete, it is followed by a newline, as with Encode.
+//
+// Each call to EncodeToken writes to the underlying writer.
+// Callers writing many small tokens may wish to wrap it in a bufio.Writer.
+func (enc *Encoder) EncodeToken(t Token) error {
+	if enc.err != nil {
+		return enc.err
+	}
+	switch t := t.(type) {
+	case Delim:
+		switch t {
+		case '[', '{':
+			if !enc.tokenValueAllowed() {
+				return enc.tokenError(t.String())
+			}
+			b := enc.tokenSeparator(nil)
+			b = append(b, byte(t))
+			enc.tokenStack = append(enc.tokenStack, enc.tokenState)
+			if t == '[' {
+				enc.tokenState = tokenArrayStart
+			} else {
+				enc.tokenState = tokenObjectStart
+			}
+			return enc.write(b)
+
+		case ']', '}':
+			start, comma := tokenArrayStart, tokenArrayComma
+			if t == '}' {
+				start, comma = tokenObjectStart, tokenObjectComma
+			}
+			if enc.tokenState != start && enc.tokenState != comma {
+				return enc.tokenError(t.String())
+			}
+			var b []byte
+			if enc.tokenState == comma {
+				b = enc.tokenNewline(b, len(enc.tokenStack)-1)
+			}
+			b = append(b, byte(t))
+			enc.tokenState = enc.tokenStack[len(enc.tokenStack)-1]
+			enc.tokenStack = enc.tokenStack[:len(enc.tokenStack)-1]
+			if enc.tokenState == tokenTopValue {
+				b = append(b, '\n')
+			}
+			enc.tokenValueEnd()
+			return enc.write(b)
+		}
+		return fmt.Errorf("json: invalid delimiter %q", rune(t))
+
+	case string:
+		if enc.tokenState == tokenObjectStart || enc.tokenState == tokenObjectComma {
+			e := newEncodeState()
+			e.string(t, enc.escapeHTML)
+			b := enc.tokenSeparator(nil)
+			b = append(b, e.Bytes()...)
+			encodeStatePool.Put(e)
+			b = append(b, ':')
+			if enc.indenting() {
+				b = append(b, ' ')
+			}
+			enc.tokenState = tokenObjectValue
+			return enc.write(b)
+		}
+		return enc.Encode(t)
+
+	case nil, bool, float64, Number:
+		return enc.Encode(t)
+	}
+	return fmt.Errorf("json: invalid token type %T", t)
+}
+
+// The Encoder's token state uses the constants of the Decoder's
+// but moves only between the start, comma and value states:
+// tokenArrayComma and tokenObjectComma mean that an element or
+// key:value pair has been written and a comma must precede the next.
+
+func (enc *Encoder) tokenValueAllowed() bool {
+	switch enc.tokenState {
+	case tokenTopValue, tokenArrayStart, tokenArrayComma, tokenObjectValue:
+		return true
+	}
+	return false
+}
+
+func (enc *Encoder) tokenValueEnd() {
+	switch enc.tokenState {
+	case tokenArrayStart, tokenArrayComma:
+		enc.tokenState = tokenArrayComma
+	case tokenObjectValue:
+		enc.tokenState = tokenObjectComma
+	}
+}
+
+// tokenSeparator appends to b the separator that must precede
+// the next array element or object key: a comma, if it is not the first,
+// and, when indenting, a newline and the indentation for the current depth.
+func (enc *Encoder) tokenSeparator(b []byte) []byte {
+	switch enc.tokenState {
+	case tokenArrayComma, tokenObjectComma:
+		b = append(b, ',')
+	case tokenArrayStart, tokenObjectStart:
+	default:
+		return b
+	}
+	return enc.tokenNewline(b, len(enc.tokenStack))
+}
+
+// tokenNewline appends to b a newline and the indentation for
+// the given depth, if the Encoder is indenting.
+func (enc *Encoder) tokenNewline(b []byte, depth int) []byte {
+	if !enc.indenting() {
+		return b
+	}
+	b = append(b, '\n')
+	b = append(b, enc.indentPrefix...)
+	for i := 0; i < depth; i++ {
+		b = append(b, enc.indentValue...)
+	}
+	return b
+}
+
+// tokenWriteValue writes data, the encoding of a value inside an array
+// or object, preceded by its separator and indented to the current depth.
+func (enc *Encoder) tokenWriteValue(data []byte) error {
+	if enc.indenting() {
+		if enc.indentBuf == nil {
+			enc.indentBuf = new(bytes.Buffer)
+		}
+		enc.indentBuf.Reset()
+		prefix := enc.indentPrefix + strings.Repeat(enc.indentValue, len(enc.tokenStack))
+		if err := Indent(enc.indentBuf, data, prefix, enc.indentValue); err != nil {
+			return err
+		}
+		data = enc.indentBuf.Bytes()
+	}
+	b := enc.tokenSeparator(nil)
+	b = append(b, data...)
+	enc.tokenValueEnd()
+	return enc.write(b)
+}
+
+func (enc *Encoder) write(b []byte) error {
+	if _, err := enc.w.Write(b); err != nil {
+		enc.err = err
+		return err
+	}
+	return nil
+}
+
+func (enc *Encoder) tokenError(what string) error {
+	var context string
+	switch enc.tokenState {
+	case tokenTopValue:
+		context = "outside of an array or object"
+	case tokenArrayStart, tokenArrayComma:
+		context = "inside an array"
+	case tokenObjectStart, tokenObjectComma:
+		context = "where an object key is expected"
+	case tokenObjectValue:
+		context = "where an object value is expected"
+	}
+	return errors.New("json: cannot encode " + what + " " + context)
+}
+
 // SetEscapeHTML specifies whether problematic HTML characters
 // should be escaped inside JSON quoted strings.
 // The default behavior is to escape &, <, and > to \u0026, \u003c, and \u003e
@@ -504,20 +708,3 @@ func (dec *Decoder) peek() (byte, error) {
 		err = dec.refill()
 	}
 }
-
-/*
-TODO
-
-// EncodeToken writes the given JSON token to the stream.
-// It returns an error if the delimiters [ ] { } are not properly used.
-//
-// EncodeToken does not call Flush, because usually it is part of
-// a larger operation such as Encode, and those will call Flush when finished.
-// Callers that create an Encoder and then invoke EncodeToken directly,
-// without using Encode, need to call Flush when finished to ensure that
-// the JSON is written to the underlying writer.
-func (e *Encoder) EncodeToken(t Token) error  {
-	...
-}
-
-*/
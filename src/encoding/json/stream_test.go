@@ -387,6 +387,98 @@ func TestDecodeInStream(t *testing.T) {
 
 }
 
+func TestEncodeInStream(t *testing.T) {
+	for ci, tcase := range tokenStreamCases {
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		ok := true
+		for _, etk := range tcase.expTokens {
+			var err error
+			if dt, isDecode := etk.(decodeThis); isDecode {
+				if _, isErr := dt.v.(error); isErr {
+					ok = false
+					break
+				}
+				err = enc.Encode(dt.v)
+			} else {
+				err = enc.EncodeToken(etk)
+			}
+			if err != nil {
+				t.Errorf("case %v: unexpected error encoding %v: %v", ci, etk, err)
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		var want bytes.Buffer
+		if err := Compact(&want, []byte(tcase.json)); err != nil {
+			t.Fatal(err)
+		}
+		want.WriteByte('\n')
+		if buf.String() != want.String() {
+			t.Errorf("case %v: EncodeToken output %q, want %q", ci, buf.String(), want.String())
+		}
+	}
+}
+
+func TestEncodeTokenIndent(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetIndent(">", ".")
+	for _, tk := range []Token{Delim('['), Delim('{'), "a", 1.0, "b", Delim('['), Delim(']'), Delim('}')} {
+		if err := enc.EncodeToken(tk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Encode(map[string]int{"c": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.EncodeToken(Delim(']')); err != nil {
+		t.Fatal(err)
+	}
+	const want = `[
+>.{
+>.."a": 1,
+>.."b": []
+>.},
+>.{
+>.."c": 2
+>.}
+>]
+`
+	if have := buf.String(); have != want {
+		t.Errorf("indented EncodeToken mismatch:\nhave:\n%s\nwant:\n%s", have, want)
+	}
+}
+
+func TestEncodeTokenErrors(t *testing.T) {
+	for _, tt := range []struct {
+		tokens []Token
+		want   string
+	}{
+		{[]Token{Delim(']')}, "json: cannot encode ] outside of an array or object"},
+		{[]Token{Delim('['), Delim('}')}, "json: cannot encode } inside an array"},
+		{[]Token{Delim('{'), Delim(']')}, "json: cannot encode ] where an object key is expected"},
+		{[]Token{Delim('{'), 1.0}, "json: cannot encode value where an object key is expected"},
+		{[]Token{Delim('{'), "a", Delim('}')}, "json: cannot encode } where an object value is expected"},
+		{[]Token{Delim('(')}, `json: invalid delimiter '('`},
+		{[]Token{Delim('['), 1}, "json: invalid token type int"},
+	} {
+		enc := NewEncoder(ioutil.Discard)
+		var err error
+		for _, tk := range tt.tokens {
+			if err = enc.EncodeToken(tk); err != nil {
+				break
+			}
+		}
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("EncodeToken(%v): got error %v, want %q", tt.tokens, err, tt.want)
+		}
+	}
+}
+
 // Test from golang.org/issue/11893
 func TestHTTPDecoding(t *testing.T) {
 	const raw = `{ "foo": "bar" }`
@@ -34,7 +34,7 @@ import (
 //
 var pkgDeps = map[string][]string{
 	// L0 is the lowest level, core, nearly unavoidable packages.
-	"errors":                  {},
+	"errors":                  {"internal/reflectlite"},
 	"io":                      {"errors", "sync"},
 	"runtime":                 {"unsafe", "runtime/internal/atomic", "runtime/internal/sys"},
 	"runtime/internal/sys":    {},
@@ -44,6 +44,9 @@ var pkgDeps = map[string][]string{
 	"sync/atomic":             {"unsafe"},
 	"unsafe":                  {},
 	"internal/cpu":            {"runtime"},
+	"internal/oserror":        {"errors"},
+	"internal/reflectlite":    {"runtime", "unsafe"},
+	"internal/testlog":        {"sync/atomic"},
 
 	"L0": {
 		"errors",
@@ -54,6 +57,8 @@ var pkgDeps = map[string][]string{
 		"sync/atomic",
 		"unsafe",
 		"internal/cpu",
+		"internal/oserror",
+		"internal/reflectlite",
 	},
 
 	// L1 adds simple functions and strings processing,
@@ -155,7 +160,7 @@ var pkgDeps = map[string][]string{
 	},
 
 	"internal/poll": {"L0", "internal/race", "syscall", "time", "unicode/utf16", "unicode/utf8"},
-	"os":            {"L1", "os", "syscall", "time", "internal/poll", "internal/syscall/windows"},
+	"os":            {"L1", "os", "syscall", "time", "internal/poll", "internal/syscall/windows", "internal/testlog"},
 	"path/filepath": {"L2", "os", "syscall"},
 	"io/ioutil":     {"L2", "os", "path/filepath", "time"},
 	"os/exec":       {"L2", "os", "context", "path/filepath", "syscall"},
@@ -172,18 +177,20 @@ var pkgDeps = map[string][]string{
 	},
 
 	// Formatted I/O: few dependencies (L1) but we must add reflect.
-	"fmt": {"L1", "os", "reflect"},
-	"log": {"L1", "os", "fmt", "time"},
+	"fmt":          {"L1", "os", "reflect"},
+	"log":          {"L1", "os", "fmt", "log/internal", "time"},
+	"log/internal": {},
 
 	// Packages used by testing must be low-level (L2+fmt).
-	"regexp":         {"L2", "regexp/syntax"},
-	"regexp/syntax":  {"L2"},
-	"runtime/debug":  {"L2", "fmt", "io/ioutil", "os", "time"},
-	"runtime/pprof":  {"L2", "compress/gzip", "context", "encoding/binary", "fmt", "io/ioutil", "os", "text/tabwriter", "time"},
-	"runtime/trace":  {"L0"},
-	"text/tabwriter": {"L2"},
-
-	"testing":          {"L2", "flag", "fmt", "internal/race", "os", "runtime/debug", "runtime/pprof", "runtime/trace", "time"},
+	"regexp":          {"L2", "regexp/syntax"},
+	"regexp/syntax":   {"L2"},
+	"runtime/debug":   {"L2", "fmt", "io/ioutil", "os", "time"},
+	"runtime/metrics": {"L0", "math"},
+	"runtime/pprof":   {"L2", "compress/gzip", "context", "encoding/binary", "fmt", "io/ioutil", "os", "text/tabwriter", "time"},
+	"runtime/trace":   {"L0", "context", "fmt"},
+	"text/tabwriter":  {"L2"},
+
+	"testing":          {"L2", "flag", "fmt", "internal/race", "io/ioutil", "math/rand", "os", "reflect", "runtime/debug", "runtime/pprof", "runtime/trace", "time"},
 	"testing/iotest":   {"L2", "log"},
 	"testing/quick":    {"L2", "flag", "fmt", "reflect", "time"},
 	"internal/testenv": {"L2", "OS", "flag", "testing", "syscall"},
@@ -262,7 +269,9 @@ var pkgDeps = map[string][]string{
 	"image/png":                {"L4", "compress/zlib"},
 	"index/suffixarray":        {"L4", "regexp"},
 	"internal/singleflight":    {"sync"},
+	"internal/fuzz":            {"L4", "OS", "GOPARSER", "context", "crypto/sha256", "encoding/json"},
 	"internal/trace":           {"L4", "OS"},
+	"log/slog":                 {"L4", "OS", "context", "encoding", "encoding/json", "log/internal"},
 	"math/big":                 {"L4"},
 	"mime":                     {"L4", "OS", "syscall", "internal/syscall/windows/registry"},
 	"mime/quotedprintable":     {"L4"},
@@ -270,7 +279,7 @@ var pkgDeps = map[string][]string{
 	"net/url":                  {"L4"},
 	"plugin":                   {"L0", "OS", "CGO"},
 	"runtime/pprof/internal/profile": {"L4", "OS", "compress/gzip", "regexp"},
-	"testing/internal/testdeps":      {"L4", "runtime/pprof", "regexp"},
+	"testing/internal/testdeps":      {"L4", "OS", "context", "internal/fuzz", "internal/testlog", "os/signal", "runtime/pprof", "regexp"},
 	"text/scanner":                   {"L4", "OS"},
 	"text/template/parse":            {"L4"},
 
@@ -344,6 +353,7 @@ var pkgDeps = map[string][]string{
 		"crypto/sha512",
 		"golang_org/x/crypto/chacha20poly1305",
 		"golang_org/x/crypto/curve25519",
+		"golang_org/x/crypto/hkdf",
 		"golang_org/x/crypto/poly1305",
 	},
 
@@ -355,14 +365,20 @@ var pkgDeps = map[string][]string{
 	// Mathematical crypto: dependencies on fmt (L4) and math/big.
 	// We could avoid some of the fmt, but math/big imports fmt anyway.
 	"crypto/dsa":      {"L4", "CRYPTO", "math/big"},
-	"crypto/ecdsa":    {"L4", "CRYPTO", "crypto/elliptic", "math/big", "encoding/asn1"},
+	"crypto/ecdh":     {"L4", "CRYPTO", "crypto/elliptic", "math/big"},
+	"crypto/ecdsa":    {"L4", "CRYPTO", "crypto/ecdh", "crypto/elliptic", "math/big", "encoding/asn1"},
+	"crypto/ed25519":  {"L4", "CRYPTO", "crypto/ed25519/internal/edwards25519", "crypto/rand"},
 	"crypto/elliptic": {"L4", "CRYPTO", "math/big"},
 	"crypto/rsa":      {"L4", "CRYPTO", "crypto/rand", "math/big"},
 
+	"crypto/ed25519/internal/edwards25519": {"L3"},
+
 	"CRYPTO-MATH": {
 		"CRYPTO",
 		"crypto/dsa",
+		"crypto/ecdh",
 		"crypto/ecdsa",
+		"crypto/ed25519",
 		"crypto/elliptic",
 		"crypto/rand",
 		"crypto/rsa",
@@ -373,13 +389,14 @@ var pkgDeps = map[string][]string{
 	// SSL/TLS.
 	"crypto/tls": {
 		"L4", "CRYPTO-MATH", "OS",
-		"container/list", "crypto/x509", "encoding/pem", "net", "syscall",
+		"container/list", "crypto/x509", "crypto/x509/ocsp", "encoding/pem", "net", "syscall",
 	},
 	"crypto/x509": {
 		"L4", "CRYPTO-MATH", "OS", "CGO",
-		"crypto/x509/pkix", "encoding/pem", "encoding/hex", "net", "os/user", "syscall",
+		"crypto/x509/pkix", "encoding/pem", "encoding/hex", "net", "os/user", "syscall", "net/url",
 	},
 	"crypto/x509/pkix": {"L4", "CRYPTO-MATH"},
+	"crypto/x509/ocsp": {"L4", "CRYPTO-MATH", "crypto/x509", "crypto/x509/pkix"},
 
 	// Simple net+crypto-aware packages.
 	"mime/multipart": {"L4", "OS", "mime", "crypto/rand", "net/textproto", "mime/quotedprintable"},
@@ -0,0 +1,272 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalMarshal(t *testing.T) {
+	var tests = []struct {
+		in string
+		ok bool
+	}{
+		{
+			in: "int(1234)",
+			ok: false, // missing version
+		},
+		{
+			in: `go test fuzz v1
+string("a"bcad")`,
+			ok: false, // malformed
+		},
+		{
+			in: `go test fuzz v1
+int()`,
+			ok: false, // empty value
+		},
+		{
+			in: `go test fuzz v1
+uint(-32)`,
+			ok: false, // invalid negative uint
+		},
+		{
+			in: `go test fuzz v1
+int8(1234456)`,
+			ok: false, // int8 too large
+		},
+		{
+			in: `go test fuzz v1
+int(20*5)`,
+			ok: false, // expression in int value
+		},
+		{
+			in: `go test fuzz v1
+int(--5)`,
+			ok: false, // expression in int value
+		},
+		{
+			in: `go test fuzz v1
+bool(0)`,
+			ok: false, // malformed bool
+		},
+		{
+			in: `go test fuzz v1
+byte('aa)`,
+			ok: false, // malformed byte
+		},
+		{
+			in: `go test fuzz v1
+byte('☃')`,
+			ok: false, // byte out of range
+		},
+		{
+			in: `go test fuzz v1
+string("has final newline")
+`,
+			ok: true, // has final newline
+		},
+		{
+			in: `go test fuzz v1
+string("extra")
+[]byte("spacing")
+    `,
+			ok: true, // extra spaces in the final newline
+		},
+		{
+			in: `go test fuzz v1
+float64(0)
+float32(0)`,
+			ok: true, // will be an integer literal since there is no decimal
+		},
+		{
+			in: `go test fuzz v1
+int(-23)
+int8(-2)
+int64(2342425)
+uint(1)
+uint16(234)
+uint32(352342)
+uint64(123)
+rune('œ')
+byte('K')
+byte('ÿ')
+[]byte("hello¿")
+[]byte("a")
+bool(true)
+string("hello\\xbd\\xb2=\\xbc ⌘")
+float64(-12.5)
+float32(2.5)`,
+			ok: true,
+		},
+		{
+			in: `go test fuzz v1
+float32(-0)
+float64(-0)
+float32(+Inf)
+float32(-Inf)
+float32(NaN)
+float64(+Inf)
+float64(-Inf)
+float64(NaN)
+math.Float64frombits(0x7ff8000000000002)
+math.Float32frombits(0x7fc00001)`,
+			ok: false, // infinities and NaN need math.FloatNNfrombits
+		},
+		{
+			in: `go test fuzz v1
+math.Float64frombits(0x7ff8000000000002)
+math.Float32frombits(0x7fc00001)
+math.Float64frombits(0x7ff0000000000000)`,
+			ok: true,
+		},
+		{
+			in: `go test fuzz v1
+int32(-1)
+int32(2147483647)`,
+			ok: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			vals, err := unmarshalCorpusFile([]byte(test.in))
+			if test.ok && err != nil {
+				t.Fatalf("unmarshal unexpected error: %v", err)
+			} else if !test.ok && err == nil {
+				t.Fatalf("unmarshal unexpected success")
+			}
+			if !test.ok {
+				return // skip the rest of the test
+			}
+			newB := marshalCorpusFile(vals...)
+			if len(newB) == 0 {
+				t.Fatalf("marshal unexpected error: empty output")
+			}
+			newVals, err := unmarshalCorpusFile(newB)
+			if err != nil {
+				t.Fatalf("unmarshal of marshaled output unexpected error: %v", err)
+			}
+			if !equalValues(vals, newVals) {
+				t.Errorf("values changed after round trip:\nbefore %#v\nafter  %#v\nencoded:\n%s", vals, newVals, newB)
+			}
+		})
+	}
+}
+
+func TestMarshalValues(t *testing.T) {
+	vals := []interface{}{
+		[]byte("\x00\xff abc"),
+		"héllo\n",
+		true,
+		int(-1),
+		int8(math.MinInt8),
+		int16(math.MaxInt16),
+		int32(0xd800), // not a valid rune
+		rune('☃'),
+		int64(math.MinInt64),
+		uint(math.MaxUint32),
+		uint8(0),
+		byte(0xff),
+		uint16(7),
+		uint32(math.MaxUint32),
+		uint64(math.MaxUint64),
+		float32(1.1),
+		float64(math.SmallestNonzeroFloat64),
+		float64(math.Inf(-1)),
+		float32(math.NaN()),
+	}
+	b := marshalCorpusFile(vals...)
+	got, err := unmarshalCorpusFile(b)
+	if err != nil {
+		t.Fatalf("unmarshal %s: %v", b, err)
+	}
+	if !equalValues(vals, got) {
+		t.Errorf("values changed after round trip:\nbefore %#v\nafter  %#v\nencoded:\n%s", vals, got, b)
+	}
+}
+
+// equalValues is like reflect.DeepEqual but treats
+// NaNs with the same bits as equal.
+func equalValues(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		switch x := a[i].(type) {
+		case float32:
+			y, ok := b[i].(float32)
+			if !ok || math.Float32bits(x) != math.Float32bits(y) {
+				return false
+			}
+		case float64:
+			y, ok := b[i].(float64)
+			if !ok || math.Float64bits(x) != math.Float64bits(y) {
+				return false
+			}
+		default:
+			if !reflect.DeepEqual(a[i], b[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestReadCorpus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fuzz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	types := []reflect.Type{reflect.TypeOf([]byte(nil)), reflect.TypeOf(int(0))}
+	good, err := writeToCorpus(marshalCorpusFile([]byte("abc"), 1), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeToCorpus(marshalCorpusFile("abc", 1), dir); err != nil {
+		t.Fatal(err)
+	}
+
+	corpus, err := ReadCorpus(dir, types)
+	if _, ok := err.(*MalformedCorpusError); !ok {
+		t.Errorf("ReadCorpus error = %v, want *MalformedCorpusError", err)
+	}
+	if len(corpus) != 1 || corpus[0].Path != good {
+		t.Fatalf("ReadCorpus returned %v, want just %s", corpus, good)
+	}
+	if want := []interface{}{[]byte("abc"), 1}; !reflect.DeepEqual(corpus[0].Values, want) {
+		t.Errorf("ReadCorpus values = %#v, want %#v", corpus[0].Values, want)
+	}
+
+	corpus, err = ReadCorpus(dir+"/missing", types)
+	if len(corpus) != 0 || err != nil {
+		t.Errorf("ReadCorpus of missing directory = %v, %v; want empty corpus and no error", corpus, err)
+	}
+}
+
+func TestParseCharLiterals(t *testing.T) {
+	for _, c := range []struct {
+		lit  string
+		want interface{}
+	}{
+		{`byte('\xff')`, byte(0xff)},
+		{`byte('\x00')`, byte(0)},
+		{`rune('\xff')`, rune(0xff)},
+		{`rune('☃')`, rune('☃')},
+	} {
+		got, err := parseCorpusValue([]byte(c.lit))
+		if err != nil {
+			t.Errorf("parseCorpusValue(%s): %v", c.lit, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("parseCorpusValue(%s) = %#v, want %#v", c.lit, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,461 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fuzz provides the coverage-guided fuzzing engine used by
+// fuzz targets in the testing package when run with "go test -fuzz".
+//
+// The engine runs inside the test binary, the coordinator, which starts
+// another copy of the test binary, the worker, to run the fuzz function.
+// The coordinator starts from a corpus of seed inputs, repeatedly mutates
+// them, and keeps any mutated input that reaches code not covered by
+// earlier inputs. When an input makes the fuzz function fail, or makes
+// the worker exit, crash, or hang, the engine minimizes it and writes it
+// to the package's testdata directory, where it becomes part of the seed
+// corpus.
+package fuzz
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// CorpusEntry represents an individual input for fuzzing.
+//
+// It is declared as an alias of an unnamed struct type so that
+// package testing can declare an identical type without importing
+// this package.
+type CorpusEntry = struct {
+	// Path is the path of the corpus file, if the entry was loaded from
+	// or written to disk. For seed values added with F.Add, it is the
+	// name of the corresponding subtest, such as "seed#0".
+	Path string
+
+	// Data is the encoded contents of the corpus file, if any.
+	Data []byte
+
+	// Values holds the arguments to pass to the fuzz function.
+	Values []interface{}
+
+	// IsSeed reports whether the entry is part of the seed corpus:
+	// added with F.Add or read from testdata.
+	IsSeed bool
+}
+
+// CoordinateFuzzingOpts is a set of arguments for CoordinateFuzzing.
+// The zero value is valid for each field unless specified otherwise.
+type CoordinateFuzzingOpts struct {
+	// Log is a writer for logging progress messages and warnings.
+	// If nil, ioutil.Discard will be used instead.
+	Log io.Writer
+
+	// Timeout is the amount of wall clock time to spend fuzzing after
+	// the corpus has loaded. If zero, there is no time limit.
+	Timeout time.Duration
+
+	// Limit is the number of random inputs to generate and test.
+	// If zero, there is no limit on the number of inputs.
+	Limit int64
+
+	// MinimizeTimeout is the amount of wall clock time to spend
+	// minimizing an input after it causes a failure.
+	// If zero, there is no time limit.
+	MinimizeTimeout time.Duration
+
+	// MinimizeLimit is the maximum number of calls to the fuzz function
+	// made while minimizing an input after it causes a failure.
+	// If zero, there is no limit.
+	MinimizeLimit int64
+
+	// Seed is the list of seed inputs, added with F.Add
+	// or read from the testdata directory.
+	Seed []CorpusEntry
+
+	// Types is the list of types making up a corpus entry.
+	// Types must be set and must match the values in Seed.
+	Types []reflect.Type
+
+	// CorpusDir is the directory where a failing input is written.
+	// CorpusDir must be set.
+	CorpusDir string
+
+	// CacheDir is a directory of additional interesting inputs found
+	// by earlier runs. The fuzzer derives new inputs from these and
+	// writes newly found interesting inputs there. If empty, inputs
+	// are neither read from nor written to a cache.
+	CacheDir string
+}
+
+// CoordinateFuzzing runs the fuzz function over the seed corpus and then
+// over mutated inputs until the context is cancelled, the time or input
+// limits are reached, or an input causes a failure. A cancelled context
+// is not an error; CoordinateFuzzing then returns nil.
+//
+// If an input causes a failure, CoordinateFuzzing minimizes it, writes it
+// to opts.CorpusDir, and returns an error describing the failure. That
+// error has a CrashPath method returning the path of the written file.
+//
+// The inputs run in a worker process, which is the test binary run again
+// with the -test.fuzzworker flag added and which must call RunFuzzWorker.
+// An input that makes the worker process exit, or keeps it from answering
+// for 10 seconds, is a failure too.
+func CoordinateFuzzing(ctx context.Context, opts CoordinateFuzzingOpts) error {
+	w := newWorker()
+	defer w.stop()
+	return coordinate(ctx, opts, w.run)
+}
+
+// runFunc runs the fuzz function on an entry and returns its coverage,
+// one byte per counter, or nil if the code under test is not instrumented
+// for coverage. If the entry makes the fuzz function fail, it returns a
+// failure describing it. It returns an error if it cannot run the entry.
+type runFunc func(e CorpusEntry) (cov []byte, failure, err error)
+
+// coordinate implements CoordinateFuzzing, running inputs with run.
+func coordinate(ctx context.Context, opts CoordinateFuzzingOpts, run runFunc) error {
+	if opts.Log == nil {
+		opts.Log = ioutil.Discard
+	}
+	if opts.CorpusDir == "" {
+		return errors.New("fuzz: CorpusDir must be set")
+	}
+	if len(opts.Types) == 0 {
+		return errors.New("fuzz: Types must be set")
+	}
+
+	c := &coordinator{
+		opts:      opts,
+		run:       run,
+		interrupt: ctx,
+		startTime: time.Now(),
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	// Gather the corpus: the seed inputs, then any interesting inputs
+	// cached by earlier runs. Cached inputs that no longer match the
+	// types of the fuzz function are ignored.
+	for _, e := range opts.Seed {
+		if err := checkCorpus(e.Values, opts.Types); err != nil {
+			return fmt.Errorf("fuzz: seed corpus entry %s: %v", e.Path, err)
+		}
+		c.corpus = append(c.corpus, e)
+	}
+	if opts.CacheDir != "" {
+		cached, _ := ReadCorpus(opts.CacheDir, opts.Types)
+		c.corpus = append(c.corpus, cached...)
+	}
+	if len(c.corpus) == 0 {
+		c.corpus = append(c.corpus, CorpusEntry{Path: "zero", Values: zeroValues(opts.Types)})
+	}
+
+	// Run every entry once to learn the coverage of the starting corpus.
+	fmt.Fprintf(c.opts.Log, "fuzz: elapsed: %s, gathering baseline coverage: %d inputs\n", c.elapsed(), len(c.corpus))
+	for _, e := range c.corpus {
+		if ctx.Err() != nil {
+			c.logStats()
+			return nil
+		}
+		cov, failure, err := c.run(e)
+		if err != nil {
+			return err
+		}
+		if failure != nil {
+			if e.IsSeed {
+				return fmt.Errorf("failure while testing seed corpus entry: %s\n%v", e.Path, failure)
+			}
+			return c.crash(e.Values, failure)
+		}
+		c.updateCoverage(cov)
+	}
+	if len(c.covered) == 0 {
+		fmt.Fprintf(c.opts.Log, "fuzz: warning: code under test is not instrumented for coverage; mutating inputs without coverage guidance\n")
+	}
+
+	m := newMutator()
+	c.fuzzStart = time.Now()
+	ticker := time.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			c.logStats()
+			return nil
+		case <-ticker.C:
+			c.logStats()
+		default:
+		}
+		if opts.Limit > 0 && c.count >= opts.Limit {
+			c.logStats()
+			return nil
+		}
+
+		parent := c.corpus[m.r.Intn(len(c.corpus))]
+		vals := append([]interface{}(nil), parent.Values...)
+		m.mutate(vals)
+		c.count++
+		cov, failure, err := c.run(CorpusEntry{Values: vals})
+		if err != nil {
+			return err
+		}
+		if failure != nil {
+			c.logStats()
+			return c.crash(vals, failure)
+		}
+		if c.updateCoverage(cov) {
+			e := CorpusEntry{Data: marshalCorpusFile(vals...), Values: vals}
+			if opts.CacheDir != "" {
+				path, err := writeToCorpus(e.Data, opts.CacheDir)
+				if err != nil {
+					return err
+				}
+				e.Path = path
+			}
+			c.corpus = append(c.corpus, e)
+			c.interesting++
+		}
+	}
+}
+
+// coordinator holds the state of a single call to CoordinateFuzzing.
+type coordinator struct {
+	opts CoordinateFuzzingOpts
+	run  runFunc
+
+	// interrupt is the context passed to CoordinateFuzzing, without the
+	// fuzzing time limit. Minimization continues past that limit but
+	// stops if interrupt is cancelled.
+	interrupt context.Context
+
+	startTime time.Time // when CoordinateFuzzing was called
+	fuzzStart time.Time // when the first mutated input was tested
+
+	corpus      []CorpusEntry
+	covered     []byte // union of coverage of all inputs in corpus
+	count       int64  // number of mutated inputs tested
+	interesting int    // number of inputs added to corpus while fuzzing
+}
+
+func (c *coordinator) elapsed() time.Duration {
+	return time.Since(c.startTime).Round(time.Second)
+}
+
+func (c *coordinator) logStats() {
+	var rate float64
+	if !c.fuzzStart.IsZero() {
+		rate = float64(c.count) / time.Since(c.fuzzStart).Seconds()
+	}
+	fmt.Fprintf(c.opts.Log, "fuzz: elapsed: %s, execs: %d (%.0f/sec), new interesting: %d (total: %d)\n", c.elapsed(), c.count, rate, c.interesting, len(c.corpus))
+}
+
+// updateCoverage adds cov to the coverage seen so far
+// and reports whether cov contained anything new.
+func (c *coordinator) updateCoverage(cov []byte) bool {
+	if len(c.covered) < len(cov) {
+		c.covered = append(c.covered, make([]byte, len(cov)-len(c.covered))...)
+	}
+	newCoverage := false
+	for i, b := range cov {
+		if b&^c.covered[i] != 0 {
+			c.covered[i] |= b
+			newCoverage = true
+		}
+	}
+	return newCoverage
+}
+
+// crash minimizes vals, which caused failure, writes the
+// result to the corpus directory, and returns the error to report.
+func (c *coordinator) crash(vals []interface{}, failure error) error {
+	fmt.Fprintf(c.opts.Log, "fuzz: elapsed: %s, minimizing\n", c.elapsed())
+	vals, failure, err := c.minimize(vals, failure)
+	if err != nil {
+		return err
+	}
+	path, err := writeToCorpus(marshalCorpusFile(vals...), c.opts.CorpusDir)
+	if err != nil {
+		return fmt.Errorf("%v\nfuzz: writing failing input: %v", failure, err)
+	}
+	return &crashError{path: path, err: failure}
+}
+
+// minimize looks for smaller []byte and string values in vals that still
+// cause a failure. It returns the smallest values found along with the
+// failure they cause. It returns an error if it cannot run an input.
+func (c *coordinator) minimize(vals []interface{}, failure error) (_ []interface{}, _ error, err error) {
+	vals = append([]interface{}(nil), vals...)
+	var deadline time.Time
+	if c.opts.MinimizeTimeout > 0 {
+		deadline = time.Now().Add(c.opts.MinimizeTimeout)
+	}
+	var count int64
+	shouldStop := func() bool {
+		return err != nil || c.interrupt.Err() != nil ||
+			!deadline.IsZero() && time.Now().After(deadline) ||
+			c.opts.MinimizeLimit > 0 && count >= c.opts.MinimizeLimit
+	}
+
+	for i, v := range vals {
+		var b []byte
+		switch v := v.(type) {
+		case []byte:
+			b = v
+		case string:
+			b = []byte(v)
+		default:
+			continue
+		}
+		_, isString := v.(string)
+		try := func(candidate []byte) bool {
+			next := append([]interface{}(nil), vals...)
+			if isString {
+				next[i] = string(candidate)
+			} else {
+				next[i] = append([]byte(nil), candidate...)
+			}
+			count++
+			_, f, runErr := c.run(CorpusEntry{Values: next})
+			if runErr != nil {
+				err = runErr
+				return false
+			}
+			if f != nil {
+				failure = f
+				return true
+			}
+			return false
+		}
+		b = minimizeBytes(b, try, shouldStop)
+		if isString {
+			vals[i] = string(b)
+		} else {
+			vals[i] = b
+		}
+	}
+	return vals, failure, err
+}
+
+// crashError describes a failure found while fuzzing.
+type crashError struct {
+	path string
+	err  error
+}
+
+func (e *crashError) Error() string {
+	return e.err.Error()
+}
+
+// CrashPath returns the path of the corpus file
+// holding the input that caused the failure.
+func (e *crashError) CrashPath() string {
+	return e.path
+}
+
+// ReadCorpus reads the corpus from the provided directory, checking
+// that each entry holds values of the given types. A directory that
+// does not exist holds an empty corpus.
+//
+// If some files are malformed, ReadCorpus returns the entries read
+// from the other files along with an error listing the malformed ones.
+func ReadCorpus(dir string, types []reflect.Type) ([]CorpusEntry, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil // No corpus to read
+	} else if err != nil {
+		return nil, fmt.Errorf("reading corpus directory: %v", err)
+	}
+	var corpus []CorpusEntry
+	var errs []error
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		filename := filepath.Join(dir, file.Name())
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("reading corpus file: %v", err)
+		}
+		vals, err := unmarshalCorpusFile(data)
+		if err == nil {
+			err = checkCorpus(vals, types)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", filename, err))
+			continue
+		}
+		corpus = append(corpus, CorpusEntry{Path: filename, Data: data, Values: vals})
+	}
+	if len(errs) > 0 {
+		return corpus, &MalformedCorpusError{errs: errs}
+	}
+	return corpus, nil
+}
+
+// MalformedCorpusError is returned by ReadCorpus when
+// some of the files in the corpus cannot be decoded.
+type MalformedCorpusError struct {
+	errs []error
+}
+
+func (e *MalformedCorpusError) Error() string {
+	var msg string
+	for i, err := range e.errs {
+		if i > 0 {
+			msg += "\n"
+		}
+		msg += err.Error()
+	}
+	return msg
+}
+
+// checkCorpus verifies that the values in vals have the types in types.
+func checkCorpus(vals []interface{}, types []reflect.Type) error {
+	if len(vals) != len(types) {
+		return fmt.Errorf("wrong number of values in corpus entry: %d, want %d", len(vals), len(types))
+	}
+	for i, v := range vals {
+		if t := reflect.TypeOf(v); t != types[i] {
+			return fmt.Errorf("mismatched types in corpus entry: value %d has type %v, want %v", i, t, types[i])
+		}
+	}
+	return nil
+}
+
+// zeroValues returns the zero value of each of types.
+func zeroValues(types []reflect.Type) []interface{} {
+	vals := make([]interface{}, len(types))
+	for i, t := range types {
+		if t.Kind() == reflect.Slice {
+			vals[i] = reflect.MakeSlice(t, 0, 0).Interface()
+		} else {
+			vals[i] = reflect.Zero(t).Interface()
+		}
+	}
+	return vals
+}
+
+// writeToCorpus writes b to a file in dir named after the
+// hash of its contents, and returns the path of the file.
+func writeToCorpus(b []byte, dir string) (path string, err error) {
+	name := fmt.Sprintf("%x", sha256.Sum256(b))[:16]
+	path = filepath.Join(dir, name)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(path, b, 0666); err != nil {
+		os.Remove(path) // remove partially written file
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestCoordinateFuzzing checks that coverage guidance lets the fuzzer find an
+// input that needs several bytes to be right at once, and that the failing
+// input is minimized and written to the corpus directory.
+func TestCoordinateFuzzing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fuzz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The fake coverage has one counter per byte of the magic prefix
+	// matched, as if each comparison were a separate basic block.
+	const magic = "FUZZ"
+	run := func(e CorpusEntry) ([]byte, error, error) {
+		b := e.Values[0].([]byte)
+		cov := make([]byte, len(magic))
+		for i := 0; i < len(magic) && i < len(b) && b[i] == magic[i]; i++ {
+			cov[i] = 1
+		}
+		if bytes.HasPrefix(b, []byte(magic)) {
+			return cov, errors.New("found it"), nil
+		}
+		return cov, nil, nil
+	}
+
+	corpusDir := filepath.Join(dir, "testdata")
+	cacheDir := filepath.Join(dir, "cache")
+	err = coordinate(context.Background(), CoordinateFuzzingOpts{
+		Limit:     10000000,
+		Seed:      []CorpusEntry{{Path: "seed#0", Values: []interface{}{[]byte("hello")}, IsSeed: true}},
+		Types:     []reflect.Type{reflect.TypeOf([]byte(nil))},
+		CorpusDir: corpusDir,
+		CacheDir:  cacheDir,
+	}, run)
+	crashErr, ok := err.(*crashError)
+	if !ok {
+		t.Fatalf("CoordinateFuzzing returned %v, want a crash", err)
+	}
+	if !strings.Contains(crashErr.Error(), "found it") {
+		t.Errorf("crash error %q does not describe the failure", crashErr)
+	}
+	if dir := filepath.Dir(crashErr.CrashPath()); dir != corpusDir {
+		t.Errorf("failing input written to %s, want %s", dir, corpusDir)
+	}
+	corpus, err := ReadCorpus(corpusDir, []reflect.Type{reflect.TypeOf([]byte(nil))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corpus) != 1 {
+		t.Fatalf("corpus directory has %d entries, want 1", len(corpus))
+	}
+	if got := corpus[0].Values[0].([]byte); string(got) != magic {
+		t.Errorf("failing input was minimized to %q, want %q", got, magic)
+	}
+	if cached, _ := ReadCorpus(cacheDir, []reflect.Type{reflect.TypeOf([]byte(nil))}); len(cached) == 0 {
+		t.Errorf("no interesting inputs were written to the cache")
+	}
+}
+
+func TestCoordinateFuzzingLimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fuzz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	runs := 0
+	err = coordinate(context.Background(), CoordinateFuzzingOpts{
+		Limit:     100,
+		Types:     []reflect.Type{reflect.TypeOf(""), reflect.TypeOf(int64(0))},
+		CorpusDir: dir,
+	}, func(e CorpusEntry) ([]byte, error, error) {
+		runs++
+		return nil, nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One baseline run of the zero value, then the limit.
+	if runs != 101 {
+		t.Errorf("fuzz function ran %d times, want 101", runs)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("corpus directory has %d files, want none", len(files))
+	}
+}
+
+func TestCoordinateFuzzingSeedFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fuzz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = coordinate(context.Background(), CoordinateFuzzingOpts{
+		Seed:      []CorpusEntry{{Path: "seed#0", Values: []interface{}{"bad"}, IsSeed: true}},
+		Types:     []reflect.Type{reflect.TypeOf("")},
+		CorpusDir: dir,
+	}, func(e CorpusEntry) ([]byte, error, error) {
+		return nil, errors.New("seed failed"), nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "seed#0") {
+		t.Fatalf("CoordinateFuzzing returned %v, want failure naming the seed", err)
+	}
+	if _, ok := err.(*crashError); ok {
+		t.Errorf("failing seed was reported as a new crash")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+// minimizeBytes attempts to find a smaller value than v for which
+// try still reports true, and returns the smallest such value found.
+// It stops early once shouldStop reports true.
+//
+// The slice passed to try is only valid for the duration of the call;
+// try must copy it if it needs to keep it.
+func minimizeBytes(v []byte, try func([]byte) bool, shouldStop func() bool) []byte {
+	v = append([]byte(nil), v...)
+	tmp := make([]byte, len(v))
+
+	// First, try to cut the tail.
+	for n := 1024; n != 0; n /= 2 {
+		for len(v) > n {
+			if shouldStop() {
+				return v
+			}
+			candidate := v[:len(v)-n]
+			if !try(candidate) {
+				break
+			}
+			v = candidate
+		}
+	}
+
+	// Then, try to remove each individual byte.
+	for i := 0; i < len(v)-1; i++ {
+		if shouldStop() {
+			return v
+		}
+		candidate := tmp[:len(v)-1]
+		copy(candidate[:i], v[:i])
+		copy(candidate[i:], v[i+1:])
+		if !try(candidate) {
+			continue
+		}
+		copy(v[i:], v[i+1:])
+		v = v[:len(candidate)]
+		// v[i] is now a different byte, so look at index i again.
+		i--
+	}
+
+	// Then, try to remove each possible range of bytes.
+	for i := 0; i < len(v)-1; i++ {
+		copy(tmp, v[:i])
+		for j := len(v); j > i+1; j-- {
+			if shouldStop() {
+				return v
+			}
+			candidate := tmp[:len(v)-j+i]
+			copy(candidate[i:], v[j:])
+			if !try(candidate) {
+				continue
+			}
+			copy(v[i:], v[j:])
+			v = v[:len(candidate)]
+			j = len(v)
+		}
+	}
+
+	// Finally, try to make the value easier to read by
+	// replacing each byte with a printable character.
+	const printable = "012789ABCXYZabcxyz !\"#$%&'()*+,."
+	for i, b := range v {
+		if b >= ' ' && b <= '~' {
+			continue
+		}
+		for _, c := range []byte(printable) {
+			if shouldStop() {
+				return v
+			}
+			v[i] = c
+			if try(v) {
+				break
+			}
+			v[i] = b
+		}
+	}
+	return v
+}
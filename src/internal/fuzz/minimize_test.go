@@ -0,0 +1,91 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestMinimizeBytes(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		in    []byte
+		fails func([]byte) bool
+		want  []byte
+	}{
+		{
+			name: "prefix",
+			in:   []byte("xxxxFUZZ\x00\x01 junk at the end"),
+			fails: func(b []byte) bool {
+				return bytes.Contains(b, []byte("FUZ"))
+			},
+			want: []byte("FUZ"),
+		},
+		{
+			name: "length",
+			in:   bytes.Repeat([]byte{0xff}, 5000),
+			fails: func(b []byte) bool {
+				return len(b) >= 10
+			},
+			want: []byte("0000000000"),
+		},
+		{
+			name: "scattered",
+			in:   []byte("a1b2c3"),
+			fails: func(b []byte) bool {
+				return bytes.Count(b, []byte("a")) == 1 && bytes.Count(b, []byte("c")) == 1
+			},
+			want: []byte("ac"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			in := append([]byte(nil), tc.in...)
+			got := minimizeBytes(in, tc.fails, func() bool { return false })
+			if !bytes.Equal(got, tc.want) {
+				t.Errorf("minimizeBytes(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+			if !bytes.Equal(in, tc.in) {
+				t.Errorf("minimizeBytes modified its input")
+			}
+		})
+	}
+}
+
+func TestMinimizeBytesStop(t *testing.T) {
+	calls := 0
+	in := []byte("0123456789")
+	got := minimizeBytes(in, func([]byte) bool {
+		calls++
+		return true
+	}, func() bool { return calls >= 3 })
+	if calls > 3 {
+		t.Errorf("minimizeBytes made %d calls after being told to stop at 3", calls)
+	}
+	if len(got) == 0 || !bytes.HasPrefix(in, got) {
+		t.Errorf("minimizeBytes = %q, want a non-empty prefix of %q", got, in)
+	}
+}
+
+func TestMutatorTypes(t *testing.T) {
+	m := newMutator()
+	vals := []interface{}{
+		[]byte("abc"), "abc", true, int(1), int8(1), int16(1), int32(1), int64(1),
+		uint(1), uint8(1), uint16(1), uint32(1), uint64(1), float32(1), float64(1),
+	}
+	orig := append([]interface{}(nil), vals...)
+	for i := 0; i < 1000; i++ {
+		m.mutate(vals)
+		for j, v := range vals {
+			if have, want := fmt.Sprintf("%T", v), fmt.Sprintf("%T", orig[j]); have != want {
+				t.Fatalf("after mutation, value %d has type %s, want %s", j, have, want)
+			}
+		}
+	}
+	if !bytes.Equal(orig[0].([]byte), []byte("abc")) {
+		t.Errorf("mutator modified the memory of a []byte value")
+	}
+}
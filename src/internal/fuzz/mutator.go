@@ -0,0 +1,236 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// maxMutatedBytes is the largest []byte or string value
+// that the mutator will grow an input to.
+const maxMutatedBytes = 1 << 20
+
+// A mutator derives new inputs from existing ones by making
+// small random changes to a single value.
+type mutator struct {
+	r *rand.Rand
+}
+
+func newMutator() *mutator {
+	return &mutator{r: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// mutate performs one or more mutations on a randomly chosen
+// element of vals, modifying vals in place. It never modifies
+// the memory of a []byte held in vals.
+func (m *mutator) mutate(vals []interface{}) {
+	i := m.r.Intn(len(vals))
+	switch v := vals[i].(type) {
+	case int:
+		vals[i] = int(m.mutateInt(int64(v), strconvIntSize))
+	case int8:
+		vals[i] = int8(m.mutateInt(int64(v), 8))
+	case int16:
+		vals[i] = int16(m.mutateInt(int64(v), 16))
+	case int32:
+		vals[i] = int32(m.mutateInt(int64(v), 32))
+	case int64:
+		vals[i] = m.mutateInt(v, 64)
+	case uint:
+		vals[i] = uint(m.mutateInt(int64(v), strconvIntSize))
+	case uint8:
+		vals[i] = uint8(m.mutateInt(int64(v), 8))
+	case uint16:
+		vals[i] = uint16(m.mutateInt(int64(v), 16))
+	case uint32:
+		vals[i] = uint32(m.mutateInt(int64(v), 32))
+	case uint64:
+		vals[i] = uint64(m.mutateInt(int64(v), 64))
+	case float32:
+		vals[i] = float32(m.mutateFloat(float64(v)))
+	case float64:
+		vals[i] = m.mutateFloat(v)
+	case bool:
+		vals[i] = !v
+	case string:
+		vals[i] = string(m.mutateBytes([]byte(v)))
+	case []byte:
+		vals[i] = m.mutateBytes(append([]byte(nil), v...))
+	default:
+		panic("unsupported type in mutator")
+	}
+}
+
+// strconvIntSize is the size in bits of an int or uint value.
+const strconvIntSize = 32 << (^uint(0) >> 63)
+
+// interestingInt holds values that commonly trigger edge cases
+// in code handling integers.
+var interestingInt = []int64{
+	0, 1, -1, 16, 32, 64, 100, 127, -128, 128, 255, 256, 1000, 1024,
+	4096, 32767, -32768, 65535, 65536, math.MaxInt32, math.MinInt32,
+	math.MaxUint32, math.MaxInt64, math.MinInt64,
+}
+
+// mutateInt returns a mutation of v, an integer of the given size in bits.
+// The caller truncates the result to the size of its type.
+func (m *mutator) mutateInt(v int64, bits uint) int64 {
+	switch m.r.Intn(4) {
+	case 0:
+		// Add a small value.
+		return v + int64(1+m.r.Intn(16))
+	case 1:
+		// Subtract a small value.
+		return v - int64(1+m.r.Intn(16))
+	case 2:
+		// Flip a bit.
+		return v ^ 1<<uint(m.r.Intn(int(bits)))
+	default:
+		// Replace with an interesting value.
+		return interestingInt[m.r.Intn(len(interestingInt))]
+	}
+}
+
+// mutateFloat returns a mutation of v.
+func (m *mutator) mutateFloat(v float64) float64 {
+	switch m.r.Intn(5) {
+	case 0:
+		return v + float64(1+m.r.Intn(16))
+	case 1:
+		return v - float64(1+m.r.Intn(16))
+	case 2:
+		return v * float64(2+m.r.Intn(16))
+	case 3:
+		if v == 0 {
+			return 1
+		}
+		return v / float64(2+m.r.Intn(16))
+	default:
+		return float64(interestingInt[m.r.Intn(len(interestingInt))])
+	}
+}
+
+// interestingByte holds byte values that commonly trigger edge cases.
+var interestingByte = []byte{0, 1, ' ', '0', 'A', 'a', 0x7f, 0x80, 0xfe, 0xff}
+
+// mutateBytes returns a mutation of b, reusing its memory if possible.
+// It applies a small random number of changes at once, so that
+// inputs needing several coordinated edits can still be found.
+func (m *mutator) mutateBytes(b []byte) []byte {
+	for n := 1 + m.r.Intn(3); n > 0; {
+		var ok bool
+		b, ok = m.mutateBytesOnce(b)
+		if ok {
+			n--
+		}
+	}
+	return b
+}
+
+// mutateBytesOnce applies a single randomly chosen mutation to b.
+// It reports false if the chosen mutation does not apply to b,
+// for example because b is empty.
+func (m *mutator) mutateBytesOnce(b []byte) ([]byte, bool) {
+	switch m.r.Intn(9) {
+	case 0:
+		// Insert random bytes.
+		n := 1 + m.r.Intn(8)
+		if len(b)+n > maxMutatedBytes {
+			return b, false
+		}
+		pos := m.r.Intn(len(b) + 1)
+		b = append(b, make([]byte, n)...)
+		copy(b[pos+n:], b[pos:])
+		for i := 0; i < n; i++ {
+			b[pos+i] = byte(m.r.Intn(256))
+		}
+		return b, true
+	case 1:
+		// Remove a range of bytes.
+		if len(b) == 0 {
+			return b, false
+		}
+		pos := m.r.Intn(len(b))
+		n := 1 + m.r.Intn(len(b)-pos)
+		return append(b[:pos], b[pos+n:]...), true
+	case 2:
+		// Duplicate a range of bytes elsewhere in b.
+		if len(b) == 0 {
+			return b, false
+		}
+		src := m.r.Intn(len(b))
+		n := 1 + m.r.Intn(len(b)-src)
+		if len(b)+n > maxMutatedBytes {
+			return b, false
+		}
+		chunk := append([]byte(nil), b[src:src+n]...)
+		dst := m.r.Intn(len(b) + 1)
+		b = append(b, chunk...)
+		copy(b[dst+n:], b[dst:])
+		copy(b[dst:], chunk)
+		return b, true
+	case 3:
+		// Overwrite a range of bytes with another range.
+		if len(b) < 2 {
+			return b, false
+		}
+		src := m.r.Intn(len(b))
+		dst := m.r.Intn(len(b))
+		n := 1 + m.r.Intn(len(b)-max(src, dst))
+		copy(b[dst:dst+n], b[src:src+n])
+		return b, true
+	case 4:
+		// Replace a byte with a random value.
+		if len(b) == 0 {
+			return b, false
+		}
+		b[m.r.Intn(len(b))] = byte(m.r.Intn(256))
+		return b, true
+	case 5:
+		// Flip a bit.
+		if len(b) == 0 {
+			return b, false
+		}
+		b[m.r.Intn(len(b))] ^= 1 << uint(m.r.Intn(8))
+		return b, true
+	case 6:
+		// Replace a byte with an interesting value.
+		if len(b) == 0 {
+			return b, false
+		}
+		b[m.r.Intn(len(b))] = interestingByte[m.r.Intn(len(interestingByte))]
+		return b, true
+	case 7:
+		// Add or subtract a small value from a byte.
+		if len(b) == 0 {
+			return b, false
+		}
+		pos := m.r.Intn(len(b))
+		delta := byte(1 + m.r.Intn(16))
+		if m.r.Intn(2) == 0 {
+			b[pos] += delta
+		} else {
+			b[pos] -= delta
+		}
+		return b, true
+	default:
+		// Swap two bytes.
+		if len(b) < 2 {
+			return b, false
+		}
+		i, j := m.r.Intn(len(b)), m.r.Intn(len(b))
+		b[i], b[j] = b[j], b[i]
+		return b, true
+	}
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
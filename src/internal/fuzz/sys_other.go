@@ -0,0 +1,24 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package fuzz
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+var errWorkerUnsupported = errors.New("fuzz: fuzzing is not supported on " + runtime.GOOS)
+
+func setWorkerComm(cmd *exec.Cmd, r, w *os.File) error {
+	return errWorkerUnsupported
+}
+
+func getWorkerComm() (r, w *os.File, err error) {
+	return nil, nil, errWorkerUnsupported
+}
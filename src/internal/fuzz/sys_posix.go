@@ -0,0 +1,25 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package fuzz
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setWorkerComm arranges for the worker process started by cmd to read
+// requests from r and write responses to w, as file descriptors 3 and 4.
+func setWorkerComm(cmd *exec.Cmd, r, w *os.File) error {
+	cmd.ExtraFiles = []*os.File{r, w}
+	return nil
+}
+
+// getWorkerComm returns the files set up by setWorkerComm
+// in the worker process.
+func getWorkerComm() (r, w *os.File, err error) {
+	return os.NewFile(3, "fuzz_in"), os.NewFile(4, "fuzz_out"), nil
+}
@@ -0,0 +1,249 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// workerTimeout is how long the coordinator waits for the worker
+// process to run a single input before deciding that it hangs.
+var workerTimeout = 10 * time.Second
+
+// workerRequest asks the worker process to run one input.
+type workerRequest struct {
+	// Data is the input, encoded as a corpus file.
+	Data []byte
+}
+
+// workerResponse is the worker process's reply to a workerRequest.
+type workerResponse struct {
+	// Err describes the failure of the fuzz function, if any.
+	Err string
+
+	// Coverage is the coverage snapshot of the input.
+	Coverage []byte
+}
+
+// worker manages the worker process, a copy of the test binary that
+// runs inputs on behalf of the coordinator. An input that makes the
+// fuzz function exit, crash, or hang takes down only the worker, so
+// the coordinator can minimize it and write it to the corpus like any
+// other failing input. The worker is restarted when needed.
+type worker struct {
+	args []string // command line of the worker process
+
+	cmd    *exec.Cmd
+	toW    *os.File // closing it asks the worker to exit
+	fromW  *os.File
+	enc    *json.Encoder
+	dec    *json.Decoder
+	output lockedBuffer // output of the worker since the last request
+}
+
+func newWorker() *worker {
+	return &worker{args: append(os.Args[:len(os.Args):len(os.Args)], "-test.fuzzworker")}
+}
+
+// start starts the worker process.
+func (w *worker) start() error {
+	toR, toW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	fromR, fromW, err := os.Pipe()
+	if err != nil {
+		toR.Close()
+		toW.Close()
+		return err
+	}
+	cmd := exec.Command(w.args[0], w.args[1:]...)
+	cmd.Stdout = &w.output
+	cmd.Stderr = &w.output
+	if err := setWorkerComm(cmd, toR, fromW); err != nil {
+		toR.Close()
+		toW.Close()
+		fromR.Close()
+		fromW.Close()
+		return err
+	}
+	err = cmd.Start()
+	// The worker has its own copies of these now.
+	toR.Close()
+	fromW.Close()
+	if err != nil {
+		toW.Close()
+		fromR.Close()
+		return err
+	}
+	w.cmd = cmd
+	w.toW = toW
+	w.fromW = fromR
+	w.enc = json.NewEncoder(toW)
+	w.dec = json.NewDecoder(fromR)
+	return nil
+}
+
+// run runs e in the worker process, starting it if needed, and returns
+// the coverage of e. If e makes the fuzz function fail, run returns
+// a failure describing it; this includes making the worker process
+// exit or run for longer than workerTimeout. run returns an error only
+// if the worker process cannot be started.
+func (w *worker) run(e CorpusEntry) (cov []byte, failure, err error) {
+	if w.cmd == nil {
+		if err := w.start(); err != nil {
+			return nil, nil, fmt.Errorf("fuzz: starting worker process: %v", err)
+		}
+	}
+	w.output.Reset()
+	if err := w.enc.Encode(workerRequest{Data: marshalCorpusFile(e.Values...)}); err != nil {
+		return nil, w.terminated(), nil
+	}
+
+	type result struct {
+		resp workerResponse
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var resp workerResponse
+		err := w.dec.Decode(&resp)
+		done <- result{resp, err}
+	}()
+	timer := time.NewTimer(workerTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, w.terminated(), nil
+		}
+		if r.resp.Err != "" {
+			failure = errors.New(r.resp.Err)
+		}
+		return r.resp.Coverage, failure, nil
+	case <-timer.C:
+		w.cmd.Process.Kill()
+		<-done
+		w.wait()
+		return nil, w.failure(fmt.Sprintf("fuzzing process hung: fuzz function did not return within %v", workerTimeout)), nil
+	}
+}
+
+// terminated waits for the worker process, which has stopped talking
+// to the coordinator, and returns a failure describing how it exited.
+func (w *worker) terminated() error {
+	// Make sure it is gone. This doesn't change the exit status
+	// if the worker process has already exited.
+	w.cmd.Process.Kill()
+	status := "exit status 0"
+	if err := w.wait(); err != nil {
+		status = err.Error()
+	}
+	return w.failure("fuzzing process terminated unexpectedly: " + status)
+}
+
+// failure returns an error made of msg followed by
+// the output of the worker process.
+func (w *worker) failure(msg string) error {
+	if out := strings.TrimSpace(w.output.String()); out != "" {
+		msg += "\n" + out
+	}
+	return errors.New(msg)
+}
+
+// wait waits for the worker process to exit and
+// releases its resources.
+func (w *worker) wait() error {
+	w.toW.Close()
+	err := w.cmd.Wait()
+	w.fromW.Close()
+	w.cmd = nil
+	return err
+}
+
+// stop asks the worker process to exit, if it is running,
+// and kills it if it does not exit within workerTimeout.
+func (w *worker) stop() {
+	if w.cmd == nil {
+		return
+	}
+	p := w.cmd.Process
+	timer := time.AfterFunc(workerTimeout, func() { p.Kill() })
+	defer timer.Stop()
+	w.wait()
+}
+
+// RunFuzzWorker runs inputs on behalf of the coordinator process that
+// started this process, until the coordinator asks it to exit.
+// fn runs the fuzz function on an input and returns an error describing
+// its failure, if any. It must reset the coverage counters first.
+// coverage returns a snapshot of the coverage counters set by the most
+// recent call to fn, one byte per counter, or nil if the code under test
+// is not instrumented for coverage.
+func RunFuzzWorker(fn func(CorpusEntry) error, coverage func() []byte) error {
+	in, out, err := getWorkerComm()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	defer out.Close()
+	dec := json.NewDecoder(in)
+	enc := json.NewEncoder(out)
+	for {
+		var req workerRequest
+		if err := dec.Decode(&req); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("fuzz: reading request from coordinator: %v", err)
+		}
+		vals, err := unmarshalCorpusFile(req.Data)
+		if err != nil {
+			return fmt.Errorf("fuzz: decoding input from coordinator: %v", err)
+		}
+		var resp workerResponse
+		if err := fn(CorpusEntry{Data: req.Data, Values: vals}); err != nil {
+			resp.Err = err.Error()
+		}
+		if coverage != nil {
+			resp.Coverage = coverage()
+		}
+		if err := enc.Encode(resp); err != nil {
+			return fmt.Errorf("fuzz: writing response to coordinator: %v", err)
+		}
+	}
+}
+
+// lockedBuffer is a bytes.Buffer that is safe for concurrent use.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fuzz
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain lets the test binary act as the worker process
+// started by TestWorker.
+func TestMain(m *testing.M) {
+	if os.Getenv("GO_FUZZ_TEST_WORKER") == "1" {
+		err := RunFuzzWorker(func(e CorpusEntry) error {
+			switch e.Values[0].(string) {
+			case "fail":
+				return errors.New("failed")
+			case "exit":
+				os.Exit(3)
+			case "hang":
+				time.Sleep(time.Hour)
+			}
+			return nil
+		}, func() []byte { return []byte{1} })
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestWorker(t *testing.T) {
+	switch runtime.GOOS {
+	case "darwin", "dragonfly", "freebsd", "linux", "netbsd", "openbsd", "solaris":
+	default:
+		t.Skipf("fuzzing is not supported on %s", runtime.GOOS)
+	}
+	t.Setenv("GO_FUZZ_TEST_WORKER", "1")
+	defer func(d time.Duration) { workerTimeout = d }(workerTimeout)
+	workerTimeout = time.Second
+
+	w := newWorker()
+	defer w.stop()
+	tests := []struct {
+		in      string
+		failure string // prefix of the failure, if any
+	}{
+		{"ok", ""},
+		{"fail", "failed"},
+		{"exit", "fuzzing process terminated unexpectedly: exit status 3"},
+		{"ok", ""}, // the worker process is restarted
+		{"hang", "fuzzing process hung"},
+		{"ok", ""},
+	}
+	for _, tt := range tests {
+		cov, failure, err := w.run(CorpusEntry{Values: []interface{}{tt.in}})
+		if err != nil {
+			t.Fatalf("run(%q): %v", tt.in, err)
+		}
+		if tt.failure == "" {
+			if failure != nil {
+				t.Errorf("run(%q) failed: %v", tt.in, failure)
+			} else if len(cov) != 1 {
+				t.Errorf("run(%q) returned coverage %v, want [1]", tt.in, cov)
+			}
+			continue
+		}
+		if failure == nil || !strings.HasPrefix(failure.Error(), tt.failure) {
+			t.Errorf("run(%q) failure = %v, want %s...", tt.in, failure, tt.failure)
+		}
+	}
+}
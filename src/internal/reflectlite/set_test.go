@@ -0,0 +1,112 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reflectlite_test
+
+import (
+	"bytes"
+	. "internal/reflectlite"
+	"io"
+	"testing"
+)
+
+func TestImplicitSetConversion(t *testing.T) {
+	// Assume TestImplicitMapConversion covered the basics.
+	// Just make sure conversions are being applied at all.
+	var r io.Reader
+	b := new(bytes.Buffer)
+	rv := ValueOf(&r).Elem()
+	rv.Set(ValueOf(b))
+	if r != b {
+		t.Errorf("after Set: r=%T(%v)", r, r)
+	}
+}
+
+var implementsTests = []struct {
+	x interface{}
+	t interface{}
+	b bool
+}{
+	{new(*bytes.Buffer), new(io.Reader), true},
+	{new(bytes.Buffer), new(io.Reader), false},
+	{new(*bytes.Buffer), new(io.ReaderAt), false},
+	{new(*io.Reader), new(io.Reader), false},
+	{new(io.ReadWriter), new(io.Reader), true},
+	{new(io.Reader), new(io.ReadWriter), false},
+	{new(error), new(error), true},
+	{new(notAnError), new(error), false},
+	{new(*notAnError), new(error), false},
+	{new(*anError), new(error), true},
+}
+
+type notAnError struct{}
+
+type anError struct{}
+
+func (*anError) Error() string { return "an error" }
+
+func TestImplements(t *testing.T) {
+	for _, tt := range implementsTests {
+		xv := TypeOf(tt.x).Elem()
+		xt := TypeOf(tt.t).Elem()
+		if b := xv.Implements(xt); b != tt.b {
+			t.Errorf("(%s).Implements(%s) = %v, want %v", xv.String(), xt.String(), b, tt.b)
+		}
+	}
+}
+
+var assignableTests = []struct {
+	x interface{}
+	t interface{}
+	b bool
+}{
+	{new(chan int), new(<-chan int), true},
+	{new(<-chan int), new(chan int), false},
+	{new(*int), new(IntPtr), true},
+	{new(IntPtr), new(*int), true},
+	{new(IntPtr), new(IntPtr1), false},
+	{new(Ch), new(<-chan interface{}), true},
+	{new(func(int) int), new(func(int) int), true},
+	{new(func(int) int), new(func(int) string), false},
+	{new(map[string]int), new(map[string]int), true},
+	{new(map[string]int), new(map[int]string), false},
+	{new([2]int), new([2]int), true},
+	{new([2]int), new([3]int), false},
+	{new(struct{ A int }), new(struct{ A int }), true},
+	{new(struct{ A int }), new(struct{ B int }), false},
+	// test runs implementsTests too
+}
+
+type IntPtr *int
+type IntPtr1 *int
+type Ch <-chan interface{}
+
+func TestAssignableTo(t *testing.T) {
+	for _, tt := range append(assignableTests, implementsTests...) {
+		xv := TypeOf(tt.x).Elem()
+		xt := TypeOf(tt.t).Elem()
+		if b := xv.AssignableTo(xt); b != tt.b {
+			t.Errorf("(%s).AssignableTo(%s) = %v, want %v", xv.String(), xt.String(), b, tt.b)
+		}
+	}
+}
+
+func TestComparable(t *testing.T) {
+	for _, tt := range []struct {
+		x interface{}
+		b bool
+	}{
+		{1, true},
+		{"a", true},
+		{struct{ A int }{}, true},
+		{[]int(nil), false},
+		{map[int]int(nil), false},
+		{func() {}, false},
+		{struct{ A []int }{}, false},
+	} {
+		if b := TypeOf(tt.x).Comparable(); b != tt.b {
+			t.Errorf("TypeOf(%T).Comparable() = %v, want %v", tt.x, b, tt.b)
+		}
+	}
+}
@@ -41,6 +41,8 @@ type Event struct {
 	// for blocking GoSysCall: the associated GoSysExit
 	// for GoSysExit: the next GoStart
 	// for GCMarkAssistStart: the associated GCMarkAssistDone
+	// for UserTaskCreate: the UserTaskEnd
+	// for UserRegion: if the start region, the corresponding UserRegion end event
 	Link *Event
 }
 
@@ -108,9 +110,10 @@ func parse(r io.Reader, bin string) (int, []*Event, error) {
 
 // rawEvent is a helper type used during parsing.
 type rawEvent struct {
-	off  int
-	typ  byte
-	args []uint64
+	off   int
+	typ   byte
+	args  []uint64
+	sargs []string
 }
 
 // readTrace does wire-format parsing and verification.
@@ -128,7 +131,7 @@ func readTrace(r io.Reader) (ver int, events []rawEvent, strings map[uint64]stri
 		return
 	}
 	switch ver {
-	case 1005, 1007, 1008, 1009, 1010:
+	case 1005, 1007, 1008, 1009, 1010, 1011:
 		// Note: When adding a new version, add canned traces
 		// from the old version to the test suite using mkcanned.bash.
 		break
@@ -179,28 +182,16 @@ func readTrace(r io.Reader) (ver int, events []rawEvent, strings map[uint64]stri
 				err = fmt.Errorf("string at offset %d has duplicate id %v", off, id)
 				return
 			}
-			var ln uint64
-			ln, off, err = readVal(r, off)
+			var s string
+			s, off, err = readStr(r, off)
 			if err != nil {
 				return
 			}
-			if ln == 0 {
+			if len(s) == 0 {
 				err = fmt.Errorf("string at offset %d has invalid length 0", off)
 				return
 			}
-			if ln > 1e6 {
-				err = fmt.Errorf("string at offset %d has too large length %v", off, ln)
-				return
-			}
-			buf := make([]byte, ln)
-			var n int
-			n, err = io.ReadFull(r, buf)
-			if err != nil {
-				err = fmt.Errorf("failed to read trace at offset %d: read %v, want %v, error %v", off, n, ln, err)
-				return
-			}
-			off += n
-			strings[id] = string(buf)
+			strings[id] = s
 			continue
 		}
 		ev := rawEvent{typ: typ, off: off0}
@@ -237,11 +228,38 @@ func readTrace(r io.Reader) (ver int, events []rawEvent, strings map[uint64]stri
 				return
 			}
 		}
+		if typ == EvUserLog {
+			// EvUserLog is followed by the message string [length, string].
+			var s string
+			s, off, err = readStr(r, off)
+			if err != nil {
+				return
+			}
+			ev.sargs = append(ev.sargs, s)
+		}
 		events = append(events, ev)
 	}
 	return
 }
 
+// readStr reads a length-prefixed string from r.
+func readStr(r io.Reader, off0 int) (s string, off int, err error) {
+	var sz uint64
+	sz, off, err = readVal(r, off0)
+	if err != nil || sz == 0 {
+		return "", off, err
+	}
+	if sz > 1e6 {
+		return "", off, fmt.Errorf("string at offset %d is too large (len=%d)", off, sz)
+	}
+	buf := make([]byte, sz)
+	n, err := io.ReadFull(r, buf)
+	if err != nil || sz != uint64(n) {
+		return "", off + n, fmt.Errorf("failed to read trace at offset %d: read %v, want %v, error %v", off, n, sz, err)
+	}
+	return string(buf), off + n, nil
+}
+
 // parseHeader parses trace header of the form "go 1.7 trace\x00\x00\x00\x00"
 // and returns parsed version as 1007.
 func parseHeader(buf []byte) (int, error) {
@@ -393,6 +411,15 @@ func parseEvents(ver int, rawEvents []rawEvent, strings map[uint64]string) (even
 				lastG = 0
 			case EvGoSysExit, EvGoWaiting, EvGoInSyscall:
 				e.G = e.Args[0]
+			case EvUserTaskCreate:
+				// e.Args 0: taskID, 1: parentID, 2: nameID
+				e.SArgs = []string{strings[e.Args[2]]}
+			case EvUserRegion:
+				// e.Args 0: taskID, 1: mode, 2: nameID
+				e.SArgs = []string{strings[e.Args[2]]}
+			case EvUserLog:
+				// e.Args 0: taskID, 1: categoryID
+				e.SArgs = []string{strings[e.Args[1]], raw.sargs[0]}
 			}
 			batches[lastP] = append(batches[lastP], e)
 		}
@@ -528,6 +555,8 @@ func postProcessTrace(ver int, events []*Event) error {
 
 	gs := make(map[uint64]gdesc)
 	ps := make(map[int]pdesc)
+	tasks := make(map[uint64]*Event)           // task id to task creation events
+	activeRegions := make(map[uint64][]*Event) // goroutine id to stack of active regions
 	gs[0] = gdesc{state: gRunning}
 	var evGC, evSTW *Event
 
@@ -733,6 +762,44 @@ func postProcessTrace(ver int, events []*Event) error {
 			g.evStart.Link = ev
 			g.evStart = nil
 			p.g = 0
+		case EvUserTaskCreate:
+			taskid := ev.Args[0]
+			if prevEv, ok := tasks[taskid]; ok {
+				return fmt.Errorf("task id conflicts (id:%d) at offset %v and %v", taskid, prevEv.Off, ev.Off)
+			}
+			tasks[taskid] = ev
+		case EvUserTaskEnd:
+			taskid := ev.Args[0]
+			// The task may have been created before tracing started.
+			if taskCreateEv, ok := tasks[taskid]; ok {
+				taskCreateEv.Link = ev
+				delete(tasks, taskid)
+			}
+		case EvUserRegion:
+			mode := ev.Args[1]
+			regions := activeRegions[ev.G]
+			switch mode {
+			case 0: // region start
+				activeRegions[ev.G] = append(regions, ev)
+			case 1: // region end
+				// The region may have started before tracing started.
+				n := len(regions)
+				if n == 0 {
+					break
+				}
+				s := regions[n-1]
+				if s.Args[0] != ev.Args[0] || s.SArgs[0] != ev.SArgs[0] {
+					return fmt.Errorf("misuse of region in goroutine %v: region %q ends while region %q is active (offset %v, time %v)", ev.G, ev.SArgs[0], s.SArgs[0], ev.Off, ev.Ts)
+				}
+				s.Link = ev
+				if n > 1 {
+					activeRegions[ev.G] = regions[:n-1]
+				} else {
+					delete(activeRegions, ev.G)
+				}
+			default:
+				return fmt.Errorf("invalid user region mode %v (offset %v, time %v)", mode, ev.Off, ev.Ts)
+			}
 		}
 
 		gs[ev.G] = g
@@ -944,7 +1011,11 @@ const (
 	EvGoBlockGC         = 42 // goroutine blocks on GC assist [timestamp, stack]
 	EvGCMarkAssistStart = 43 // GC mark assist start [timestamp, stack]
 	EvGCMarkAssistDone  = 44 // GC mark assist done [timestamp]
-	EvCount             = 45
+	EvUserTaskCreate    = 45 // trace.NewTask [timestamp, internal task id, internal parent task id, name string id, stack]
+	EvUserTaskEnd       = 46 // end of a task [timestamp, internal task id, stack]
+	EvUserRegion        = 47 // trace.WithRegion [timestamp, internal task id, mode(0:start, 1:end), name string id, stack]
+	EvUserLog           = 48 // trace.Log [timestamp, internal task id, category string id, stack, value string]
+	EvCount             = 49
 )
 
 var EventDescriptions = [EvCount]struct {
@@ -998,4 +1069,8 @@ var EventDescriptions = [EvCount]struct {
 	EvGoBlockGC:         {"GoBlockGC", 1008, true, []string{}},
 	EvGCMarkAssistStart: {"GCMarkAssistStart", 1009, true, []string{}},
 	EvGCMarkAssistDone:  {"GCMarkAssistDone", 1009, false, []string{}},
+	EvUserTaskCreate:    {"UserTaskCreate", 1011, true, []string{"taskid", "pid", "typeid"}},
+	EvUserTaskEnd:       {"UserTaskEnd", 1011, true, []string{"taskid"}},
+	EvUserRegion:        {"UserRegion", 1011, true, []string{"taskid", "mode", "typeid"}},
+	EvUserLog:           {"UserLog", 1011, true, []string{"id", "keyid"}},
 }
@@ -4,9 +4,10 @@
 //
 // [[[3-over]]] 2017-6-21 13:22:06
 
-package ioutil
+package ioutil_test
 
 import (
+	. "io/ioutil"
 	"os"
 	"testing"
 )
@@ -4,9 +4,10 @@
 //
 // [[[4-over]]] 2017-6-21 13:07:51
 
-package ioutil
+package ioutil_test
 
 import (
+	. "io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
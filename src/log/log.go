@@ -20,6 +20,7 @@ package log
 import (
 	"fmt"
 	"io"
+	"log/internal"
 	"os"
 	"runtime"
 	"sync"
@@ -86,6 +87,12 @@ func (l *Logger) SetOutput(w io.Writer) {
 // LstdFlags 会生成这样格式的日志: '2009/01/23 01:23:23 message'
 var std = New(os.Stderr, "", LstdFlags)
 
+func init() {
+	internal.DefaultOutput = func(pc uintptr, data []byte) error {
+		return std.output(pc, 0, string(data))
+	}
+}
+
 // Cheap integer to fixed-width decimal ASCII. Give a negative width to avoid zero-padding.
 //
 // 将整数 i 转换为固定宽度 wid 的十进制 ascii 数字
@@ -242,6 +249,13 @@ func (l *Logger) formatHeader(buf *[]byte, t time.Time, file string, line int) {
 // provided for generality, although at the moment on all pre-defined
 // paths it will be 2.
 func (l *Logger) Output(calldepth int, s string) error {
+	calldepth++ // +1 for this frame.
+	return l.output(0, calldepth, s)
+}
+
+// output can take either a calldepth or a pc to get source line information.
+// It uses the pc if it is non-zero.
+func (l *Logger) output(pc uintptr, calldepth int, s string) error {
 	// Get time early if we need it.
 	var now time.Time
 	if l.flag&(Ldate|Ltime|Lmicroseconds) != 0 {
@@ -258,9 +272,15 @@ func (l *Logger) Output(calldepth int, s string) error {
 		// 注意在unlock和lock之间并没有对l.xxx有写操作
 		// ok: 是否获取到file和line
 		var ok bool
-		// $ go doc runtime.Caller
-		// 返回值 ok: The boolean ok is false if it was not possible to recover the information
-		_, file, line, ok = runtime.Caller(calldepth)
+		if pc == 0 {
+			// $ go doc runtime.Caller
+			// 返回值 ok: The boolean ok is false if it was not possible to recover the information
+			_, file, line, ok = runtime.Caller(calldepth)
+		} else {
+			fs := runtime.CallersFrames([]uintptr{pc})
+			f, _ := fs.Next()
+			file, line, ok = f.File, f.Line, f.File != ""
+		}
 		if !ok {
 			// if it was not possible to recover the information
 			// 无法恢复调用信息
@@ -0,0 +1,112 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package slog_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+func Example() {
+	removeTime := func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey && len(groups) == 0 {
+			return slog.Attr{}
+		}
+		return a
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: removeTime}))
+
+	logger.Info("hello", "count", 3)
+	logger.With("user", "gopher").Warn("disk almost full",
+		slog.Group("disk", "used", 93, "path", "/var"))
+
+	// Output:
+	// level=INFO msg=hello count=3
+	// level=WARN msg="disk almost full" user=gopher disk.used=93 disk.path=/var
+}
+
+func ExampleGroup() {
+	removeTime := func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey && len(groups) == 0 {
+			return slog.Attr{}
+		}
+		return a
+	}
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: removeTime}))
+
+	logger.Info("finished",
+		slog.Group("req",
+			slog.String("method", "GET"),
+			slog.String("url", "http://example.com")),
+		slog.Int("status", 200))
+
+	// Output:
+	// {"level":"INFO","msg":"finished","req":{"method":"GET","url":"http://example.com"},"status":200}
+}
+
+func ExampleLevelVar() {
+	var programLevel slog.LevelVar // Info by default
+	removeTime := func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey && len(groups) == 0 {
+			return slog.Attr{}
+		}
+		return a
+	}
+	h := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: &programLevel, ReplaceAttr: removeTime})
+	logger := slog.New(h)
+
+	logger.Debug("not shown")
+	programLevel.Set(slog.LevelDebug)
+	logger.Debug("shown")
+
+	// Output:
+	// level=DEBUG msg=shown
+}
+
+func ExampleNewContext() {
+	removeTime := func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey && len(groups) == 0 {
+			return slog.Attr{}
+		}
+		return a
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: removeTime}))
+
+	// A request handler attaches a Logger carrying the request ID
+	// to the request's context...
+	ctx := slog.NewContext(context.Background(), logger.With("request", 42))
+
+	// ...and the functions it calls retrieve it.
+	handle := func(ctx context.Context) {
+		slog.FromContext(ctx).InfoContext(ctx, "handled")
+	}
+	handle(ctx)
+
+	// Output:
+	// level=INFO msg=handled request=42
+}
+
+// Password is a string that is never logged.
+type Password string
+
+// LogValue implements slog.LogValuer.
+func (Password) LogValue() slog.Value {
+	return slog.StringValue("REDACTED")
+}
+
+func ExampleLogValuer() {
+	removeTime := func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey && len(groups) == 0 {
+			return slog.Attr{}
+		}
+		return a
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: removeTime}))
+	logger.Info("login", "user", "gopher", "password", Password("hunter2"))
+
+	// Output:
+	// level=INFO msg=login user=gopher password=REDACTED
+}
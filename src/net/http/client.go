@@ -97,7 +97,11 @@ type Client struct {
 	// The Jar is used to insert relevant cookies into every
 	// outbound Request and is updated with the cookie values
 	// of every inbound Response. The Jar is consulted for every
-	// redirect that the Client follows.
+	// redirect that the Client follows. If the Jar has a method
+	// CookiesFrom(method string, u, from *url.URL) []*Cookie, such as
+	// the Jar in net/http/cookiejar, the Client uses it for redirects,
+	// passing the method of the new request and the URL of the
+	// redirecting request as from.
 	//
 	// If Jar is nil, cookies are only sent if they are explicitly
 	// set on the Request.
@@ -198,8 +202,8 @@ func refererForURL(lastReq, newReq *url.URL) string {
 func (c *Client) send(req *Request, deadline time.Time) (resp *Response, didTimeout func() bool, err error) {
 	// 如果c.Jar中有需要发送的cookie,将其添加到req中
 	if c.Jar != nil {
-		// c.Jar.Cookies(req.URL)代表了请求req.URL时应该发送哪些cookie
-		for _, cookie := range c.Jar.Cookies(req.URL) {
+		// c.jarCookies(req)代表了请求req.URL时应该发送哪些cookie
+		for _, cookie := range c.jarCookies(req) {
 			// 添加cookie到请求中
 			req.AddCookie(cookie)
 		}
@@ -219,6 +223,17 @@ func (c *Client) send(req *Request, deadline time.Time) (resp *Response, didTime
 	return resp, nil, nil
 }
 
+// jarCookies returns the cookies from c.Jar to send with req.
+// If req follows a redirect and the Jar implements CookiesFrom,
+// the Jar is told the method of req and the URL of the request that
+// was redirected.
+func (c *Client) jarCookies(req *Request) []*Cookie {
+	if j, ok := c.Jar.(cookiesFromJar); ok && req.Response != nil && req.Response.Request != nil {
+		return j.CookiesFrom(req.Method, req.URL, req.Response.Request.URL)
+	}
+	return c.Jar.Cookies(req.URL)
+}
+
 // 返回Client应该在什么时间到deadline
 // 根据c.Timeout的配置情况.
 func (c *Client) deadline() time.Time {
@@ -754,6 +754,43 @@ func (j *RecordingJar) logf(format string, args ...interface{}) {
 	fmt.Fprintf(&j.log, format, args...)
 }
 
+func TestJarCallsCookiesFrom(t *testing.T) {
+	defer afterTest(t)
+	ts := httptest.NewServer(HandlerFunc(func(w ResponseWriter, r *Request) {
+		if r.RequestURI == "/" {
+			Redirect(w, r, "http://secondhost.fake/secondpath", 302)
+		}
+	}))
+	defer ts.Close()
+	jar := new(RecordingFromJar)
+	c := ts.Client()
+	c.Jar = jar
+	c.Transport.(*Transport).Dial = func(_ string, _ string) (net.Conn, error) {
+		return net.Dial("tcp", ts.Listener.Addr().String())
+	}
+	_, err := c.Get("http://firsthost.fake/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := jar.log.String()
+	want := `Cookies("http://firsthost.fake/")
+CookiesFrom("GET", "http://secondhost.fake/secondpath", "http://firsthost.fake/")
+`
+	if got != want {
+		t.Errorf("Got Jar calls:\n%s\nWant:\n%s", got, want)
+	}
+}
+
+// RecordingFromJar is a RecordingJar that also implements CookiesFrom.
+type RecordingFromJar struct {
+	RecordingJar
+}
+
+func (j *RecordingFromJar) CookiesFrom(method string, u, from *url.URL) []*Cookie {
+	j.logf("CookiesFrom(%q, %q, %q)\n", method, u, from)
+	return nil
+}
+
 func TestStreamingGet_h1(t *testing.T) { testStreamingGet(t, h1Mode) }
 func TestStreamingGet_h2(t *testing.T) { testStreamingGet(t, h2Mode) }
 
@@ -977,6 +1014,7 @@ func TestResponseSetsTLSConnectionState(t *testing.T) {
 	c := ts.Client()
 	tr := c.Transport.(*Transport)
 	tr.TLSClientConfig.CipherSuites = []uint16{tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA}
+	tr.TLSClientConfig.MaxVersion = tls.VersionTLS12 // CipherSuites is not configurable in TLS 1.3
 	tr.Dial = func(netw, addr string) (net.Conn, error) {
 		return net.Dial(netw, ts.Listener.Addr().String())
 	}
@@ -7,6 +7,7 @@
 package http_test
 
 import (
+	"bufio"
 	"bytes"
 	"compress/gzip"
 	"crypto/tls"
@@ -1408,3 +1409,332 @@ func TestBadResponseAfterReadingBody(t *testing.T) {
 		t.Errorf("closes = %d; want 1", closes)
 	}
 }
+
+// newHTTP2ConfigServer starts a TLS server for h that offers HTTP/2
+// and leaves setting it up to Serve, which applies the server's HTTP2
+// configuration c.
+func newHTTP2ConfigServer(h Handler, c *HTTP2Config) *httptest.Server {
+	ts := httptest.NewUnstartedServer(h)
+	ts.TLS = &tls.Config{NextProtos: []string{"h2"}}
+	ts.Config.HTTP2 = c
+	ts.StartTLS()
+	return ts
+}
+
+func TestHTTP2ConfigMaxConcurrentStreams(t *testing.T) {
+	defer afterTest(t)
+	var (
+		mu          sync.Mutex
+		inFlight    int
+		maxInFlight int
+	)
+	ts := newHTTP2ConfigServer(HandlerFunc(func(w ResponseWriter, r *Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}), &HTTP2Config{MaxConcurrentStreams: 1})
+	defer ts.Close()
+	tr := &Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	defer tr.CloseIdleConnections()
+	if err := ExportHttp2ConfigureTransport(tr); err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{Transport: tr}
+
+	// Make a first request so that the client has seen the server's SETTINGS.
+	res, err := c.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.ProtoMajor != 2 {
+		t.Fatalf("response proto = %q; want HTTP/2.0", res.Proto)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := c.Get(ts.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+		}()
+	}
+	wg.Wait()
+	if maxInFlight != 1 {
+		t.Errorf("saw %d concurrent requests; want 1", maxInFlight)
+	}
+}
+
+// readHTTP2Frame reads a single HTTP/2 frame from r and returns
+// its type and flags, discarding the payload.
+func readHTTP2Frame(r io.Reader) (typ, flags byte, err error) {
+	var hdr [9]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, 0, err
+	}
+	n := int64(hdr[0])<<16 | int64(hdr[1])<<8 | int64(hdr[2])
+	if _, err := io.CopyN(ioutil.Discard, r, n); err != nil {
+		return 0, 0, err
+	}
+	return hdr[3], hdr[4], nil
+}
+
+const (
+	http2FramePing     = 0x6
+	http2FlagPingAck   = 0x1
+	http2ClientPreface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+)
+
+var http2EmptySettingsFrame = []byte{0, 0, 0, 0x4, 0, 0, 0, 0, 0}
+
+// Tests that the server pings an idle client and closes the connection
+// when the PING is not answered.
+func TestHTTP2ServerPingTimeout(t *testing.T) {
+	defer afterTest(t)
+	ts := newHTTP2ConfigServer(HandlerFunc(func(w ResponseWriter, r *Request) {}), &HTTP2Config{
+		SendPingTimeout: 50 * time.Millisecond,
+		PingTimeout:     50 * time.Millisecond,
+	})
+	defer ts.Close()
+
+	conn, err := tls.Dial("tcp", ts.Listener.Addr().String(), &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{"h2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := io.WriteString(conn, http2ClientPreface); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write(http2EmptySettingsFrame); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read, but never answer, until the server closes the connection.
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	sawPing := false
+	for {
+		typ, flags, err := readHTTP2Frame(conn)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				t.Fatal("server did not close the connection")
+			}
+			break
+		}
+		if typ == http2FramePing && flags&http2FlagPingAck == 0 {
+			sawPing = true
+		}
+	}
+	if !sawPing {
+		t.Error("server closed the connection without sending a PING")
+	}
+}
+
+// Tests that the Transport pings a silent server and fails the
+// request when the PING is not answered.
+func TestHTTP2TransportPingTimeout(t *testing.T) {
+	defer afterTest(t)
+	sawPing := make(chan bool, 1)
+	ts := httptest.NewUnstartedServer(HandlerFunc(func(w ResponseWriter, r *Request) {}))
+	ts.TLS = &tls.Config{NextProtos: []string{"h2"}}
+	ts.Config.TLSNextProto = map[string]func(*Server, *tls.Conn, Handler){
+		"h2": func(_ *Server, c *tls.Conn, _ Handler) {
+			// Speak just enough HTTP/2 to be accepted,
+			// then ignore everything the client sends.
+			preface := make([]byte, len(http2ClientPreface))
+			if _, err := io.ReadFull(c, preface); err != nil {
+				return
+			}
+			if _, err := c.Write(http2EmptySettingsFrame); err != nil {
+				return
+			}
+			for {
+				typ, flags, err := readHTTP2Frame(c)
+				if err != nil {
+					return
+				}
+				if typ == http2FramePing && flags&http2FlagPingAck == 0 {
+					select {
+					case sawPing <- true:
+					default:
+					}
+				}
+			}
+		},
+	}
+	ts.StartTLS()
+	defer ts.Close()
+
+	tr := &Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		HTTP2: &HTTP2Config{
+			SendPingTimeout: 50 * time.Millisecond,
+			PingTimeout:     50 * time.Millisecond,
+		},
+		Protocols: new(Protocols),
+	}
+	tr.Protocols.SetHTTP2(true)
+	defer tr.CloseIdleConnections()
+	res, err := (&Client{Transport: tr}).Get(ts.URL)
+	if err == nil {
+		res.Body.Close()
+		t.Fatal("Get succeeded; want error")
+	}
+	select {
+	case <-sawPing:
+	default:
+		t.Error("Transport did not send a PING")
+	}
+}
+
+func TestUnencryptedHTTP2PriorKnowledge(t *testing.T) {
+	defer afterTest(t)
+	ts := httptest.NewUnstartedServer(HandlerFunc(func(w ResponseWriter, r *Request) {
+		io.WriteString(w, r.Proto)
+	}))
+	ts.Config.Protocols = new(Protocols)
+	ts.Config.Protocols.SetHTTP1(true)
+	ts.Config.Protocols.SetUnencryptedHTTP2(true)
+	ts.Start()
+	defer ts.Close()
+
+	tr := &Transport{Protocols: new(Protocols)}
+	tr.Protocols.SetUnencryptedHTTP2(true)
+	defer tr.CloseIdleConnections()
+	c := &Client{Transport: tr}
+	for i := 0; i < 2; i++ {
+		res, err := c.Get(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.ProtoMajor != 2 || string(body) != "HTTP/2.0" {
+			t.Errorf("request %d: response proto %q, handler saw %q; want HTTP/2.0", i, res.Proto, body)
+		}
+	}
+
+	// The server still speaks HTTP/1.1 to other clients.
+	h1 := &Transport{}
+	defer h1.CloseIdleConnections()
+	res, err := (&Client{Transport: h1}).Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "HTTP/1.1" {
+		t.Errorf("HTTP/1.1 client: handler saw %q", body)
+	}
+}
+
+func TestUnencryptedHTTP2Upgrade(t *testing.T) {
+	defer afterTest(t)
+	gotReq := make(chan *Request, 1)
+	ts := httptest.NewUnstartedServer(HandlerFunc(func(w ResponseWriter, r *Request) {
+		gotReq <- r
+	}))
+	ts.Config.Protocols = new(Protocols)
+	ts.Config.Protocols.SetHTTP1(true)
+	ts.Config.Protocols.SetUnencryptedHTTP2(true)
+	ts.Start()
+	defer ts.Close()
+
+	conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	const req = "GET /foo HTTP/1.1\r\n" +
+		"Host: example.com\r\n" +
+		"Connection: Upgrade, HTTP2-Settings\r\n" +
+		"Upgrade: h2c\r\n" +
+		"HTTP2-Settings: AAMAAABk\r\n" + // SETTINGS_MAX_CONCURRENT_STREAMS = 100
+		"X-Foo: bar\r\n" +
+		"\r\n"
+	if _, err := io.WriteString(conn, req); err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	res, err := ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != StatusSwitchingProtocols || res.Header.Get("Upgrade") != "h2c" {
+		t.Fatalf("upgrade response = %v, Upgrade: %q; want 101 to h2c", res.Status, res.Header.Get("Upgrade"))
+	}
+	// The server answers the upgraded request only after reading
+	// the client preface.
+	if _, err := io.WriteString(conn, http2ClientPreface); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write(http2EmptySettingsFrame); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case r := <-gotReq:
+		if r.ProtoMajor != 2 || r.URL.Path != "/foo" || r.Host != "example.com" {
+			t.Errorf("handler saw %s %s for host %q; want HTTP/2.0 /foo for example.com", r.Proto, r.URL.Path, r.Host)
+		}
+		if v := r.Header.Get("Http2-Settings"); v != "" {
+			t.Errorf("handler saw HTTP2-Settings header %q", v)
+		}
+		if v := r.Header.Get("X-Foo"); v != "bar" {
+			t.Errorf("handler saw X-Foo header %q; want %q", v, "bar")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for upgraded request")
+	}
+
+	const http2FrameHeaders = 0x1
+	for {
+		typ, _, err := readHTTP2Frame(br)
+		if err != nil {
+			t.Fatalf("reading response to upgraded request: %v", err)
+		}
+		if typ == http2FrameHeaders {
+			break
+		}
+	}
+}
+
+func TestServerHTTP1Disabled(t *testing.T) {
+	defer afterTest(t)
+	ts := httptest.NewUnstartedServer(HandlerFunc(func(w ResponseWriter, r *Request) {
+		t.Errorf("unexpected %s request", r.Proto)
+	}))
+	ts.Config.Protocols = new(Protocols)
+	ts.Config.Protocols.SetUnencryptedHTTP2(true)
+	ts.Start()
+	defer ts.Close()
+
+	tr := &Transport{}
+	defer tr.CloseIdleConnections()
+	res, err := (&Client{Transport: tr}).Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != StatusHTTPVersionNotSupported {
+		t.Errorf("status = %v; want 505", res.Status)
+	}
+}
@@ -8,6 +8,8 @@ package http
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"log"
 	"net"
 	"strconv"
@@ -63,12 +65,41 @@ type Cookie struct {
 	Secure   bool
 	// 参考:https://tools.ietf.org/html/rfc6265#section-4.1.2.6
 	HttpOnly bool
+	SameSite SameSite
+	// Partitioned marks a cookie for partitioned storage by the user
+	// agent (CHIPS). A partitioned cookie must also be Secure.
+	Partitioned bool
 	// ??????
 	Raw      string
 	// ??????
 	Unparsed []string // Raw text of unparsed attribute-value pairs
 }
 
+// SameSite allows a server to define a cookie attribute making it impossible for
+// the browser to send this cookie along with cross-site requests. The main
+// goal is to mitigate the risk of cross-origin information leakage, and provide
+// some protection against cross-site request forgery attacks.
+//
+// See https://tools.ietf.org/html/draft-ietf-httpbis-rfc6265bis for details.
+type SameSite int
+
+const (
+	// SameSiteDefaultMode sets the SameSite attribute with no value,
+	// leaving the enforcement mode to the user agent.
+	SameSiteDefaultMode SameSite = iota + 1
+	SameSiteLaxMode
+	SameSiteStrictMode
+	SameSiteNoneMode
+)
+
+// Cookie name prefixes defined by RFC 6265bis section 4.1.3.
+// A user agent only accepts a cookie whose name starts with one of them
+// if the cookie meets the prefix's requirements; see Cookie.Valid.
+const (
+	cookieSecurePrefix = "__Secure-"
+	cookieHostPrefix   = "__Host-"
+)
+
 // readSetCookies parses all "Set-Cookie" values from
 // the header h and returns the successfully parsed Cookies.
 //
@@ -127,6 +158,21 @@ func readSetCookies(h Header) []*Cookie {
 			case "httponly":
 				c.HttpOnly = true
 				continue
+			case "samesite":
+				switch strings.ToLower(val) {
+				case "lax":
+					c.SameSite = SameSiteLaxMode
+				case "strict":
+					c.SameSite = SameSiteStrictMode
+				case "none":
+					c.SameSite = SameSiteNoneMode
+				default:
+					c.SameSite = SameSiteDefaultMode
+				}
+				continue
+			case "partitioned":
+				c.Partitioned = true
+				continue
 			case "domain":
 				c.Domain = val
 				continue
@@ -234,9 +280,68 @@ func (c *Cookie) String() string {
 	if c.Secure {
 		b.WriteString("; Secure")
 	}
+	switch c.SameSite {
+	case SameSiteDefaultMode:
+		b.WriteString("; SameSite")
+	case SameSiteLaxMode:
+		b.WriteString("; SameSite=Lax")
+	case SameSiteStrictMode:
+		b.WriteString("; SameSite=Strict")
+	case SameSiteNoneMode:
+		b.WriteString("; SameSite=None")
+	}
+	if c.Partitioned {
+		b.WriteString("; Partitioned")
+	}
 	return b.String()
 }
 
+// Valid reports whether the cookie is valid.
+// Besides the syntax of its name, value, path, domain and expiry,
+// it checks the requirements of the "__Secure-" and "__Host-" name
+// prefixes: both require Secure, and "__Host-" additionally requires
+// Path "/" and no Domain. A Partitioned cookie must be Secure.
+func (c *Cookie) Valid() error {
+	if c == nil {
+		return errors.New("http: nil Cookie")
+	}
+	if !isCookieNameValid(c.Name) {
+		return errors.New("http: invalid Cookie.Name")
+	}
+	if !c.Expires.IsZero() && !validCookieExpires(c.Expires) {
+		return errors.New("http: invalid Cookie.Expires")
+	}
+	for i := 0; i < len(c.Value); i++ {
+		if !validCookieValueByte(c.Value[i]) {
+			return fmt.Errorf("http: invalid byte %q in Cookie.Value", c.Value[i])
+		}
+	}
+	if len(c.Path) > 0 {
+		for i := 0; i < len(c.Path); i++ {
+			if !validCookiePathByte(c.Path[i]) {
+				return fmt.Errorf("http: invalid byte %q in Cookie.Path", c.Path[i])
+			}
+		}
+	}
+	if len(c.Domain) > 0 {
+		if !validCookieDomain(c.Domain) {
+			return errors.New("http: invalid Cookie.Domain")
+		}
+	}
+	if strings.HasPrefix(c.Name, cookieSecurePrefix) && !c.Secure {
+		return errors.New("http: __Secure- prefixed cookie must be Secure")
+	}
+	if strings.HasPrefix(c.Name, cookieHostPrefix) {
+		if !c.Secure || c.Path != "/" || c.Domain != "" {
+			return errors.New("http: __Host- prefixed cookie must be Secure, with Path \"/\" and no Domain")
+		}
+	}
+	if c.Partitioned && !c.Secure {
+		return errors.New("http: partitioned cookie must be Secure")
+	}
+	return nil
+}
+
 // readCookies parses all "Cookie" values from the header h and
 // returns the successfully parsed Cookies.
 //
@@ -65,6 +65,26 @@ var writeSetCookiesTests = []struct {
 		&Cookie{Name: "cookie-11", Value: "invalid-expiry", Expires: time.Date(1600, 1, 1, 1, 1, 1, 1, time.UTC)},
 		"cookie-11=invalid-expiry",
 	},
+	{
+		&Cookie{Name: "cookie-12", Value: "samesite-default", SameSite: SameSiteDefaultMode},
+		"cookie-12=samesite-default; SameSite",
+	},
+	{
+		&Cookie{Name: "cookie-13", Value: "samesite-lax", SameSite: SameSiteLaxMode},
+		"cookie-13=samesite-lax; SameSite=Lax",
+	},
+	{
+		&Cookie{Name: "cookie-14", Value: "samesite-strict", SameSite: SameSiteStrictMode},
+		"cookie-14=samesite-strict; SameSite=Strict",
+	},
+	{
+		&Cookie{Name: "cookie-15", Value: "samesite-none", Secure: true, SameSite: SameSiteNoneMode},
+		"cookie-15=samesite-none; Secure; SameSite=None",
+	},
+	{
+		&Cookie{Name: "cookie-16", Value: "partitioned", Path: "/", Secure: true, SameSite: SameSiteNoneMode, Partitioned: true},
+		"cookie-16=partitioned; Path=/; Secure; SameSite=None; Partitioned",
+	},
 	// The "special" cookies have values containing commas or spaces which
 	// are disallowed by RFC 6265 but are common in the wild.
 	{
@@ -241,6 +261,54 @@ var readSetCookiesTests = []struct {
 			Raw:      "ASP.NET_SessionId=foo; path=/; HttpOnly",
 		}},
 	},
+	{
+		Header{"Set-Cookie": {"samesitedefault=foo; SameSite"}},
+		[]*Cookie{{
+			Name:     "samesitedefault",
+			Value:    "foo",
+			SameSite: SameSiteDefaultMode,
+			Raw:      "samesitedefault=foo; SameSite",
+		}},
+	},
+	{
+		Header{"Set-Cookie": {"samesiteinvalid=foo; SameSite=Bogus"}},
+		[]*Cookie{{
+			Name:     "samesiteinvalid",
+			Value:    "foo",
+			SameSite: SameSiteDefaultMode,
+			Raw:      "samesiteinvalid=foo; SameSite=Bogus",
+		}},
+	},
+	{
+		Header{"Set-Cookie": {"samesitelax=foo; SameSite=Lax"}},
+		[]*Cookie{{
+			Name:     "samesitelax",
+			Value:    "foo",
+			SameSite: SameSiteLaxMode,
+			Raw:      "samesitelax=foo; SameSite=Lax",
+		}},
+	},
+	{
+		Header{"Set-Cookie": {"samesitestrict=foo; samesite=STRICT"}},
+		[]*Cookie{{
+			Name:     "samesitestrict",
+			Value:    "foo",
+			SameSite: SameSiteStrictMode,
+			Raw:      "samesitestrict=foo; samesite=STRICT",
+		}},
+	},
+	{
+		Header{"Set-Cookie": {"__Host-partitioned=foo; Path=/; Secure; SameSite=None; Partitioned"}},
+		[]*Cookie{{
+			Name:        "__Host-partitioned",
+			Value:       "foo",
+			Path:        "/",
+			Secure:      true,
+			SameSite:    SameSiteNoneMode,
+			Partitioned: true,
+			Raw:         "__Host-partitioned=foo; Path=/; Secure; SameSite=None; Partitioned",
+		}},
+	},
 	// Make sure we can properly read back the Set-Cookie headers we create
 	// for values containing spaces or commas:
 	{
@@ -527,3 +595,38 @@ func BenchmarkReadCookies(b *testing.B) {
 		b.Fatalf("readCookies:\nhave: %s\nwant: %s\n", toJSON(c), toJSON(wantCookies))
 	}
 }
+
+func TestCookieValid(t *testing.T) {
+	tests := []struct {
+		cookie *Cookie
+		valid  bool
+	}{
+		{nil, false},
+		{&Cookie{Name: ""}, false},
+		{&Cookie{Name: "invalid-value", Value: "foo\"bar"}, false},
+		{&Cookie{Name: "invalid-path", Path: "/foo;bar/"}, false},
+		{&Cookie{Name: "invalid-domain", Domain: "example.com:80"}, false},
+		{&Cookie{Name: "invalid-expiry", Value: "", Expires: time.Date(1600, 1, 1, 1, 1, 1, 1, time.UTC)}, false},
+		{&Cookie{Name: "valid-empty"}, true},
+		{&Cookie{Name: "valid-expires", Value: "foo", Path: "/bar", Domain: "example.com", Expires: time.Unix(0, 0)}, true},
+		{&Cookie{Name: "valid-max-age", Value: "foo", Path: "/bar", Domain: "example.com", MaxAge: 60}, true},
+		{&Cookie{Name: "__Secure-insecure", Value: "foo"}, false},
+		{&Cookie{Name: "__Secure-ok", Value: "foo", Domain: "example.com", Secure: true}, true},
+		{&Cookie{Name: "__Host-insecure", Value: "foo", Path: "/"}, false},
+		{&Cookie{Name: "__Host-no-path", Value: "foo", Secure: true}, false},
+		{&Cookie{Name: "__Host-domain", Value: "foo", Path: "/", Domain: "example.com", Secure: true}, false},
+		{&Cookie{Name: "__Host-ok", Value: "foo", Path: "/", Secure: true}, true},
+		{&Cookie{Name: "partitioned-insecure", Value: "foo", Partitioned: true}, false},
+		{&Cookie{Name: "partitioned-ok", Value: "foo", Secure: true, Partitioned: true}, true},
+	}
+
+	for _, tt := range tests {
+		err := tt.cookie.Valid()
+		if err != nil && tt.valid {
+			t.Errorf("%#v.Valid() returned error %v; expected it to be valid", tt.cookie, err)
+		}
+		if err == nil && !tt.valid {
+			t.Errorf("%#v.Valid() returned nil; expected it to be invalid", tt.cookie)
+		}
+	}
+}
@@ -122,6 +122,7 @@ type entry struct {
 	// host-only-flag为false时，Domain属性为example.com的Cookie，在example.com、
 	// www.example.com、sub.example.com等等都可能获取到。
 	HostOnly   bool
+	SameSite   http.SameSite // SameSiteStrictMode, SameSiteLaxMode or SameSiteNoneMode
 	Expires    time.Time
 	Creation   time.Time
 	LastAccess time.Time
@@ -144,9 +145,28 @@ func (e *entry) id() string {
 //
 // qualifies to be: 有资格成为
 // shouldSend决定是否e这个cookie应该在请求中被发送.检查cookie是否过期是调用者的责任.
-func (e *entry) shouldSend(https bool, host, path string) bool {
+//
+// crossSite reports whether the request is made on behalf of another site,
+// and safeMethod whether its method is GET or HEAD. Such requests are
+// treated as top-level navigations, like the redirects followed by
+// http.Client: they carry SameSite=Lax cookies only if safeMethod is set,
+// and never carry SameSite=Strict ones.
+func (e *entry) shouldSend(https, crossSite, safeMethod bool, host, path string) bool {
 	// 域名匹配 && path匹配 && (如果是https请求 || 是https请求或e.Secure==false)
-	return e.domainMatch(host) && e.pathMatch(path) && (https || !e.Secure)
+	return e.domainMatch(host) && e.pathMatch(path) && (https || !e.Secure) &&
+		(!crossSite || e.sameSiteAllowsCrossSite(safeMethod))
+}
+
+// sameSiteAllowsCrossSite reports whether e's SameSite attribute allows it
+// to be sent with a cross-site request. See RFC 6265bis section 5.8.3.
+func (e *entry) sameSiteAllowsCrossSite(safeMethod bool) bool {
+	switch e.SameSite {
+	case http.SameSiteStrictMode:
+		return false
+	case http.SameSiteLaxMode:
+		return safeMethod
+	}
+	return true
 }
 
 // domainMatch implements "domain-match" of RFC 6265 section 5.1.3.
@@ -204,15 +224,35 @@ func hasDotSuffix(s, suffix string) bool {
 // Cookies返回发送请求到u时应使用的cookie.
 // 本方法有责任遵守RFC 6265规定的标准cookie限制.
 // 也就是说,Cookies返回发送http请求时应该发送的cookie.
+//
+// The request is taken to be a same-site request, so cookies restricted
+// with the SameSite attribute are included. Use CookiesFrom for requests
+// that may be made on behalf of another site.
 func (j *Jar) Cookies(u *url.URL) (cookies []*http.Cookie) {
-	return j.cookies(u, time.Now())
+	return j.cookies("", u, nil, time.Now())
 }
 
-// cookies is like Cookies but takes the current time as a parameter.
+// CookiesFrom is like Cookies, but for a request with the given method
+// to u made on behalf of a page or redirect at the URL from. If from is
+// nil or belongs to the same site as u, CookiesFrom is equivalent to
+// Cookies.
+//
+// Two URLs belong to the same site if they have the same scheme and
+// the same registrable domain (their eTLD+1, as determined by the Jar's
+// PublicSuffixList). A cross-site request is taken to be a top-level
+// navigation, which is how http.Client uses CookiesFrom when following
+// redirects: cookies with SameSite=Strict are not returned, and cookies
+// with SameSite=Lax are returned only if method is GET or HEAD. An empty
+// method means GET.
+func (j *Jar) CookiesFrom(method string, u, from *url.URL) (cookies []*http.Cookie) {
+	return j.cookies(method, u, from, time.Now())
+}
+
+// cookies is like CookiesFrom but takes the current time as a parameter.
 //
 // u 代表了要请求哪个 url
 // 函数内部会用now判断cookie是否过期,是否应该发送; 以及用于设置LastAccess字段
-func (j *Jar) cookies(u *url.URL, now time.Time) (cookies []*http.Cookie) {
+func (j *Jar) cookies(method string, u, from *url.URL, now time.Time) (cookies []*http.Cookie) {
 	if u.Scheme != "http" && u.Scheme != "https" {
 		// 文档:It returns an empty slice if the URL's scheme is not HTTP or HTTPS.
 		return cookies
@@ -236,6 +276,8 @@ func (j *Jar) cookies(u *url.URL, now time.Time) (cookies []*http.Cookie) {
 
 	// 是否是https请求
 	https := u.Scheme == "https"
+	crossSite := from != nil && !j.sameSite(u.Scheme, key, from)
+	safeMethod := method == "" || method == "GET" || method == "HEAD"
 	// [start: 计算 path]
 	path := u.Path
 	if path == "" {
@@ -257,7 +299,7 @@ func (j *Jar) cookies(u *url.URL, now time.Time) (cookies []*http.Cookie) {
 			modified = true
 			continue
 		}
-		if !e.shouldSend(https, host, path) {
+		if !e.shouldSend(https, crossSite, safeMethod, host, path) {
 			// 不应该发送,continue到下个循环
 			continue
 		}
@@ -303,10 +345,28 @@ func (j *Jar) cookies(u *url.URL, now time.Time) (cookies []*http.Cookie) {
 	return cookies
 }
 
+// sameSite reports whether a request with the given scheme and jar key
+// is same-site with respect to the URL from.
+func (j *Jar) sameSite(scheme, key string, from *url.URL) bool {
+	if from.Scheme != scheme {
+		return false
+	}
+	host, err := canonicalHost(from.Host)
+	if err != nil {
+		return false
+	}
+	return jarKey(host, j.psList) == key
+}
+
 // SetCookies implements the SetCookies method of the http.CookieJar interface.
 //
 // It does nothing if the URL's scheme is not HTTP or HTTPS.
 //
+// Following RFC 6265bis, cookies whose names start with "__Secure-" or
+// "__Host-" are rejected unless they were received over HTTPS and meet
+// the requirements of the prefix (see http.Cookie.Valid), and cookies
+// with SameSite=None are rejected unless they are Secure.
+//
 // 把 SetCookies 想象成浏览器接受 Set-Cookie 头部的动作
 //
 // $ go doc http.CookieJar
@@ -341,6 +401,7 @@ func (j *Jar) setCookies(u *url.URL, cookies []*http.Cookie, now time.Time) {
 	key := jarKey(host, j.psList)
 	// 返回the directory part of an URL's path
 	defPath := defaultPath(u.Path)
+	https := u.Scheme == "https"
 
 	j.mu.Lock()
 	defer j.mu.Unlock()
@@ -351,7 +412,7 @@ func (j *Jar) setCookies(u *url.URL, cookies []*http.Cookie, now time.Time) {
 	modified := false
 	for _, cookie := range cookies {
 		// remove代表此cookie是否应该被移除
-		e, remove, err := j.newEntry(cookie, now, defPath, host)
+		e, remove, err := j.newEntry(cookie, now, defPath, host, https)
 		if err != nil {
 			// 如果出错,进行下轮循环
 			continue
@@ -517,14 +578,16 @@ func defaultPath(path string) string {
 
 // newEntry creates an entry from a http.Cookie c. now is the current time and
 // is compared to c.Expires to determine deletion of c. defPath and host are the
-// default-path and the canonical host name of the URL c was received from.
+// default-path and the canonical host name of the URL c was received from,
+// and https reports whether it was received over HTTPS.
 //
 // remove records whether the jar should delete this cookie, as it has already
 // expired with respect to now. In this case, e may be incomplete, but it will
 // be valid to call e.id (which depends on e's Name, Domain and Path).
 //
-// A malformed c.Domain will result in an error.
-func (j *Jar) newEntry(c *http.Cookie, now time.Time, defPath, host string) (e entry, remove bool, err error) {
+// A malformed c.Domain, or a cookie that does not meet the requirements
+// of its name prefix or of SameSite=None, will result in an error.
+func (j *Jar) newEntry(c *http.Cookie, now time.Time, defPath, host string, https bool) (e entry, remove bool, err error) {
 	// e 是函数返回值,此时是zero value
 	e.Name = c.Name
 
@@ -541,6 +604,17 @@ func (j *Jar) newEntry(c *http.Cookie, now time.Time, defPath, host string) (e e
 		return e, false, err
 	}
 
+	// See RFC 6265bis section 5.4, steps 20 and 21.
+	if strings.HasPrefix(c.Name, "__Secure-") && (!https || !c.Secure) {
+		return e, false, errSecurePrefix
+	}
+	if strings.HasPrefix(c.Name, "__Host-") && (!https || !c.Secure || !e.HostOnly || c.Domain != "" || e.Path != "/") {
+		return e, false, errHostPrefix
+	}
+	if c.SameSite == http.SameSiteNoneMode && !c.Secure {
+		return e, false, errInsecureSameSiteNone
+	}
+
 	/**
 	Expires 属性: Optional. This attribute specifies a date string that defines the valid lifetime of that cookie. Once the expiration date has been reached, will no longer be stored or given out. The date is formatted as:
 Weekday, DD-Mon-YY HH:MM:SS GMT
@@ -587,6 +661,16 @@ Set-Cookie: foo=bar; expires=Wednesday, 09-Nov-99 23:12:40 GMT
 	e.Secure = c.Secure
 	e.HttpOnly = c.HttpOnly
 
+	// A SameSite attribute that is missing, has no value or has an
+	// unknown value is treated as SameSite=None. See RFC 6265bis
+	// section 5.4.7.
+	switch c.SameSite {
+	case http.SameSiteStrictMode, http.SameSiteLaxMode:
+		e.SameSite = c.SameSite
+	default:
+		e.SameSite = http.SameSiteNoneMode
+	}
+
 	return e, false, nil
 }
 
@@ -594,6 +678,10 @@ var (
 	errIllegalDomain   = errors.New("cookiejar: illegal cookie domain attribute")
 	errMalformedDomain = errors.New("cookiejar: malformed cookie domain attribute")
 	errNoHostname      = errors.New("cookiejar: no host name available (IP only)")
+
+	errSecurePrefix         = errors.New("cookiejar: __Secure- prefixed cookie must be Secure and set over HTTPS")
+	errHostPrefix           = errors.New("cookiejar: __Host- prefixed cookie must be Secure, host-only, with Path \"/\" and set over HTTPS")
+	errInsecureSameSiteNone = errors.New("cookiejar: SameSite=None cookie must be Secure")
 )
 
 // endOfTime is the time when session (non-persistent) cookies expire.
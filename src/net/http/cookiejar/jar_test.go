@@ -418,7 +418,7 @@ func (test jarTest) run(t *testing.T, jar *Jar) {
 	for i, query := range test.queries {
 		now = now.Add(1001 * time.Millisecond)
 		var s []string
-		for _, c := range jar.cookies(mustParseURL(query.toURL), now) {
+		for _, c := range jar.cookies("", mustParseURL(query.toURL), nil, now) {
 			s = append(s, c.Name+"="+c.Value)
 		}
 		if got := strings.Join(s, " "); got != query.want {
@@ -613,6 +613,55 @@ var basicsTests = [...]jarTest{
 			{"http://www.host.test:1234/", "a=1"},
 		},
 	},
+	{
+		"__Secure- prefixed cookies must be Secure and set over https.",
+		"https://www.host.test/",
+		[]string{
+			"__Secure-a=1",
+			"__Secure-b=2; secure",
+			"__Secure-c=3; secure; domain=host.test",
+		},
+		"__Secure-b=2 __Secure-c=3",
+		[]query{
+			{"https://www.host.test", "__Secure-b=2 __Secure-c=3"},
+			{"http://www.host.test", ""},
+		},
+	},
+	{
+		"__Secure- prefixed cookies are not accepted over http.",
+		"http://www.host.test/",
+		[]string{"__Secure-a=1; secure"},
+		"",
+		[]query{{"https://www.host.test", ""}},
+	},
+	{
+		"__Host- prefixed cookies must be Secure, host-only and have path /.",
+		"https://www.host.test/some/path",
+		[]string{
+			"__Host-a=1; secure; path=/",
+			"__Host-b=2; path=/",
+			"__Host-c=3; secure",
+			"__Host-d=4; secure; path=/some",
+			"__Host-e=5; secure; path=/; domain=host.test",
+			"__Host-f=6; secure; path=/; domain=www.host.test",
+		},
+		"__Host-a=1",
+		[]query{
+			{"https://www.host.test", "__Host-a=1"},
+			{"https://sub.www.host.test", ""},
+		},
+	},
+	{
+		"SameSite=None cookies must be Secure.",
+		"https://www.host.test/",
+		[]string{
+			"a=1; samesite=none",
+			"b=2; samesite=none; secure",
+			"c=3; samesite=lax",
+		},
+		"b=2 c=3",
+		[]query{{"https://www.host.test", "b=2 c=3"}},
+	},
 }
 
 func TestBasics(t *testing.T) {
@@ -1320,3 +1369,51 @@ func TestIssue19384(t *testing.T) {
 		}
 	}
 }
+
+func TestCookiesFrom(t *testing.T) {
+	jar := newTestJar()
+	now := tNow
+	var cookies []*http.Cookie
+	for _, cs := range []string{
+		"a=1",
+		"b=2; samesite",
+		"c=3; samesite=lax",
+		"d=4; samesite=strict",
+		"e=5; samesite=none; secure",
+		"f=6; samesite=bogus",
+	} {
+		cookies = append(cookies, (&http.Response{Header: http.Header{"Set-Cookie": {cs}}}).Cookies()...)
+	}
+	jar.setCookies(mustParseURL("https://www.host.test/"), cookies, now)
+
+	tests := []struct {
+		method string
+		from   string // URL the request is made on behalf of, if any
+		want   string
+	}{
+		{"GET", "", "a=1 b=2 c=3 d=4 e=5 f=6"},
+		{"GET", "https://www.host.test/page", "a=1 b=2 c=3 d=4 e=5 f=6"},
+		{"GET", "https://other.host.test/page", "a=1 b=2 c=3 d=4 e=5 f=6"},
+		{"GET", "https://www.other.test/page", "a=1 b=2 c=3 e=5 f=6"},
+		{"HEAD", "https://www.other.test/page", "a=1 b=2 c=3 e=5 f=6"},
+		{"", "https://www.other.test/page", "a=1 b=2 c=3 e=5 f=6"},
+		{"GET", "http://www.host.test/page", "a=1 b=2 c=3 e=5 f=6"},
+		{"POST", "https://www.host.test/page", "a=1 b=2 c=3 d=4 e=5 f=6"},
+		{"POST", "https://www.other.test/page", "a=1 b=2 e=5 f=6"},
+		{"PUT", "http://www.host.test/page", "a=1 b=2 e=5 f=6"},
+	}
+	for _, tt := range tests {
+		var from *url.URL
+		if tt.from != "" {
+			from = mustParseURL(tt.from)
+		}
+		now = now.Add(1001 * time.Millisecond)
+		var s []string
+		for _, c := range jar.cookies(tt.method, mustParseURL("https://www.host.test/"), from, now) {
+			s = append(s, c.Name+"="+c.Value)
+		}
+		if got := strings.Join(s, " "); got != tt.want {
+			t.Errorf("%s from %q: got %q, want %q", tt.method, tt.from, got, tt.want)
+		}
+	}
+}
@@ -45,6 +45,16 @@ var (
 	SetRoundTripRetried   = hookSetter(&testHookRoundTripRetried)
 )
 
+var GodebugValue = godebugValue
+
+// SetUse19 sets whether ServeMux behaves as with GODEBUG=httpmuxgo19=1,
+// and returns a function that restores the previous setting.
+func SetUse19(v bool) (restore func()) {
+	old := use19
+	use19 = v
+	return func() { use19 = old }
+}
+
 func SetReadLoopBeforeNextReadHook(f func()) {
 	testHookMu.Lock()
 	defer testHookMu.Unlock()
@@ -42,3 +42,13 @@ type CookieJar interface {
 	// 也就是说,Cookies返回发送http请求时应该发送的cookie.
 	Cookies(u *url.URL) []*Cookie
 }
+
+// cookiesFromJar is implemented by cookie jars that can restrict the
+// cookies they return according to the site a request is made on behalf
+// of, such as net/http/cookiejar's Jar. When following a redirect, the
+// Client passes the method of the new request and the URL of the request
+// that was redirected as from, so that the jar can withhold SameSite
+// cookies from cross-site requests.
+type cookiesFromJar interface {
+	CookiesFrom(method string, u, from *url.URL) []*Cookie
+}
@@ -461,6 +461,267 @@ func TestMuxRedirectLeadingSlashes(t *testing.T) {
 	}
 }
 
+func TestServeMuxPatterns(t *testing.T) {
+	setParallel(t)
+	mux := NewServeMux()
+	for _, p := range []string{
+		"/",
+		"GET /users/{id}",
+		"DELETE /users/{id}",
+		"GET /users/{id}/{$}",
+		"POST /users/new",
+		"/files/{path...}",
+		"example.com/users/{id}",
+		"GET /posts/{$}",
+	} {
+		p := p
+		mux.HandleFunc(p, func(w ResponseWriter, r *Request) {
+			io.WriteString(w, p)
+		})
+	}
+
+	for _, tt := range []struct {
+		method  string
+		host    string
+		path    string
+		code    int
+		pattern string
+		allow   string
+	}{
+		{"GET", "google.com", "/users/7", 200, "GET /users/{id}", ""},
+		{"HEAD", "google.com", "/users/7", 200, "GET /users/{id}", ""},
+		{"DELETE", "google.com", "/users/7", 200, "DELETE /users/{id}", ""},
+		{"PUT", "google.com", "/users/7", 200, "/", ""},
+		{"GET", "google.com", "/users/7/", 200, "GET /users/{id}/{$}", ""},
+		{"POST", "google.com", "/users/new", 200, "POST /users/new", ""},
+		{"GET", "google.com", "/users/new", 200, "GET /users/{id}", ""},
+		{"PUT", "example.com", "/users/7", 200, "example.com/users/{id}", ""},
+		{"GET", "google.com", "/files/a/b/c", 200, "/files/{path...}", ""},
+		{"GET", "google.com", "/files", 301, "/files/{path...}", ""},
+		{"GET", "google.com", "/posts", 301, "GET /posts/{$}", ""},
+		{"GET", "google.com", "/posts/", 200, "GET /posts/{$}", ""},
+		{"GET", "google.com", "/posts/1", 200, "/", ""},
+		{"GET", "google.com", "/other", 200, "/", ""},
+	} {
+		r := &Request{
+			Method: tt.method,
+			Host:   tt.host,
+			URL:    &url.URL{Path: tt.path},
+		}
+		h, pattern := mux.Handler(r)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, r)
+		if pattern != tt.pattern || rr.Code != tt.code {
+			t.Errorf("%s %s %s = %d, %q, want %d, %q", tt.method, tt.host, tt.path, rr.Code, pattern, tt.code, tt.pattern)
+		}
+		if got := rr.HeaderMap.Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s %s: Allow = %q, want %q", tt.method, tt.host, tt.path, got, tt.allow)
+		}
+	}
+}
+
+func TestServeMuxMethodNotAllowed(t *testing.T) {
+	setParallel(t)
+	mux := NewServeMux()
+	mux.HandleFunc("GET /thing", func(w ResponseWriter, r *Request) {})
+	mux.HandleFunc("PUT /thing", func(w ResponseWriter, r *Request) {})
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("POST", "/thing", nil))
+	if rr.Code != StatusMethodNotAllowed {
+		t.Errorf("code = %d, want %d", rr.Code, StatusMethodNotAllowed)
+	}
+	if got, want := rr.HeaderMap.Get("Allow"), "GET, HEAD, PUT"; got != want {
+		t.Errorf("Allow = %q, want %q", got, want)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("POST", "/other", nil))
+	if rr.Code != StatusNotFound {
+		t.Errorf("unmatched path: code = %d, want %d", rr.Code, StatusNotFound)
+	}
+}
+
+func TestPathValue(t *testing.T) {
+	setParallel(t)
+	for _, test := range []struct {
+		pattern string
+		url     string
+		want    map[string]string
+	}{
+		{
+			"/{a}/is/{b}/{c...}",
+			"/now/is/the/time/for/all",
+			map[string]string{
+				"a": "now",
+				"b": "the",
+				"c": "time/for/all",
+				"d": "",
+			},
+		},
+		{
+			"/names/{name}/{other...}",
+			"/names/%2fjohn/address",
+			map[string]string{
+				"name":  "/john",
+				"other": "address",
+			},
+		},
+		{
+			"/names/{name}/{other...}",
+			"/names/john%2Fdoe/there/is%2F/more",
+			map[string]string{
+				"name":  "john/doe",
+				"other": "there/is//more",
+			},
+		},
+	} {
+		mux := NewServeMux()
+		mux.HandleFunc(test.pattern, func(w ResponseWriter, r *Request) {
+			for name, want := range test.want {
+				if got := r.PathValue(name); got != want {
+					t.Errorf("%q, %q: got %q, want %q", test.pattern, name, got, want)
+				}
+			}
+		})
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", test.url, nil))
+	}
+}
+
+func TestSetPathValue(t *testing.T) {
+	setParallel(t)
+	mux := NewServeMux()
+	mux.HandleFunc("/a/{b}/c/{d...}", func(_ ResponseWriter, r *Request) {
+		kvs := map[string]string{
+			"b": "X",
+			"d": "Y",
+			"a": "Z",
+		}
+		for k, v := range kvs {
+			r.SetPathValue(k, v)
+		}
+		for k, w := range kvs {
+			if g := r.PathValue(k); g != w {
+				t.Errorf("got %q, want %q", g, w)
+			}
+		}
+	})
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/a/b/c/d/e", nil))
+}
+
+func TestServeMuxRegisterConflict(t *testing.T) {
+	for _, test := range []struct {
+		p1, p2 string
+		want   string
+	}{
+		{"/a", "/a", "http: multiple registrations for /a"},
+		{"/a/{x}", "/a/{y}", `http: pattern "/a/{y}" matches the same requests as pattern "/a/{x}"`},
+		{"/a/{x}", "/{y}/b", `http: pattern "/{y}/b" conflicts with pattern "/a/{x}": both match some paths, like "/a/b", but neither is more specific`},
+		{"GET /", "/index.html", `http: pattern "/index.html" conflicts with pattern "GET /": both match some paths, like "/index.html", but neither is more specific`},
+		{"/", "/{x}/{y}/{", `http: invalid pattern "/{x}/{y}/{": at offset 9: bad wildcard segment (must end with '}')`},
+	} {
+		mux := NewServeMux()
+		mux.Handle(test.p1, NotFoundHandler())
+		got := func() (msg string) {
+			defer func() {
+				if e := recover(); e != nil {
+					msg = fmt.Sprint(e)
+				}
+			}()
+			mux.Handle(test.p2, NotFoundHandler())
+			return ""
+		}()
+		if got != test.want {
+			t.Errorf("registering %q after %q:\ngot panic  %q\nwant panic %q", test.p2, test.p1, got, test.want)
+		}
+	}
+}
+
+// Test that GODEBUG=httpmuxgo19=1 restores the Go 1.9 pattern syntax,
+// in which patterns are plain paths with an optional host.
+func TestGodebugValue(t *testing.T) {
+	for _, tt := range []struct {
+		godebug string
+		want    string
+	}{
+		{"", ""},
+		{"httpmuxgo19=1", "1"},
+		{"http2debug=1,httpmuxgo19=1", "1"},
+		{"httpmuxgo19=1,http2debug=1", "1"},
+		{"httpmuxgo19=1,httpmuxgo19=0", "0"},
+		{"httpmuxgo19=10", "10"},
+		{"xhttpmuxgo19=1", ""},
+		{"httpmuxgo190=1", ""},
+		{"httpmuxgo19", ""},
+	} {
+		if got := GodebugValue(tt.godebug, "httpmuxgo19"); got != tt.want {
+			t.Errorf("godebugValue(%q, %q) = %q, want %q", tt.godebug, "httpmuxgo19", got, tt.want)
+		}
+	}
+}
+
+func TestServeMux19(t *testing.T) {
+	defer SetUse19(true)()
+
+	mux := NewServeMux()
+	for _, p := range []string{
+		"/a b",
+		"/{x}",
+		"/tree/",
+		"example.com/",
+	} {
+		p := p
+		mux.HandleFunc(p, func(w ResponseWriter, r *Request) {
+			io.WriteString(w, p)
+		})
+	}
+
+	for _, tt := range []struct {
+		method  string
+		host    string
+		path    string
+		code    int
+		pattern string
+	}{
+		{"GET", "google.com", "/a b", 200, "/a b"},
+		{"POST", "google.com", "/a b", 200, "/a b"},
+		{"GET", "google.com", "/{x}", 200, "/{x}"},
+		{"GET", "google.com", "/y", 404, ""},
+		{"GET", "google.com", "/tree/leaf", 200, "/tree/"},
+		{"GET", "google.com", "/tree", 301, "/tree/"},
+		{"GET", "example.com", "/anything", 200, "example.com/"},
+	} {
+		r := &Request{
+			Method: tt.method,
+			Host:   tt.host,
+			URL:    &url.URL{Path: tt.path},
+		}
+		h, pattern := mux.Handler(r)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, r)
+		if pattern != tt.pattern || rr.Code != tt.code {
+			t.Errorf("%s %s %s = %d, %q, want %d, %q", tt.method, tt.host, tt.path, rr.Code, pattern, tt.code, tt.pattern)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "http://google.com/a%20b", nil))
+	if got, want := rr.Body.String(), "/a b"; got != want {
+		t.Errorf("ServeHTTP(/a%%20b) body = %q, want %q", got, want)
+	}
+}
+
+func TestServeMuxSpaceInPattern(t *testing.T) {
+	// Without GODEBUG=httpmuxgo19=1, text before a space is a method.
+	defer func() {
+		want := `http: invalid pattern "/a b": at offset 0: invalid method "/a"`
+		if e := recover(); fmt.Sprint(e) != want {
+			t.Errorf("got panic %q, want %q", e, want)
+		}
+	}()
+	NewServeMux().Handle("/a b", NotFoundHandler())
+}
+
 func BenchmarkServeMux(b *testing.B) {
 
 	type test struct {
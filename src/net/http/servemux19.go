@@ -0,0 +1,168 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package http
+
+// This file implements ServeMux behavior as in Go 1.9.
+// It is used when GODEBUG=httpmuxgo19=1.
+// Apart from the names of its types, it is the ServeMux
+// code from Go 1.9, so a pattern is a plain path with an
+// optional host and never has a method or wildcards.
+
+import (
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// use19 reports whether GODEBUG=httpmuxgo19=1 was set at
+// program startup, restoring the Go 1.9 ServeMux behavior.
+var use19 = godebugValue(os.Getenv("GODEBUG"), "httpmuxgo19") == "1"
+
+// godebugValue returns the value of the setting key in godebug,
+// a comma-separated list of key=value pairs in the format of the
+// GODEBUG environment variable. If key appears more than once,
+// the last value wins. If key does not appear, it returns "".
+func godebugValue(godebug, key string) string {
+	value := ""
+	for _, kv := range strings.Split(godebug, ",") {
+		if i := strings.Index(kv, "="); i >= 0 && kv[:i] == key {
+			value = kv[i+1:]
+		}
+	}
+	return value
+}
+
+// serveMux19 is the ServeMux implementation used when use19 is set.
+type serveMux19 struct {
+	mu    sync.RWMutex
+	m     map[string]muxEntry19
+	hosts bool // whether any patterns contain hostnames
+}
+
+type muxEntry19 struct {
+	explicit bool
+	h        Handler
+	pattern  string
+}
+
+// handle is the Go 1.9 implementation of ServeMux.Handle.
+func (mux *serveMux19) handle(pattern string, handler Handler) {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+
+	if pattern == "" {
+		panic("http: invalid pattern")
+	}
+	if handler == nil {
+		panic("http: nil handler")
+	}
+	if mux.m[pattern].explicit {
+		panic("http: multiple registrations for " + pattern)
+	}
+
+	if mux.m == nil {
+		mux.m = make(map[string]muxEntry19)
+	}
+	mux.m[pattern] = muxEntry19{explicit: true, h: handler, pattern: pattern}
+
+	if pattern[0] != '/' {
+		mux.hosts = true
+	}
+
+	// Helpful behavior:
+	// If pattern is /tree/, insert an implicit permanent redirect for /tree.
+	// It can be overridden by an explicit registration.
+	n := len(pattern)
+	if n > 0 && pattern[n-1] == '/' && !mux.m[pattern[0:n-1]].explicit {
+		// If pattern contains a host name, strip it and use remaining
+		// path for redirect.
+		path := pattern
+		if pattern[0] != '/' {
+			// In pattern, at least the last character is a '/', so
+			// strings.Index can't be -1.
+			path = pattern[strings.Index(pattern, "/"):]
+		}
+		url := &url.URL{Path: path}
+		mux.m[pattern[0:n-1]] = muxEntry19{h: RedirectHandler(url.String(), StatusMovedPermanently), pattern: pattern}
+	}
+}
+
+// findHandler is the Go 1.9 implementation of ServeMux.Handler.
+func (mux *serveMux19) findHandler(r *Request) (h Handler, pattern string) {
+	// CONNECT requests are not canonicalized.
+	if r.Method == "CONNECT" {
+		return mux.handler(r.Host, r.URL.Path)
+	}
+
+	// All other requests have any port stripped and path cleaned
+	// before passing to mux.handler.
+	host := stripHostPort(r.Host)
+	path := cleanPath(r.URL.Path)
+	if path != r.URL.Path {
+		_, pattern = mux.handler(host, path)
+		url := *r.URL
+		url.Path = path
+		return RedirectHandler(url.String(), StatusMovedPermanently), pattern
+	}
+
+	return mux.handler(host, r.URL.Path)
+}
+
+// handler is the main implementation of findHandler.
+// The path is known to be in canonical form, except for CONNECT methods.
+func (mux *serveMux19) handler(host, path string) (h Handler, pattern string) {
+	mux.mu.RLock()
+	defer mux.mu.RUnlock()
+
+	// Host-specific pattern takes precedence over generic ones
+	if mux.hosts {
+		h, pattern = mux.match(host + path)
+	}
+	if h == nil {
+		h, pattern = mux.match(path)
+	}
+	if h == nil {
+		h, pattern = NotFoundHandler(), ""
+	}
+	return
+}
+
+// Find a handler on a handler map given a path string.
+// Most-specific (longest) pattern wins.
+func (mux *serveMux19) match(path string) (h Handler, pattern string) {
+	// Check for exact match first.
+	v, ok := mux.m[path]
+	if ok {
+		return v.h, v.pattern
+	}
+
+	// Check for longest valid match.
+	var n = 0
+	for k, v := range mux.m {
+		if !pathMatch19(k, path) {
+			continue
+		}
+		if h == nil || len(k) > n {
+			n = len(k)
+			h = v.h
+			pattern = v.pattern
+		}
+	}
+	return
+}
+
+// Does path match pattern?
+func pathMatch19(pattern, path string) bool {
+	if len(pattern) == 0 {
+		// should not happen
+		return false
+	}
+	n := len(pattern)
+	if pattern[n-1] != '/' {
+		return pattern == path
+	}
+	return len(path) >= n && path[0:n] == pattern
+}
@@ -13,23 +13,27 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
+	"math"
 	"net"
 	"net/textproto"
 	"net/url"
 	"os"
 	"path"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"golang_org/x/net/http2/hpack"
 	"golang_org/x/net/lex/httplex"
 )
 
@@ -1788,6 +1792,9 @@ func (c *conn) serve(ctx context.Context) {
 	c.bufr = newBufioReader(c.r)
 	c.bufw = newBufioWriterSize(checkConnErrorWriter{c}, 4<<10)
 
+	protocols := c.server.protocols()
+	const errorHeaders = "\r\nContent-Type: text/plain; charset=utf-8\r\nConnection: close\r\n\r\n"
+
 	for {
 		w, err := c.readRequest(ctx)
 		if c.r.remain != c.server.initialReadLimitSize() {
@@ -1795,8 +1802,6 @@ func (c *conn) serve(ctx context.Context) {
 			c.setState(c.rwc, StateActive)
 		}
 		if err != nil {
-			const errorHeaders = "\r\nContent-Type: text/plain; charset=utf-8\r\nConnection: close\r\n\r\n"
-
 			if err == errTooLarge {
 				// Their HTTP client may or may not be
 				// able to read this if we're
@@ -1821,8 +1826,28 @@ func (c *conn) serve(ctx context.Context) {
 			return
 		}
 
-		// Expect 100 Continue support
 		req := w.req
+		if c.tlsState == nil && protocols.UnencryptedHTTP2() {
+			if req.isH2Upgrade() {
+				// HTTP/2 with prior knowledge: the request we just
+				// read is the start of the client preface.
+				w.cancelCtx()
+				c.serveUnencryptedHTTP2(nil)
+				return
+			}
+			if isH2CUpgrade(req) {
+				w.cancelCtx()
+				c.serveUnencryptedHTTP2(req)
+				return
+			}
+		}
+		if !protocols.HTTP1() {
+			const publicErr = "505 HTTP Version Not Supported"
+			fmt.Fprintf(c.rwc, "HTTP/1.1 "+publicErr+errorHeaders+publicErr)
+			return
+		}
+
+		// Expect 100 Continue support
 		if req.expectsContinue() {
 			if req.ProtoAtLeast(1, 1) && req.ContentLength != 0 {
 				// Wrap the Body reader with one that replies on the connection
@@ -1884,6 +1909,159 @@ func (c *conn) serve(ctx context.Context) {
 	}
 }
 
+// serveUnencryptedHTTP2 hands c over to the server's HTTP/2
+// implementation. If upgrade is nil, c has just read the "PRI"
+// request line of an HTTP/2 client preface. Otherwise upgrade is an
+// HTTP/1.1 request asking to switch to h2c, and it becomes the
+// connection's first stream.
+func (c *conn) serveUnencryptedHTTP2(upgrade *Request) {
+	var r io.Reader
+	if upgrade == nil {
+		// readRequest consumed the start of the preface; give it
+		// back so the HTTP/2 server sees the whole thing.
+		r = io.MultiReader(strings.NewReader("PRI * HTTP/2.0\r\n\r\n"), c.bufr)
+	} else {
+		const switching = "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: h2c\r\n\r\n"
+		if _, err := io.WriteString(c.rwc, switching); err != nil {
+			return
+		}
+		c.rwc.SetReadDeadline(time.Now().Add(http2prefaceTimeout))
+		start, err := h2cUpgradeStart(c.bufr, upgrade)
+		if err != nil {
+			c.server.logf("http: h2c upgrade from %s failed: %v", c.remoteAddr, err)
+			return
+		}
+		r = io.MultiReader(bytes.NewReader(start), c.bufr)
+	}
+	c.rwc.SetReadDeadline(time.Time{})
+	c.server.unencryptedHTTP2Server().ServeConn(h2cConn{c.rwc, r}, &http2ServeConnOpts{
+		Handler:    serverHandler{c.server},
+		BaseConfig: c.server,
+	})
+}
+
+// h2cConn is a net.Conn whose reads come from r, which includes
+// anything the HTTP/1 server already buffered from the connection.
+type h2cConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c h2cConn) Read(p []byte) (int, error) { return c.r.Read(p) }
+
+// isH2CUpgrade reports whether req asks to upgrade the connection
+// to HTTP/2 over cleartext (RFC 7540, section 3.2).
+//
+// Only requests without a body are upgraded; the others are served
+// over HTTP/1.1, which the RFC permits.
+func isH2CUpgrade(req *Request) bool {
+	if req.Body != NoBody || req.Method == "CONNECT" {
+		return false
+	}
+	if !httplex.HeaderValuesContainsToken(req.Header["Upgrade"], "h2c") {
+		return false
+	}
+	conn := req.Header["Connection"]
+	if !httplex.HeaderValuesContainsToken(conn, "Upgrade") || !httplex.HeaderValuesContainsToken(conn, "HTTP2-Settings") {
+		return false
+	}
+	vv := req.Header["Http2-Settings"]
+	if len(vv) != 1 {
+		return false
+	}
+	settings, err := base64.RawURLEncoding.DecodeString(vv[0])
+	return err == nil && len(settings)%6 == 0
+}
+
+// h2cUpgradeStart reads the client connection preface and the
+// SETTINGS frame that follow an h2c upgrade from br, and returns
+// them followed by a HEADERS frame carrying req on stream 1, as
+// RFC 7540, section 3.2 requires. The HTTP/2 server reads the
+// result before the rest of the connection, so it serves req like
+// any other request.
+//
+// The settings in the HTTP2-Settings header are not applied; the
+// client's SETTINGS frame, which it must send anyway, carries them.
+func h2cUpgradeStart(br *bufio.Reader, req *Request) ([]byte, error) {
+	var buf bytes.Buffer
+	preface := make([]byte, len(http2ClientPreface))
+	if _, err := io.ReadFull(br, preface); err != nil {
+		return nil, err
+	}
+	if string(preface) != http2ClientPreface {
+		return nil, errors.New("bogus client preface")
+	}
+	buf.Write(preface)
+
+	hdr := make([]byte, 9)
+	if _, err := io.ReadFull(br, hdr); err != nil {
+		return nil, err
+	}
+	fh, _ := http2ReadFrameHeader(bytes.NewReader(hdr))
+	if fh.Type != http2FrameSettings || fh.StreamID != 0 || fh.Length > http2initialMaxFrameSize {
+		return nil, errors.New("client preface not followed by a SETTINGS frame")
+	}
+	buf.Write(hdr)
+	if _, err := io.CopyN(&buf, br, int64(fh.Length)); err != nil {
+		return nil, err
+	}
+
+	// Encode req's header without adding to the server's dynamic
+	// table, since the client's encoder doesn't know about it.
+	var block bytes.Buffer
+	enc := hpack.NewEncoder(&block)
+	writeField := func(name, value string) {
+		enc.WriteField(hpack.HeaderField{Name: name, Value: value, Sensitive: true})
+	}
+	writeField(":method", req.Method)
+	writeField(":scheme", "http")
+	writeField(":authority", req.Host)
+	writeField(":path", req.RequestURI)
+	hopByHop := map[string]bool{"Http2-Settings": true}
+	for _, k := range http2connHeaders {
+		hopByHop[k] = true
+	}
+	for _, v := range req.Header["Connection"] {
+		http2foreachHeaderElement(v, func(f string) {
+			hopByHop[CanonicalHeaderKey(f)] = true
+		})
+	}
+	for k, vv := range req.Header {
+		if hopByHop[k] {
+			continue
+		}
+		for _, v := range vv {
+			if k == "Te" && v != "trailers" {
+				continue
+			}
+			writeField(strings.ToLower(k), v)
+		}
+	}
+
+	fr := http2NewFramer(&buf, nil)
+	frag := block.Bytes()
+	first := frag
+	if len(first) > http2initialMaxFrameSize {
+		first = first[:http2initialMaxFrameSize]
+	}
+	frag = frag[len(first):]
+	fr.WriteHeaders(http2HeadersFrameParam{
+		StreamID:      1,
+		BlockFragment: first,
+		EndStream:     true,
+		EndHeaders:    len(frag) == 0,
+	})
+	for len(frag) > 0 {
+		chunk := frag
+		if len(chunk) > http2initialMaxFrameSize {
+			chunk = chunk[:http2initialMaxFrameSize]
+		}
+		frag = frag[len(chunk):]
+		fr.WriteContinuation(1, len(frag) == 0, chunk)
+	}
+	return buf.Bytes(), nil
+}
+
 func (w *response) sendExpectationFailed() {
 	// TODO(bradfitz): let ServeHTTP handlers handle
 	// requests with non-standard expectation[s]? Seems
@@ -2181,56 +2359,149 @@ func RedirectHandler(url string, code int) Handler {
 // patterns and calls the handler for the pattern that
 // most closely matches the URL.
 //
-// Patterns name fixed, rooted paths, like "/favicon.ico",
-// or rooted subtrees, like "/images/" (note the trailing slash).
-// Longer patterns take precedence over shorter ones, so that
-// if there are handlers registered for both "/images/"
-// and "/images/thumbnails/", the latter handler will be
-// called for paths beginning "/images/thumbnails/" and the
-// former will receive requests for any other paths in the
-// "/images/" subtree.
-//
-// Patterns name都是固定的,以'/'开头的路径.
-//
-// Note that since a pattern ending in a slash names a rooted subtree,
-// the pattern "/" matches all paths not matched by other registered
-// patterns, not just the URL with Path == "/".
-//
-// If a subtree has been registered and a request is received naming the
-// subtree root without its trailing slash, ServeMux redirects that
-// request to the subtree root (adding the trailing slash). This behavior can
-// be overridden with a separate registration for the path without
-// the trailing slash. For example, registering "/images/" causes ServeMux
+// Patterns
+//
+// Patterns can match the method, host and path of a request.
+// Some examples:
+//
+//	"/index.html" matches the path "/index.html" for any host and method.
+//	"GET /static/" matches a GET request whose path begins with "/static/".
+//	"example.com/" matches any request to the host "example.com".
+//	"example.com/{$}" matches requests with host "example.com" and path "/".
+//	"/b/{bucket}/o/{objectname...}" matches paths whose first segment is "b"
+//	and whose third segment is "o". The name "bucket" denotes the second
+//	segment and "objectname" denotes the remainder of the path.
+//
+// In general, a pattern looks like
+//
+//	[METHOD ][HOST]/[PATH]
+//
+// All three parts are optional; "/" is a valid pattern.
+// If METHOD is present, it must be followed by at least one space or tab.
+//
+// Literal (that is, non-wildcard) parts of a pattern match
+// the corresponding parts of a request case-sensitively.
+//
+// A pattern with no method matches every method. A pattern
+// with the method GET matches both GET and HEAD requests.
+// Otherwise, the method must match exactly.
+//
+// A pattern with no host matches every host.
+// A pattern with a host matches URLs on that host only.
+//
+// A path can include wildcard segments of the form {NAME} or {NAME...}.
+// For example, "/b/{bucket}/o/{objectname...}".
+// The wildcard name must be a valid Go identifier.
+// Wildcards must be full path segments: they must be preceded by a slash and followed by
+// either a slash or the end of the string.
+// For example, "/b_{bucket}" is not a valid pattern.
+//
+// Normally a wildcard matches only a single path segment,
+// ending at the next literal slash (not %2F) in the request URL.
+// But if the "..." is present, then the wildcard matches the remainder of the URL path, including slashes.
+// (Therefore it is invalid for a "..." wildcard to appear anywhere but at the end of a pattern.)
+// The match for a wildcard can be obtained by calling Request.PathValue with the wildcard's name.
+// A trailing slash in a path acts as an anonymous "..." wildcard.
+//
+// The special wildcard {$} matches only the end of the URL.
+// For example, the pattern "/{$}" matches only the path "/",
+// whereas the pattern "/" matches every path.
+//
+// For matching, both pattern paths and incoming request paths are unescaped segment by segment.
+// So, for example, the path "/a%2Fb/100%25" is treated as having two segments, "a/b" and "100%".
+// The pattern "/a%2fb/" matches it, but the pattern "/a/b/" does not.
+//
+// Precedence
+//
+// If two or more patterns match a request, then the most specific pattern takes precedence.
+// A pattern P1 is more specific than P2 if P1 matches a strict subset of P2's requests;
+// that is, if P2 matches all the requests of P1 and more.
+// If neither is more specific, then the patterns conflict.
+// There is one exception to this rule, for backwards compatibility:
+// if two patterns would otherwise conflict and one has a host while the other does not,
+// then the pattern with the host takes precedence.
+// If a pattern passed to ServeMux.Handle or ServeMux.HandleFunc conflicts with
+// another pattern that is already registered, those functions panic.
+//
+// As an example of the general rule, "/images/thumbnails/" is more specific than "/images/",
+// so both can be registered.
+// The former matches paths beginning with "/images/thumbnails/"
+// and the latter will match any other path in the "/images/" subtree.
+//
+// As another example, consider the patterns "GET /" and "/index.html":
+// both match a GET request for "/index.html", but the former pattern
+// matches all other GET and HEAD requests, while the latter matches any
+// request for "/index.html" that uses a different method.
+// The patterns conflict.
+//
+// Trailing-slash redirection
+//
+// Consider a ServeMux with a handler for a subtree, registered using a trailing slash or "..." wildcard.
+// If the ServeMux receives a request for the subtree root without a trailing slash,
+// it redirects the request by adding the trailing slash.
+// This behavior can be overridden with a separate registration for the path without
+// the trailing slash or "..." wildcard. For example, registering "/images/" causes ServeMux
 // to redirect a request for "/images" to "/images/", unless "/images" has
 // been registered separately.
 //
-// Patterns may optionally begin with a host name, restricting matches to
-// URLs on that host only. Host-specific patterns take precedence over
-// general patterns, so that a handler might register for the two patterns
-// "/codesearch" and "codesearch.google.com/" without also taking over
-// requests for "http://www.google.com/".
-//
-// ServeMux also takes care of sanitizing the URL request path,
-// redirecting any request containing . or .. elements or repeated slashes
-// to an equivalent, cleaner URL.
+// Request sanitizing
+//
+// ServeMux also takes care of sanitizing the URL request path and the Host
+// header, stripping the port number and redirecting any request containing . or
+// .. elements or repeated slashes to an equivalent, cleaner URL.
+//
+// If a request's path matches a pattern except for its method,
+// ServeMux replies with 405 Method Not Allowed and an Allow header
+// listing the methods of the patterns that do match.
+//
+// Compatibility
+//
+// The pattern syntax and matching behavior of ServeMux changed
+// significantly after Go 1.9. To restore the old behavior, set the
+// GODEBUG environment variable to "httpmuxgo19=1". This setting is
+// read once, at program startup; changes during execution are ignored.
+//
+// The backwards-incompatible changes include:
+//
+//	- In Go 1.9, a pattern was a plain path with an optional host.
+//	  Now, text before the first space or tab is a method, so a
+//	  pattern like "/a b", which used to match the path "/a b",
+//	  either is rejected or matches only requests with method "/a".
+//	- Wildcards are just ordinary literal path segments in Go 1.9.
+//	  For example, the pattern "/{x}" matches only that path in Go 1.9,
+//	  but now matches any one-segment path.
+//	- In Go 1.9, no pattern was rejected, unless it was empty or
+//	  was already registered. Now, syntactically invalid patterns and
+//	  conflicting patterns cause Handle and HandleFunc to panic.
+//	  For example, in Go 1.9 the patterns "/{" and "/a{x}" match
+//	  themselves, but now they are invalid.
+//	- Each segment of a pattern and of a request path is now
+//	  unescaped; Go 1.9 did not unescape patterns and unescaped the
+//	  request path as a whole. For example, the pattern "/%61" now
+//	  matches the path "/a".
 //
 // multiplexer: 多路传输器;多路复用器
 // sanitize ['sænɪtaɪz] vt. 使…无害；给…消毒；对…采取卫生措施
 // 注意: *ServeMux实现了Handler接口
 type ServeMux struct {
-	mu    sync.RWMutex
-	// key是注册的path
-	m     map[string]muxEntry
-	hosts bool // whether any patterns contain hostnames
+	mu sync.RWMutex
+	// 注册的规则,按注册顺序排列
+	es []muxEntry
+	// byFirst indexes the entries whose path begins with a literal
+	// segment by that segment; wild holds the others. A request can
+	// only match entries in byFirst[its first segment] or wild.
+	byFirst map[string][]int
+	wild    []int
+	hosts   bool // whether any patterns contain hostnames
+
+	mux19 serveMux19 // used if GODEBUG=httpmuxgo19=1
 }
 
 type muxEntry struct {
-	// 此规则是显式指定的还是隐式插入的,参考: func (mux *ServeMux) Handle(pattern string, handler Handler)
-	explicit bool
-	// 注册的handler
-	h        Handler
 	// 注册的pattern
-	pattern  string
+	pat *pattern
+	// 注册的handler
+	h Handler
 }
 
 // NewServeMux allocates and returns a new ServeMux.
@@ -2243,23 +2514,6 @@ var DefaultServeMux = &defaultServeMux
 
 var defaultServeMux ServeMux
 
-// Does path match pattern?
-// 检查 pattern 和 path 是否匹配
-// @see
-func pathMatch(pattern, path string) bool {
-	if len(pattern) == 0 {
-		// should not happen
-		return false
-	}
-	n := len(pattern)
-	if pattern[n-1] != '/' {
-		// 如果pattern最后一个字符不是'/',根据patern和path是否完全相等来判断是否匹配
-		return pattern == path
-	}
-	// 现在,pattern最后一个字符是'/',根据path的前缀是否等于pattern判断是否匹配
-	return len(path) >= n && path[0:n] == pattern
-}
-
 // Return the canonical path for p, eliminating . and .. elements.
 // p是指path
 // @see
@@ -2294,37 +2548,92 @@ func stripHostPort(h string) string {
 	return host
 }
 
-// Find a handler on a handler map given a path string.
-// Most-specific (longest) pattern wins.
-//
-// Find a handler(h返回值)
-// given a path string(path参数)
-func (mux *ServeMux) match(path string) (h Handler, pattern string) {
-	// Check for exact match first.
-	v, ok := mux.m[path]
-	if ok {
-		// 找到了一个精确匹配
-		return v.h, v.pattern
+// match returns the entry whose pattern most closely matches a request
+// with the given host, method and path segments, along with the values
+// of the pattern's wildcards. Host-specific patterns take precedence
+// over generic ones.
+func (mux *ServeMux) match(host, method string, elems []string) (e *muxEntry, matches []string) {
+	if mux.hosts && host != "" {
+		if e, matches = mux.matchHost(host, method, elems); e != nil {
+			return e, matches
+		}
 	}
-	// 现在,不存在精确匹配,需要进行前缀检查
-	// 在满足前缀检查的情况下,选择最长的匹配
+	return mux.matchHost("", method, elems)
+}
 
-	// Check for longest valid match.
-	var n = 0
-	for k, v := range mux.m {
-		if !pathMatch(k, path) {
-			// 不匹配,进行下轮循环
-			continue
+// matchHost is like match, but considers only patterns for host.
+func (mux *ServeMux) matchHost(host, method string, elems []string) (best *muxEntry, bestMatches []string) {
+	if len(elems) == 0 {
+		return nil, nil
+	}
+	for _, idx := range [2][]int{mux.byFirst[elems[0]], mux.wild} {
+		for _, i := range idx {
+			e := &mux.es[i]
+			if e.pat.host != host || !e.pat.matchMethod(method) {
+				continue
+			}
+			matches, ok := e.pat.matchPath(elems)
+			if !ok {
+				continue
+			}
+			// Handle rejects conflicting patterns, so of any two patterns
+			// that match the same request, one is more specific.
+			if best == nil || e.pat.comparePathsAndMethods(best.pat) == moreSpecific {
+				best, bestMatches = e, matches
+			}
 		}
-		// 现在,找到一个匹配
-		if h == nil || len(k) > n {
-			// 如果比之前找到的匹配的前缀长,更新返回值
-			n = len(k)
-			h = v.h
-			pattern = v.pattern
+	}
+	return best, bestMatches
+}
+
+// allowedMethods returns the sorted methods of the patterns that
+// match host and the path segments, ignoring the request method.
+func (mux *ServeMux) allowedMethods(host string, elems []string) []string {
+	if len(elems) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, idx := range [2][]int{mux.byFirst[elems[0]], mux.wild} {
+		for _, i := range idx {
+			pat := mux.es[i].pat
+			if pat.host != "" && pat.host != host {
+				continue
+			}
+			if _, ok := pat.matchPath(elems); ok && pat.method != "" {
+				seen[pat.method] = true
+				if pat.method == "GET" {
+					seen["HEAD"] = true
+				}
+			}
 		}
 	}
-	return
+	var methods []string
+	for m := range seen {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// exactMatch reports whether e's pattern matches path without relying
+// on a non-empty match for a trailing "..." wildcard.
+func exactMatch(e *muxEntry, path string) bool {
+	if e == nil {
+		return false
+	}
+	// If there is no multi, the match is exact.
+	if !e.pat.lastSegment().multi {
+		return true
+	}
+	// If the path doesn't end in a trailing slash, then the multi match
+	// is non-empty.
+	if len(path) > 0 && path[len(path)-1] != '/' {
+		return false
+	}
+	// For the match to be exact, the number of pattern segments should
+	// be the same as the number of slashes in the path.
+	// E.g. "/a/b/{$}" and "/a/b/{...}" exactly match "/a/b/", but "/a/" does not.
+	return len(e.pat.segments) == strings.Count(path, "/")
 }
 
 // Handler returns the handler to use for the given request,
@@ -2346,12 +2655,24 @@ func (mux *ServeMux) match(path string) (h Handler, pattern string) {
 //
 // If there is no registered handler that applies to the request,
 // Handler returns a ``page not found'' handler and an empty pattern.
+// If a pattern matches the request's path but not its method, Handler
+// returns a handler that replies with 405 Method Not Allowed and an
+// empty pattern.
 func (mux *ServeMux) Handler(r *Request) (h Handler, pattern string) {
+	if use19 {
+		return mux.mux19.findHandler(r)
+	}
+	h, pattern, _, _ = mux.findHandler(r)
+	return
+}
 
+// findHandler is the implementation of Handler. It also returns the
+// matched pattern and the values of its wildcards.
+func (mux *ServeMux) findHandler(r *Request) (h Handler, patStr string, pat *pattern, matches []string) {
 	// CONNECT requests are not canonicalized.
 	if r.Method == "CONNECT" {
 		// 文档: The path and host are used unchanged for CONNECT requests.
-		return mux.handler(r.Host, r.URL.Path)
+		return mux.handler(r.Host, r.Method, r.URL)
 	}
 
 	// All other requests have any port stripped and path cleaned
@@ -2363,39 +2684,48 @@ func (mux *ServeMux) Handler(r *Request) (h Handler, pattern string) {
 		// 文档: If the path is not in its canonical form, the handler will be
 		// an internally-generated handler that redirects to the canonical path.
 		// 如果r.URL.Path不是规范化格式,返回的handler是内部生成的handler(此handler的行为是重定向到规范化path)
-		_, pattern = mux.handler(host, path)
 		url := *r.URL
 		url.Path = path
+		_, patStr, _, _ = mux.handler(host, r.Method, &url)
 		// 重定向到规范化的path, 返回的pattern是(the pattern that will match after following the redirect.)
-		return RedirectHandler(url.String(), StatusMovedPermanently), pattern
+		return RedirectHandler(url.String(), StatusMovedPermanently), patStr, nil, nil
 	}
 
-	return mux.handler(host, r.URL.Path)
+	return mux.handler(host, r.Method, r.URL)
 }
 
 // handler is the main implementation of Handler.
 // The path is known to be in canonical form, except for CONNECT methods.
 //
 // @see
-func (mux *ServeMux) handler(host, path string) (h Handler, pattern string) {
+func (mux *ServeMux) handler(host, method string, u *url.URL) (h Handler, patStr string, pat *pattern, matches []string) {
 	mux.mu.RLock()
 	defer mux.mu.RUnlock()
 
-	// Host-specific pattern takes precedence over generic ones
-	if mux.hosts {
-		// 如果注册的pattern中包含hostname
-		h, pattern = mux.match(host + path)
-	}
-	if h == nil {
-		// 如果上一条规则没有找到
-		h, pattern = mux.match(path)
+	path := u.EscapedPath()
+	elems := splitPath(path)
+	e, matches := mux.match(host, method, elems)
+	// If the path names a subtree root without its trailing slash,
+	// and adding one would result in an exact match, redirect.
+	if !exactMatch(e, path) && !strings.HasSuffix(path, "/") {
+		if e2, _ := mux.match(host, method, splitPath(path+"/")); exactMatch(e2, path+"/") {
+			url := &url.URL{Path: u.Path + "/", RawQuery: u.RawQuery}
+			return RedirectHandler(url.String(), StatusMovedPermanently), e2.pat.str, nil, nil
+		}
 	}
-	if h == nil {
-		// 如果上一条规则没有找到,根据文档: If there is no registered handler that applies to the request,
+	if e == nil {
+		// 如果没有找到,根据文档: If there is no registered handler that applies to the request,
 		// Handler returns a ``page not found'' handler and an empty pattern.
-		h, pattern = NotFoundHandler(), ""
+		// 但如果有pattern匹配path而只是method不同,返回405.
+		if allow := mux.allowedMethods(host, elems); len(allow) > 0 {
+			return HandlerFunc(func(w ResponseWriter, r *Request) {
+				w.Header().Set("Allow", strings.Join(allow, ", "))
+				Error(w, StatusText(StatusMethodNotAllowed), StatusMethodNotAllowed)
+			}), "", nil, nil
+		}
+		return NotFoundHandler(), "", nil, nil
 	}
-	return
+	return e.h, e.pat.str, e.pat, matches
 }
 
 // ServeHTTP dispatches the request to the handler whose
@@ -2442,71 +2772,68 @@ func (mux *ServeMux) ServeHTTP(w ResponseWriter, r *Request) {
 		return
 	}
 	// 获取应该由ServeMux中哪个内部的Handler来进行实际的处理
-	h, _ := mux.Handler(r)
-	// 使用mux.Handler(r)返回的新的Handler来处理请求
+	// 同时记录匹配的pattern和通配符的值,供 r.PathValue 使用
+	var h Handler
+	if use19 {
+		h, _ = mux.mux19.findHandler(r)
+	} else {
+		h, _, r.pat, r.matches = mux.findHandler(r)
+	}
+	// 使用mux.findHandler(r)返回的新的Handler来处理请求
 	// 文档:dispatches the request to the handler whose pattern most closely matches the request URL.
 	h.ServeHTTP(w, r)
 }
 
 // Handle registers the handler for the given pattern.
-// If a handler already exists for pattern, Handle panics.
+// If the given pattern conflicts with one that is already registered,
+// Handle panics.
 //
 // 此方法用于注册 pattern => Handler 的对应关系
-// 如果 pattern 已经被注册,会 panic
-// 如果 pattern 为空字符串 "",会 panic
+// 如果 pattern 无法解析,或与已注册的 pattern 冲突,会 panic
 // if handler == nil : 会 panic
-//
-// 查看源码,发现:
-// 如果注册的pattern='/tree/',会隐式的插入一条规则:(pattern='/tree',该规则自动跳转到pattern='/tree/')
-// 如果不需要这样的自动插入规则,应该显示指定一条规则(pattern='/tree'), (可以在 pattern='/tree/' 这条规则之前或之后指定都行)
 func (mux *ServeMux) Handle(pattern string, handler Handler) {
-	mux.mu.Lock()
-	defer mux.mu.Unlock()
-
-	if pattern == "" {
-		panic("http: invalid pattern")
+	if use19 {
+		mux.mux19.handle(pattern, handler)
+		return
+	}
+	pat, err := parsePattern(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("http: invalid pattern %q: %v", pattern, err))
 	}
 	if handler == nil {
 		panic("http: nil handler")
 	}
-	if mux.m[pattern].explicit {
-		// 注意,这个if只针对explicit=true
-		panic("http: multiple registrations for " + pattern)
-	}
 
-	if mux.m == nil {
-		// 确保map不是nil
-		mux.m = make(map[string]muxEntry)
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+
+	for _, e := range mux.es {
+		if !pat.conflictsWith(e.pat) {
+			continue
+		}
+		if pattern == e.pat.str {
+			panic("http: multiple registrations for " + pattern)
+		}
+		if pat.comparePathsAndMethods(e.pat) == equivalent {
+			panic(fmt.Sprintf("http: pattern %q matches the same requests as pattern %q", pattern, e.pat.str))
+		}
+		panic(fmt.Sprintf("http: pattern %q conflicts with pattern %q: both match some paths, like %q, but neither is more specific",
+			pattern, e.pat.str, commonPath(pat, e.pat)))
+	}
+	mux.es = append(mux.es, muxEntry{pat: pat, h: handler})
+	if seg := pat.segments[0]; !seg.wild && seg.s != "/" {
+		if mux.byFirst == nil {
+			mux.byFirst = make(map[string][]int)
+		}
+		mux.byFirst[seg.s] = append(mux.byFirst[seg.s], len(mux.es)-1)
+	} else {
+		mux.wild = append(mux.wild, len(mux.es)-1)
 	}
-	// explicit: true 显式指定的规则
-	mux.m[pattern] = muxEntry{explicit: true, h: handler, pattern: pattern}
 
-	if pattern[0] != '/' {
+	if pat.host != "" {
 		// 规则中包含hostname
 		mux.hosts = true
 	}
-
-	// Helpful behavior:
-	// If pattern is /tree/, insert an implicit permanent redirect for /tree.
-	// It can be overridden by an explicit registration.
-	n := len(pattern)
-	if n > 0 && pattern[n-1] == '/' && !mux.m[pattern[0:n-1]].explicit {
-		// If pattern contains a host name, strip it and use remaining
-		// path for redirect.
-		// if n > 0 && pattern[n-1] == '/' && !mux.m[pattern[0:n-1]].explicit: 说明 If pattern is /tree/
-		path := pattern
-		if pattern[0] != '/' {
-			// In pattern, at least the last character is a '/', so
-			// strings.Index can't be -1.
-			// if pattern[0] != '/': 说明 pattern contains a host name.
-			// 第一个 / 到最后的位置,属于host后面的字符串.
-			path = pattern[strings.Index(pattern, "/"):]
-		}
-		// 为什么这里一定要通过构造url.URL,下面使用url.String()? 而不是直接使用 path ? 因为url.String会返回一个绝对是合法的url
-		url := &url.URL{Path: path}
-		// insert an implicit permanent redirect for /tree,由于没有提到muxEntry.explicit,自动获得默认值false, 因此是implict,是属于隐式插入的规则 
-		mux.m[pattern[0:n-1]] = muxEntry{h: RedirectHandler(url.String(), StatusMovedPermanently), pattern: pattern}
-	}
 }
 
 // HandleFunc registers the handler function for the given pattern.
@@ -2629,10 +2956,26 @@ type Server struct {
 	// If nil, logging is done via the log package's standard logger.
 	ErrorLog *log.Logger
 
+	// HTTP2 configures HTTP/2 connections.
+	// If nil, default values are used.
+	HTTP2 *HTTP2Config
+
+	// Protocols is the set of protocols accepted by the server.
+	//
+	// If Protocols includes UnencryptedHTTP2, the server will accept
+	// unencrypted HTTP/2 connections, either with prior knowledge
+	// (a connection starting with the HTTP/2 client preface) or by
+	// upgrading an HTTP/1.1 request carrying "Upgrade: h2c".
+	//
+	// If nil, Protocols is HTTP1 and HTTP2.
+	Protocols *Protocols
+
 	disableKeepAlives int32     // accessed atomically.
 	inShutdown        int32     // accessed atomically (non-zero means we're in Shutdown)
 	nextProtoOnce     sync.Once // guards setupHTTP2_* init
 	nextProtoErr      error     // result of http2.ConfigureServer if used
+	h2cOnce           sync.Once // guards h2cServer init
+	h2cServer         *http2Server
 
 	mu         sync.Mutex
 	listeners  map[net.Listener]struct{}
@@ -2922,6 +3265,10 @@ func (srv *Server) Serve(l net.Listener) error {
 	if err := srv.setupHTTP2_Serve(); err != nil {
 		return err
 	}
+	if srv.protocols().UnencryptedHTTP2() {
+		// Set up h2c now so graceful shutdown reaches its connections.
+		srv.unencryptedHTTP2Server()
+	}
 
 	srv.trackListener(l, true)
 	defer srv.trackListener(l, false)
@@ -3246,12 +3593,65 @@ func (srv *Server) onceSetNextProtoDefaults() {
 	}
 	// Enable HTTP/2 by default if the user hasn't otherwise
 	// configured their TLSNextProto map.
-	if srv.TLSNextProto == nil {
-		conf := &http2Server{
-			NewWriteScheduler: func() http2WriteScheduler { return http2NewPriorityWriteScheduler(nil) },
-		}
-		srv.nextProtoErr = http2ConfigureServer(srv, conf)
+	if srv.TLSNextProto == nil && srv.protocols().HTTP2() {
+		srv.nextProtoErr = http2ConfigureServer(srv, srv.newHTTP2Server())
+	}
+}
+
+// newHTTP2Server returns a new HTTP/2 server configuration for srv,
+// with the settings from srv.HTTP2 applied.
+func (srv *Server) newHTTP2Server() *http2Server {
+	conf := &http2Server{
+		NewWriteScheduler: func() http2WriteScheduler { return http2NewPriorityWriteScheduler(nil) },
+	}
+	c := srv.HTTP2
+	if c == nil {
+		return conf
+	}
+	// Out-of-range values are left unset, so that the HTTP/2
+	// server uses its defaults.
+	if c.MaxConcurrentStreams > 0 && int64(c.MaxConcurrentStreams) <= math.MaxUint32 {
+		conf.MaxConcurrentStreams = uint32(c.MaxConcurrentStreams)
+	}
+	if c.MaxReadFrameSize >= http2minMaxFrameSize && c.MaxReadFrameSize <= http2maxFrameSize {
+		conf.MaxReadFrameSize = uint32(c.MaxReadFrameSize)
+	}
+	if c.MaxReceiveBufferPerConnection >= http2initialWindowSize && int64(c.MaxReceiveBufferPerConnection) <= math.MaxInt32 {
+		conf.MaxUploadBufferPerConnection = int32(c.MaxReceiveBufferPerConnection)
 	}
+	if c.MaxReceiveBufferPerStream >= http2minMaxFrameSize && int64(c.MaxReceiveBufferPerStream) <= math.MaxInt32 {
+		conf.MaxUploadBufferPerStream = int32(c.MaxReceiveBufferPerStream)
+	}
+	if c.SendPingTimeout > 0 {
+		conf.ReadIdleTimeout = c.SendPingTimeout
+	}
+	if c.PingTimeout > 0 {
+		conf.PingTimeout = c.PingTimeout
+	}
+	conf.PermitProhibitedCipherSuites = c.PermitProhibitedCipherSuites
+	return conf
+}
+
+func (srv *Server) protocols() Protocols {
+	if srv.Protocols != nil {
+		return *srv.Protocols
+	}
+	return defaultProtocols()
+}
+
+// unencryptedHTTP2Server returns the HTTP/2 server used for
+// unencrypted connections, creating it on first use.
+func (srv *Server) unencryptedHTTP2Server() *http2Server {
+	srv.h2cOnce.Do(func() {
+		conf := srv.newHTTP2Server()
+		conf.state = &http2serverInternalState{activeConns: make(map[*http2serverConn]struct{})}
+		// Unlike http2ConfigureServer, leave srv.TLSConfig and
+		// srv.TLSNextProto alone.
+		http2configureServer18(srv, conf)
+		http2configureServer19(srv, conf)
+		srv.h2cServer = conf
+	})
+	return srv.h2cServer
 }
 
 // TimeoutHandler returns a Handler that runs h with the given time limit.
@@ -222,14 +222,46 @@ type Transport struct {
 	// 那么问题来了,如果超过了这个限制,会怎么样
 	MaxResponseHeaderBytes int64
 
-	// nextProtoOnce guards initialization of TLSNextProto and
-	// h2transport (via onceSetNextProtoDefaults)
+	// HTTP2 configures HTTP/2 connections.
+	// If nil, default values are used.
+	HTTP2 *HTTP2Config
+
+	// Protocols is the set of protocols supported by the transport.
+	//
+	// If Protocols includes UnencryptedHTTP2 and does not include
+	// HTTP1, the transport will use unencrypted HTTP/2 with prior
+	// knowledge (h2c) for requests to http:// URLs that don't go
+	// through a proxy.
+	//
+	// If Protocols is set and includes HTTP2, HTTP/2 is enabled
+	// even when TLSClientConfig or a custom dialer is set.
+	//
+	// If nil, Protocols is HTTP1 and HTTP2.
+	Protocols *Protocols
+
+	// nextProtoOnce guards initialization of TLSNextProto,
+	// h2transport and h2cTransport (via onceSetNextProtoDefaults)
 	nextProtoOnce sync.Once
 	h2transport   *http2Transport // non-nil if http2 wired up
+	h2cTransport  *http2Transport // non-nil if h2c wired up
 
 	// TODO: tunable on max per-host TCP dials in flight (Issue 13957)
 }
 
+func (t *Transport) protocols() Protocols {
+	if t.Protocols != nil {
+		return *t.Protocols
+	}
+	return defaultProtocols()
+}
+
+// useUnencryptedHTTP2 reports whether t sends requests for http://
+// URLs as HTTP/2 with prior knowledge.
+func (t *Transport) useUnencryptedHTTP2() bool {
+	p := t.protocols()
+	return p.UnencryptedHTTP2() && !p.HTTP1()
+}
+
 // onceSetNextProtoDefaults initializes TLSNextProto.
 // It must be called via t.nextProtoOnce.Do.
 // @notsee
@@ -242,7 +274,13 @@ func (t *Transport) onceSetNextProtoDefaults() {
 		// Transport.
 		return
 	}
-	if t.TLSClientConfig != nil || t.Dial != nil || t.DialTLS != nil {
+	if t.useUnencryptedHTTP2() {
+		t.setUpUnencryptedHTTP2()
+	}
+	if !t.protocols().HTTP2() {
+		return
+	}
+	if t.Protocols == nil && (t.TLSClientConfig != nil || t.Dial != nil || t.DialTLS != nil) {
 		// Be conservative and don't automatically enable
 		// http2 if they've specified a custom TLS config or
 		// custom dialers. Let them opt-in themselves via
@@ -256,7 +294,44 @@ func (t *Transport) onceSetNextProtoDefaults() {
 		return
 	}
 	t.h2transport = t2
+	t.configureHTTP2(t2)
+}
 
+// setUpUnencryptedHTTP2 makes t send requests for http:// URLs as
+// HTTP/2 with prior knowledge, using a separate HTTP/2 transport
+// that dials cleartext connections with t's dialer.
+func (t *Transport) setUpUnencryptedHTTP2() {
+	t2 := &http2Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return t.dial(context.Background(), network, addr)
+		},
+		t1: t,
+	}
+	t.configureHTTP2(t2)
+	t.h2cTransport = t2
+	t.RegisterProtocol("http", h2cRoundTripper{t, t2})
+}
+
+// h2cRoundTripper sends requests over t2, except for those that
+// t would send through a proxy, which it leaves to t.
+type h2cRoundTripper struct {
+	t  *Transport
+	t2 *http2Transport
+}
+
+func (rt h2cRoundTripper) RoundTrip(req *Request) (*Response, error) {
+	if rt.t.Proxy != nil {
+		if u, err := rt.t.Proxy(req); u != nil || err != nil {
+			return nil, ErrSkipAltProtocol
+		}
+	}
+	return rt.t2.RoundTrip(req)
+}
+
+// configureHTTP2 applies the settings of t that have a counterpart
+// in t2, the HTTP/2 transport used by t, to t2.
+func (t *Transport) configureHTTP2(t2 *http2Transport) {
 	// Auto-configure the http2.Transport's MaxHeaderListSize from
 	// the http.Transport's MaxResponseHeaderBytes. They don't
 	// exactly mean the same thing, but they're close.
@@ -271,6 +346,20 @@ func (t *Transport) onceSetNextProtoDefaults() {
 			t2.MaxHeaderListSize = uint32(limit1)
 		}
 	}
+
+	c := t.HTTP2
+	if c == nil {
+		return
+	}
+	if c.MaxReadFrameSize >= http2minMaxFrameSize && c.MaxReadFrameSize <= http2maxFrameSize {
+		t2.MaxReadFrameSize = uint32(c.MaxReadFrameSize)
+	}
+	if c.SendPingTimeout > 0 {
+		t2.ReadIdleTimeout = c.SendPingTimeout
+	}
+	if c.PingTimeout > 0 {
+		t2.PingTimeout = c.PingTimeout
+	}
 }
 
 // ProxyFromEnvironment returns the URL of the proxy to use for a
@@ -583,6 +672,9 @@ func (t *Transport) CloseIdleConnections() {
 	if t2 := t.h2transport; t2 != nil {
 		t2.CloseIdleConnections()
 	}
+	if t2 := t.h2cTransport; t2 != nil {
+		t2.CloseIdleConnections()
+	}
 }
 
 // CancelRequest cancels an in-flight request by closing its connection.
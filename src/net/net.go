@@ -439,6 +439,9 @@ type OpError struct {
 	Err error
 }
 
+// Unwrap returns the underlying error.
+func (e *OpError) Unwrap() error { return e.Err }
+
 // @see
 func (e *OpError) Error() string {
 	if e == nil {
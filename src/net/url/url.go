@@ -28,6 +28,7 @@ type Error struct {
 	Err error
 }
 
+func (e *Error) Unwrap() error { return e.Err }
 func (e *Error) Error() string { return e.Op + " " + e.URL + ": " + e.Err.Error() }
 
 type timeout interface {
@@ -8,7 +8,10 @@
 
 package os
 
-import "syscall"
+import (
+	"internal/testlog"
+	"syscall"
+)
 
 // Expand replaces ${var} or $var in the string based on the mapping function.
 // For example, os.ExpandEnv(s) is equivalent to os.Expand(s, os.Getenv).
@@ -112,6 +115,7 @@ func getShellName(s string) (string, int) {
 //
 // 对比一下本函数和os.LookupEnv的源码
 func Getenv(key string) string {
+	testlog.Getenv(key)
 	v, _ := syscall.Getenv(key)
 	return v
 }
@@ -124,6 +128,7 @@ func Getenv(key string) string {
 //
 // 对比一下本函数和os.Getenv的源码
 func LookupEnv(key string) (string, bool) {
+	testlog.Getenv(key)
 	return syscall.Getenv(key)
 }
 
@@ -6,18 +6,18 @@
 package os
 
 import (
-	"errors"
+	"internal/oserror"
 )
 
 // Portable analogs of some common system call errors.
 // analogs n. 类似物（analog的复数）；同型物
 // analog ['ænəlɒɡ] n. [自] 模拟；类似物 adj. [自] 模拟的；有长短针的
 var (
-	ErrInvalid    = errors.New("invalid argument") // methods on File will return this error when the receiver is nil
-	ErrPermission = errors.New("permission denied")
-	ErrExist      = errors.New("file already exists")
-	ErrNotExist   = errors.New("file does not exist")
-	ErrClosed     = errors.New("file already closed")
+	ErrInvalid    = oserror.ErrInvalid // methods on File will return this error when the receiver is nil
+	ErrPermission = oserror.ErrPermission
+	ErrExist      = oserror.ErrExist
+	ErrNotExist   = oserror.ErrNotExist
+	ErrClosed     = oserror.ErrClosed
 )
 
 // PathError records an error and the operation and file path that caused it.
@@ -32,6 +32,9 @@ type PathError struct {
 
 func (e *PathError) Error() string { return e.Op + " " + e.Path + ": " + e.Err.Error() }
 
+// Unwrap returns the underlying error.
+func (e *PathError) Unwrap() error { return e.Err }
+
 // SyscallError records an error from a specific system call.
 type SyscallError struct {
 	Syscall string
@@ -40,6 +43,9 @@ type SyscallError struct {
 
 func (e *SyscallError) Error() string { return e.Syscall + ": " + e.Err.Error() }
 
+// Unwrap returns the underlying error.
+func (e *SyscallError) Unwrap() error { return e.Err }
+
 // NewSyscallError returns, as an error, a new SyscallError
 // with the given system call name and error details.
 // As a convenience, if err is nil, NewSyscallError returns nil.
@@ -92,6 +92,56 @@ func SetGCPercent(percent int) int {
 	return int(setGCPercent(int32(percent)))
 }
 
+// SetMemoryLimit provides the runtime with a soft memory limit.
+//
+// The runtime undertakes several processes to try to respect this
+// memory limit, including adjustments to the frequency of garbage
+// collections and returning memory to the underlying system more
+// aggressively. This limit will be respected even if GOGC=off (or,
+// if SetGCPercent(-1) is executed).
+//
+// The input limit is provided as bytes, and includes all memory
+// mapped, managed, and not released by the Go runtime. Notably, it
+// does not account for space used by the Go binary and memory
+// external to Go, such as memory managed by the underlying system
+// on behalf of the process, or memory managed by non-Go code inside
+// the same process.
+//
+// A zero limit or a limit that's lower than the amount of memory
+// used by the Go runtime may cause the garbage collector to run
+// nearly continuously. However, the application may still make
+// progress: when staying under the limit would require the garbage
+// collector to use more than about half of the available CPU time,
+// the runtime lets the heap grow past the limit instead. This
+// protects the application from spending all its time collecting
+// garbage when the limit is too low for its live heap. The
+// /gc/gomemlimit/last-active:gc-cycle and
+// /gc/limiter/last-enabled:gc-cycle metrics in runtime/metrics
+// report when the limit last took effect and when it was last
+// relaxed in this way.
+//
+// The memory limit is always respected by the Go runtime, so to
+// effectively disable this behavior, set the limit very high.
+// math.MaxInt64 is the canonical value for disabling the limit,
+// but values much greater than the available memory on the
+// underlying system work just as well.
+//
+// The initial setting is math.MaxInt64 unless the GOMEMLIMIT
+// environment variable is set, in which case it provides the
+// initial setting. GOMEMLIMIT is a numeric value in bytes with an
+// optional unit suffix. The supported suffixes include B, KiB, MiB,
+// GiB, and TiB. These suffixes represent quantities of bytes as
+// defined by the IEC 80000-13 standard. That is, they are based on
+// powers of two: KiB means 2^10 bytes, MiB means 2^20 bytes, and so
+// on. GOMEMLIMIT=off is equivalent to not setting it.
+//
+// SetMemoryLimit returns the previously set memory limit.
+// A negative input does not adjust the limit, and allows for
+// retrieval of the currently set memory limit.
+func SetMemoryLimit(limit int64) int64 {
+	return setMemoryLimit(limit)
+}
+
 // FreeOSMemory forces a garbage collection followed by an
 // attempt to return as much memory to the operating system
 // as possible. (Even if this is not called, the runtime gradually
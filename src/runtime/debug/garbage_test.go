@@ -172,6 +172,52 @@ func TestSetGCPercent(t *testing.T) {
 	}
 }
 
+func TestSetMemoryLimit(t *testing.T) {
+	// Test that the variable is being set and returned correctly.
+	old := SetMemoryLimit(123 << 20)
+	defer SetMemoryLimit(old)
+	if got := SetMemoryLimit(-1); got != 123<<20 {
+		t.Errorf("SetMemoryLimit(123 MB); SetMemoryLimit(-1) = %d, want %d", got, 123<<20)
+	}
+	if got := SetMemoryLimit(old); got != 123<<20 {
+		t.Errorf("SetMemoryLimit(123 MB); SetMemoryLimit(x) = %d, want %d", got, 123<<20)
+	}
+	if got := SetMemoryLimit(-1); got != old {
+		t.Errorf("SetMemoryLimit(-1) = %d, want %d", got, old)
+	}
+}
+
+var setMemoryLimitSink []byte
+
+func TestSetMemoryLimitGCOff(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+
+	// With GC off, the memory limit alone must drive collections
+	// and keep the heap bounded.
+	defer SetGCPercent(SetGCPercent(-1))
+	defer SetMemoryLimit(SetMemoryLimit(64 << 20))
+	runtime.GC()
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	ngc := ms.NumGC
+	// Allocate 1 GB of garbage.
+	for i := 0; i < 1<<30; i += 64 << 10 {
+		setMemoryLimitSink = make([]byte, 64<<10)
+	}
+	setMemoryLimitSink = nil
+	runtime.ReadMemStats(&ms)
+	if ms.NumGC == ngc {
+		t.Errorf("no GC ran while allocating past the memory limit with GOGC=off")
+	}
+	// The limit is soft, so allow some slack over it.
+	if ms.HeapSys-ms.HeapReleased > 256<<20 {
+		t.Errorf("heap in use is %d MB, want at most about 64 MB", (ms.HeapSys-ms.HeapReleased)>>20)
+	}
+}
+
 func abs64(a int64) int64 {
 	if a < 0 {
 		return -a
@@ -0,0 +1,96 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debug_test
+
+import (
+	"reflect"
+	. "runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestBuildInfoRoundTrip(t *testing.T) {
+	info := &BuildInfo{
+		GoVersion: "go1.10",
+		Path:      "example.com/m/cmd/hello",
+		Main:      Module{Path: "example.com/m", Version: "(devel)"},
+		Deps: []*Module{
+			{Path: "example.com/a", Version: "v1.0.0", Sum: "h1:abc="},
+			{
+				Path:    "example.com/b",
+				Version: "v1.2.3",
+				Replace: &Module{Path: "../b", Version: "", Sum: ""},
+			},
+		},
+		Settings: []BuildSetting{
+			{Key: "-compiler", Value: "gc"},
+			{Key: "-ldflags", Value: "-s -w"},
+			{Key: "-gcflags", Value: `all="-N -l"`},
+			{Key: "quoted key", Value: "x"},
+			{Key: "CGO_ENABLED", Value: "1"},
+			{Key: "vcs.modified", Value: "false"},
+			{Key: "empty", Value: ""},
+		},
+	}
+
+	const want = "go\tgo1.10\n" +
+		"path\texample.com/m/cmd/hello\n" +
+		"mod\texample.com/m\t(devel)\t\n" +
+		"dep\texample.com/a\tv1.0.0\th1:abc=\n" +
+		"dep\texample.com/b\tv1.2.3\n" +
+		"=>\t../b\t\t\n" +
+		"build\t-compiler=gc\n" +
+		"build\t-ldflags=\"-s -w\"\n" +
+		"build\t-gcflags=\"all=\\\"-N -l\\\"\"\n" +
+		"build\t\"quoted key\"=x\n" +
+		"build\tCGO_ENABLED=1\n" +
+		"build\tvcs.modified=false\n" +
+		"build\tempty=\n"
+	text := info.String()
+	if text != want {
+		t.Errorf("String() =\n%s\nwant\n%s", text, want)
+	}
+	got, err := ParseBuildInfo(text)
+	if err != nil {
+		t.Fatalf("ParseBuildInfo(%q): %v", text, err)
+	}
+	if !reflect.DeepEqual(got, info) {
+		t.Errorf("ParseBuildInfo(%q) =\n%+v\nwant\n%+v", text, got, info)
+	}
+	if s := got.String(); s != text {
+		t.Errorf("round trip String() =\n%s\nwant\n%s", s, text)
+	}
+}
+
+func TestParseBuildInfoErrors(t *testing.T) {
+	for _, text := range []string{
+		"mod\tonly-path\n",
+		"=>\texample.com/b\tv1.0.0\t\n",
+		"build\t=value\n",
+		"build\tkey value\n",
+		"build\tkey=a b\n",
+		"build\t\"key\n",
+		"build\t\"key\"value\n",
+	} {
+		if _, err := ParseBuildInfo(text); err == nil {
+			t.Errorf("ParseBuildInfo(%q) succeeded, want error", text)
+		} else if !strings.HasPrefix(err.Error(), "could not parse Go build info") {
+			t.Errorf("ParseBuildInfo(%q): unexpected error %v", text, err)
+		}
+	}
+}
+
+func TestReadBuildInfo(t *testing.T) {
+	// Test binaries are built by the go command, but it only
+	// records build information for main packages, so there
+	// may or may not be any. Just make sure it is consistent.
+	info, ok := ReadBuildInfo()
+	if !ok {
+		return
+	}
+	if info.GoVersion == "" {
+		t.Errorf("ReadBuildInfo returned empty GoVersion")
+	}
+}
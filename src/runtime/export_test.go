@@ -34,6 +34,7 @@ var Fastlog2 = fastlog2
 
 var Atoi = atoi
 var Atoi32 = atoi32
+var ParseByteCount = parseByteCount
 
 type LFNode struct {
 	Next    uint64
@@ -344,6 +345,52 @@ func ReadMemStatsSlow() (base, slow MemStats) {
 	return
 }
 
+// ReadMetricsSlow reads the given metrics and MemStats while the
+// world is stopped, so the two are consistent with each other.
+//
+// The samples must have been read at least once before so that
+// reading them again does not allocate.
+func ReadMetricsSlow(memStats *MemStats, samplesp unsafe.Pointer, len, cap int) {
+	stopTheWorld("ReadMetricsSlow")
+
+	// Read memstats first. It flushes the mcaches, which
+	// readMetrics does not do, so going the other way around
+	// may result in inconsistent statistics.
+	systemstack(func() {
+		readmemstats_m(memStats)
+	})
+
+	readMetrics(samplesp, len, cap)
+
+	startTheWorld()
+}
+
+type TimeHistogram timeHistogram
+
+// Count returns the counts for the given bucket, subBucket indices.
+// Returns true if the bucket was valid, otherwise returns the counts
+// for the underflow bucket and false.
+func (th *TimeHistogram) Count(bucket, subBucket uint) (uint64, bool) {
+	t := (*timeHistogram)(th)
+	i := bucket*TimeHistNumSubBuckets + subBucket
+	if i >= uint(len(t.counts)) {
+		return t.underflow, false
+	}
+	return t.counts[i], true
+}
+
+func (th *TimeHistogram) Record(duration int64) {
+	(*timeHistogram)(th).record(duration)
+}
+
+const (
+	TimeHistSubBucketBits   = timeHistSubBucketBits
+	TimeHistNumSubBuckets   = timeHistNumSubBuckets
+	TimeHistNumSuperBuckets = timeHistNumSuperBuckets
+)
+
+var TimeHistogramMetricsBuckets = timeHistogramMetricsBuckets
+
 // BlockOnSystemStack switches to the system stack, prints "x\n" to
 // stderr, and blocks in a stack containing
 // "runtime.blockOnSystemStackInternal".
@@ -0,0 +1,543 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtime
+
+// Metrics implementation exported to runtime/metrics.
+
+import (
+	"runtime/internal/atomic"
+	"unsafe"
+)
+
+var (
+	// metrics is a map of runtime/metrics keys to
+	// data used by the runtime to sample each metric's
+	// value.
+	metricsSema uint32 = 1
+	metricsInit bool
+	metrics     map[string]metricData
+
+	// timeHistBuckets are the bucket boundaries shared by all
+	// histograms backed by a timeHistogram.
+	timeHistBuckets []float64
+)
+
+type metricData struct {
+	// deps is the set of runtime statistics that this metric
+	// depends on. Before compute is called, the statAggregate
+	// which will be passed must ensure() these dependencies.
+	deps statDepSet
+
+	// compute is a function that populates a metricValue
+	// given a populated statAggregate structure.
+	compute func(in *statAggregate, out *metricValue)
+}
+
+// initMetrics initializes the metrics map if it hasn't been yet.
+//
+// metricsSema must be held.
+func initMetrics() {
+	if metricsInit {
+		return
+	}
+	timeHistBuckets = timeHistogramMetricsBuckets()
+	metrics = map[string]metricData{
+		"/gc/cycles/automatic:gc-cycles": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = uint64(atomic.Load(&memstats.numgc) - atomic.Load(&memstats.numforcedgc))
+			},
+		},
+		"/gc/cycles/forced:gc-cycles": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = uint64(atomic.Load(&memstats.numforcedgc))
+			},
+		},
+		"/gc/cycles/total:gc-cycles": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = uint64(atomic.Load(&memstats.numgc))
+			},
+		},
+		"/gc/gomemlimit/last-active:gc-cycle": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = atomic.Load64(&memstats.memoryLimitLastActive)
+			},
+		},
+		"/gc/gomemlimit:bytes": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = atomic.Load64((*uint64)(unsafe.Pointer(&memoryLimit)))
+			},
+		},
+		"/gc/heap/allocs:bytes": {
+			deps: heapStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.heapStats.totalAllocated
+			},
+		},
+		"/gc/heap/allocs:objects": {
+			deps: heapStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.heapStats.totalAllocs
+			},
+		},
+		"/gc/heap/frees:bytes": {
+			deps: heapStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.heapStats.totalFreed
+			},
+		},
+		"/gc/heap/frees:objects": {
+			deps: heapStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.heapStats.totalFrees
+			},
+		},
+		"/gc/heap/goal:bytes": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = atomic.Load64(&memstats.next_gc)
+			},
+		},
+		"/gc/heap/objects:objects": {
+			deps: heapStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.heapStats.numObjects
+			},
+		},
+		"/gc/heap/tiny/allocs:objects": {
+			deps: heapStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.heapStats.tinyAllocs
+			},
+		},
+		"/gc/limiter/last-enabled:gc-cycle": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = atomic.Load64(&memstats.limiterLastEnabled)
+			},
+		},
+		"/gc/pauses:seconds": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				hist := out.float64HistOrInit(timeHistBuckets)
+				hist.counts[0] = atomic.Load64(&memstats.gcPauseDist.underflow)
+				for i := range memstats.gcPauseDist.counts {
+					hist.counts[i+1] = atomic.Load64(&memstats.gcPauseDist.counts[i])
+				}
+			},
+		},
+		"/memory/classes/heap/free:bytes": {
+			deps: heapStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.heapStats.free
+			},
+		},
+		"/memory/classes/heap/objects:bytes": {
+			deps: heapStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.heapStats.inObjects
+			},
+		},
+		"/memory/classes/heap/released:bytes": {
+			deps: heapStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.heapStats.released
+			},
+		},
+		"/memory/classes/heap/stacks:bytes": {
+			deps: heapStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.heapStats.inStacks
+			},
+		},
+		"/memory/classes/heap/unused:bytes": {
+			deps: heapStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.heapStats.unused
+			},
+		},
+		"/memory/classes/metadata/mcache/free:bytes": {
+			deps: heapStatsDep | sysStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.sysStats.mCacheSys - in.heapStats.mCacheInUse
+			},
+		},
+		"/memory/classes/metadata/mcache/inuse:bytes": {
+			deps: heapStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.heapStats.mCacheInUse
+			},
+		},
+		"/memory/classes/metadata/mspan/free:bytes": {
+			deps: heapStatsDep | sysStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.sysStats.mSpanSys - in.heapStats.mSpanInUse
+			},
+		},
+		"/memory/classes/metadata/mspan/inuse:bytes": {
+			deps: heapStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.heapStats.mSpanInUse
+			},
+		},
+		"/memory/classes/metadata/other:bytes": {
+			deps: sysStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.sysStats.gcMiscSys
+			},
+		},
+		"/memory/classes/os-stacks:bytes": {
+			deps: sysStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.sysStats.stacksSys
+			},
+		},
+		"/memory/classes/other:bytes": {
+			deps: sysStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.sysStats.otherSys
+			},
+		},
+		"/memory/classes/profiling/buckets:bytes": {
+			deps: sysStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.sysStats.buckHashSys
+			},
+		},
+		"/memory/classes/total:bytes": {
+			deps: heapStatsDep | sysStatsDep,
+			compute: func(in *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = in.heapStats.heapSys + in.heapStats.inStacks +
+					in.sysStats.stacksSys + in.sysStats.mSpanSys +
+					in.sysStats.mCacheSys + in.sysStats.buckHashSys +
+					in.sysStats.gcMiscSys + in.sysStats.otherSys
+			},
+		},
+		"/sched/gomaxprocs:threads": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = uint64(gomaxprocs)
+			},
+		},
+		"/sched/goroutines:goroutines": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindUint64
+				out.scalar = uint64(gcount())
+			},
+		},
+		"/sched/latencies:seconds": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				hist := out.float64HistOrInit(timeHistBuckets)
+				hist.counts[0] = atomic.Load64(&sched.timeToRun.underflow)
+				for i := range sched.timeToRun.counts {
+					hist.counts[i+1] = atomic.Load64(&sched.timeToRun.counts[i])
+				}
+			},
+		},
+		"/sync/mutex/wait/total:seconds": {
+			compute: func(_ *statAggregate, out *metricValue) {
+				out.kind = metricKindFloat64
+				out.scalar = float64bits(nsToSec(atomic.Loadint64(&sched.totalMutexWaitTime)))
+			},
+		},
+	}
+	metricsInit = true
+}
+
+// statDepSet is a set of dependencies on groups of statistics
+// that a metric might have.
+type statDepSet uint
+
+const (
+	heapStatsDep statDepSet = 1 << iota // corresponds to heapStatsAggregate
+	sysStatsDep                         // corresponds to sysStatsAggregate
+)
+
+// heapStatsAggregate represents memory stats obtained from the
+// runtime that are protected by the heap lock. Reading them
+// requires acquiring mheap_.lock but not stopping the world.
+//
+// Allocation counts are only flushed from each P's mcache
+// periodically, so they may lag slightly behind the true values.
+type heapStatsAggregate struct {
+	// heapSys is the amount of address space mapped for the
+	// GC'd heap, excluding heap memory used for stacks.
+	heapSys uint64
+
+	// inObjects is the number of bytes in allocated objects,
+	// including unswept dead objects.
+	inObjects uint64
+
+	// numObjects is the number of live or unswept objects in the heap.
+	numObjects uint64
+
+	// totalAllocated is the total bytes of heap objects allocated
+	// over the lifetime of the program.
+	totalAllocated uint64
+
+	// totalFreed is the total bytes of heap objects freed
+	// over the lifetime of the program.
+	totalFreed uint64
+
+	// totalAllocs is the number of heap objects allocated over
+	// the lifetime of the program, excluding tiny allocations.
+	totalAllocs uint64
+
+	// totalFrees is the number of heap objects freed over
+	// the lifetime of the program, excluding tiny allocations.
+	totalFrees uint64
+
+	// tinyAllocs is the number of allocations that were combined
+	// into a tiny block rather than allocating a new object.
+	tinyAllocs uint64
+
+	// free is the number of bytes in free spans that are still
+	// backed by physical memory.
+	free uint64
+
+	// released is the number of bytes in free spans that have
+	// been returned to the OS.
+	released uint64
+
+	// unused is the number of bytes in in-use spans that are not
+	// occupied by objects.
+	unused uint64
+
+	// inStacks is the number of bytes of heap memory used for
+	// goroutine stacks.
+	inStacks uint64
+
+	// mSpanInUse and mCacheInUse are the number of bytes of
+	// mspan and mcache structures in use.
+	mSpanInUse  uint64
+	mCacheInUse uint64
+}
+
+// compute populates the heapStatsAggregate with values from the runtime.
+func (a *heapStatsAggregate) compute() {
+	// The heap lock may only be held on the system stack.
+	systemstack(func() {
+		lock(&mheap_.lock)
+
+		for i := range mheap_.central {
+			c := &mheap_.central[i].mcentral
+			n := atomic.Load64(&c.nmalloc)
+			a.totalAllocs += n
+			a.totalAllocated += n * uint64(class_to_size[spanClass(i).sizeclass()])
+		}
+		a.totalAllocs += mheap_.nlargealloc
+		a.totalAllocated += mheap_.largealloc
+		a.totalFrees += mheap_.nlargefree
+		a.totalFreed += mheap_.largefree
+		for i := 1; i < _NumSizeClasses; i++ {
+			a.totalFrees += mheap_.nsmallfree[i]
+			a.totalFreed += mheap_.nsmallfree[i] * uint64(class_to_size[i])
+		}
+		a.tinyAllocs = memstats.tinyallocs
+
+		a.heapSys = memstats.heap_sys
+		a.free = memstats.heap_idle - memstats.heap_released
+		a.released = memstats.heap_released
+		a.inStacks = memstats.stacks_inuse
+		a.mSpanInUse = uint64(mheap_.spanalloc.inuse)
+		a.mCacheInUse = uint64(mheap_.cachealloc.inuse)
+
+		a.unused = memstats.heap_inuse
+
+		unlock(&mheap_.lock)
+	})
+
+	// The counters above are flushed from the mcaches at
+	// different times, so they may briefly disagree.
+	if a.totalFreed > a.totalAllocated {
+		a.totalFreed = a.totalAllocated
+	}
+	if a.totalFrees > a.totalAllocs {
+		a.totalFrees = a.totalAllocs
+	}
+	a.inObjects = a.totalAllocated - a.totalFreed
+	a.numObjects = a.totalAllocs - a.totalFrees
+	if a.inObjects > a.unused {
+		a.inObjects = a.unused
+	}
+	a.unused -= a.inObjects
+}
+
+// sysStatsAggregate represents system memory stats obtained
+// from the runtime. These stats are all updated atomically,
+// so reading them requires no locks.
+type sysStatsAggregate struct {
+	stacksSys   uint64
+	mSpanSys    uint64
+	mCacheSys   uint64
+	buckHashSys uint64
+	gcMiscSys   uint64
+	otherSys    uint64
+}
+
+// compute populates the sysStatsAggregate with values from the runtime.
+func (a *sysStatsAggregate) compute() {
+	a.stacksSys = atomic.Load64(&memstats.stacks_sys)
+	a.mSpanSys = atomic.Load64(&memstats.mspan_sys)
+	a.mCacheSys = atomic.Load64(&memstats.mcache_sys)
+	a.buckHashSys = atomic.Load64(&memstats.buckhash_sys)
+	a.gcMiscSys = atomic.Load64(&memstats.gc_sys)
+	a.otherSys = atomic.Load64(&memstats.other_sys)
+}
+
+// statAggregate is the main driver of the metrics implementation.
+//
+// It contains multiple aggregates of runtime statistics, as well
+// as a set of these aggregates that it has populated. The aggregates
+// are populated lazily by its ensure method.
+type statAggregate struct {
+	ensured   statDepSet
+	heapStats heapStatsAggregate
+	sysStats  sysStatsAggregate
+}
+
+// ensure populates statistics aggregates determined by deps if they
+// haven't yet been populated.
+func (a *statAggregate) ensure(deps statDepSet) {
+	missing := deps &^ a.ensured
+	if missing == 0 {
+		return
+	}
+	if missing&heapStatsDep != 0 {
+		a.heapStats.compute()
+	}
+	if missing&sysStatsDep != 0 {
+		a.sysStats.compute()
+	}
+	a.ensured |= missing
+}
+
+// metricKind is a runtime copy of runtime/metrics.ValueKind and
+// must be kept structurally identical to that type.
+type metricKind int
+
+const (
+	// These values must be kept identical to their corresponding Kind* values
+	// in the runtime/metrics package.
+	metricKindBad metricKind = iota
+	metricKindUint64
+	metricKindFloat64
+	metricKindFloat64Histogram
+)
+
+// metricSample is a runtime copy of runtime/metrics.Sample and
+// must be kept structurally identical to that type.
+type metricSample struct {
+	name  string
+	value metricValue
+}
+
+// metricValue is a runtime copy of runtime/metrics.Value and
+// must be kept structurally identical to that type.
+type metricValue struct {
+	kind    metricKind
+	scalar  uint64         // contains scalar values for scalar Kinds.
+	pointer unsafe.Pointer // contains non-scalar values.
+}
+
+// float64HistOrInit tries to pull out an existing float64Histogram
+// from the value, but if none exists, then it allocates one with
+// the given buckets.
+func (v *metricValue) float64HistOrInit(buckets []float64) *metricFloat64Histogram {
+	var hist *metricFloat64Histogram
+	if v.kind == metricKindFloat64Histogram && v.pointer != nil {
+		hist = (*metricFloat64Histogram)(v.pointer)
+	} else {
+		v.kind = metricKindFloat64Histogram
+		hist = new(metricFloat64Histogram)
+		v.pointer = unsafe.Pointer(hist)
+	}
+	hist.buckets = buckets
+	if len(hist.counts) != len(hist.buckets)-1 {
+		hist.counts = make([]uint64, len(buckets)-1)
+	}
+	return hist
+}
+
+// metricFloat64Histogram is a runtime copy of runtime/metrics.Float64Histogram
+// and must be kept structurally identical to that type.
+type metricFloat64Histogram struct {
+	counts  []uint64
+	buckets []float64
+}
+
+// agg is used by readMetrics, and is protected by metricsSema.
+//
+// Managed as a global variable because its pointer will be
+// an argument to a dynamically-defined function, and we'd
+// like to avoid it escaping to the heap.
+var agg statAggregate
+
+// readMetrics is the implementation of runtime/metrics.Read.
+//
+//go:linkname readMetrics runtime/metrics.runtime_readMetrics
+func readMetrics(samplesp unsafe.Pointer, len int, cap int) {
+	// Construct a slice from the args.
+	sl := slice{samplesp, len, cap}
+	samples := *(*[]metricSample)(unsafe.Pointer(&sl))
+
+	// Acquire the metricsSema but with handoff. This operation
+	// is expensive enough that queueing up goroutines and handing
+	// off between them will be noticeably better-behaved.
+	semacquire1(&metricsSema, true, 0)
+
+	// Ensure the map is initialized.
+	initMetrics()
+
+	// Clear agg defensively.
+	agg = statAggregate{}
+
+	// Sample.
+	for i := range samples {
+		sample := &samples[i]
+		data, ok := metrics[sample.name]
+		if !ok {
+			sample.value.kind = metricKindBad
+			continue
+		}
+		// Ensure we have all the stats we need.
+		// agg is populated lazily.
+		agg.ensure(data.deps)
+
+		// Compute the value based on the stats we have.
+		data.compute(&agg, &sample.value)
+	}
+
+	semrelease1(&metricsSema, true)
+}
+
+// nsToSec takes a duration in nanoseconds and converts it to seconds as
+// a float64.
+func nsToSec(ns int64) float64 {
+	return float64(ns) / 1e9
+}
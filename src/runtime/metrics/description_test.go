@@ -0,0 +1,95 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package metrics_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"regexp"
+	"runtime/metrics"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestDescriptionNameFormat(t *testing.T) {
+	r := regexp.MustCompile("^(?P<name>/[^:]+):(?P<unit>[^:*/]+(?:[*/][^:*/]+)*)$")
+	descriptions := metrics.All()
+	for _, desc := range descriptions {
+		if !r.MatchString(desc.Name) {
+			t.Errorf("metrics %q does not match regexp %s", desc.Name, r)
+		}
+	}
+}
+
+func TestDescriptionsSorted(t *testing.T) {
+	descriptions := metrics.All()
+	if !sort.SliceIsSorted(descriptions, func(i, j int) bool {
+		return descriptions[i].Name < descriptions[j].Name
+	}) {
+		t.Error("metric descriptions are not sorted by name")
+	}
+}
+
+func TestDescriptionKinds(t *testing.T) {
+	descriptions := metrics.All()
+	samples := make([]metrics.Sample, len(descriptions)+1)
+	for i, desc := range descriptions {
+		samples[i].Name = desc.Name
+	}
+	samples[len(descriptions)].Name = "/not/a/metric:bytes"
+	metrics.Read(samples)
+	for i, desc := range descriptions {
+		if got := samples[i].Value.Kind(); got != desc.Kind {
+			t.Errorf("metric %q has kind %d, want %d", desc.Name, got, desc.Kind)
+		}
+	}
+	if got := samples[len(descriptions)].Value.Kind(); got != metrics.KindBad {
+		t.Errorf("unknown metric has kind %d, want KindBad", got)
+	}
+}
+
+func wrap(prefix, text string, width int) string {
+	var lines []string
+	line := prefix
+	for _, word := range strings.Fields(text) {
+		if len(line) > len(prefix) && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = prefix
+		}
+		if len(line) > len(prefix) {
+			line += " "
+		}
+		line += word
+	}
+	lines = append(lines, line)
+	return strings.Join(lines, "\n")
+}
+
+func formatDesc(descs []metrics.Description) string {
+	var b bytes.Buffer
+	for _, desc := range descs {
+		b.WriteString("\t" + desc.Name + "\n")
+		b.WriteString(wrap("\t\t", desc.Description, 72) + "\n\n")
+	}
+	return b.String()
+}
+
+func TestDocs(t *testing.T) {
+	doc, err := ioutil.ReadFile("doc.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const header = "Below is the full list of supported metrics, ordered lexicographically.\n\n"
+	i := bytes.Index(doc, []byte(header))
+	j := bytes.LastIndex(doc, []byte("*/"))
+	if i < 0 || j < i {
+		t.Fatal("could not find list of supported metrics in doc.go")
+	}
+	got := string(doc[i+len(header) : j])
+	if want := formatDesc(metrics.All()); got != want {
+		t.Errorf("doc.go is out of date with metric descriptions; want list:\n%s", want)
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package runtime_test
+
+import (
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func prepareAllMetricsSamples() (map[string]metrics.Description, []metrics.Sample) {
+	all := metrics.All()
+	samples := make([]metrics.Sample, len(all))
+	descs := make(map[string]metrics.Description)
+	for i := range all {
+		samples[i].Name = all[i].Name
+		descs[all[i].Name] = all[i]
+	}
+	return descs, samples
+}
+
+func TestReadMetrics(t *testing.T) {
+	// Tests whether readMetrics produces values aligning
+	// with ReadMemStats while the world is stopped.
+	var mstats runtime.MemStats
+	_, samples := prepareAllMetricsSamples()
+	// Read once so that histogram values are allocated
+	// before the world is stopped.
+	metrics.Read(samples)
+	runtime.ReadMetricsSlow(&mstats, unsafe.Pointer(&samples[0]), len(samples), cap(samples))
+
+	checkUint64 := func(t *testing.T, m string, got, want uint64) {
+		t.Helper()
+		if got != want {
+			t.Errorf("metric %q: got %d, want %d", m, got, want)
+		}
+	}
+
+	var tinyAllocs uint64
+	for i := range samples {
+		if samples[i].Name == "/gc/heap/tiny/allocs:objects" {
+			tinyAllocs = samples[i].Value.Uint64()
+		}
+	}
+	for i := range samples {
+		name := samples[i].Name
+		switch name {
+		case "/gc/cycles/forced:gc-cycles":
+			checkUint64(t, name, samples[i].Value.Uint64(), uint64(mstats.NumForcedGC))
+		case "/gc/cycles/total:gc-cycles":
+			checkUint64(t, name, samples[i].Value.Uint64(), uint64(mstats.NumGC))
+		case "/gc/gomemlimit:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), uint64(debug.SetMemoryLimit(-1)))
+		case "/gc/heap/allocs:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.TotalAlloc)
+		case "/gc/heap/allocs:objects":
+			checkUint64(t, name, samples[i].Value.Uint64()+tinyAllocs, mstats.Mallocs)
+		case "/gc/heap/frees:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.TotalAlloc-mstats.HeapAlloc)
+		case "/gc/heap/frees:objects":
+			checkUint64(t, name, samples[i].Value.Uint64()+tinyAllocs, mstats.Frees)
+		case "/gc/heap/goal:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.NextGC)
+		case "/gc/heap/objects:objects":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.HeapObjects)
+		case "/memory/classes/heap/free:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.HeapIdle-mstats.HeapReleased)
+		case "/memory/classes/heap/objects:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.HeapAlloc)
+		case "/memory/classes/heap/released:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.HeapReleased)
+		case "/memory/classes/heap/stacks:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.StackInuse)
+		case "/memory/classes/heap/unused:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.HeapInuse-mstats.HeapAlloc)
+		case "/memory/classes/metadata/mcache/free:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.MCacheSys-mstats.MCacheInuse)
+		case "/memory/classes/metadata/mcache/inuse:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.MCacheInuse)
+		case "/memory/classes/metadata/mspan/free:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.MSpanSys-mstats.MSpanInuse)
+		case "/memory/classes/metadata/mspan/inuse:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.MSpanInuse)
+		case "/memory/classes/metadata/other:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.GCSys)
+		case "/memory/classes/os-stacks:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.StackSys-mstats.StackInuse)
+		case "/memory/classes/other:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.OtherSys)
+		case "/memory/classes/profiling/buckets:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.BuckHashSys)
+		case "/memory/classes/total:bytes":
+			checkUint64(t, name, samples[i].Value.Uint64(), mstats.Sys)
+		case "/sched/gomaxprocs:threads":
+			checkUint64(t, name, samples[i].Value.Uint64(), uint64(runtime.GOMAXPROCS(-1)))
+		}
+	}
+}
+
+func TestReadMetricsConsistency(t *testing.T) {
+	// Tests whether readMetrics produces consistent, sensible values.
+	// The values are read concurrently with the runtime doing other
+	// things (e.g. allocating) so what we read can't reasonably compared
+	// to runtime values.
+
+	// Run a couple of GC cycles and exercise the scheduler and
+	// sync.Mutex to make sure the histograms have some samples.
+	runtime.GC()
+	runtime.GC()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	mu.Lock()
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mu.Lock()
+			mu.Unlock()
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	mu.Unlock()
+	wg.Wait()
+
+	// Read all the supported metrics through the metrics package.
+	descs, samples := prepareAllMetricsSamples()
+	metrics.Read(samples)
+
+	// Check to make sure the values we read line up with other values we read.
+	var totalVirtual struct {
+		got, want uint64
+	}
+	var objects struct {
+		alloc, free   uint64
+		numGC, pauses uint64
+	}
+	var gc struct {
+		numGC, auto, forced uint64
+	}
+	for i := range samples {
+		kind := samples[i].Value.Kind()
+		if want := descs[samples[i].Name].Kind; kind != want {
+			t.Errorf("supported metric %q has unexpected kind: got %d, want %d", samples[i].Name, kind, want)
+			continue
+		}
+		if samples[i].Name != "/memory/classes/total:bytes" && strings.HasPrefix(samples[i].Name, "/memory/classes") {
+			v := samples[i].Value.Uint64()
+			totalVirtual.want += v
+
+			// None of these stats should ever get this big.
+			// If they do, there's probably overflow involved,
+			// usually due to bad accounting.
+			if int64(v) < 0 {
+				t.Errorf("%q has high/negative value: %d", samples[i].Name, v)
+			}
+		}
+		switch samples[i].Name {
+		case "/memory/classes/total:bytes":
+			totalVirtual.got = samples[i].Value.Uint64()
+		case "/gc/heap/allocs:objects":
+			objects.alloc = samples[i].Value.Uint64()
+		case "/gc/heap/frees:objects":
+			objects.free = samples[i].Value.Uint64()
+		case "/gc/cycles/automatic:gc-cycles":
+			gc.auto = samples[i].Value.Uint64()
+		case "/gc/cycles/forced:gc-cycles":
+			gc.forced = samples[i].Value.Uint64()
+		case "/gc/cycles/total:gc-cycles":
+			gc.numGC = samples[i].Value.Uint64()
+			objects.numGC = gc.numGC
+		case "/gc/pauses:seconds":
+			h := samples[i].Value.Float64Histogram()
+			checkHistogram(t, samples[i].Name, h)
+			for _, c := range h.Counts {
+				objects.pauses += c
+			}
+		case "/sched/latencies:seconds":
+			h := samples[i].Value.Float64Histogram()
+			checkHistogram(t, samples[i].Name, h)
+			var n uint64
+			for _, c := range h.Counts {
+				n += c
+			}
+			if n == 0 {
+				t.Errorf("%q has no samples", samples[i].Name)
+			}
+		case "/sched/goroutines:goroutines":
+			if samples[i].Value.Uint64() < 1 {
+				t.Error("number of goroutines is less than one")
+			}
+		case "/sync/mutex/wait/total:seconds":
+			if samples[i].Value.Float64() <= 0 {
+				t.Errorf("%q is not positive after a contended sync.Mutex", samples[i].Name)
+			}
+		}
+	}
+	if totalVirtual.got != totalVirtual.want {
+		t.Errorf(`"/memory/classes/total:bytes" does not match sum of /memory/classes/**: got %d, want %d`, totalVirtual.got, totalVirtual.want)
+	}
+	if objects.alloc < objects.free {
+		t.Errorf("allocated objects %d less than freed objects %d", objects.alloc, objects.free)
+	}
+	if gc.numGC < 2 || gc.forced < 2 {
+		t.Errorf("expected at least two forced GC cycles, got %d total, %d forced", gc.numGC, gc.forced)
+	}
+	if gc.auto+gc.forced != gc.numGC {
+		t.Errorf("automatic GC cycles (%d) + forced GC cycles (%d) != total GC cycles (%d)", gc.auto, gc.forced, gc.numGC)
+	}
+	// Each GC cycle has at least one stop-the-world pause.
+	if objects.pauses < objects.numGC {
+		t.Errorf("GC pause histogram has %d samples, want at least %d", objects.pauses, objects.numGC)
+	}
+}
+
+func checkHistogram(t *testing.T, name string, h *metrics.Float64Histogram) {
+	t.Helper()
+	if len(h.Counts) == 0 || len(h.Buckets) != len(h.Counts)+1 {
+		t.Errorf("%q: got %d counts and %d buckets", name, len(h.Counts), len(h.Buckets))
+		return
+	}
+	if !sort.Float64sAreSorted(h.Buckets) {
+		t.Errorf("%q: buckets are not sorted", name)
+	}
+}
+
+func BenchmarkReadMetrics(b *testing.B) {
+	_, samples := prepareAllMetricsSamples()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		metrics.Read(samples)
+	}
+}
@@ -173,6 +173,34 @@ const defaultHeapMinimum = 4 << 20
 // Initialized from $GOGC.  GOGC=off means no GC.
 var gcpercent int32
 
+// memoryLimit is the soft limit on the total amount of memory
+// mapped by the runtime, in bytes. The GC works to keep the
+// runtime's memory use below it by lowering the heap goal.
+// maxInt64 means there is no limit.
+//
+// Initialized from $GOMEMLIMIT. Written with mheap_.lock held;
+// read atomically.
+var memoryLimit int64 = maxInt64
+
+const (
+	// gcMemoryLimitCPUCap is the fraction of CPU time GC cycles
+	// whose heap goal is lowered by the memory limit may use
+	// before the runtime relaxes the limit to avoid a death
+	// spiral. See gcControllerState.updateMemoryLimitGrowth.
+	gcMemoryLimitCPUCap = 0.5
+
+	// gcMemoryLimitMinGrowth and gcMemoryLimitMaxGrowth bound the
+	// heap growth over the marked heap that the memory limit
+	// allows once it has been relaxed.
+	gcMemoryLimitMinGrowth = 1.0 / 16
+	gcMemoryLimitMaxGrowth = 1.0
+
+	// gcMemoryLimitHeadroom is the fraction of the memory limit
+	// kept free to absorb growth of non-heap memory and heap
+	// fragmentation during a cycle.
+	gcMemoryLimitHeadroom = 0.03
+)
+
 func gcinit() {
 	if unsafe.Sizeof(workbuf{}) != _WorkbufSize {
 		throw("size of Workbuf is suboptimal")
@@ -189,8 +217,9 @@ func gcinit() {
 	// This will go into computing the initial GC goal.
 	memstats.heap_marked = uint64(float64(heapminimum) / (1 + memstats.triggerRatio))
 
-	// Set gcpercent from the environment. This will also compute
-	// and set the GC trigger and goal.
+	// Set the memory limit and gcpercent from the environment.
+	// The latter will also compute and set the GC trigger and goal.
+	memoryLimit = readGOMEMLIMIT()
 	_ = setGCPercent(readgogc())
 
 	work.startSema = 1
@@ -208,6 +237,22 @@ func readgogc() int32 {
 	return 100
 }
 
+// readGOMEMLIMIT reads the soft memory limit from the environment.
+// The value is a number of bytes with an optional unit suffix,
+// or "off" for no limit.
+func readGOMEMLIMIT() int64 {
+	p := gogetenv("GOMEMLIMIT")
+	if p == "" || p == "off" {
+		return maxInt64
+	}
+	n, ok := parseByteCount(p)
+	if !ok {
+		print("GOMEMLIMIT=", p, "\n")
+		throw("malformed GOMEMLIMIT; see `go doc runtime/debug.SetMemoryLimit`")
+	}
+	return n
+}
+
 // gcenable is called after the bulk of the runtime initialization,
 // just before we're about to start letting user code run.
 // It kicks off the background sweeper goroutine and enables GC.
@@ -233,6 +278,19 @@ func setGCPercent(in int32) (out int32) {
 	return out
 }
 
+//go:linkname setMemoryLimit runtime/debug.setMemoryLimit
+func setMemoryLimit(in int64) (out int64) {
+	lock(&mheap_.lock)
+	out = memoryLimit
+	if in >= 0 {
+		atomic.Store64((*uint64)(unsafe.Pointer(&memoryLimit)), uint64(in))
+		// Update pacing in response to the limit change.
+		gcSetTriggerRatio(memstats.triggerRatio)
+	}
+	unlock(&mheap_.lock)
+	return out
+}
+
 // Garbage collector phase.
 // Indicates to write barrier and synchronization task to perform.
 var gcphase uint32
@@ -331,8 +389,8 @@ var gcMarkWorkerModeStrings = [...]string{
 // GOMAXPROCS. The high-level design of this algorithm is documented
 // at https://golang.org/s/go15gcpacing.
 //
-// All fields of gcController are used only during a single mark
-// cycle.
+// Except where noted, all fields of gcController are used only
+// during a single mark cycle.
 var gcController gcControllerState
 
 type gcControllerState struct {
@@ -404,6 +462,18 @@ type gcControllerState struct {
 	// beginning of each cycle.
 	fractionalUtilizationGoal float64
 
+	// memoryLimitGrowth is the minimum growth of the heap goal
+	// over heap_marked that the memory limit must allow. It is
+	// raised when cycles constrained by the memory limit use too
+	// much CPU time and decays otherwise. This persists across
+	// cycles and is updated at mark termination.
+	memoryLimitGrowth float64
+
+	// lastCycleEnd is the time at which the last mark termination
+	// finished, or 0 before the first cycle. This persists across
+	// cycles.
+	lastCycleEnd int64
+
 	_ [sys.CacheLineSize]byte
 
 	// fractionalMarkWorkersNeeded is the number of fractional
@@ -431,16 +501,13 @@ func (c *gcControllerState) startCycle() {
 	// real heap_marked may not have a meaningful value (on the
 	// first cycle) or may be much smaller (resulting in a large
 	// error response).
-	if memstats.gc_trigger <= heapminimum {
+	if gcpercent >= 0 && memstats.gc_trigger <= heapminimum {
 		memstats.heap_marked = uint64(float64(memstats.gc_trigger) / (1 + memstats.triggerRatio))
 	}
 
 	// Re-compute the heap goal for this cycle in case something
 	// changed. This is the same calculation we use elsewhere.
-	memstats.next_gc = memstats.heap_marked + memstats.heap_marked*uint64(gcpercent)/100
-	if gcpercent < 0 {
-		memstats.next_gc = ^uint64(0)
-	}
+	memstats.next_gc, memstats.heapGoalLimited = gcHeapGoal()
 
 	// Ensure that the heap goal is at least a little larger than
 	// the current live heap size. This may not be the case if GC
@@ -562,9 +629,9 @@ func (c *gcControllerState) endCycle() float64 {
 	// growth during this cycle and scale that by how far off from
 	// the goal CPU utilization we were (to estimate the heap
 	// growth if we had the desired CPU utilization). The
-	// difference between this estimate and the GOGC-based goal
-	// heap growth is the error.
-	goalGrowthRatio := float64(gcpercent) / 100
+	// difference between this estimate and the goal heap growth
+	// is the error.
+	goalGrowthRatio := gcGoalGrowthRatio(memstats.next_gc, memstats.heapGoalLimited)
 	actualGrowthRatio := float64(memstats.heap_live)/float64(memstats.heap_marked) - 1
 	assistDuration := nanotime() - c.markStartTime
 
@@ -609,6 +676,53 @@ func (c *gcControllerState) endCycle() float64 {
 	return triggerRatio
 }
 
+// updateMemoryLimitGrowth adjusts memoryLimitGrowth based on the
+// fraction of CPU time the GC used since the end of the previous
+// cycle. now is the time at which mark termination is finishing.
+//
+// If the memory limit lowers the heap goal below what the live heap
+// needs, the GC would run back to back and starve the application.
+// To avoid such a death spiral, when a cycle constrained by the limit
+// uses more than gcMemoryLimitCPUCap of the available CPU time, the
+// heap goal is allowed to exceed the limit by a growing fraction of
+// the live heap. The allowance decays again once the GC CPU usage
+// drops.
+//
+// The world must be stopped.
+func (c *gcControllerState) updateMemoryLimitGrowth(now int64) {
+	start := c.lastCycleEnd
+	if start == 0 {
+		start = runtimeInitTime
+	}
+	c.lastCycleEnd = now
+	if !memstats.heapGoalLimited {
+		c.memoryLimitGrowth = 0
+		return
+	}
+	atomic.Store64(&memstats.memoryLimitLastActive, uint64(work.cycles))
+
+	period := now - start
+	if period <= 0 {
+		return
+	}
+	stw := int64(work.stwprocs) * (work.tMark - work.tSweepTerm + now - work.tMarkTerm)
+	gcTime := stw + c.assistTime + c.dedicatedMarkTime + c.fractionalMarkTime
+	utilization := float64(gcTime) / float64(period*int64(gomaxprocs))
+	switch {
+	case utilization > gcMemoryLimitCPUCap:
+		if c.memoryLimitGrowth == 0 {
+			c.memoryLimitGrowth = gcMemoryLimitMinGrowth
+		} else if c.memoryLimitGrowth *= 2; c.memoryLimitGrowth > gcMemoryLimitMaxGrowth {
+			c.memoryLimitGrowth = gcMemoryLimitMaxGrowth
+		}
+		atomic.Store64(&memstats.limiterLastEnabled, uint64(work.cycles))
+	case utilization < gcMemoryLimitCPUCap/2:
+		if c.memoryLimitGrowth /= 2; c.memoryLimitGrowth < gcMemoryLimitMinGrowth {
+			c.memoryLimitGrowth = 0
+		}
+	}
+}
+
 // enlistWorker encourages another dedicated mark worker to start on
 // another P if there are spare worker slots. It is used by putfull
 // when more work is made available.
@@ -745,6 +859,90 @@ func (c *gcControllerState) findRunnableGCWorker(_p_ *p) *g {
 	return gp
 }
 
+// gcHeapGoal returns the heap goal for the next cycle and whether
+// the memory limit lowered it below the GOGC-based goal. The goal is
+// ^0 if GOGC is off and there is no memory limit.
+//
+// This depends on gcpercent, memoryLimit, and memstats.heap_marked.
+//
+// mheap_.lock must be held or the world must be stopped.
+func gcHeapGoal() (goal uint64, limited bool) {
+	// The GOGC-based goal is when the allocated heap has grown
+	// by GOGC/100 over the heap marked by the last cycle.
+	goal = ^uint64(0)
+	if gcpercent >= 0 {
+		goal = memstats.heap_marked + memstats.heap_marked*uint64(gcpercent)/100
+	}
+	if limitGoal := gcMemoryLimitHeapGoal(); limitGoal < goal {
+		goal, limited = limitGoal, true
+	}
+	return goal, limited
+}
+
+// gcMemoryLimitHeapGoal returns the heap goal implied by the memory
+// limit, or ^0 if there is no memory limit.
+//
+// mheap_.lock must be held or the world must be stopped.
+func gcMemoryLimitHeapGoal() uint64 {
+	limit := int64(atomic.Load64((*uint64)(unsafe.Pointer(&memoryLimit))))
+	if limit == maxInt64 {
+		return ^uint64(0)
+	}
+
+	// Subtract the memory the runtime has mapped for things
+	// other than the heap, since the heap has to fit in what is
+	// left. Also leave some headroom for fragmentation and for
+	// non-heap memory that may be allocated during the cycle.
+	nonHeap := memstats.stacks_inuse + atomic.Load64(&memstats.stacks_sys) +
+		atomic.Load64(&memstats.mspan_sys) + atomic.Load64(&memstats.mcache_sys) +
+		atomic.Load64(&memstats.buckhash_sys) + atomic.Load64(&memstats.gc_sys) +
+		atomic.Load64(&memstats.other_sys)
+	headroom := uint64(float64(limit) * gcMemoryLimitHeadroom)
+	goal := uint64(0)
+	if uint64(limit) > nonHeap+headroom {
+		goal = uint64(limit) - nonHeap - headroom
+	}
+
+	// If the GC has been using too much CPU trying to stay under
+	// the limit, let the heap grow past it. See
+	// gcControllerState.updateMemoryLimitGrowth.
+	minGoal := memstats.heap_marked + uint64(float64(memstats.heap_marked)*gcController.memoryLimitGrowth)
+	if goal < minGoal {
+		goal = minGoal
+	}
+	return goal
+}
+
+// gcMemoryLimitExceeded reports whether the memory mapped by the
+// runtime exceeds the memory limit. It reads the statistics without
+// synchronization, so the result is only approximate.
+func gcMemoryLimitExceeded() bool {
+	limit := int64(atomic.Load64((*uint64)(unsafe.Pointer(&memoryLimit))))
+	if limit == maxInt64 {
+		return false
+	}
+	mapped := atomic.Load64(&memstats.heap_sys) - atomic.Load64(&memstats.heap_released) +
+		atomic.Load64(&memstats.stacks_inuse) + atomic.Load64(&memstats.stacks_sys) +
+		atomic.Load64(&memstats.mspan_sys) + atomic.Load64(&memstats.mcache_sys) +
+		atomic.Load64(&memstats.buckhash_sys) + atomic.Load64(&memstats.gc_sys) +
+		atomic.Load64(&memstats.other_sys)
+	return int64(mapped) > limit
+}
+
+// gcGoalGrowthRatio returns the growth over memstats.heap_marked
+// that the heap goal goal represents. limited reports whether goal
+// was lowered by the memory limit; otherwise the ratio is the one
+// implied by GOGC.
+func gcGoalGrowthRatio(goal uint64, limited bool) float64 {
+	if !limited {
+		return float64(gcpercent) / 100
+	}
+	if goal <= memstats.heap_marked || memstats.heap_marked == 0 {
+		return 0
+	}
+	return float64(goal-memstats.heap_marked) / float64(memstats.heap_marked)
+}
+
 // gcSetTriggerRatio sets the trigger ratio and updates everything
 // derived from it: the absolute trigger, the heap goal, mark pacing,
 // and sweep pacing.
@@ -752,20 +950,23 @@ func (c *gcControllerState) findRunnableGCWorker(_p_ *p) *g {
 // This can be called any time. If GC is the in the middle of a
 // concurrent phase, it will adjust the pacing of that phase.
 //
-// This depends on gcpercent, memstats.heap_marked, and
+// This depends on gcpercent, memoryLimit, memstats.heap_marked, and
 // memstats.heap_live. These must be up to date.
 //
 // mheap_.lock must be held or the world must be stopped.
 func gcSetTriggerRatio(triggerRatio float64) {
+	// Compute the next GC goal. See gcHeapGoal.
+	goal, limited := gcHeapGoal()
+
 	// Set the trigger ratio, capped to reasonable bounds.
 	if triggerRatio < 0 {
 		// This can happen if the mutator is allocating very
 		// quickly or the GC is scanning very slowly.
 		triggerRatio = 0
-	} else if gcpercent >= 0 {
+	} else if goal != ^uint64(0) {
 		// Ensure there's always a little margin so that the
 		// mutator assist ratio isn't infinity.
-		maxTriggerRatio := 0.95 * float64(gcpercent) / 100
+		maxTriggerRatio := 0.95 * gcGoalGrowthRatio(goal, limited)
 		if triggerRatio > maxTriggerRatio {
 			triggerRatio = maxTriggerRatio
 		}
@@ -777,20 +978,27 @@ func gcSetTriggerRatio(triggerRatio float64) {
 	// We trigger the next GC cycle when the allocated heap has
 	// grown by the trigger ratio over the marked heap size.
 	trigger := ^uint64(0)
-	if gcpercent >= 0 {
+	if goal != ^uint64(0) {
 		trigger = uint64(float64(memstats.heap_marked) * (1 + triggerRatio))
-		// Don't trigger below the minimum heap size.
-		minTrigger := heapminimum
-		if !gosweepdone() {
-			// Concurrent sweep happens in the heap growth
-			// from heap_live to gc_trigger, so ensure
-			// that concurrent sweep has some heap growth
-			// in which to perform sweeping before we
-			// start the next GC cycle.
-			sweepMin := atomic.Load64(&memstats.heap_live) + sweepMinHeapDistance*uint64(gcpercent)/100
-			if sweepMin > minTrigger {
-				minTrigger = sweepMin
+		// Don't trigger below the minimum heap size. The
+		// memory limit takes precedence over these minimums,
+		// so they only apply to the GOGC-based goal.
+		minTrigger := uint64(0)
+		if !limited {
+			minTrigger = heapminimum
+			if !gosweepdone() {
+				// Concurrent sweep happens in the heap growth
+				// from heap_live to gc_trigger, so ensure
+				// that concurrent sweep has some heap growth
+				// in which to perform sweeping before we
+				// start the next GC cycle.
+				sweepMin := atomic.Load64(&memstats.heap_live) + sweepMinHeapDistance*uint64(gcpercent)/100
+				if sweepMin > minTrigger {
+					minTrigger = sweepMin
+				}
 			}
+		} else if trigger > goal {
+			trigger = goal
 		}
 		if trigger < minTrigger {
 			trigger = minTrigger
@@ -802,20 +1010,14 @@ func gcSetTriggerRatio(triggerRatio float64) {
 	}
 	memstats.gc_trigger = trigger
 
-	// Compute the next GC goal, which is when the allocated heap
-	// has grown by GOGC/100 over the heap marked by the last
-	// cycle.
-	goal := ^uint64(0)
-	if gcpercent >= 0 {
-		goal = memstats.heap_marked + memstats.heap_marked*uint64(gcpercent)/100
-		if goal < trigger {
-			// The trigger ratio is always less than GOGC/100, but
-			// other bounds on the trigger may have raised it.
-			// Push up the goal, too.
-			goal = trigger
-		}
+	if !limited && goal < trigger {
+		// The trigger ratio is always less than GOGC/100, but
+		// other bounds on the trigger may have raised it.
+		// Push up the goal, too.
+		goal = trigger
 	}
 	memstats.next_gc = goal
+	memstats.heapGoalLimited = limited
 	if trace.enabled {
 		traceNextGC()
 	}
@@ -1158,7 +1360,12 @@ func (t gcTrigger) test() bool {
 	if t.kind == gcTriggerAlways {
 		return true
 	}
-	if gcphase != _GCoff || gcpercent < 0 {
+	if gcphase != _GCoff {
+		return false
+	}
+	if gcpercent < 0 && t.kind != gcTriggerHeap {
+		// GOGC is off. The heap trigger may still be set
+		// by the memory limit; otherwise it is ^0.
 		return false
 	}
 	switch t.kind {
@@ -1315,6 +1522,7 @@ func gcStart(mode gcMode, trigger gcTrigger) {
 			now = startTheWorldWithSema(trace.enabled)
 		})
 		work.pauseNS += now - work.pauseStart
+		memstats.gcPauseDist.record(now - work.pauseStart)
 		work.tMark = now
 	} else {
 		if trace.enabled {
@@ -1538,6 +1746,7 @@ func gcMarkTermination(nextTriggerRatio float64) {
 	}
 
 	// Update GC trigger and pacing for the next cycle.
+	gcController.updateMemoryLimitGrowth(nanotime())
 	gcSetTriggerRatio(nextTriggerRatio)
 
 	// Update timing memstats
@@ -1545,6 +1754,7 @@ func gcMarkTermination(nextTriggerRatio float64) {
 	sec, nsec, _ := time_now()
 	unixNow := sec*1e9 + int64(nsec)
 	work.pauseNS += now - work.pauseStart
+	memstats.gcPauseDist.record(now - work.pauseStart)
 	work.tEnd = now
 	atomic.Store64(&memstats.last_gc_unix, uint64(unixNow)) // must be Unix time to make sense to user
 	atomic.Store64(&memstats.last_gc_nanotime, uint64(now)) // monotonic time for us
@@ -772,8 +772,9 @@ func scanstack(gp *g, gcw *gcWork) {
 
 	// Scan the stack.
 	var cache pcvalueCache
+	var conservative bool
 	scanframe := func(frame *stkframe, unused unsafe.Pointer) bool {
-		scanframeworker(frame, &cache, gcw)
+		scanframeworker(frame, &cache, &conservative, gcw)
 		return true
 	}
 	gentraceback(^uintptr(0), ^uintptr(0), 0, gp, 0, nil, 0x7fffffff, scanframe, nil, 0)
@@ -782,10 +783,37 @@ func scanstack(gp *g, gcw *gcWork) {
 }
 
 // Scan a stack frame: local variables and function arguments/results.
+//
+// If *conservative is set, the frame was stopped at an asynchronous
+// safe point and has no stack map for its current PC, so it is
+// scanned conservatively instead.
 //go:nowritebarrier
-func scanframeworker(frame *stkframe, cache *pcvalueCache, gcw *gcWork) {
+func scanframeworker(frame *stkframe, cache *pcvalueCache, conservative *bool, gcw *gcWork) {
 
 	f := frame.fn
+	isAsyncPreempt := f.entry == asyncPreemptPC
+	if *conservative || isAsyncPreempt {
+		if _DebugGC > 1 {
+			print("scanframe conservatively ", funcname(f), "\n")
+		}
+		// Conservatively scan the frame. Unlike the precise
+		// case, this includes the outgoing argument space
+		// since we may have stopped while this function was
+		// setting up a call.
+		if frame.varp > frame.sp {
+			scanConservative(frame.sp, frame.varp-frame.sp, gcw)
+		}
+		if frame.arglen != 0 {
+			scanConservative(frame.argp, frame.arglen, gcw)
+		}
+		// The asyncPreempt frame holds the registers of the
+		// asynchronously stopped frame, so that frame must be
+		// scanned conservatively as well. Frames further up
+		// are stopped at calls and have stack maps.
+		*conservative = isAsyncPreempt
+		return
+	}
+
 	targetpc := frame.continpc
 	if targetpc == 0 {
 		// Frame is dead.
@@ -1089,6 +1117,36 @@ func scanblock(b0, n0 uintptr, ptrmask *uint8, gcw *gcWork) {
 	}
 }
 
+// scanConservative scans block [b, b+n) conservatively, treating
+// any word that points into an allocated heap object as a pointer
+// to that object.
+//
+// This is used to scan stack frames of asynchronously preempted
+// goroutines, which are not at a point with a stack map.
+//
+//go:nowritebarrier
+func scanConservative(b, n uintptr, gcw *gcWork) {
+	for i := uintptr(0); i < n; i += sys.PtrSize {
+		val := *(*uintptr)(unsafe.Pointer(b + i))
+
+		// Check if val points into an in-use heap span.
+		if !inheap(val) {
+			continue
+		}
+		span := spanOfUnchecked(val)
+
+		// Check if val points to an allocated object.
+		idx := span.objIndex(val)
+		if span.isFree(idx) {
+			continue
+		}
+
+		// val points to an allocated object. Mark it.
+		obj := span.base() + idx*span.elemsize
+		greyobject(obj, b, i, heapBitsForAddr(obj), span, gcw, idx)
+	}
+}
+
 // scanobject scans the object starting at b, adding pointers to gcw.
 // b must point to the beginning of a heap object or an oblet.
 // scanobject consults the GC bitmap for the pointer mask and the
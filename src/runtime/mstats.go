@@ -151,6 +151,27 @@ type mstats struct {
 	// unlike heap_live, heap_marked does not change until the
 	// next mark termination.
 	heap_marked uint64
+
+	// gcPauseDist represents the distribution of all GC-related
+	// application pauses in the runtime.
+	//
+	// Each individual pause is counted separately, unlike pause_ns.
+	gcPauseDist timeHistogram
+
+	// memoryLimitLastActive is the number of the last GC cycle
+	// whose heap goal was lowered by the memory limit, or 0 if
+	// there was none. Updated atomically.
+	memoryLimitLastActive uint64
+
+	// limiterLastEnabled is the number of the last GC cycle in
+	// which the memory limit was relaxed because the GC was
+	// using too much CPU time, or 0 if there was none. Updated
+	// atomically.
+	limiterLastEnabled uint64
+
+	// heapGoalLimited indicates that next_gc was lowered by the
+	// memory limit.
+	heapGoalLimited bool
 }
 
 var memstats mstats
@@ -446,6 +467,14 @@ func init() {
 		println(unsafe.Offsetof(memstats.heap_live))
 		throw("memstats.heap_live not aligned to 8 bytes")
 	}
+	if unsafe.Offsetof(memstats.gcPauseDist)%8 != 0 {
+		println(unsafe.Offsetof(memstats.gcPauseDist))
+		throw("memstats.gcPauseDist not aligned to 8 bytes")
+	}
+	if unsafe.Offsetof(memstats.memoryLimitLastActive)%8 != 0 {
+		println(unsafe.Offsetof(memstats.memoryLimitLastActive))
+		throw("memstats.memoryLimitLastActive not aligned to 8 bytes")
+	}
 }
 
 // ReadMemStats populates m with memory allocator statistics.
@@ -0,0 +1,223 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Goroutine preemption
+//
+// A goroutine can be preempted at any safe-point. Currently, there
+// are a few categories of safe-points:
+//
+// 1. A blocked safe-point occurs for the duration that a goroutine is
+//    descheduled, blocked on synchronization, or in a system call.
+//
+// 2. Synchronous safe-points occur when a running goroutine checks
+//    for a preemption request.
+//
+// 3. Asynchronous safe-points occur at any instruction in user code
+//    where the goroutine can be safely paused and a conservative
+//    stack and register scan can find stack roots. The runtime can
+//    stop a goroutine at an async safe-point using a signal.
+//
+// Synchronous safe-points are implemented by overloading the stack
+// bound check in function prologues. To preempt a goroutine at the
+// next synchronous safe-point, the runtime poisons the goroutine's
+// stack bound to a value that will cause the next stack bound check
+// to fail and enter the stack growth implementation, which will
+// detect that it was actually a preemption and redirect to preemption
+// handling.
+//
+// A goroutine running a loop without function calls never reaches a
+// synchronous safe-point. To stop it anyway, preemptM sends a signal
+// to the thread running it. The signal handler checks whether the
+// interrupted instruction is an asynchronous safe-point and, if so,
+// injects a call to asyncPreempt, which saves all registers and
+// enters the scheduler through asyncPreempt2 and preempt_m.
+//
+// An instruction is an asynchronous safe-point if the goroutine is
+// running user code, the compiler has not marked the instruction as
+// unsafe, and there is enough stack to inject the call. The compiler
+// marks the code that must not be interrupted, such as write barrier
+// sequences and nosplit functions, using the PCDATA_UnsafePoint
+// table. Since the interrupted frame has no stack map for its current
+// instruction, the garbage collector scans it and the asyncPreempt
+// frame holding its registers conservatively. Asynchronous preemption
+// can be disabled with GODEBUG=asyncpreemptoff=1.
+
+package runtime
+
+import (
+	"runtime/internal/sys"
+	"unsafe"
+)
+
+// asyncPreempt saves all user registers and calls asyncPreempt2.
+//
+// When stack scanning encounters an asyncPreempt frame, it scans that
+// frame and its parent frame conservatively.
+//
+// asyncPreempt is implemented in assembly.
+func asyncPreempt()
+
+//go:nosplit
+func asyncPreempt2() {
+	gp := getg()
+	gp.asyncSafePoint = true
+	mcall(preempt_m)
+	gp.asyncSafePoint = false
+}
+
+// asyncPreemptStack is the bytes of stack space required to inject an
+// asyncPreempt call.
+var asyncPreemptStack = ^uintptr(0)
+
+func init() {
+	f := findfunc(funcPC(asyncPreempt))
+	total := funcMaxSPDelta(f)
+	f = findfunc(funcPC(asyncPreempt2))
+	total += funcMaxSPDelta(f)
+	// Add some overhead for return PCs, etc.
+	asyncPreemptStack = uintptr(total) + 8*sys.PtrSize
+	if asyncPreemptStack > _StackLimit {
+		// We need asyncPreemptStack <= _StackLimit so that
+		// asyncPreempt can be injected anywhere a nosplit
+		// function could run.
+		println("runtime: asyncPreemptStack=", asyncPreemptStack)
+		throw("async stack too large")
+	}
+}
+
+// preempt_m handles a preemption request for gp, which has stopped
+// either at a synchronous safe point in newstack or at an
+// asynchronous safe point in asyncPreempt2. If the garbage collector
+// asked gp to scan its own stack, preempt_m does so and resumes gp.
+// Otherwise gp yields as if it had called runtime.Gosched.
+//
+// preempt_m runs on the system stack and does not return.
+func preempt_m(gp *g) {
+	// Synchronize with scang.
+	casgstatus(gp, _Grunning, _Gwaiting)
+	if gp.preemptscan {
+		for !castogscanstatus(gp, _Gwaiting, _Gscanwaiting) {
+			// Likely to be racing with the GC as
+			// it sees a _Gwaiting and does the
+			// stack scan. If so, gcworkdone will
+			// be set and gcphasework will simply
+			// return.
+		}
+		if !gp.gcscandone {
+			// gcw is safe because we're on the
+			// system stack.
+			gcw := &gp.m.p.ptr().gcw
+			scanstack(gp, gcw)
+			if gcBlackenPromptly {
+				gcw.dispose()
+			}
+			gp.gcscandone = true
+		}
+		gp.preemptscan = false
+		gp.preempt = false
+		casfrom_Gscanstatus(gp, _Gscanwaiting, _Gwaiting)
+		// This clears gcscanvalid.
+		casgstatus(gp, _Gwaiting, _Grunning)
+		gp.stackguard0 = gp.stack.lo + _StackGuard
+		gogo(&gp.sched) // never return
+	}
+
+	// Act like goroutine called runtime.Gosched.
+	casgstatus(gp, _Gwaiting, _Grunning)
+	gopreempt_m(gp) // never return
+}
+
+// canPreemptM reports whether mp is in a state that is safe to preempt.
+//
+// It is nosplit because it has nosplit callers.
+//
+//go:nosplit
+func canPreemptM(mp *m) bool {
+	return mp.locks == 0 && mp.mallocing == 0 && mp.preemptoff == "" && mp.p.ptr().status == _Prunning
+}
+
+// wantAsyncPreempt reports whether an asynchronous preemption is
+// queued for gp.
+//
+//go:nosplit
+func wantAsyncPreempt(gp *g) bool {
+	return gp.preempt && readgstatus(gp)&^_Gscan == _Grunning
+}
+
+// isAsyncSafePoint reports whether gp at instruction PC is an
+// asynchronous safe point. This indicates that:
+//
+// 1. It's safe to suspend gp and conservatively scan its stack and
+// registers. There are no potentially hidden pointer values and it's
+// not in the middle of an atomic sequence like a write barrier.
+//
+// 2. gp has enough stack space to inject the asyncPreempt call.
+//
+// 3. It's generally safe to interact with the runtime, even if we're
+// in a signal handler stopped here. For example, there are no runtime
+// locks held, so acquiring a runtime lock won't self-deadlock.
+//
+//go:nosplit
+func isAsyncSafePoint(gp *g, pc, sp uintptr) bool {
+	mp := gp.m
+
+	// Only user Gs can have safe points. We check this first
+	// because it's extremely common that we'll catch mp in the
+	// scheduler processing this G preemption.
+	if mp.curg != gp {
+		return false
+	}
+
+	// Check M state.
+	if mp.p == 0 || !canPreemptM(mp) {
+		return false
+	}
+
+	// Check stack space.
+	if sp < gp.stack.lo || sp-gp.stack.lo < asyncPreemptStack {
+		return false
+	}
+
+	// Check if PC is an unsafe-point.
+	f := findfunc(pc)
+	if !f.valid() {
+		// Not Go code.
+		return false
+	}
+	if pcdatavalue(f, _PCDATA_UnsafePoint, pc, nil) == _PCDATA_UnsafePointUnsafe {
+		// Unsafe-point marked by compiler. This includes
+		// write barrier sequences and nosplit functions.
+		return false
+	}
+	if fd := funcdata(f, _FUNCDATA_LocalsPointerMaps); fd == nil || fd == unsafe.Pointer(&no_pointers_stackmap) {
+		// This is assembly code. Don't assume it's
+		// well-formed. We identify assembly code by
+		// checking that it has either no stack map, or
+		// no_pointers_stackmap, which is the stack map
+		// for ones marked as NO_LOCAL_POINTERS.
+		return false
+	}
+	name := funcname(f)
+	if inldata := funcdata(f, _FUNCDATA_InlTree); inldata != nil {
+		inltree := (*[1 << 20]inlinedCall)(inldata)
+		ix := pcdatavalue(f, _PCDATA_InlTreeIndex, pc, nil)
+		if ix >= 0 {
+			name = funcnameFromNameoff(f, inltree[ix].func_)
+		}
+	}
+	if hasprefix(name, "runtime.") ||
+		hasprefix(name, "runtime/internal/") ||
+		hasprefix(name, "reflect.") {
+		// For now we never async preempt the runtime or
+		// anything closely tied to the runtime. Known issues
+		// include: various points in the scheduler ("don't
+		// preempt between here and here"), much of the defer
+		// implementation (untyped info on stack), bulk write
+		// barriers (write barrier check), and reflect.{makeFuncStub,
+		// methodValueCall}.
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,153 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+#include "textflag.h"
+
+// asyncPreempt is injected by the signal handler as a call from the
+// interrupted instruction. On entry, LR holds the interrupted PC and
+// 0(RSP) holds the interrupted LR, which pushCall saved in a 16-byte
+// slot below the interrupted SP. asyncPreempt saves every register
+// the interrupted code may be using, calls asyncPreempt2, restores
+// them, pops the saved LR and jumps back to the interrupted PC.
+//
+// R18 is the platform register, which Go code never uses, so it is
+// free to hold the return PC. R27 (REGTMP) is saved because the
+// assembler may have been in the middle of a multi-instruction
+// expansion that uses it. The assembler does not accept NZCV and
+// FPSR as MOVD operands, so those are accessed with raw MRS/MSR
+// encodings.
+TEXT ·asyncPreempt(SB),NOSPLIT|NOFRAME,$0-0
+	SUB	$496, RSP
+	MOVD	R30, (RSP)
+	MOVD	R0, 8(RSP)
+	MOVD	R1, 16(RSP)
+	MOVD	R2, 24(RSP)
+	MOVD	R3, 32(RSP)
+	MOVD	R4, 40(RSP)
+	MOVD	R5, 48(RSP)
+	MOVD	R6, 56(RSP)
+	MOVD	R7, 64(RSP)
+	MOVD	R8, 72(RSP)
+	MOVD	R9, 80(RSP)
+	MOVD	R10, 88(RSP)
+	MOVD	R11, 96(RSP)
+	MOVD	R12, 104(RSP)
+	MOVD	R13, 112(RSP)
+	MOVD	R14, 120(RSP)
+	MOVD	R15, 128(RSP)
+	MOVD	R16, 136(RSP)
+	MOVD	R17, 144(RSP)
+	MOVD	R19, 152(RSP)
+	MOVD	R20, 160(RSP)
+	MOVD	R21, 168(RSP)
+	MOVD	R22, 176(RSP)
+	MOVD	R23, 184(RSP)
+	MOVD	R24, 192(RSP)
+	MOVD	R25, 200(RSP)
+	MOVD	R26, 208(RSP)
+	MOVD	R27, 216(RSP)
+	WORD	$0xd53b4200	// MRS NZCV, R0
+	MOVD	R0, 224(RSP)
+	WORD	$0xd53b4420	// MRS FPSR, R0
+	MOVD	R0, 232(RSP)
+	FMOVD	F0, 240(RSP)
+	FMOVD	F1, 248(RSP)
+	FMOVD	F2, 256(RSP)
+	FMOVD	F3, 264(RSP)
+	FMOVD	F4, 272(RSP)
+	FMOVD	F5, 280(RSP)
+	FMOVD	F6, 288(RSP)
+	FMOVD	F7, 296(RSP)
+	FMOVD	F8, 304(RSP)
+	FMOVD	F9, 312(RSP)
+	FMOVD	F10, 320(RSP)
+	FMOVD	F11, 328(RSP)
+	FMOVD	F12, 336(RSP)
+	FMOVD	F13, 344(RSP)
+	FMOVD	F14, 352(RSP)
+	FMOVD	F15, 360(RSP)
+	FMOVD	F16, 368(RSP)
+	FMOVD	F17, 376(RSP)
+	FMOVD	F18, 384(RSP)
+	FMOVD	F19, 392(RSP)
+	FMOVD	F20, 400(RSP)
+	FMOVD	F21, 408(RSP)
+	FMOVD	F22, 416(RSP)
+	FMOVD	F23, 424(RSP)
+	FMOVD	F24, 432(RSP)
+	FMOVD	F25, 440(RSP)
+	FMOVD	F26, 448(RSP)
+	FMOVD	F27, 456(RSP)
+	FMOVD	F28, 464(RSP)
+	FMOVD	F29, 472(RSP)
+	FMOVD	F30, 480(RSP)
+	FMOVD	F31, 488(RSP)
+	CALL	·asyncPreempt2(SB)
+	FMOVD	488(RSP), F31
+	FMOVD	480(RSP), F30
+	FMOVD	472(RSP), F29
+	FMOVD	464(RSP), F28
+	FMOVD	456(RSP), F27
+	FMOVD	448(RSP), F26
+	FMOVD	440(RSP), F25
+	FMOVD	432(RSP), F24
+	FMOVD	424(RSP), F23
+	FMOVD	416(RSP), F22
+	FMOVD	408(RSP), F21
+	FMOVD	400(RSP), F20
+	FMOVD	392(RSP), F19
+	FMOVD	384(RSP), F18
+	FMOVD	376(RSP), F17
+	FMOVD	368(RSP), F16
+	FMOVD	360(RSP), F15
+	FMOVD	352(RSP), F14
+	FMOVD	344(RSP), F13
+	FMOVD	336(RSP), F12
+	FMOVD	328(RSP), F11
+	FMOVD	320(RSP), F10
+	FMOVD	312(RSP), F9
+	FMOVD	304(RSP), F8
+	FMOVD	296(RSP), F7
+	FMOVD	288(RSP), F6
+	FMOVD	280(RSP), F5
+	FMOVD	272(RSP), F4
+	FMOVD	264(RSP), F3
+	FMOVD	256(RSP), F2
+	FMOVD	248(RSP), F1
+	FMOVD	240(RSP), F0
+	MOVD	232(RSP), R0
+	WORD	$0xd51b4420	// MSR R0, FPSR
+	MOVD	224(RSP), R0
+	WORD	$0xd51b4200	// MSR R0, NZCV
+	MOVD	216(RSP), R27
+	MOVD	208(RSP), R26
+	MOVD	200(RSP), R25
+	MOVD	192(RSP), R24
+	MOVD	184(RSP), R23
+	MOVD	176(RSP), R22
+	MOVD	168(RSP), R21
+	MOVD	160(RSP), R20
+	MOVD	152(RSP), R19
+	MOVD	144(RSP), R17
+	MOVD	136(RSP), R16
+	MOVD	128(RSP), R15
+	MOVD	120(RSP), R14
+	MOVD	112(RSP), R13
+	MOVD	104(RSP), R12
+	MOVD	96(RSP), R11
+	MOVD	88(RSP), R10
+	MOVD	80(RSP), R9
+	MOVD	72(RSP), R8
+	MOVD	64(RSP), R7
+	MOVD	56(RSP), R6
+	MOVD	48(RSP), R5
+	MOVD	40(RSP), R4
+	MOVD	32(RSP), R3
+	MOVD	24(RSP), R2
+	MOVD	16(RSP), R1
+	MOVD	8(RSP), R0
+	MOVD	496(RSP), R30
+	MOVD	(RSP), R18
+	ADD	$512, RSP
+	JMP	(R18)
@@ -0,0 +1,12 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !amd64,!arm64
+
+#include "textflag.h"
+
+// Asynchronous preemption is not implemented on this architecture,
+// so the signal handler never injects a call to asyncPreempt.
+TEXT ·asyncPreempt(SB),NOSPLIT|NOFRAME,$0-0
+	UNDEF
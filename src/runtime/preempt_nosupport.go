@@ -0,0 +1,17 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !linux !amd64,!arm64
+
+package runtime
+
+// Asynchronous preemption is only implemented on linux/amd64 and
+// linux/arm64. Elsewhere, goroutines are only preempted at
+// synchronous safe points.
+
+const preemptMSupported = false
+
+func preemptM(mp *m) {
+	throw("preemptM not implemented")
+}
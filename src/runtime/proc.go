@@ -12,6 +12,11 @@ import (
 
 var buildVersion = sys.TheVersion
 
+// modinfo holds the build information recorded by the go command.
+// It is set using cmd/go/internal/load.ModInfoProg, which defines
+// the variable in package main.
+var modinfo string
+
 // Goroutine scheduler
 // The scheduler's job is to distribute ready-to-run goroutines over worker threads.
 //
@@ -454,6 +459,11 @@ const (
 	_GoidCacheBatch = 16
 )
 
+// gTrackingPeriod is the number of transitions out of _Grunning between
+// latency tracking runs for a G. Tracking every transition would make
+// the scheduler noticeably slower, so only a sample is measured.
+const gTrackingPeriod = 8
+
 // The bootstrap sequence is:
 //
 //	call osinit
@@ -507,6 +517,11 @@ func schedinit() {
 		// to ensure runtime·buildVersion is kept in the resulting binary.
 		buildVersion = "unknown"
 	}
+	if len(modinfo) == 1 {
+		// Condition should never trigger. This code just serves
+		// to ensure runtime·modinfo is kept in the resulting binary.
+		modinfo = ""
+	}
 }
 
 func dumpgstatus(gp *g) {
@@ -801,6 +816,37 @@ func casgstatus(gp *g, oldval, newval uint32) {
 	if newval == _Grunning {
 		gp.gcscanvalid = false
 	}
+
+	// Handle tracking for scheduling latencies.
+	if oldval == _Grunning {
+		// Track every gTrackingPeriod'th time a goroutine
+		// transitions out of running.
+		if gp.trackingSeq%gTrackingPeriod == 0 {
+			gp.tracking = true
+		}
+		gp.trackingSeq++
+	}
+	if gp.tracking {
+		if oldval == _Grunnable {
+			// We transitioned out of runnable, so measure how much
+			// time we spent in this state and add it to
+			// runnableTime.
+			gp.runnableTime += nanotime() - gp.runnableStamp
+			gp.runnableStamp = 0
+		}
+		if newval == _Grunnable {
+			// We just transitioned into runnable, so record what
+			// time that happened.
+			gp.runnableStamp = nanotime()
+		} else if newval == _Grunning {
+			// We're transitioning into running, so turn off
+			// tracking and record how much time we spent in
+			// runnable.
+			gp.tracking = false
+			sched.timeToRun.record(gp.runnableTime)
+			gp.runnableTime = 0
+		}
+	}
 }
 
 // casgstatus(gp, oldstatus, Gcopystack), assuming oldstatus is Gwaiting or Grunnable.
@@ -835,6 +881,7 @@ func scang(gp *g, gcw *gcWork) {
 	// See http://golang.org/cl/21503 for justification of the yield delay.
 	const yieldDelay = 10 * 1000
 	var nextYield int64
+	var nextPreemptM int64
 
 	// Endeavor to get gcscandone set to true,
 	// either by doing the stack scan ourselves or by coercing gp to scan itself.
@@ -875,22 +922,32 @@ loop:
 
 		case _Grunning:
 			// Goroutine running. Try to preempt execution so it can scan itself.
-			// The preemption handler (in newstack) does the actual scan.
+			// The preemption handler (preempt_m) does the actual scan.
 
 			// Optimization: if there is already a pending preemption request
 			// (from the previous loop iteration), don't bother with the atomics.
-			if gp.preemptscan && gp.preempt && gp.stackguard0 == stackPreempt {
-				break
+			if !(gp.preemptscan && gp.preempt && gp.stackguard0 == stackPreempt) {
+				// Ask for preemption and self scan.
+				if castogscanstatus(gp, _Grunning, _Gscanrunning) {
+					if !gp.gcscandone {
+						gp.preemptscan = true
+						gp.preempt = true
+						gp.stackguard0 = stackPreempt
+					}
+					casfrom_Gscanstatus(gp, _Gscanrunning, _Grunning)
+				}
 			}
 
-			// Ask for preemption and self scan.
-			if castogscanstatus(gp, _Grunning, _Gscanrunning) {
-				if !gp.gcscandone {
-					gp.preemptscan = true
-					gp.preempt = true
-					gp.stackguard0 = stackPreempt
+			// A goroutine in a loop without calls never notices
+			// the request above, so also send it an asynchronous
+			// preemption, but don't flood its M with signals.
+			if preemptMSupported && debug.asyncpreemptoff == 0 {
+				if now := nanotime(); now >= nextPreemptM {
+					nextPreemptM = now + yieldDelay/2
+					if mp := gp.m; mp != nil {
+						preemptM(mp)
+					}
 				}
-				casfrom_Gscanstatus(gp, _Gscanrunning, _Grunning)
 			}
 		}
 
@@ -3019,6 +3076,10 @@ func newproc1(fn *funcval, argp *uint8, narg int32, nret int32, callerpc uintptr
 		atomic.Xadd(&sched.ngsys, +1)
 	}
 	newg.gcscanvalid = false
+	// Track the initial transition to _Grunnable for a sample of
+	// new goroutines, starting at a random point in the period.
+	newg.trackingSeq = uint8(fastrand())
+	newg.tracking = newg.trackingSeq%gTrackingPeriod == 0
 	casgstatus(newg, _Gdead, _Grunnable)
 
 	if _p_.goidcache == _p_.goidcacheend {
@@ -3931,6 +3992,12 @@ func sysmon() {
 			mheap_.scavenge(int32(nscavenge), uint64(now), uint64(scavengelimit))
 			lastscavenge = now
 			nscavenge++
+		} else if lastscavenge+1e8 < now && gcMemoryLimitExceeded() {
+			// Over the memory limit. Release all free
+			// memory, but no more than every 100ms.
+			mheap_.scavenge(int32(nscavenge), uint64(now), 0)
+			lastscavenge = now
+			nscavenge++
 		}
 		if debug.schedtrace > 0 && lasttrace+int64(debug.schedtrace)*1000000 <= now {
 			lasttrace = now
@@ -4051,6 +4118,13 @@ func preemptone(_p_ *p) bool {
 	// Setting gp->stackguard0 to StackPreempt folds
 	// preemption into the normal stack overflow check.
 	gp.stackguard0 = stackPreempt
+
+	// Request an async preemption of this goroutine, in case it
+	// is running a loop without any calls.
+	if preemptMSupported && debug.asyncpreemptoff == 0 {
+		preemptM(mp)
+	}
+
 	return true
 }
 
@@ -351,6 +351,7 @@ type g struct {
 	preemptscan    bool     // preempted g does scan for gc
 	gcscandone     bool     // g has scanned stack; protected by _Gscan bit in status
 	gcscanvalid    bool     // false at start of gc cycle, true if G has not run since last scan; TODO: remove?
+	asyncSafePoint bool     // set if g is stopped at an asynchronous safe point
 	throwsplit     bool     // must not split stack
 	raceignore     int8     // ignore race detection events
 	sysblocktraced bool     // StartTrace has emitted EvGoInSyscall about this goroutine
@@ -372,6 +373,13 @@ type g struct {
 	timer          *timer         // cached timer for time.Sleep
 	selectDone     uint32         // are we participating in a select and did someone win the race?
 
+	// Scheduling latency tracking. Only a sample of transitions
+	// out of _Grunning are tracked; see gTrackingPeriod.
+	tracking      bool  // whether we're tracking this G for sched latency statistics
+	trackingSeq   uint8 // used to decide whether to track this G
+	runnableStamp int64 // timestamp of when the G last became runnable, only used when tracking
+	runnableTime  int64 // the amount of time spent runnable, cleared when running, only used when tracking
+
 	// Per-G GC state
 
 	// gcAssistBytes is this G's GC assist credit in terms of
@@ -439,6 +447,7 @@ type m struct {
 	startingtrace bool
 	syscalltick   uint32
 	thread        uintptr // thread handle
+	signalPending uint32  // whether a preemption signal is pending; accessed atomically
 
 	// these are here because they are too large to be on the stack
 	// of low-level NOSPLIT functions.
@@ -531,6 +540,15 @@ type schedt struct {
 	goidgen  uint64
 	lastpoll uint64
 
+	// totalMutexWaitTime is the sum of time goroutines have spent
+	// blocked acquiring a sync.Mutex, in nanoseconds.
+	totalMutexWaitTime int64
+
+	// timeToRun is a distribution of scheduling latencies, defined
+	// as the sum of time a G spends in the _Grunnable state before
+	// it transitions to _Grunning.
+	timeToRun timeHistogram
+
 	lock mutex
 
 	midle        muintptr // idle m's waiting for work
@@ -128,6 +128,10 @@ func semacquire1(addr *uint32, lifo bool, profile semaProfileFlags) {
 		}
 		s.acquiretime = t0
 	}
+	var waitStart int64
+	if profile&semaMutexProfile != 0 {
+		waitStart = nanotime()
+	}
 	for {
 		lock(&root.lock)
 		// Add ourselves to nwait to disable "easy case" in semrelease.
@@ -146,6 +150,9 @@ func semacquire1(addr *uint32, lifo bool, profile semaProfileFlags) {
 			break
 		}
 	}
+	if waitStart != 0 {
+		atomic.Xaddint64(&sched.totalMutexWaitTime, nanotime()-waitStart)
+	}
 	if s.releasetime > 0 {
 		blockevent(s.releasetime-t0, 3)
 	}
@@ -92,3 +92,15 @@ func (c *sigctxt) preparePanic(sig uint32, gp *g) {
 	}
 	c.set_rip(uint64(funcPC(sigpanic)))
 }
+
+// pushCall arranges for the interrupted goroutine to call targetPC
+// and then return to the instruction it was stopped at.
+func (c *sigctxt) pushCall(targetPC uintptr) {
+	// Make it look like the signaled instruction called target.
+	pc := uintptr(c.rip())
+	sp := uintptr(c.rsp())
+	sp -= sys.PtrSize
+	*(*uintptr)(unsafe.Pointer(sp)) = pc
+	c.set_rsp(uint64(sp))
+	c.set_rip(uint64(targetPC))
+}
@@ -88,3 +88,16 @@ func (c *sigctxt) preparePanic(sig uint32, gp *g) {
 	c.set_r28(uint64(uintptr(unsafe.Pointer(gp))))
 	c.set_pc(uint64(funcPC(sigpanic)))
 }
+
+// pushCall arranges for the interrupted goroutine to call targetPC
+// and then return to the instruction it was stopped at.
+func (c *sigctxt) pushCall(targetPC uintptr) {
+	// Make it look like the signaled instruction called target.
+	// As in preparePanic, save the LR to the stack so the
+	// interrupted function can still return to its caller.
+	sp := c.sp() - sys.SpAlign // needs only sizeof uint64, but must align the stack
+	c.set_sp(sp)
+	*(*uint64)(unsafe.Pointer(uintptr(sp))) = c.lr()
+	c.set_lr(c.pc())
+	c.set_pc(uint64(targetPC))
+}
@@ -0,0 +1,15 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build darwin dragonfly freebsd nacl netbsd openbsd solaris linux,!amd64,!arm64
+
+package runtime
+
+// No signal is reserved for preemption where asynchronous preemption
+// is not supported, so sighandler never calls doSigPreempt.
+const sigPreempt = 0
+
+func doSigPreempt(gp *g, ctxt *sigctxt) {
+	throw("doSigPreempt not implemented")
+}
@@ -970,7 +970,7 @@ func newstack(ctxt unsafe.Pointer) {
 	// it needs a lock held by the goroutine), that small preemption turns
 	// into a real deadlock.
 	if preempt {
-		if thisg.m.locks != 0 || thisg.m.mallocing != 0 || thisg.m.preemptoff != "" || thisg.m.p.ptr().status != _Prunning {
+		if !canPreemptM(thisg.m) {
 			// Let the goroutine keep running for now.
 			// gp->preempt is set, so it will be preempted next time.
 			gp.stackguard0 = gp.stack.lo + _StackGuard
@@ -1004,38 +1004,7 @@ func newstack(ctxt unsafe.Pointer) {
 		if thisg.m.p == 0 && thisg.m.locks == 0 {
 			throw("runtime: g is running but p is not")
 		}
-		// Synchronize with scang.
-		casgstatus(gp, _Grunning, _Gwaiting)
-		if gp.preemptscan {
-			for !castogscanstatus(gp, _Gwaiting, _Gscanwaiting) {
-				// Likely to be racing with the GC as
-				// it sees a _Gwaiting and does the
-				// stack scan. If so, gcworkdone will
-				// be set and gcphasework will simply
-				// return.
-			}
-			if !gp.gcscandone {
-				// gcw is safe because we're on the
-				// system stack.
-				gcw := &gp.m.p.ptr().gcw
-				scanstack(gp, gcw)
-				if gcBlackenPromptly {
-					gcw.dispose()
-				}
-				gp.gcscandone = true
-			}
-			gp.preemptscan = false
-			gp.preempt = false
-			casfrom_Gscanstatus(gp, _Gscanwaiting, _Gwaiting)
-			// This clears gcscanvalid.
-			casgstatus(gp, _Gwaiting, _Grunning)
-			gp.stackguard0 = gp.stack.lo + _StackGuard
-			gogo(&gp.sched) // never return
-		}
-
-		// Act like goroutine called runtime.Gosched.
-		casgstatus(gp, _Gwaiting, _Grunning)
-		gopreempt_m(gp) // never return
+		preempt_m(gp) // never return
 	}
 
 	// Allocate a bigger segment and move the stack.
@@ -1107,6 +1076,13 @@ func shrinkstack(gp *g) {
 		// stack (see gcBgMarkWorker for explanation).
 		return
 	}
+	if gp.asyncSafePoint {
+		// Only shrink the stack if the goroutine is at a
+		// synchronous safe point. Its innermost frames were
+		// stopped asynchronously and have no stack maps, so
+		// their pointers into the stack cannot be adjusted.
+		return
+	}
 
 	oldsize := gp.stack.hi - gp.stack.lo
 	newsize := oldsize / 2
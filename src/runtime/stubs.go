@@ -307,3 +307,5 @@ func bool2int(x bool) int {
 	// exactly what you would want it to.
 	return int(uint8(*(*uint8)(unsafe.Pointer(&x))))
 }
+
+var no_pointers_stackmap uint64 // defined in assembly, for NO_LOCAL_POINTERS macro
@@ -36,6 +36,7 @@
 #define SYS_gettid		178
 #define SYS_kill		129
 #define SYS_tkill		130
+#define SYS_tgkill		131
 #define SYS_futex		98
 #define SYS_sched_getaffinity	123
 #define SYS_exit_group		94
@@ -166,6 +167,20 @@ TEXT runtime·raiseproc(SB),NOSPLIT,$-8
 	SVC
 	RET
 
+TEXT runtime·getpid(SB),NOSPLIT,$-8-8
+	MOVD	$SYS_getpid, R8
+	SVC
+	MOVD	R0, ret+0(FP)
+	RET
+
+TEXT runtime·tgkill(SB),NOSPLIT,$-8
+	MOVD	tgid+0(FP), R0
+	MOVD	tid+8(FP), R1
+	MOVD	sig+16(FP), R2
+	MOVD	$SYS_tgkill, R8
+	SVC
+	RET
+
 TEXT runtime·setitimer(SB),NOSPLIT,$-8-24
 	MOVW	mode+0(FP), R0
 	MOVD	new+8(FP), R1
@@ -6,10 +6,14 @@ package testing_test
 
 import (
 	"bytes"
+	"os"
 	"runtime"
+	"sort"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"text/template"
+	"time"
 )
 
 var roundDownTests = []struct {
@@ -94,6 +98,113 @@ func TestRunParallelFail(t *testing.T) {
 	})
 }
 
+func TestBenchmarkResultString(t *testing.T) {
+	for _, tt := range []struct {
+		r    testing.BenchmarkResult
+		want string
+	}{
+		{testing.BenchmarkResult{N: 100, T: 240 * time.Millisecond}, "     100\t   2400000 ns/op"},
+		{testing.BenchmarkResult{N: 100, T: 4000}, "     100\t        40.0 ns/op"},
+		{testing.BenchmarkResult{N: 100, T: 200}, "     100\t         2.00 ns/op"},
+		{testing.BenchmarkResult{N: 1, T: 2 * time.Second, Bytes: 1e6}, "       1\t2000000000 ns/op\t   0.50 MB/s"},
+		{
+			testing.BenchmarkResult{N: 1, T: 1, Extra: map[string]float64{"ns/op": 0}},
+			"       1",
+		},
+		{
+			testing.BenchmarkResult{N: 1, T: 1, Extra: map[string]float64{"ns/op": 12.5}},
+			"       1\t        12.50 ns/op",
+		},
+		{
+			testing.BenchmarkResult{N: 1, T: 1, Extra: map[string]float64{"frobs/op": 0.25, "B/op": 5, "allocs/op": 1, "bars/op": 1234}},
+			"       1\t         1.00 ns/op\t      1234 bars/op\t         0.2500 frobs/op",
+		},
+	} {
+		if got := tt.r.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestBenchmarkResultExtraOverrides(t *testing.T) {
+	r := testing.BenchmarkResult{
+		N:         10,
+		T:         100,
+		MemAllocs: 50,
+		MemBytes:  500,
+		Extra:     map[string]float64{"ns/op": 3, "allocs/op": 2, "B/op": 1},
+	}
+	if got := r.NsPerOp(); got != 3 {
+		t.Errorf("NsPerOp() = %d, want 3", got)
+	}
+	if got := r.AllocsPerOp(); got != 2 {
+		t.Errorf("AllocsPerOp() = %d, want 2", got)
+	}
+	if got := r.AllocedBytesPerOp(); got != 1 {
+		t.Errorf("AllocedBytesPerOp() = %d, want 1", got)
+	}
+}
+
+func TestReportMetric(t *testing.T) {
+	res := testing.Benchmark(func(b *testing.B) {
+		b.ReportMetric(12345, "ns/op")
+		b.ReportMetric(0.2, "frobs/op")
+	})
+	// Test built-in overriding.
+	if res.NsPerOp() != 12345 {
+		t.Errorf("NsPerOp: expected %v, actual %v", 12345, res.NsPerOp())
+	}
+	// Test stringing.
+	res.N = 1 // Make the output stable
+	want := "       1\t     12345 ns/op\t         0.2000 frobs/op"
+	if want != res.String() {
+		t.Errorf("expected %q, actual %q", want, res.String())
+	}
+	var keys []string
+	for k := range res.Extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if got := strings.Join(keys, " "); got != "frobs/op ns/op" {
+		t.Errorf("Extra has keys %q, want %q", got, "frobs/op ns/op")
+	}
+}
+
+func TestReportMetricBadUnit(t *testing.T) {
+	for _, unit := range []string{"", "frobs per op"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("ReportMetric(1, %q) did not panic", unit)
+				}
+			}()
+			new(testing.B).ReportMetric(1, unit)
+		}()
+	}
+}
+
+func TestBenchmarkTempDir(t *testing.T) {
+	// Cleanups run after each round of a benchmark, removing the
+	// parent of the directories returned by TempDir. Later rounds
+	// must still get a usable directory.
+	var rounds, ok int
+	testing.Benchmark(func(b *testing.B) {
+		rounds++
+		dir := b.TempDir()
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("round %d: %v", rounds, err)
+			return
+		}
+		ok++
+	})
+	if rounds < 2 {
+		t.Fatalf("benchmark ran %d rounds, want at least 2", rounds)
+	}
+	if ok != rounds {
+		t.Errorf("TempDir succeeded in %d of %d rounds", ok, rounds)
+	}
+}
+
 func ExampleB_RunParallel() {
 	// Parallel benchmark for text/template.Template.Execute on a single object.
 	testing.Benchmark(func(b *testing.B) {
@@ -111,3 +222,21 @@ func ExampleB_RunParallel() {
 		})
 	})
 }
+
+func ExampleB_ReportMetric() {
+	// This reports a custom benchmark metric relevant to a
+	// specific algorithm (in this case, sorting).
+	testing.Benchmark(func(b *testing.B) {
+		var compares int64
+		for i := 0; i < b.N; i++ {
+			s := []int{5, 4, 3, 2, 1}
+			sort.Slice(s, func(i, j int) bool {
+				compares++
+				return s[i] < s[j]
+			})
+		}
+		// This metric is per-operation, so divide by b.N and
+		// report it as a "/op" unit.
+		b.ReportMetric(float64(compares)/float64(b.N), "compares/op")
+	})
+}
@@ -0,0 +1,619 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testing
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	matchFuzz        = flag.String("test.fuzz", "", "run the fuzz target matching `regexp`")
+	fuzzDuration     durationOrCountFlag
+	minimizeDuration = durationOrCountFlag{d: 60 * time.Second}
+
+	// The directory in which the fuzzing engine keeps inputs that
+	// expanded coverage, so that later runs can start from them.
+	// It is set by "go test" and is not meant to be used directly.
+	fuzzCacheDir = flag.String("test.fuzzcachedir", "", "store interesting fuzzing inputs in `dir` (for use only by cmd/go)")
+
+	// Whether this process is a worker started by the fuzzing engine
+	// to run inputs, rather than the test binary started by "go test".
+	isFuzzWorker = flag.Bool("test.fuzzworker", false, "coordinate with the parent process to fuzz random values (for use only by the fuzzing engine)")
+)
+
+func init() {
+	flag.Var(&fuzzDuration, "test.fuzztime", "time to spend fuzzing, or `d` executions if of the form Nx; default is to run indefinitely")
+	flag.Var(&minimizeDuration, "test.fuzzminimizetime", "time to spend minimizing a failing input, or `d` executions if of the form Nx")
+}
+
+// durationOrCountFlag is a flag holding either a duration or,
+// when written with an "x" suffix as in "100x", a count.
+type durationOrCountFlag struct {
+	d time.Duration
+	n int64
+}
+
+func (f *durationOrCountFlag) String() string {
+	if f.n > 0 {
+		return fmt.Sprintf("%dx", f.n)
+	}
+	return f.d.String()
+}
+
+func (f *durationOrCountFlag) Set(s string) error {
+	if strings.HasSuffix(s, "x") {
+		n, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+		if err != nil || n <= 0 {
+			return errors.New("invalid count")
+		}
+		*f = durationOrCountFlag{n: n}
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return errors.New("invalid duration")
+	}
+	*f = durationOrCountFlag{d: d}
+	return nil
+}
+
+// corpusDir is the directory, relative to the package being tested,
+// holding the seed corpus files of each fuzz target.
+const corpusDir = "testdata/fuzz"
+
+// An internal type but exported because it is cross-package; part of the implementation
+// of the "go test" command.
+type InternalFuzzTarget struct {
+	Name string
+	Fn   func(f *F)
+}
+
+// F is a type passed to fuzz targets.
+//
+// A fuzz target has the form
+//
+//	func FuzzXxx(f *testing.F)
+//
+// It may add seed inputs with f.Add and must then call f.Fuzz
+// with the fuzz function to run:
+//
+//	func FuzzHex(f *testing.F) {
+//		f.Add([]byte{0x12, 0xab})
+//		f.Fuzz(func(t *testing.T, in []byte) {
+//			out, err := hex.DecodeString(hex.EncodeToString(in))
+//			if err != nil || !bytes.Equal(in, out) {
+//				t.Fatalf("%x: round trip gave %x, %v", in, out, err)
+//			}
+//		})
+//	}
+//
+// By default, "go test" runs the fuzz function once for each seed input:
+// those added with f.Add and those stored in files in the testdata/fuzz/FuzzXxx
+// directory, each as a subtest. With the -fuzz flag, "go test" instead
+// generates new inputs by mutating the seed inputs, guided by coverage of
+// the code under test. When an input makes the fuzz function fail, it is
+// minimized and written to testdata/fuzz/FuzzXxx, so that it is run as a
+// seed input by later tests.
+//
+// Methods such as Log, Error, and Skip may be called on an F before
+// f.Fuzz; inside the fuzz function, use the corresponding methods of T.
+type F struct {
+	common
+	context     *fuzzContext
+	testContext *testContext
+	corpus      []corpusEntry // seed inputs added with Add
+	fuzzCalled  bool
+}
+
+var _ TB = (*F)(nil)
+
+// corpusEntry is an alias to the same type as internal/fuzz.CorpusEntry.
+// We use a type alias because we don't want to export this type, and we can't
+// import internal/fuzz from testing.
+type corpusEntry = struct {
+	Path   string
+	Data   []byte
+	Values []interface{}
+	IsSeed bool
+}
+
+// fuzzContext holds the fields common to all fuzz targets in a test binary.
+type fuzzContext struct {
+	deps testDeps
+	mode fuzzMode
+}
+
+type fuzzMode uint8
+
+const (
+	seedCorpusOnly  fuzzMode = iota // run the fuzz function on the seed corpus
+	fuzzCoordinator                 // run the fuzzing engine
+	fuzzWorker                      // run inputs sent by the fuzzing engine
+)
+
+// supportedTypes lists the types a fuzz function may take
+// after its *T argument.
+var supportedTypes = map[reflect.Type]bool{
+	reflect.TypeOf(([]byte)("")):  true,
+	reflect.TypeOf((string)("")):  true,
+	reflect.TypeOf((bool)(false)): true,
+	reflect.TypeOf((byte)(0)):     true,
+	reflect.TypeOf((rune)(0)):     true,
+	reflect.TypeOf((float32)(0)):  true,
+	reflect.TypeOf((float64)(0)):  true,
+	reflect.TypeOf((int)(0)):      true,
+	reflect.TypeOf((int8)(0)):     true,
+	reflect.TypeOf((int16)(0)):    true,
+	reflect.TypeOf((int32)(0)):    true,
+	reflect.TypeOf((int64)(0)):    true,
+	reflect.TypeOf((uint)(0)):     true,
+	reflect.TypeOf((uint8)(0)):    true,
+	reflect.TypeOf((uint16)(0)):   true,
+	reflect.TypeOf((uint32)(0)):   true,
+	reflect.TypeOf((uint64)(0)):   true,
+}
+
+// Add adds the arguments to the seed corpus of the fuzz target.
+// The arguments must match the types of the arguments of the fuzz function,
+// after its *T. Add must be called before Fuzz.
+func (f *F) Add(args ...interface{}) {
+	if f.fuzzCalled {
+		panic("testing: F.Add called after or inside F.Fuzz")
+	}
+	var values []interface{}
+	for i := range args {
+		if t := reflect.TypeOf(args[i]); !supportedTypes[t] {
+			panic(fmt.Sprintf("testing: unsupported type to Add %v", t))
+		}
+		values = append(values, args[i])
+	}
+	f.corpus = append(f.corpus, corpusEntry{Path: fmt.Sprintf("seed#%d", len(f.corpus)), Values: values, IsSeed: true})
+}
+
+// Fuzz runs the fuzz function, ff. When fuzzing, if ff fails for a
+// generated set of arguments, those arguments are minimized and added
+// to the seed corpus in testdata.
+//
+// ff must be a function with no return value whose first argument is *T
+// and whose remaining arguments are the types to be fuzzed. For example:
+//
+//	f.Fuzz(func(t *testing.T, b []byte, i int) { ... })
+//
+// The following types are allowed: []byte, string, bool, byte, rune,
+// float32, float64, int, int8, int16, int32, int64, uint, uint8, uint16,
+// uint32, uint64.
+//
+// ff should be fast and deterministic, and its behavior should not depend
+// on shared state. It must not retain or modify its []byte arguments, whose
+// memory may be reused by later calls. ff must not call Parallel on its T,
+// nor any methods of f.
+//
+// When fuzzing, ff runs in a separate worker process. An input that makes
+// ff exit, crash the process, or run for more than 10 seconds counts as a
+// failure, like an input that makes ff call t.Fail.
+//
+// When fuzzing, Fuzz does not return until an input causes a failure,
+// the time set with -fuzztime runs out, or the test process is interrupted.
+// Fuzz must be called exactly once, unless the fuzz target is skipped or
+// fails first.
+func (f *F) Fuzz(ff interface{}) {
+	if f.fuzzCalled {
+		panic("testing: F.Fuzz called more than once")
+	}
+	f.fuzzCalled = true
+	f.Helper()
+
+	fn := reflect.ValueOf(ff)
+	fnType := fn.Type()
+	if fnType.Kind() != reflect.Func {
+		panic("testing: F.Fuzz must receive a function")
+	}
+	if fnType.NumIn() < 2 || fnType.In(0) != reflect.TypeOf((*T)(nil)) {
+		panic("testing: fuzz function must receive at least two arguments, where the first argument is a *T")
+	}
+	if fnType.NumOut() != 0 {
+		panic("testing: fuzz function must not return a value")
+	}
+	var types []reflect.Type
+	for i := 1; i < fnType.NumIn(); i++ {
+		t := fnType.In(i)
+		if !supportedTypes[t] {
+			panic(fmt.Sprintf("testing: unsupported type for fuzzing %v", t))
+		}
+		types = append(types, t)
+	}
+
+	// Check the seed inputs added with Add, then load
+	// the seed inputs stored in testdata.
+	for _, e := range f.corpus {
+		if err := checkCorpusTypes(e.Values, types); err != nil {
+			f.Fatalf("%s: %v", e.Path, err)
+		}
+	}
+	dir := corpusDir + "/" + f.name
+	stored, err := f.context.deps.ReadCorpus(dir, types)
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, e := range stored {
+		e.IsSeed = true
+		f.corpus = append(f.corpus, e)
+	}
+
+	// call calls ff with the values of e, passing t as its T.
+	call := func(t *T, e corpusEntry) {
+		args := make([]reflect.Value, 0, len(e.Values)+1)
+		args = append(args, reflect.ValueOf(t))
+		for _, v := range e.Values {
+			args = append(args, reflect.ValueOf(v))
+		}
+		fn.Call(args)
+	}
+
+	switch f.context.mode {
+	case fuzzCoordinator:
+		// The fuzzing engine runs the inputs in a worker process,
+		// so that an input that makes ff exit, crash, or hang is
+		// reported like any other failure.
+		err := f.context.deps.CoordinateFuzzing(
+			fuzzDuration.d, fuzzDuration.n,
+			minimizeDuration.d, minimizeDuration.n,
+			f.corpus, types, dir, *fuzzCacheDir)
+		if err != nil {
+			f.Fail()
+			fmt.Fprintf(f.w, "%v\n", err)
+			if crashErr, ok := err.(fuzzCrashError); ok {
+				crashPath := crashErr.CrashPath()
+				fmt.Fprintf(f.w, "Failing input written to %s\n", crashPath)
+				fmt.Fprintf(f.w, "To re-run:\ngo test -run=%s/%s\n", f.name, baseName(crashPath))
+			}
+		}
+
+	case fuzzWorker:
+		// Each input runs in a T of its own, reporting into a buffer
+		// that becomes the error describing a failure. A panic in ff
+		// is reported as a failure instead of crashing the worker.
+		cov := newFuzzCoverage()
+		run := func(e corpusEntry) error {
+			var buf bytes.Buffer
+			t := &T{
+				common: common{
+					barrier:  make(chan bool),
+					signal:   make(chan bool),
+					name:     f.name,
+					parent:   &common{w: &buf},
+					level:    f.level + 1,
+					inFuzzFn: true,
+				},
+				context: f.testContext,
+			}
+			t.w = indenter{&t.common}
+			cov.reset()
+			go tRunner(t, func(t *T) {
+				defer func() {
+					if err := recover(); err != nil {
+						t.Fail()
+						t.mu.Lock()
+						t.output = append(t.output, fmt.Sprintf("\tpanic: %v\n%s", err, debug.Stack())...)
+						t.mu.Unlock()
+					}
+				}()
+				call(t, e)
+			})
+			<-t.signal
+			if !t.Failed() {
+				return nil
+			}
+			return errors.New(strings.TrimSuffix(buf.String(), "\n"))
+		}
+		if err := f.context.deps.RunFuzzWorker(run, cov.snapshot); err != nil {
+			f.Fatal(err)
+		}
+
+	default:
+		// Run the fuzz function once for each seed input, as a subtest.
+		for _, e := range f.corpus {
+			if shouldFailFast() {
+				break
+			}
+			testName, ok, _ := f.testContext.match.fullName(&f.common, baseName(e.Path))
+			if !ok {
+				continue
+			}
+			t := &T{
+				common: common{
+					barrier:  make(chan bool),
+					signal:   make(chan bool),
+					name:     testName,
+					parent:   &f.common,
+					level:    f.level + 1,
+					chatty:   f.chatty,
+					inFuzzFn: true,
+				},
+				context: f.testContext,
+			}
+			t.w = indenter{&t.common}
+			if t.chatty {
+				root := t.parent
+				for ; root.parent != nil; root = root.parent {
+				}
+				root.mu.Lock()
+				fmt.Fprintf(root.w, "=== RUN   %s\n", t.name)
+				root.mu.Unlock()
+			}
+			e := e
+			go tRunner(t, func(t *T) { call(t, e) })
+			<-t.signal
+		}
+	}
+}
+
+// fuzzCrashError is satisfied by the error returned by CoordinateFuzzing
+// when an input causes a failure.
+type fuzzCrashError interface {
+	error
+
+	// CrashPath returns the path of the file holding the failing input.
+	CrashPath() string
+}
+
+// checkCorpusTypes verifies that vals holds values of the given types.
+func checkCorpusTypes(vals []interface{}, types []reflect.Type) error {
+	if len(vals) != len(types) {
+		return fmt.Errorf("wrong number of values in corpus entry: %d, want %d", len(vals), len(types))
+	}
+	for i, v := range vals {
+		if t := reflect.TypeOf(v); t != types[i] {
+			return fmt.Errorf("mismatched types in corpus entry: value %d has type %v, want %v", i, t, types[i])
+		}
+	}
+	return nil
+}
+
+// baseName returns the last element of the file path p.
+func baseName(p string) string {
+	if i := strings.LastIndexAny(p, "/"+string(os.PathSeparator)); i >= 0 {
+		return p[i+1:]
+	}
+	return p
+}
+
+// fuzzCoverage tracks the coverage counters of the code under test
+// to tell the fuzzing engine which blocks each input executed.
+type fuzzCoverage struct {
+	counters [][]uint32 // counters of each instrumented file, in a fixed order
+	last     []uint32   // counter values before the current input ran
+}
+
+func newFuzzCoverage() *fuzzCoverage {
+	names := make([]string, 0, len(cover.Counters))
+	for name := range cover.Counters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	c := new(fuzzCoverage)
+	n := 0
+	for _, name := range names {
+		c.counters = append(c.counters, cover.Counters[name])
+		n += len(cover.Counters[name])
+	}
+	c.last = make([]uint32, n)
+	return c
+}
+
+// reset records the current counter values, so that the next snapshot
+// reports only the blocks executed after the call to reset.
+// Leaving the counters themselves intact keeps -cover reports accurate.
+func (c *fuzzCoverage) reset() {
+	i := 0
+	for _, counters := range c.counters {
+		for j := range counters {
+			c.last[i] = atomic.LoadUint32(&counters[j])
+			i++
+		}
+	}
+}
+
+// snapshot returns one byte for each counter describing how many times
+// its block ran since the last reset. The counts are grouped into
+// buckets so that only meaningful changes look like new coverage.
+func (c *fuzzCoverage) snapshot() []byte {
+	if len(c.last) == 0 {
+		return nil
+	}
+	snap := make([]byte, len(c.last))
+	i := 0
+	for _, counters := range c.counters {
+		for j := range counters {
+			snap[i] = coverageBucket(atomic.LoadUint32(&counters[j]) - c.last[i])
+			i++
+		}
+	}
+	return snap
+}
+
+func coverageBucket(n uint32) byte {
+	switch {
+	case n == 0:
+		return 0
+	case n == 1:
+		return 1
+	case n == 2:
+		return 2
+	case n == 3:
+		return 4
+	case n <= 7:
+		return 8
+	case n <= 15:
+		return 16
+	case n <= 31:
+		return 32
+	case n <= 127:
+		return 64
+	default:
+		return 128
+	}
+}
+
+func (f *F) report() {
+	if f.parent == nil {
+		return
+	}
+	dstr := fmtDuration(f.duration)
+	format := "--- %s: %s (%s)\n"
+	if f.Failed() {
+		f.flushToParent(format, "FAIL", f.name, dstr)
+	} else if f.chatty {
+		if f.Skipped() {
+			f.flushToParent(format, "SKIP", f.name, dstr)
+		} else {
+			f.flushToParent(format, "PASS", f.name, dstr)
+		}
+	}
+}
+
+// fRunner runs the fuzz target fn, in the same way tRunner runs a test.
+func fRunner(f *F, fn func(*F)) {
+	f.runner = callerName(0)
+
+	// When this goroutine is done, either because fn(f) returned
+	// normally or because a failure triggered a call to runtime.Goexit,
+	// record the duration and send a signal saying that the fuzz target
+	// is done.
+	defer func() {
+		if f.Failed() {
+			atomic.AddUint32(&numFailed, 1)
+		}
+		f.duration += time.Since(f.start)
+		err := recover()
+		if !f.finished && err == nil {
+			err = fmt.Errorf("fuzz target executed panic(nil) or runtime.Goexit")
+		}
+		if err != nil {
+			f.Fail()
+			f.report()
+			panic(err)
+		}
+		f.report()
+		f.done = true
+		f.setRan()
+		f.signal <- true
+	}()
+	defer f.runCleanup(normalPanic)
+
+	f.start = time.Now()
+	fn(f)
+	f.finished = true
+}
+
+// newFuzzTarget returns an F for running the fuzz target called name
+// as a child of root.
+func newFuzzTarget(root *common, name string, fctx *fuzzContext, tctx *testContext) *F {
+	f := &F{
+		common: common{
+			signal:  make(chan bool),
+			barrier: make(chan bool),
+			name:    name,
+			parent:  root,
+			level:   root.level + 1,
+			chatty:  root.chatty,
+		},
+		context:     fctx,
+		testContext: tctx,
+	}
+	f.w = indenter{&f.common}
+	if f.chatty {
+		root.mu.Lock()
+		fmt.Fprintf(root.w, "=== RUN   %s\n", f.name)
+		root.mu.Unlock()
+	}
+	return f
+}
+
+// runFuzzTests runs the fuzz targets matching -test.run, calling each
+// fuzz function on its seed corpus only, without generating new inputs.
+func runFuzzTests(deps testDeps, fuzzTargets []InternalFuzzTarget) (ran, ok bool) {
+	ok = true
+	if len(fuzzTargets) == 0 {
+		return ran, ok
+	}
+	for _, procs := range cpuList {
+		runtime.GOMAXPROCS(procs)
+		for i := uint(0); i < *count; i++ {
+			tctx := newTestContext(*parallel, newMatcher(deps.MatchString, *match, "-test.run"))
+			fctx := &fuzzContext{deps: deps, mode: seedCorpusOnly}
+			root := common{w: os.Stdout, chatty: *chatty}
+			for _, ft := range fuzzTargets {
+				if shouldFailFast() {
+					break
+				}
+				testName, matched, _ := tctx.match.fullName(nil, ft.Name)
+				if !matched {
+					continue
+				}
+				f := newFuzzTarget(&root, testName, fctx, tctx)
+				go fRunner(f, ft.Fn)
+				<-f.signal
+			}
+			ok = ok && !root.Failed()
+			ran = ran || root.ran
+		}
+	}
+	return ran, ok
+}
+
+// runFuzzing runs the one fuzz target matching -test.fuzz using the
+// fuzzing engine, which generates and mutates inputs for its fuzz function.
+// It reports whether no failure was found.
+func runFuzzing(deps testDeps, fuzzTargets []InternalFuzzTarget) (ok bool) {
+	if *matchFuzz == "" {
+		return true
+	}
+	m := newMatcher(deps.MatchString, *matchFuzz, "-test.fuzz")
+	var target *InternalFuzzTarget
+	var targetName string
+	var matched []string
+	for i := range fuzzTargets {
+		name, ok, _ := m.fullName(nil, fuzzTargets[i].Name)
+		if !ok {
+			continue
+		}
+		matched = append(matched, name)
+		target = &fuzzTargets[i]
+		targetName = name
+	}
+	if len(matched) == 0 {
+		fmt.Fprintln(os.Stderr, "testing: warning: no fuzz targets to fuzz")
+		return true
+	}
+	if len(matched) > 1 {
+		fmt.Fprintf(os.Stderr, "testing: will not fuzz, -fuzz matches more than one fuzz target: %v\n", matched)
+		return false
+	}
+
+	// Subtests started by the fuzz function are not filtered by -test.run.
+	tctx := newTestContext(1, newMatcher(deps.MatchString, "", ""))
+	fctx := &fuzzContext{deps: deps, mode: fuzzCoordinator}
+	if *isFuzzWorker {
+		fctx.mode = fuzzWorker
+	}
+	root := common{w: os.Stdout, chatty: *chatty}
+	f := newFuzzTarget(&root, targetName, fctx, tctx)
+	go fRunner(f, target.Fn)
+	<-f.signal
+	return !f.Failed()
+}